@@ -1,11 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strings"
 
 	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/models"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,20 +15,29 @@ import (
 )
 
 type Model struct {
-	err               error
-	width             int
-	lg                *lipgloss.Renderer
-	state             State
-	styles            *Styles
-	spinner           spinner.Model
-	initialScreen     *InitialForm
-	signInScreen      *AuthForm
-	signUpScreen      *AuthForm
-	dashboardScreen   *DashboardScreen
-	clientService     client.GRPCClientProvider
-	credentialsScreen *CredentialsScreen
-	cardsScreen       *CardScreen
-	filesScreen       *FilePicker
+	err                  error
+	width                int
+	lg                   *lipgloss.Renderer
+	state                State
+	styles               *Styles
+	spinner              spinner.Model
+	initialScreen        *InitialForm
+	signInScreen         *AuthForm
+	signUpScreen         *AuthForm
+	masterPasswordScreen *MasterPasswordForm
+	dashboardScreen      *DashboardScreen
+	clientService        client.GRPCClientProvider
+	credentialsScreen    *CredentialsScreen
+	cardsScreen          *CardScreen
+	filesScreen          *FilePicker
+	notesScreen          *NoteScreen
+	fileMetadataScreen   *FileMetadataScreen
+	conflictScreen       *ConflictScreen
+	historyScreen        *HistoryScreen
+	totpScreen           *TOTPScreen
+	passwordScreen       *PasswordScreen
+	deleteAccountScreen  *DeleteAccountScreen
+	program              *tea.Program
 }
 
 type State int
@@ -39,10 +50,18 @@ const (
 	CardForm
 	FileLoad
 	Dashboard
+	MasterPassword
+	NoteForm
+	FileMetadataForm
+	ConflictForm
+	HistoryForm
+	TOTPForm
+	PasswordForm
+	DeleteAccountForm
 )
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.filesScreen.filepicker.Init(), m.spinner.Tick, textinput.Blink)
+	return tea.Batch(m.filesScreen.filepicker.Init(), m.spinner.Tick, textinput.Blink, tickTOTP())
 }
 
 type Styles struct {
@@ -73,8 +92,7 @@ func NewModel(state State) Model {
 	s.Style = lipgloss.NewStyle().Foreground(normalFg)
 
 	m := Model{state: state, spinner: s}
-	clientService := client.NewClientService(":3200")
-	m.clientService = &clientService
+	m.clientService = client.NewClientService(":3200")
 	m.clientService.TryToConnect()
 	m.initialScreen = &InitialForm{SelectedOption: 0}
 	m.signInScreen = NewAuthForm("Please, enter your credentials to SignIn:", func(email, password string) error {
@@ -83,10 +101,18 @@ func NewModel(state State) Model {
 	m.signUpScreen = NewAuthForm("Please, enter your email and create a password to SignUp:", func(email, password string) error {
 		return m.clientService.SignUp(email, password)
 	})
+	m.masterPasswordScreen = NewMasterPasswordForm()
 	m.dashboardScreen = NewDashboardScreen()
 	m.credentialsScreen = NewCredentialsScreen()
 	m.cardsScreen = NewCardScreen()
 	m.filesScreen = NewFilePicker()
+	m.notesScreen = NewNoteScreen()
+	m.fileMetadataScreen = NewFileMetadataScreen()
+	m.conflictScreen = NewConflictScreen()
+	m.historyScreen = NewHistoryScreen()
+	m.totpScreen = NewTOTPScreen()
+	m.passwordScreen = NewPasswordScreen()
+	m.deleteAccountScreen = NewDeleteAccountScreen()
 	m.lg = lipgloss.DefaultRenderer()
 	m.styles = NewStyles(m.lg)
 	return m
@@ -99,6 +125,12 @@ func (m Model) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 	switch message := message.(type) {
 	case tea.WindowSizeMsg:
 		m.width = message.Width
+	case totpTickMsg:
+		m.dashboardScreen.refreshTOTP(&m)
+		return m, tickTOTP()
+	case changeEventMsg:
+		m.dashboardScreen.applyChange(&m, models.ChangeEvent(message))
+		return m, nil
 	case tea.KeyMsg:
 		switch message.Type {
 		case tea.KeyEsc, tea.KeyCtrlC:
@@ -111,9 +143,11 @@ func (m Model) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = Dashboard
 			case CardForm:
 				m.state = Dashboard
-			case FileLoad:
+			case FileLoad, NoteForm, FileMetadataForm, ConflictForm, HistoryForm, PasswordForm, DeleteAccountForm:
 				m.state = Dashboard
-			case Dashboard:
+			case TOTPForm:
+				m.state = m.totpScreen.backState()
+			case Dashboard, MasterPassword:
 				if m.initialScreen.AuthThroughSignIn {
 					m.state = SignIn
 				} else {
@@ -140,10 +174,20 @@ func (m Model) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		_, cmd = m.signUpScreen.Update(&m, message)
 		cmds = append(cmds, cmd)
+	case MasterPassword:
+		var cmd tea.Cmd
+		_, cmd = m.masterPasswordScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
 	case Dashboard:
 		var cmd tea.Cmd
 		_, cmd = m.dashboardScreen.Update(&m, message)
 		cmds = append(cmds, cmd)
+		m.conflictScreen.openReplayConflict(&m)
+		if m.dashboardScreen.sessionExpired != nil {
+			// offline changes wait in local cache until user signs in again
+			m.err, m.dashboardScreen.sessionExpired = m.dashboardScreen.sessionExpired, nil
+			m.state = SignIn
+		}
 	case CredentialsForm:
 		var cmd tea.Cmd
 		_, cmd = m.credentialsScreen.Update(&m, message)
@@ -156,13 +200,45 @@ func (m Model) Update(message tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		_, cmd = m.filesScreen.Update(&m, message)
 		cmds = append(cmds, cmd)
+	case NoteForm:
+		var cmd tea.Cmd
+		_, cmd = m.notesScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case FileMetadataForm:
+		var cmd tea.Cmd
+		_, cmd = m.fileMetadataScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case ConflictForm:
+		var cmd tea.Cmd
+		_, cmd = m.conflictScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case HistoryForm:
+		var cmd tea.Cmd
+		_, cmd = m.historyScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case TOTPForm:
+		var cmd tea.Cmd
+		_, cmd = m.totpScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case PasswordForm:
+		var cmd tea.Cmd
+		_, cmd = m.passwordScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
+	case DeleteAccountForm:
+		var cmd tea.Cmd
+		_, cmd = m.deleteAccountScreen.Update(&m, message)
+		cmds = append(cmds, cmd)
 	}
 	return m, tea.Batch(cmds...)
 }
 
 // View renders the current view of the application based on the model's state.
 func (m Model) View() string {
-	header := m.appBoundaryView("GophKeeper")
+	title := "GophKeeper"
+	if m.state == Dashboard {
+		title += m.syncStatusView()
+	}
+	header := m.appBoundaryView(title)
 	if m.err != nil {
 		header = m.appErrorBoundaryView(m.err.Error())
 	}
@@ -174,10 +250,31 @@ func (m Model) View() string {
 	case SignIn, SignUp:
 		body = m.signInOrSignUpView()
 		footer = "shft+tab back "
+	case MasterPassword:
+		body = m.masterPasswordScreen.View(&m)
+		footer = "shft+tab back "
 	case Dashboard:
 		body, footer = m.dashboardView()
-	case CredentialsForm, CardForm, FileLoad:
+	case CredentialsForm, CardForm, FileLoad, FileMetadataForm:
 		body, footer = m.formView()
+	case NoteForm:
+		body = m.notesScreen.View(&m)
+		footer = "shft+tab back | tab next field | ctrl+s save "
+	case ConflictForm:
+		body = m.conflictScreen.View(&m)
+		footer = "shft+tab discard | ↑/↓ field | ← mine | → theirs | enter save "
+	case HistoryForm:
+		body = m.historyScreen.View(&m)
+		footer = "shft+tab back | ↑/↓ revision | enter restore "
+	case TOTPForm:
+		body = m.totpScreen.View(&m)
+		footer = "shft+tab back | enter submit "
+	case PasswordForm:
+		body = m.passwordScreen.View(&m)
+		footer = "shft+tab back | ↑/↓ field | enter submit "
+	case DeleteAccountForm:
+		body = m.deleteAccountScreen.View(&m)
+		footer = "shft+tab back | ↑/↓ field | enter delete "
 	default:
 		return m.styles.Base.Render("Oh-oh, something crashed... press ctrl+c to quit")
 	}
@@ -187,6 +284,22 @@ func (m Model) View() string {
 	return m.styles.Base.Render(header + "\n" + body + "\n\n" + footer)
 }
 
+// syncStatusView describes connection to server and amount of changes made offline.
+func (m Model) syncStatusView() string {
+	online, pending, dropped := m.clientService.SyncStatus()
+	status := " • online"
+	if !online {
+		status = " • offline"
+	}
+	if pending > 0 {
+		status += fmt.Sprintf(", %d changes waiting for sync", pending)
+	}
+	if dropped > 0 {
+		status += fmt.Sprintf(", %d offline changes rejected by server", dropped)
+	}
+	return status
+}
+
 func (m Model) signInOrSignUpView() string {
 	if m.state == SignIn {
 		return m.signInScreen.View(&m)
@@ -199,11 +312,17 @@ func (m Model) dashboardView() (string, string) {
 	lines := []string{}
 	switch m.dashboardScreen.cursor {
 	case credentials:
-		lines = []string{"shft+tab back", "← menu", "F1 new", "F2 update", "F3 delete", "F4 copy identity", "F5 copy password"}
+		lines = []string{"shft+tab back", "← menu", "F1 new", "F2 update", "F3 delete", "F4 copy identity", "F5 copy password", "F6 copy TOTP code", "F8 history"}
 	case cards:
-		lines = []string{"shft+tab back", "← menu", "F1 new", "F2 update", "F3 delete", "F4 copy number", "F5 copy expiration", "F6 copy holder", "F7 copy CVV"}
+		lines = []string{"shft+tab back", "← menu", "F1 new", "F2 update", "F3 delete", "F4 copy number", "F5 copy expiration", "F6 copy holder", "F7 copy CVV", "F8 history"}
 	case files:
-		lines = []string{"shft+tab back", "← menu", "F1 upload", "F2 download", "F3 delete"}
+		lines = []string{"shft+tab back", "← menu", "F1 upload", "F2 download", "F3 delete", "F4 edit metadata"}
+	case notes:
+		lines = []string{"shft+tab back", "← menu", "F1 new", "F2 update", "F3 delete", "F4 copy text", "F8 history"}
+	case trash:
+		lines = []string{"shft+tab back", "← menu", "F1 restore", "F3 empty trash"}
+	case security:
+		lines = []string{"shft+tab back", "← menu", "F1 enable two-factor authentication", "F2 change password", "F3 disable two-factor authentication", "F4 delete account", "F5 change master password"}
 	}
 	footer := strings.Join(lines, " | ") + " "
 	return body, footer
@@ -218,6 +337,8 @@ func (m Model) formView() (string, string) {
 		body = m.cardsScreen.View(&m)
 	case FileLoad:
 		body = m.filesScreen.View(&m)
+	case FileMetadataForm:
+		body = m.fileMetadataScreen.View(&m)
 	}
 	return body, "shft+tab back "
 }
@@ -253,6 +374,14 @@ func (m Model) appErrorBoundaryView(text string) string {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := newCommand().run(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	logFile, _ := os.OpenFile("debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	defer logFile.Close()
 
@@ -260,7 +389,9 @@ func main() {
 	log.SetOutput(logFile)
 
 	m := NewModel(Initial)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	// model is passed by pointer, so it knows its program by the first Update
+	p := tea.NewProgram(&m, tea.WithAltScreen())
+	m.program = p
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
 		os.Exit(1)
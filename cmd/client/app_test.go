@@ -2,13 +2,18 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
 )
 
 func TestModel_Init(t *testing.T) {
@@ -197,3 +202,47 @@ func TestModel_View(t *testing.T) {
 		})
 	}
 }
+
+func TestModel_syncStatusView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name    string
+		online  bool
+		pending int
+		dropped int
+		want    string
+	}{
+		{name: "Online", online: true, want: " • online"},
+		{name: "Offline", online: false, want: " • offline"},
+		{name: "Offline with changes", online: false, pending: 2, want: " • offline, 2 changes waiting for sync"},
+		{name: "Online with rejected changes", online: true, dropped: 1, want: " • online, 1 offline changes rejected by server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := mock.NewMockGRPCClientProvider(ctrl)
+			gm.EXPECT().SyncStatus().Return(tt.online, tt.pending, tt.dropped)
+			m := NewModel(Dashboard)
+			m.clientService = gm
+			assert.Equal(t, tt.want, m.syncStatusView())
+		})
+	}
+}
+
+func TestModel_SessionExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	m := NewModel(Dashboard)
+	m.clientService = gm
+	gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{}, fmt.Errorf("Sync: %w", client.ErrSessionExpired))
+	m.dashboardScreen.syncVault(&m)
+
+	gm.EXPECT().TakeReplayConflict().Return(nil, nil)
+	updated, _ := m.Update(spinner.TickMsg{})
+	assert.Equal(t, SignIn, updated.(Model).state, "user has to sign in again to replay offline changes")
+	assert.ErrorIs(t, updated.(Model).err, client.ErrSessionExpired)
+}
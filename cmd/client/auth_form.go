@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/mail"
 
+	"github.com/PaBah/GophKeeper/internal/client"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -76,10 +78,15 @@ func (form *AuthForm) handleKeyMsg(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
 func (form *AuthForm) handleEnterKey(m *Model) (*Model, tea.Cmd) {
 	if form.focusIndex == 2 {
 		m.err = form.validateInputs(m)
-		if m.err == nil {
+		switch {
+		case errors.Is(m.err, client.ErrTOTPRequired):
+			m.err = nil
+			m.totpScreen.reset(totpVerify)
+			m.state = TOTPForm
+		case m.err == nil:
 			form.subscribeToChanges(m)
-			m.state = Dashboard
-			m.dashboardScreen.tableNavigation = false
+			m.masterPasswordScreen.reset()
+			m.state = MasterPassword
 		}
 	} else {
 		form.moveFocusForward()
@@ -102,13 +109,16 @@ func (form *AuthForm) validateInputs(m *Model) error {
 func (form *AuthForm) subscribeToChanges(m *Model) {
 	stream, err := m.clientService.SubscribeToChanges(context.Background())
 	if err != nil {
-		log.Fatal(err)
+		log.Println("changes are not tracked, server is unreachable:", err)
+		return
 	}
 
-	go form.handleSubscription(stream, m)
+	go form.handleSubscription(stream, m.clientService, m.program.Send)
 }
 
-func (form *AuthForm) handleSubscription(stream pb.GophKeeperService_SubscribeToChangesClient, m *Model) {
+// handleSubscription passes changes made by other sessions to program as messages,
+// so they are applied to dashboard in Update of model rather than in this goroutine
+func (form *AuthForm) handleSubscription(stream pb.GophKeeperService_SubscribeToChangesClient, clientService client.GRPCClientProvider, send func(tea.Msg)) {
 	for {
 		var resp pb.SubscribeToChangesResponse
 		err := stream.RecvMsg(&resp)
@@ -116,28 +126,15 @@ func (form *AuthForm) handleSubscription(stream pb.GophKeeperService_SubscribeTo
 			break
 		}
 		if err != nil {
-			log.Fatal(err)
+			log.Println("changes are not tracked anymore:", err)
+			return
 		}
-		form.updateDashboardOnChange(m, menuItem(resp.Source))
-	}
-}
-
-func (form *AuthForm) updateDashboardOnChange(m *Model, source menuItem) {
-	switch source {
-	case credentials:
-		if m.dashboardScreen.cursor == credentials {
-			m.dashboardScreen.updateMsg = "GophKeeper: credentials changed, shift → to refresh"
-		}
-	case cards:
-		if m.dashboardScreen.cursor == cards {
-			m.dashboardScreen.updateMsg = "GophKeeper: cards changed, shift → to refresh"
-		}
-	case files:
-		if m.dashboardScreen.cursor == files {
-			m.dashboardScreen.updateMsg = "GophKeeper: files changed, shift → to refresh"
+		event, err := clientService.OpenChangeEvent(&resp)
+		if err != nil {
+			log.Println("change can not be applied:", err)
+			continue
 		}
-	default:
-		m.dashboardScreen.updateMsg = ""
+		send(changeEventMsg(event))
 	}
 }
 
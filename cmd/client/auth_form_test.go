@@ -1,12 +1,18 @@
 package main
 
 import (
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/PaBah/GophKeeper/internal/client"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestValidateEmail(t *testing.T) {
@@ -129,43 +135,58 @@ func TestHandleEnterKey(t *testing.T) {
 	}
 }
 
-func TestUpdateDashboardOnChange(t *testing.T) {
-	tests := []struct {
-		name        string
-		source      menuItem
-		expectedStr string
-	}{
-		{
-			name:        "credentials source",
-			source:      credentials,
-			expectedStr: "GophKeeper: credentials changed, shift → to refresh",
-		},
-		{
-			name:        "cards source",
-			source:      cards,
-			expectedStr: "GophKeeper: cards changed, shift → to refresh",
-		},
-		{
-			name:        "files source",
-			source:      files,
-			expectedStr: "GophKeeper: files changed, shift → to refresh",
-		},
-		{
-			name:        "unknown source",
-			source:      5, // Assuming 5 is not defined in menuItem
-			expectedStr: "",
-		},
+func TestHandleSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	stream := mock.NewMockGophKeeperService_SubscribeToChangesClient(ctrl)
+	m := &Model{dashboardScreen: NewDashboardScreen(), clientService: gm}
+	m.dashboardScreen.cursor = notes
+	m.dashboardScreen.tableNavigation = true
+	m.dashboardScreen.notesState = []models.Note{{ID: "1", Title: "wifi", Version: 1}}
+	m.dashboardScreen.credentialsState = []models.Credentials{{ID: "2", ServiceName: "aws", Version: 1}}
+
+	updated := &pb.SubscribeToChangesResponse{Id: "1", Operation: pb.ChangeOperation_CHANGE_OPERATION_UPDATE}
+	deleted := &pb.SubscribeToChangesResponse{Id: "2", Operation: pb.ChangeOperation_CHANGE_OPERATION_DELETE}
+	receive := func(event *pb.SubscribeToChangesResponse) func(m any) error {
+		return func(m any) error {
+			proto.Merge(m.(*pb.SubscribeToChangesResponse), event)
+			return nil
+		}
 	}
+	gomock.InOrder(
+		stream.EXPECT().RecvMsg(gomock.Any()).DoAndReturn(receive(updated)),
+		stream.EXPECT().RecvMsg(gomock.Any()).DoAndReturn(receive(deleted)),
+		stream.EXPECT().RecvMsg(gomock.Any()).Return(io.EOF),
+	)
+	gm.EXPECT().OpenChangeEvent(gomock.Any()).Return(models.ChangeEvent{Operation: models.ChangeUpdated, ItemType: models.NoteItem,
+		ItemID: "1", Version: 2, Item: models.Note{ID: "1", Title: "home wifi", Version: 2}, Device: "laptop"}, nil)
+	gm.EXPECT().OpenChangeEvent(gomock.Any()).Return(models.ChangeEvent{Operation: models.ChangeDeleted, ItemType: models.CredentialsItem,
+		ItemID: "2", Version: 2, Device: "phone"}, nil)
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			m := &Model{dashboardScreen: NewDashboardScreen()}
-			authForm := NewAuthForm("title", nil)
-			m.dashboardScreen.cursor = tt.source
-			authForm.updateDashboardOnChange(m, tt.source)
-			if m.dashboardScreen.updateMsg != tt.expectedStr {
-				t.Errorf("updateDashboardOnChange() got = %v, want = %v", m.dashboardScreen.updateMsg, tt.expectedStr)
-			}
-		})
+	var messages []tea.Msg
+	NewAuthForm("title", nil).handleSubscription(stream, gm, func(msg tea.Msg) { messages = append(messages, msg) })
+	if len(m.dashboardScreen.notesState) != 1 || m.dashboardScreen.notesState[0].Version != 1 {
+		t.Fatalf("handleSubscription() notes = %v, want changes applied only in Update", m.dashboardScreen.notesState)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("handleSubscription() sent %d messages, want 2", len(messages))
+	}
+	for _, msg := range messages {
+		updated, _ := m.Update(msg)
+		*m = updated.(Model)
+	}
+	if !reflect.DeepEqual(m.dashboardScreen.notesState, []models.Note{{ID: "1", Title: "home wifi", Version: 2}}) {
+		t.Errorf("handleSubscription() notes = %v, want updated note", m.dashboardScreen.notesState)
+	}
+	if len(m.dashboardScreen.credentialsState) != 0 {
+		t.Errorf("handleSubscription() credentials = %v, want deleted credentials dropped", m.dashboardScreen.credentialsState)
+	}
+	if m.dashboardScreen.updateMsg != "GophKeeper: credentials deleted on phone" {
+		t.Errorf("handleSubscription() message = %v, want last change reported", m.dashboardScreen.updateMsg)
+	}
+	if !strings.Contains(m.dashboardScreen.content, "home wifi") {
+		t.Errorf("handleSubscription() content = %v, want notes redrawn", m.dashboardScreen.content)
 	}
 }
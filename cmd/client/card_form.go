@@ -16,6 +16,7 @@ import (
 type CardScreen struct {
 	inputs     []textinput.Model
 	updateID   string
+	base       models.Card
 	createMode bool
 	focused    cardFormInput
 	title      string
@@ -28,7 +29,8 @@ const (
 	expiryDate      cardFormInput = 1
 	cardHolder      cardFormInput = 2
 	cvv             cardFormInput = 3
-	totalCardFields cardFormInput = 4
+	cardMetadata    cardFormInput = 4
+	totalCardFields cardFormInput = 5
 )
 
 func NewCardScreen() *CardScreen {
@@ -51,7 +53,7 @@ func NewCardScreen() *CardScreen {
 	cvv.Width = 200
 
 	return &CardScreen{
-		inputs:  []textinput.Model{cardNumber, expiryDate, cardHolder, cvv},
+		inputs:  []textinput.Model{cardNumber, expiryDate, cardHolder, cvv, newMetadataInput()},
 		focused: 0,
 		title:   "Please, enter your payment card's details",
 	}
@@ -96,6 +98,10 @@ func (form *CardScreen) validateAndSubmit(m *Model) error {
 	if m.err != nil {
 		return m.err
 	}
+	metadata, err := parseMetadata(form.inputs[cardMetadata].Value())
+	if err != nil {
+		return err
+	}
 
 	if form.createMode {
 		return m.clientService.CreateCard(
@@ -104,19 +110,21 @@ func (form *CardScreen) validateAndSubmit(m *Model) error {
 			form.inputs[expiryDate].Value(),
 			form.inputs[cardHolder].Value(),
 			form.inputs[cvv].Value(),
+			metadata,
 		)
 	}
 
-	_, err := m.clientService.UpdateCards(
-		context.Background(),
-		models.Card{
-			ID:             form.updateID,
-			Number:         strings.ReplaceAll(form.inputs[cardNumber].Value(), " ", ""),
-			ExpirationDate: form.inputs[expiryDate].Value(),
-			HolderName:     form.inputs[cardHolder].Value(),
-			CVV:            form.inputs[cvv].Value(),
-		},
-	)
+	edited := models.Card{
+		ID:             form.updateID,
+		Number:         strings.ReplaceAll(form.inputs[cardNumber].Value(), " ", ""),
+		ExpirationDate: form.inputs[expiryDate].Value(),
+		HolderName:     form.inputs[cardHolder].Value(),
+		CVV:            form.inputs[cvv].Value(),
+		Metadata:       metadata,
+		Version:        form.base.Version,
+	}
+	_, err = m.clientService.UpdateCards(context.Background(), edited)
+	m.conflictScreen.openCard(m, form.base, edited, err)
 	return err
 }
 
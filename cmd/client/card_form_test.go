@@ -63,7 +63,7 @@ func TestCardHandleEnterKey(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	gm := mock.NewMockGRPCClientProvider(ctrl)
 	gm.EXPECT().
-		CreateCard(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		CreateCard(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil).
 		AnyTimes()
 	gm.EXPECT().
@@ -71,8 +71,8 @@ func TestCardHandleEnterKey(t *testing.T) {
 		Return(models.Card{}, nil).
 		AnyTimes()
 	gm.EXPECT().
-		GetCredentials(gomock.Any()).
-		Return([]models.Credentials{models.Credentials{}}, nil).
+		Sync(gomock.Any()).
+		Return(models.ChangeSet{Credentials: []models.Credentials{models.Credentials{}}}, nil).
 		AnyTimes()
 	clientMock = gm
 
@@ -84,12 +84,12 @@ func TestCardHandleEnterKey(t *testing.T) {
 		createMode  bool
 		errExpected bool
 	}{
-		{"ValidCardDetailsCreateMode", []string{"4242424242424242", "01/23", "John Doe", "737"}, "", 4, true, false},
-		{"ValidCardDetailsUpdateMode", []string{"4242424242424242", "01/23", "John Doe", "737"}, "123", 4, false, false},
-		{"InvalidCardNumber", []string{"1234567890123456", "01/23", "John Doe", "737"}, "", 4, true, true},
-		{"InvalidExpiryDate", []string{"4242424242424242", "13/23", "John Doe", "737"}, "", 4, true, true},
-		{"InvalidHolderName", []string{"4242424242424242", "01/23", "1234567890", "737"}, "", 4, true, true},
-		{"InvalidCVV", []string{"4242424242424242", "01/23", "John Doe", "12345"}, "", 4, true, true},
+		{"ValidCardDetailsCreateMode", []string{"4242424242424242", "01/23", "John Doe", "737"}, "", totalCardFields, true, false},
+		{"ValidCardDetailsUpdateMode", []string{"4242424242424242", "01/23", "John Doe", "737"}, "123", totalCardFields, false, false},
+		{"InvalidCardNumber", []string{"1234567890123456", "01/23", "John Doe", "737"}, "", totalCardFields, true, true},
+		{"InvalidExpiryDate", []string{"4242424242424242", "13/23", "John Doe", "737"}, "", totalCardFields, true, true},
+		{"InvalidHolderName", []string{"4242424242424242", "01/23", "1234567890", "737"}, "", totalCardFields, true, true},
+		{"InvalidCVV", []string{"4242424242424242", "01/23", "John Doe", "12345"}, "", totalCardFields, true, true},
 	}
 
 	for _, tt := range tests {
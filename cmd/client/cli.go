@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/importer"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/charmbracelet/x/term"
+)
+
+// Environment variables passwords of commands are taken from, they are asked without echo when not set
+const (
+	passwordEnv        = "GOPHKEEPER_PASSWORD"
+	masterPasswordEnv  = "GOPHKEEPER_MASTER_PASSWORD"
+	archivePasswordEnv = "GOPHKEEPER_ARCHIVE_PASSWORD"
+)
+
+const commandsUsage = `usage:
+  gophkeeper-client                                     run interactive client
+  gophkeeper-client export [-server address] -email email [-o archive]
+  gophkeeper-client import [-server address] -email email [-dry-run] archive
+  gophkeeper-client migrate [-server address] -email email [-format format] [-preview | -dry-run] export
+
+migrate imports KeePass XML, Bitwarden JSON or 1Password CSV export, format is guessed by extension
+of file when not set
+
+passwords are read from ` + passwordEnv + `, ` + masterPasswordEnv + ` and ` + archivePasswordEnv + `
+or asked interactively`
+
+// command - client run without TUI by subcommand of command line
+type command struct {
+	newClient func(serverAddress string) client.GRPCClientProvider
+	// prompt - ask user for secret value
+	prompt func(label string) (string, error)
+	out    io.Writer
+	stdin  *os.File
+	// in - buffered stdin shared by prompts, piped input would be lost by reader of each prompt
+	in *bufio.Reader
+}
+
+func newCommand() *command {
+	cmd := &command{
+		newClient: func(serverAddress string) client.GRPCClientProvider {
+			service := client.NewClientService(serverAddress)
+			service.TryToConnect()
+			return service
+		},
+		out:   os.Stdout,
+		stdin: os.Stdin,
+		in:    bufio.NewReader(os.Stdin),
+	}
+	cmd.prompt = cmd.promptSecret
+	return cmd
+}
+
+// promptSecret - read secret from terminal without echo, piped input is read by lines
+func (cmd *command) promptSecret(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	if term.IsTerminal(cmd.stdin.Fd()) {
+		secret, err := term.ReadPassword(cmd.stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(secret), err
+	}
+	line, err := cmd.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// run - execute subcommand named by first argument
+func (cmd *command) run(args []string) error {
+	switch args[0] {
+	case "export":
+		return cmd.export(args[1:])
+	case "import":
+		return cmd.importArchive(args[1:])
+	case "migrate":
+		return cmd.migrate(args[1:])
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], commandsUsage)
+}
+
+// export - write vault of account into password protected archive
+func (cmd *command) export(args []string) error {
+	flags := cmd.flagSet("export")
+	serverAddress, email := accountFlags(flags)
+	output := flags.String("o", "gophkeeper-vault.gka", "path archive is written to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	service, err := cmd.signIn(ctx, *serverAddress, *email, false)
+	if err != nil {
+		return err
+	}
+	password, err := cmd.archivePassword(true)
+	if err != nil {
+		return err
+	}
+	manifest, err := service.ExportVault(ctx, *output, password)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.out, "Exported %d credentials, %d cards, %d notes and %d files into %s\n",
+		manifest.Credentials, manifest.Cards, manifest.Notes, len(manifest.Files), *output)
+	return nil
+}
+
+// importArchive - restore archive into vault of account
+func (cmd *command) importArchive(args []string) error {
+	flags := cmd.flagSet("import")
+	serverAddress, email := accountFlags(flags)
+	dryRun := flags.Bool("dry-run", false, "only report what would be imported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("path to archive is required\n%s", commandsUsage)
+	}
+
+	ctx := context.Background()
+	service, err := cmd.signIn(ctx, *serverAddress, *email, !*dryRun)
+	if err != nil {
+		return err
+	}
+	password, err := cmd.archivePassword(false)
+	if err != nil {
+		return err
+	}
+	report, err := service.ImportVault(ctx, flags.Arg(0), password, *dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.out, formatReport(report))
+	return nil
+}
+
+// migrate - import export of other password manager into vault of account
+func (cmd *command) migrate(args []string) error {
+	flags := cmd.flagSet("migrate")
+	serverAddress, email := accountFlags(flags)
+	format := flags.String("format", "", "format of export: keepass, bitwarden or 1password")
+	preview := flags.Bool("preview", false, "only show how export is mapped to vault items")
+	dryRun := flags.Bool("dry-run", false, "only report what would be imported")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("path to export is required\n%s", commandsUsage)
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = importer.DetectFormat(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	items, err := importer.Parse(*format, file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.out, formatPreview(path, items))
+	if *preview {
+		return nil
+	}
+	if items.Count() == 0 {
+		return errors.New("export has no items to import")
+	}
+
+	ctx := context.Background()
+	service, err := cmd.signIn(ctx, *serverAddress, *email, !*dryRun)
+	if err != nil {
+		return err
+	}
+	report, err := service.ImportItems(ctx, items, *dryRun)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.out, formatReport(report))
+	return nil
+}
+
+func (cmd *command) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cmd.out)
+	return flags
+}
+
+func accountFlags(flags *flag.FlagSet) (serverAddress, email *string) {
+	serverAddress = flags.String("server", ":3200", "host:port of GophKeeper server")
+	email = flags.String("email", "", "email of account")
+	return
+}
+
+// signIn - sign in account and unlock its vault, vault of new account is initialized by entered master password
+// when initVault is set
+func (cmd *command) signIn(ctx context.Context, serverAddress, email string, initVault bool) (client.GRPCClientProvider, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email is required\n%s", commandsUsage)
+	}
+	service := cmd.newClient(serverAddress)
+	password, err := cmd.secret(passwordEnv, "Password: ")
+	if err != nil {
+		return nil, err
+	}
+	err = service.SignIn(email, password)
+	if errors.Is(err, client.ErrTOTPRequired) {
+		var code string
+		if code, err = cmd.prompt("Two-factor authentication code: "); err != nil {
+			return nil, err
+		}
+		err = service.VerifyTOTP(code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	masterPassword, err := cmd.secret(masterPasswordEnv, "Master password: ")
+	if err != nil {
+		return nil, err
+	}
+	err = service.UnlockVault(ctx, masterPassword)
+	if errors.Is(err, client.ErrVaultNotInitialized) && initVault {
+		fmt.Fprintln(cmd.out, "Vault of account is not initialized, it is created with entered master password")
+		err = service.InitVault(ctx, masterPassword)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service, nil
+}
+
+// archivePassword - password of archive, new password is asked twice
+func (cmd *command) archivePassword(confirm bool) (string, error) {
+	if password, ok := os.LookupEnv(archivePasswordEnv); ok {
+		return password, nil
+	}
+	password, err := cmd.prompt("Archive password: ")
+	if err != nil {
+		return "", err
+	}
+	if password == "" {
+		return "", errors.New("archive password is required")
+	}
+	if !confirm {
+		return password, nil
+	}
+	repeated, err := cmd.prompt("Repeat archive password: ")
+	if err != nil {
+		return "", err
+	}
+	if repeated != password {
+		return "", errors.New("archive passwords do not match")
+	}
+	return password, nil
+}
+
+// secret - value of environment variable or asked one
+func (cmd *command) secret(env, label string) (string, error) {
+	if value, ok := os.LookupEnv(env); ok {
+		return value, nil
+	}
+	return cmd.prompt(label)
+}
+
+// formatPreview - human readable vault items mapped from export, secrets are not shown
+func formatPreview(path string, items importer.Items) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is mapped to %d credentials, %d cards and %d notes:\n",
+		path, len(items.Credentials), len(items.Cards), len(items.Notes))
+	for _, credentials := range items.Credentials {
+		fmt.Fprintf(&b, "  %-12s %-30s %s", "credentials", credentials.ServiceName, credentials.Identity)
+		if credentials.TOTP != "" {
+			b.WriteString(" (TOTP)")
+		}
+		b.WriteString("\n")
+	}
+	for _, card := range items.Cards {
+		number := card.Number
+		if len(number) > 4 {
+			number = "*" + number[len(number)-4:]
+		}
+		fmt.Fprintf(&b, "  %-12s %-30s %s %s\n", "card", number, card.ExpirationDate, card.HolderName)
+	}
+	for _, note := range items.Notes {
+		fmt.Fprintf(&b, "  %-12s %s\n", "note", note.Title)
+	}
+	if len(items.Skipped) > 0 {
+		b.WriteString("Skipped:\n")
+		for _, reason := range items.Skipped {
+			fmt.Fprintf(&b, "  %s\n", reason)
+		}
+	}
+	return b.String()
+}
+
+// formatReport - human readable result of import
+func formatReport(report models.ImportReport) string {
+	var b strings.Builder
+	if report.DryRun {
+		b.WriteString("Dry run, vault is not changed. Archive would import:\n")
+	} else {
+		b.WriteString("Imported:\n")
+	}
+	for _, kind := range []struct {
+		name  string
+		count models.ImportCount
+	}{
+		{"credentials", report.Credentials},
+		{"cards", report.Cards},
+		{"notes", report.Notes},
+		{"files", report.Files},
+	} {
+		fmt.Fprintf(&b, "  %-12s %d new, %d duplicates skipped", kind.name, kind.count.Imported, kind.count.Duplicates)
+		if kind.count.Rejected > 0 {
+			fmt.Fprintf(&b, ", %d rejected", kind.count.Rejected)
+		}
+		b.WriteString("\n")
+	}
+	if len(report.Rejections) > 0 {
+		b.WriteString("Rejected by server:\n")
+		for _, rejection := range report.Rejections {
+			fmt.Fprintf(&b, "  %s\n", rejection)
+		}
+	}
+	return b.String()
+}
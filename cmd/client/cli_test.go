@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/archive"
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/importer"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestCommand - command answering prompts by their labels
+func newTestCommand(gm *mock.MockGRPCClientProvider, answers map[string]string) (*command, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return &command{
+		newClient: func(serverAddress string) client.GRPCClientProvider { return gm },
+		prompt: func(label string) (string, error) {
+			answer, ok := answers[label]
+			if !ok {
+				return "", fmt.Errorf("unexpected prompt %q", label)
+			}
+			return answer, nil
+		},
+		out: out,
+	}, out
+}
+
+func TestCommand_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	cmd, out := newTestCommand(gm, map[string]string{
+		"Password: ":                       "password",
+		"Two-factor authentication code: ": "123456",
+		"Master password: ":                "master",
+		"Archive password: ":               "archive",
+		"Repeat archive password: ":        "archive",
+	})
+	gm.EXPECT().SignIn("user@example.com", "password").Return(fmt.Errorf("SignIn: %w", client.ErrTOTPRequired))
+	gm.EXPECT().VerifyTOTP("123456").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(nil)
+	gm.EXPECT().ExportVault(gomock.Any(), "backup.gka", "archive").DoAndReturn(
+		func(ctx context.Context, path, password string) (archive.Manifest, error) {
+			return archive.Manifest{Credentials: 2, Notes: 1, Files: make([]archive.File, 3)}, nil
+		})
+
+	if err := cmd.run([]string{"export", "-email", "user@example.com", "-o", "backup.gka"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Exported 2 credentials, 0 cards, 1 notes and 3 files into backup.gka") {
+		t.Errorf("run() output = %q", out.String())
+	}
+
+	cmd, _ = newTestCommand(gm, map[string]string{
+		"Password: ":                "password",
+		"Master password: ":         "master",
+		"Archive password: ":        "archive",
+		"Repeat archive password: ": "other",
+	})
+	gm.EXPECT().SignIn("user@example.com", "password").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(nil)
+	if err := cmd.run([]string{"export", "-email", "user@example.com"}); err == nil || !strings.Contains(err.Error(), "do not match") {
+		t.Errorf("run() with different archive passwords error = %v", err)
+	}
+
+	if err := cmd.run([]string{"export"}); err == nil || !strings.Contains(err.Error(), "email is required") {
+		t.Errorf("run() without email error = %v", err)
+	}
+	if err := cmd.run([]string{"backup"}); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("run() of unknown command error = %v", err)
+	}
+}
+
+func TestCommand_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Setenv(archivePasswordEnv, "archive")
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	cmd, out := newTestCommand(gm, map[string]string{"Password: ": "password", "Master password: ": "master"})
+
+	gm.EXPECT().SignIn("new@example.com", "password").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(client.ErrVaultNotInitialized)
+	gm.EXPECT().InitVault(gomock.Any(), "master").Return(nil)
+	gm.EXPECT().ImportVault(gomock.Any(), "backup.gka", "archive", false).DoAndReturn(
+		func(ctx context.Context, path, password string, dryRun bool) (models.ImportReport, error) {
+			return models.ImportReport{Credentials: models.ImportCount{Imported: 2, Duplicates: 1},
+				Cards: models.ImportCount{Rejected: 1}, Rejections: []string{`card "*1111": invalid card number`}}, nil
+		})
+	if err := cmd.run([]string{"import", "-email", "new@example.com", "backup.gka"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "credentials  2 new, 1 duplicates skipped\n") ||
+		!strings.Contains(out.String(), "cards        0 new, 0 duplicates skipped, 1 rejected") ||
+		!strings.Contains(out.String(), `card "*1111": invalid card number`) {
+		t.Errorf("run() output = %q", out.String())
+	}
+
+	// dry run does not create vault of new account
+	out.Reset()
+	gm.EXPECT().SignIn("new@example.com", "password").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(client.ErrVaultNotInitialized)
+	if err := cmd.run([]string{"import", "-email", "new@example.com", "-dry-run", "backup.gka"}); !errors.Is(err, client.ErrVaultNotInitialized) {
+		t.Errorf("run() of dry run error = %v, want %v", err, client.ErrVaultNotInitialized)
+	}
+
+	gm.EXPECT().SignIn("user@example.com", "password").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(nil)
+	gm.EXPECT().ImportVault(gomock.Any(), "backup.gka", "archive", true).DoAndReturn(
+		func(ctx context.Context, path, password string, dryRun bool) (models.ImportReport, error) {
+			return models.ImportReport{DryRun: true, Files: models.ImportCount{Imported: 1}}, nil
+		})
+	if err := cmd.run([]string{"import", "-email", "user@example.com", "-dry-run", "backup.gka"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Dry run") || !strings.Contains(out.String(), "files        1 new") {
+		t.Errorf("run() output = %q", out.String())
+	}
+
+	if err := cmd.run([]string{"import", "-email", "user@example.com"}); err == nil || !strings.Contains(err.Error(), "path to archive") {
+		t.Errorf("run() without archive error = %v", err)
+	}
+}
+
+func TestCommand_Migrate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	export := "Title,Url,Username,Password,Notes\nShop,https://shop.example.com,buyer,secret,\nWi-Fi,,,,password\n"
+	if err := os.WriteFile(path, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	cmd, out := newTestCommand(gm, map[string]string{"Password: ": "password", "Master password: ": "master"})
+
+	// preview does not connect to server
+	if err := cmd.run([]string{"migrate", "-preview", path}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	for _, line := range []string{"1 credentials, 0 cards and 1 notes", "Shop", "buyer", "Wi-Fi"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("run() preview = %q, want %q in it", out.String(), line)
+		}
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Errorf("run() preview = %q shows password", out.String())
+	}
+
+	out.Reset()
+	gm.EXPECT().SignIn("user@example.com", "password").Return(nil)
+	gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(nil)
+	gm.EXPECT().ImportItems(gomock.Any(), gomock.Any(), false).DoAndReturn(
+		func(ctx context.Context, items importer.Items, dryRun bool) (models.ImportReport, error) {
+			if len(items.Credentials) != 1 || items.Credentials[0].Password != "secret" || len(items.Notes) != 1 {
+				t.Errorf("ImportItems() items = %+v", items)
+			}
+			return models.ImportReport{Credentials: models.ImportCount{Imported: 1}, Notes: models.ImportCount{Imported: 1}}, nil
+		})
+	if err := cmd.run([]string{"migrate", "-email", "user@example.com", path}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "credentials  1 new, 0 duplicates skipped") {
+		t.Errorf("run() output = %q", out.String())
+	}
+
+	unknown := filepath.Join(t.TempDir(), "export.txt")
+	if err := os.WriteFile(unknown, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.run([]string{"migrate", "-email", "user@example.com", unknown}); !errors.Is(err, importer.ErrUnknownFormat) {
+		t.Errorf("run() of unknown format error = %v, want %v", err, importer.ErrUnknownFormat)
+	}
+	empty := filepath.Join(t.TempDir(), "empty.csv")
+	if err := os.WriteFile(empty, []byte("Title,Username,Password\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.run([]string{"migrate", "-email", "user@example.com", empty}); err == nil || !strings.Contains(err.Error(), "no items") {
+		t.Errorf("run() of empty export error = %v", err)
+	}
+	if err := cmd.run([]string{"migrate", "-email", "user@example.com"}); err == nil || !strings.Contains(err.Error(), "path to export") {
+		t.Errorf("run() without export error = %v", err)
+	}
+}
+
+func TestCommand_PromptSecret(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err = w.WriteString("pass\nmaster\r\narchive"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	cmd := &command{stdin: r, in: bufio.NewReader(r)}
+	for _, want := range []string{"pass", "master", "archive"} {
+		got, err := cmd.promptSecret("Secret: ")
+		if err != nil {
+			t.Fatalf("promptSecret() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("promptSecret() = %q, want %q", got, want)
+		}
+	}
+	if _, err = cmd.promptSecret("Secret: "); !errors.Is(err, io.EOF) {
+		t.Errorf("promptSecret() after last line error = %v, want %v", err, io.EOF)
+	}
+}
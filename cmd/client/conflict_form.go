@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// conflictField - field of item which was edited here and by another client at the same time.
+// Base is value both edits started from, mine is value edited here and theirs is current value on server.
+type conflictField struct {
+	name      string
+	base      string
+	mine      string
+	theirs    string
+	useTheirs bool
+}
+
+// conflicting reports if both sides changed field differently, so user has to pick one of values.
+func (field conflictField) conflicting() bool {
+	return field.mine != field.base && field.theirs != field.base && field.mine != field.theirs
+}
+
+// merged - value of field after three-way merge: one-side changes are taken as is, conflicting ones by user choice.
+func (field conflictField) merged() string {
+	switch {
+	case field.conflicting() && field.useTheirs:
+		return field.theirs
+	case field.conflicting(), field.mine != field.base:
+		return field.mine
+	default:
+		return field.theirs
+	}
+}
+
+// ConflictScreen - three-way merge of item which was changed by another client while it was edited here
+type ConflictScreen struct {
+	title  string
+	fields []conflictField
+	cursor int
+	apply  func(m *Model, values []string) error
+}
+
+func NewConflictScreen() *ConflictScreen {
+	return &ConflictScreen{}
+}
+
+// serverCopy - current server copy of item if update was rejected because of concurrent change
+func serverCopy(err error) any {
+	var conflict *client.ConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Server
+	}
+	return nil
+}
+
+func (form *ConflictScreen) reset(m *Model, title string, fields []conflictField, apply func(m *Model, values []string) error) {
+	form.title = title
+	form.fields = fields
+	form.cursor = 0
+	form.apply = apply
+	m.state = ConflictForm
+}
+
+// openCredentials starts resolution if update of credentials was rejected because of concurrent change.
+func (form *ConflictScreen) openCredentials(m *Model, base, mine models.Credentials, err error) {
+	theirs, ok := serverCopy(err).(models.Credentials)
+	if !ok {
+		return
+	}
+	form.reset(m, "Credentials were changed by another client, pick values to keep", []conflictField{
+		{name: "Service name", base: base.ServiceName, mine: mine.ServiceName, theirs: theirs.ServiceName},
+		{name: "Identity", base: base.Identity, mine: mine.Identity, theirs: theirs.Identity},
+		{name: "Password", base: base.Password, mine: mine.Password, theirs: theirs.Password},
+		{name: "TOTP seed", base: base.TOTP, mine: mine.TOTP, theirs: theirs.TOTP},
+		{name: "Metadata", base: formatMetadata(base.Metadata), mine: formatMetadata(mine.Metadata), theirs: formatMetadata(theirs.Metadata)},
+	}, func(m *Model, values []string) error {
+		metadata, err := parseMetadata(values[4])
+		if err != nil {
+			return err
+		}
+		merged := models.Credentials{ID: theirs.ID, ServiceName: values[0], Identity: values[1], Password: values[2],
+			TOTP: values[3], Metadata: metadata, Version: theirs.Version}
+		_, err = m.clientService.UpdateCredentials(context.Background(), merged)
+		form.openCredentials(m, theirs, merged, err)
+		return err
+	})
+}
+
+// openCard starts resolution if update of card was rejected because of concurrent change.
+func (form *ConflictScreen) openCard(m *Model, base, mine models.Card, err error) {
+	theirs, ok := serverCopy(err).(models.Card)
+	if !ok {
+		return
+	}
+	form.reset(m, "Card was changed by another client, pick values to keep", []conflictField{
+		{name: "Number", base: base.Number, mine: mine.Number, theirs: theirs.Number},
+		{name: "Expiration date", base: base.ExpirationDate, mine: mine.ExpirationDate, theirs: theirs.ExpirationDate},
+		{name: "Holder name", base: base.HolderName, mine: mine.HolderName, theirs: theirs.HolderName},
+		{name: "CVV", base: base.CVV, mine: mine.CVV, theirs: theirs.CVV},
+		{name: "Metadata", base: formatMetadata(base.Metadata), mine: formatMetadata(mine.Metadata), theirs: formatMetadata(theirs.Metadata)},
+	}, func(m *Model, values []string) error {
+		metadata, err := parseMetadata(values[4])
+		if err != nil {
+			return err
+		}
+		merged := models.Card{ID: theirs.ID, Number: values[0], ExpirationDate: values[1], HolderName: values[2], CVV: values[3],
+			Metadata: metadata, Version: theirs.Version}
+		_, err = m.clientService.UpdateCards(context.Background(), merged)
+		form.openCard(m, theirs, merged, err)
+		return err
+	})
+}
+
+// openNote starts resolution if update of note was rejected because of concurrent change.
+func (form *ConflictScreen) openNote(m *Model, base, mine models.Note, err error) {
+	theirs, ok := serverCopy(err).(models.Note)
+	if !ok {
+		return
+	}
+	form.reset(m, "Note was changed by another client, pick values to keep", []conflictField{
+		{name: "Title", base: base.Title, mine: mine.Title, theirs: theirs.Title},
+		{name: "Body", base: base.Body, mine: mine.Body, theirs: theirs.Body},
+		{name: "Metadata", base: formatMetadata(base.Metadata), mine: formatMetadata(mine.Metadata), theirs: formatMetadata(theirs.Metadata)},
+	}, func(m *Model, values []string) error {
+		metadata, err := parseMetadata(values[2])
+		if err != nil {
+			return err
+		}
+		merged := models.Note{ID: theirs.ID, Title: values[0], Body: values[1], Metadata: metadata, Version: theirs.Version}
+		_, err = m.clientService.UpdateNote(context.Background(), merged)
+		form.openNote(m, theirs, merged, err)
+		return err
+	})
+}
+
+// openReplayConflict starts resolution of change made offline if server rejected it because of concurrent change.
+// Conflicting deletion has nothing to merge, so user is only told it was dropped.
+func (form *ConflictScreen) openReplayConflict(m *Model) {
+	if m.state != Dashboard {
+		return
+	}
+	conflict, err := m.clientService.TakeReplayConflict()
+	if err != nil {
+		m.err = err
+		return
+	}
+	if conflict == nil {
+		return
+	}
+	switch mine := conflict.Mine.(type) {
+	case models.Credentials:
+		form.openCredentials(m, conflict.Base.(models.Credentials), mine, conflict.Err)
+	case models.Card:
+		form.openCard(m, conflict.Base.(models.Card), mine, conflict.Err)
+	case models.Note:
+		form.openNote(m, conflict.Base.(models.Note), mine, conflict.Err)
+	default:
+		m.dashboardScreen.updateMsg = fmt.Sprintf("GophKeeper: %s deleted offline was changed by another client, deletion is dropped", conflict.ItemType)
+	}
+}
+
+func (form *ConflictScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "up":
+		form.cursor = max(form.cursor-1, 0)
+	case "down":
+		form.cursor = min(form.cursor+1, len(form.fields)-1)
+	case "left":
+		form.fields[form.cursor].useTheirs = false
+	case "right":
+		form.fields[form.cursor].useTheirs = true
+	case "enter":
+		form.submit(m)
+	}
+	return m, nil
+}
+
+func (form *ConflictScreen) submit(m *Model) {
+	values := make([]string, len(form.fields))
+	for i, field := range form.fields {
+		values[i] = field.merged()
+	}
+	m.err = form.apply(m, values)
+	if m.err == nil {
+		m.dashboardScreen.loadActual(m)
+		m.dashboardScreen.content = m.dashboardScreen.drawContent(m)
+		m.state = Dashboard
+	}
+}
+
+func (form *ConflictScreen) View(m *Model) string {
+	rows := []string{borderStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+		headerStyle.Render("Field"),
+		headerStyle.Render("Original"),
+		headerStyle.Render("Mine"),
+		headerStyle.Render("Theirs"),
+		headerStyle.Render("Result"),
+	))}
+	for i, field := range form.fields {
+		mineStyle, theirsStyle := cellStyle, cellStyle
+		if field.conflicting() {
+			if field.useTheirs {
+				theirsStyle = selectedCellStyle
+			} else {
+				mineStyle = selectedCellStyle
+			}
+		}
+		name := field.name
+		if field.conflicting() {
+			name += " (!)"
+		}
+		if i == form.cursor {
+			name = activeMenu.Render("> " + name)
+		}
+		rows = append(rows, borderStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+			cellStyle.Render(name),
+			cellStyle.Render(field.base),
+			mineStyle.Render(field.mine),
+			theirsStyle.Render(field.theirs),
+			cellStyle.Render(field.merged()),
+		)))
+	}
+
+	ui := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(form.title),
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		buttonStyle.Render("Save merged"),
+	)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConflictField_Merged(t *testing.T) {
+	tests := []struct {
+		name        string
+		field       conflictField
+		conflicting bool
+		expected    string
+	}{
+		{name: "Changed only here", field: conflictField{base: "a", mine: "b", theirs: "a"}, expected: "b"},
+		{name: "Changed only on server", field: conflictField{base: "a", mine: "a", theirs: "c"}, expected: "c"},
+		{name: "Same change on both sides", field: conflictField{base: "a", mine: "b", theirs: "b"}, expected: "b"},
+		{name: "Conflict keeps mine", field: conflictField{base: "a", mine: "b", theirs: "c"}, conflicting: true, expected: "b"},
+		{name: "Conflict takes theirs", field: conflictField{base: "a", mine: "b", theirs: "c", useTheirs: true}, conflicting: true, expected: "c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.field.conflicting() != tt.conflicting {
+				t.Errorf("conflicting() = %v, want %v", tt.field.conflicting(), tt.conflicting)
+			}
+			if tt.field.merged() != tt.expected {
+				t.Errorf("merged() = %v, want %v", tt.field.merged(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestConflictScreen_ResolveNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(NoteForm)
+	model.clientService = gm
+	model.dashboardScreen.cursor = notes
+
+	base := models.Note{ID: "1", Title: "wifi", Body: "secret", Version: 1}
+	theirs := models.Note{ID: "1", Title: "home wifi", Body: "their secret", Version: 2}
+	gm.EXPECT().UpdateNote(gomock.Any(), models.Note{ID: "1", Title: "wifi", Body: "my secret", Metadata: nil, Version: 1}).
+		Return(models.Note{}, fmt.Errorf("UpdateNote: %w", &client.ConflictError{Server: theirs}))
+
+	model.notesScreen.reset(base, false)
+	model.notesScreen.bodyInput.SetValue("my secret")
+	_, _ = model.notesScreen.handleKeyMsg(&model, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if model.state != ConflictForm {
+		t.Fatalf("submit() state = %v, want %v", model.state, ConflictForm)
+	}
+
+	// title changed only on server, body is picked from server copy
+	_, _ = model.conflictScreen.Update(&model, tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = model.conflictScreen.Update(&model, tea.KeyMsg{Type: tea.KeyRight})
+	gm.EXPECT().UpdateNote(gomock.Any(), models.Note{ID: "1", Title: "home wifi", Body: "their secret", Metadata: nil, Version: 2}).
+		Return(models.Note{ID: "1", Title: "home wifi", Body: "their secret", Version: 3}, nil)
+	gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{Notes: []models.Note{{ID: "1", Title: "home wifi", Version: 3}}}, nil)
+	_, _ = model.conflictScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Errorf("submit() error = %v, state = %v, want merged note saved", model.err, model.state)
+	}
+}
+
+func TestConflictScreen_OpenReplayConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+
+	base := models.Note{ID: "1", Title: "wifi", Body: "secret", Version: 1}
+	mine := models.Note{ID: "1", Title: "wifi", Body: "my secret", Version: 1}
+	theirs := models.Note{ID: "1", Title: "wifi", Body: "their secret", Version: 2}
+	gm.EXPECT().TakeReplayConflict().Return(&models.ReplayConflict{ItemType: models.NoteItem, Base: base, Mine: mine,
+		Err: fmt.Errorf("UpdateNote: %w", &client.ConflictError{Server: theirs})}, nil)
+	model.conflictScreen.openReplayConflict(&model)
+	if model.state != ConflictForm || !model.conflictScreen.fields[1].conflicting() {
+		t.Fatalf("openReplayConflict() state = %v, fields = %v, want body conflict", model.state, model.conflictScreen.fields)
+	}
+
+	model.state = Dashboard
+	gm.EXPECT().TakeReplayConflict().Return(&models.ReplayConflict{ItemType: models.NoteItem, Base: base,
+		Err: fmt.Errorf("DeleteNote: %w", &client.ConflictError{Server: theirs})}, nil)
+	model.conflictScreen.openReplayConflict(&model)
+	if model.state != Dashboard || model.dashboardScreen.updateMsg == "" {
+		t.Errorf("openReplayConflict() state = %v, message = %q, want dropped deletion reported", model.state, model.dashboardScreen.updateMsg)
+	}
+
+	gm.EXPECT().TakeReplayConflict().Return(nil, nil)
+	model.conflictScreen.openReplayConflict(&model)
+	if model.state != Dashboard {
+		t.Errorf("openReplayConflict() without conflict state = %v, want %v", model.state, Dashboard)
+	}
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/totp"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,6 +15,7 @@ import (
 type CredentialsScreen struct {
 	inputs     []textinput.Model
 	updateID   string
+	base       models.Credentials
 	createMode bool
 	focused    credentialsFormInput
 	title      string
@@ -24,7 +27,9 @@ const (
 	serviceName            credentialsFormInput = 0
 	identity               credentialsFormInput = 1
 	password               credentialsFormInput = 2
-	totalCredentialsFields credentialsFormInput = 3
+	credentialsTOTP        credentialsFormInput = 3
+	credentialsMetadata    credentialsFormInput = 4
+	totalCredentialsFields credentialsFormInput = 5
 )
 
 func NewCredentialsScreen() *CredentialsScreen {
@@ -42,8 +47,12 @@ func NewCredentialsScreen() *CredentialsScreen {
 	password.Placeholder = "Password"
 	password.Width = 200
 
+	totpSeed := textinput.New()
+	totpSeed.Placeholder = "TOTP seed, base32 secret or otpauth:// URI (optional)"
+	totpSeed.Width = 200
+
 	return &CredentialsScreen{
-		inputs:  []textinput.Model{serviceName, identity, password},
+		inputs:  []textinput.Model{serviceName, identity, password, totpSeed, newMetadataInput()},
 		focused: 0,
 		title:   "Please, enter credentials of service I should keep",
 	}
@@ -90,6 +99,14 @@ func (form *CredentialsScreen) validateAndSubmit(m *Model) error {
 	if m.err != nil {
 		return m.err
 	}
+	metadata, err := parseMetadata(form.inputs[credentialsMetadata].Value())
+	if err != nil {
+		return err
+	}
+	seed, err := form.totpSeed()
+	if err != nil {
+		return err
+	}
 
 	if form.createMode {
 		return m.clientService.CreateCredentials(
@@ -97,21 +114,33 @@ func (form *CredentialsScreen) validateAndSubmit(m *Model) error {
 			form.inputs[serviceName].Value(),
 			form.inputs[identity].Value(),
 			form.inputs[password].Value(),
+			seed,
+			metadata,
 		)
 	}
 
-	_, err := m.clientService.UpdateCredentials(
-		context.Background(),
-		models.Credentials{
-			ID:          form.updateID,
-			ServiceName: form.inputs[serviceName].Value(),
-			Identity:    form.inputs[identity].Value(),
-			Password:    form.inputs[password].Value(),
-		},
-	)
+	edited := models.Credentials{
+		ID:          form.updateID,
+		ServiceName: form.inputs[serviceName].Value(),
+		Identity:    form.inputs[identity].Value(),
+		Password:    form.inputs[password].Value(),
+		TOTP:        seed,
+		Metadata:    metadata,
+		Version:     form.base.Version,
+	}
+	_, err = m.clientService.UpdateCredentials(context.Background(), edited)
+	m.conflictScreen.openCredentials(m, form.base, edited, err)
 	return err
 }
 
+// totpSeed returns seed in form it is stored in, seed is optional
+func (form *CredentialsScreen) totpSeed() (string, error) {
+	if strings.TrimSpace(form.inputs[credentialsTOTP].Value()) == "" {
+		return "", nil
+	}
+	return totp.NormalizeSeed(form.inputs[credentialsTOTP].Value())
+}
+
 func (form *CredentialsScreen) moveFocusForward() {
 	if form.focused < totalCredentialsFields {
 		form.inputs[form.focused].Blur()
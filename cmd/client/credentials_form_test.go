@@ -3,8 +3,10 @@ package main
 import (
 	"testing"
 
+	"github.com/PaBah/GophKeeper/internal/mock"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
 )
 
 func TestCredentialsFormUpdate(t *testing.T) {
@@ -194,3 +196,28 @@ func TestCredentialsScreenValidateAndSubmit(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentialsScreen_TOTPSeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	m := NewModel(Dashboard)
+	m.clientService = gm
+	form := NewCredentialsScreen()
+	form.createMode = true
+	form.inputs[serviceName].SetValue("github")
+	form.inputs[identity].SetValue("alice")
+	form.inputs[password].SetValue("secret")
+
+	form.inputs[credentialsTOTP].SetValue("not a secret!")
+	if err := form.validateAndSubmit(&m); err == nil {
+		t.Errorf("validateAndSubmit() of invalid seed error = nil")
+	}
+
+	form.inputs[credentialsTOTP].SetValue("jbsw y3dp ehpk 3pxp")
+	gm.EXPECT().CreateCredentials(gomock.Any(), "github", "alice", "secret", "JBSWY3DPEHPK3PXP", gomock.Any()).Return(nil)
+	if err := form.validateAndSubmit(&m); err != nil {
+		t.Errorf("validateAndSubmit() error = %v", err)
+	}
+}
@@ -3,17 +3,26 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/PaBah/GophKeeper/internal/client"
 	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/totp"
 	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 const (
-	defaultMessage = "Welcome to the dashboard. Select an option from the menu on the left!"
+	defaultMessage  = "Welcome to the dashboard. Select an option from the menu on the left!"
+	securityMessage = "Two-factor authentication asks for code of authenticator app on every sign in.\n" +
+		"Recovery codes shown on enabling replace the app when it is lost.\n" +
+		"Changing password signs out all other devices, master password of vault stays the same.\n" +
+		"Changing master password keeps stored items readable, other devices ask for new one on next unlock.\n" +
+		"Deleting account erases all its data on server and this device."
 )
 
 var (
@@ -46,6 +55,9 @@ const (
 	credentials menuItem = iota
 	cards
 	files
+	notes
+	trash
+	security
 	exit
 )
 
@@ -55,15 +67,19 @@ type DashboardScreen struct {
 	cardsState       []models.Card
 	credentialsState []models.Credentials
 	filesState       []models.File
+	notesState       []models.Note
+	trashState       []models.TrashItem
 	menu             []string
 	content          string
 	updateMsg        string
 	tableNavigation  bool
+	// sessionExpired - session can not be restored after offline sign in, user has to sign in again
+	sessionExpired error
 }
 
 func NewDashboardScreen() *DashboardScreen {
 	return &DashboardScreen{
-		menu:            []string{"Credentials", "Cards", "Files", "Exit"},
+		menu:            []string{"Credentials", "Cards", "Files", "Notes", "Trash", "Security", "Exit"},
 		content:         defaultMessage,
 		tableNavigation: false,
 	}
@@ -88,12 +104,16 @@ func (ds *DashboardScreen) drawCredentials(m *Model) string {
 		headerStyle.Render("ID"),
 		headerStyle.Render("ServiceName"),
 		headerStyle.Render("UploadedAt"),
+		headerStyle.Render("TOTP"),
+		headerStyle.Render("Metadata"),
 	)
 	tableData := []string{borderStyle.Render(headers)}
+	now := time.Now()
 	for index, credential := range ds.credentialsState {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			ds.renderRow(index, credential.ID, credential.ServiceName, credential.UploadedAt.Format(time.RFC3339)),
+			ds.renderRow(index, credential.ID, credential.ServiceName, credential.UploadedAt.Format(time.RFC3339),
+				formatTOTP(credential.TOTP, now), formatMetadata(credential.Metadata)),
 		)
 		tableData = append(tableData, borderStyle.Render(row))
 	}
@@ -106,18 +126,51 @@ func (ds *DashboardScreen) drawCredentials(m *Model) string {
 	return table
 }
 
+// formatTOTP - current code of credentials with seconds it stays valid, "-" when credentials have no seed
+func formatTOTP(seed string, now time.Time) string {
+	if seed == "" {
+		return "-"
+	}
+	code, remaining, err := totp.CurrentCode(seed, now)
+	if err != nil {
+		return "invalid seed"
+	}
+	return fmt.Sprintf("%s (%ds)", code, int(remaining.Seconds()))
+}
+
+// totpTickMsg - time to redraw codes of credentials, they change every period of seed
+type totpTickMsg time.Time
+
+// changeEventMsg - change made by another session, it is received in background and applied in Update of model
+type changeEventMsg models.ChangeEvent
+
+func tickTOTP() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return totpTickMsg(t)
+	})
+}
+
+// refreshTOTP redraws list of credentials so countdown of codes stays live.
+func (ds *DashboardScreen) refreshTOTP(m *Model) {
+	if m.state == Dashboard && ds.cursor == credentials && ds.tableNavigation {
+		ds.content = ds.drawContent(m)
+	}
+}
+
 func (ds *DashboardScreen) drawCards(m *Model) string {
 	headers := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		headerStyle.Render("LastDigits"),
 		headerStyle.Render("ExpirationDate"),
 		headerStyle.Render("UploadedAt"),
+		headerStyle.Render("Metadata"),
 	)
 	tableData := []string{borderStyle.Render(headers)}
 	for index, card := range ds.cardsState {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			ds.renderRow(index, "*"+card.Number[12:], card.ExpirationDate, card.UploadedAt.Format(time.RFC3339)),
+			ds.renderRow(index, "*"+card.Number[12:], card.ExpirationDate, card.UploadedAt.Format(time.RFC3339),
+				formatMetadata(card.Metadata)),
 		)
 		tableData = append(tableData, borderStyle.Render(row))
 	}
@@ -136,12 +189,89 @@ func (ds *DashboardScreen) drawFiles(m *Model) string {
 		headerStyle.Render("Name"),
 		headerStyle.Render("UploadedAt"),
 		headerStyle.Render("Size"),
+		headerStyle.Render("Metadata"),
 	)
 	tableData := []string{borderStyle.Render(headers)}
 	for index, file := range ds.filesState {
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
-			ds.renderRow(index, file.Name, file.UploadedAt.Format(time.RFC3339), file.Size),
+			ds.renderRow(index, file.Name, file.UploadedAt.Format(time.RFC3339), file.Size, formatMetadata(file.Metadata)),
+		)
+		tableData = append(tableData, borderStyle.Render(row))
+	}
+
+	table := lipgloss.JoinVertical(
+		lipgloss.Left,
+		tableData...,
+	)
+
+	return table
+}
+
+func (ds *DashboardScreen) drawNotes(m *Model) string {
+	headers := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		headerStyle.Render("ID"),
+		headerStyle.Render("Title"),
+		headerStyle.Render("UploadedAt"),
+		headerStyle.Render("Metadata"),
+	)
+	tableData := []string{borderStyle.Render(headers)}
+	for index, note := range ds.notesState {
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			ds.renderRow(index, note.ID, note.Title, note.UploadedAt.Format(time.RFC3339), formatMetadata(note.Metadata)),
+		)
+		tableData = append(tableData, borderStyle.Render(row))
+	}
+
+	table := lipgloss.JoinVertical(
+		lipgloss.Left,
+		tableData...,
+	)
+
+	return table
+}
+
+// trashItemName - name by which user recognizes deleted item
+func trashItemName(item models.TrashItem) string {
+	switch trashed := item.Item.(type) {
+	case models.Credentials:
+		return trashed.ServiceName
+	case models.Card:
+		if len(trashed.Number) > 4 {
+			return "*" + trashed.Number[len(trashed.Number)-4:]
+		}
+		return trashed.Number
+	case models.Note:
+		return trashed.Title
+	case models.File:
+		return trashed.Name
+	}
+	return ""
+}
+
+// trashItemID - ID of deleted item
+func trashItemID(item models.TrashItem) string {
+	if file, ok := item.Item.(models.File); ok {
+		return file.ID
+	}
+	id, _ := itemVersion(item.Item)
+	return id
+}
+
+func (ds *DashboardScreen) drawTrash(m *Model) string {
+	headers := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		headerStyle.Render("Type"),
+		headerStyle.Render("Name"),
+		headerStyle.Render("DeletedAt"),
+	)
+	tableData := []string{borderStyle.Render(headers)}
+	for index, item := range ds.trashState {
+		row := lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			ds.renderRow(index, item.ItemType, trashItemName(item), item.DeletedAt.Format(time.RFC3339)),
 		)
 		tableData = append(tableData, borderStyle.Render(row))
 	}
@@ -162,6 +292,12 @@ func (ds *DashboardScreen) drawContent(m *Model) string {
 		return ds.drawCards(m)
 	case files:
 		return ds.drawFiles(m)
+	case notes:
+		return ds.drawNotes(m)
+	case trash:
+		return ds.drawTrash(m)
+	case security:
+		return securityMessage
 	default:
 		return ""
 	}
@@ -175,6 +311,10 @@ func (ds *DashboardScreen) getListAmount(m *Model) int {
 		return len(ds.cardsState)
 	case files:
 		return len(ds.filesState)
+	case notes:
+		return len(ds.notesState)
+	case trash:
+		return len(ds.trashState)
 	default:
 		return 0
 	}
@@ -235,6 +375,8 @@ func (ds *DashboardScreen) handleKeyMsg(m *Model, msg tea.KeyMsg) (tea.Model, te
 		return ds.handleF6Key(m)
 	case "f7":
 		return ds.handleF7Key(m)
+	case "f8":
+		return ds.handleF8Key(m)
 	case "shift+right":
 		ds.handleShiftRightKey(m)
 	case "enter":
@@ -282,6 +424,8 @@ func (ds *DashboardScreen) handleCredentialsCreate(m *Model) {
 	m.credentialsScreen.inputs[serviceName].SetValue("")
 	m.credentialsScreen.inputs[identity].SetValue("")
 	m.credentialsScreen.inputs[password].SetValue("")
+	m.credentialsScreen.inputs[credentialsTOTP].SetValue("")
+	m.credentialsScreen.inputs[credentialsMetadata].SetValue("")
 	m.credentialsScreen.updateID = ""
 	m.state = CredentialsForm
 }
@@ -292,6 +436,7 @@ func (ds *DashboardScreen) handleCardsCreate(m *Model) {
 	m.cardsScreen.inputs[expiryDate].SetValue("")
 	m.cardsScreen.inputs[cardHolder].SetValue("")
 	m.cardsScreen.inputs[cvv].SetValue("")
+	m.cardsScreen.inputs[cardMetadata].SetValue("")
 	m.cardsScreen.updateID = ""
 	m.state = CardForm
 }
@@ -305,6 +450,13 @@ func (ds *DashboardScreen) handleF1Key(m *Model) (tea.Model, tea.Cmd) {
 	case files:
 		m.state = FileLoad
 		return m, m.filesScreen.filepicker.Init()
+	case notes:
+		m.notesScreen.reset(models.Note{}, true)
+		m.state = NoteForm
+	case trash:
+		ds.restoreFromTrash(m)
+	case security:
+		m.totpScreen.enroll(m)
 	default:
 		return m, nil
 	}
@@ -316,7 +468,10 @@ func (ds *DashboardScreen) handleCredentialsEdit(m *Model) {
 	m.credentialsScreen.inputs[serviceName].SetValue(ds.credentialsState[ds.tableCursor].ServiceName)
 	m.credentialsScreen.inputs[identity].SetValue(ds.credentialsState[ds.tableCursor].Identity)
 	m.credentialsScreen.inputs[password].SetValue(ds.credentialsState[ds.tableCursor].Password)
+	m.credentialsScreen.inputs[credentialsTOTP].SetValue(ds.credentialsState[ds.tableCursor].TOTP)
+	m.credentialsScreen.inputs[credentialsMetadata].SetValue(formatMetadata(ds.credentialsState[ds.tableCursor].Metadata))
 	m.credentialsScreen.updateID = ds.credentialsState[ds.tableCursor].ID
+	m.credentialsScreen.base = ds.credentialsState[ds.tableCursor]
 	m.state = CredentialsForm
 }
 
@@ -326,7 +481,9 @@ func (ds *DashboardScreen) handleCardsEdit(m *Model) {
 	m.cardsScreen.inputs[expiryDate].SetValue(ds.cardsState[ds.tableCursor].ExpirationDate)
 	m.cardsScreen.inputs[cardHolder].SetValue(ds.cardsState[ds.tableCursor].HolderName)
 	m.cardsScreen.inputs[cvv].SetValue(ds.cardsState[ds.tableCursor].CVV)
+	m.cardsScreen.inputs[cardMetadata].SetValue(formatMetadata(ds.cardsState[ds.tableCursor].Metadata))
 	m.cardsScreen.updateID = ds.cardsState[ds.tableCursor].ID
+	m.cardsScreen.base = ds.cardsState[ds.tableCursor]
 	m.state = CardForm
 }
 
@@ -337,7 +494,12 @@ func (ds *DashboardScreen) handleF2Key(m *Model) (tea.Model, tea.Cmd) {
 	case cards:
 		ds.handleCardsEdit(m)
 	case files:
-		m.clientService.DownloadsFile(context.Background(), ds.filesState[ds.tableCursor].Name)
+		m.clientService.DownloadsFile(context.Background(), ds.filesState[ds.tableCursor].ID)
+	case notes:
+		m.notesScreen.reset(ds.notesState[ds.tableCursor], false)
+		m.state = NoteForm
+	case security:
+		m.passwordScreen.open(m, false)
 	default:
 		return m, nil
 	}
@@ -345,26 +507,75 @@ func (ds *DashboardScreen) handleF2Key(m *Model) (tea.Model, tea.Cmd) {
 }
 
 func (ds *DashboardScreen) deleteCredentials(m *Model) {
-	_ = m.clientService.DeleteCredentials(context.Background(), ds.credentialsState[ds.tableCursor].ID)
+	item := ds.credentialsState[ds.tableCursor]
+	err := m.clientService.DeleteCredentials(context.Background(), item.ID, item.Version)
 	ds.tableCursor = max(ds.tableCursor-1, 0)
 	ds.loadActual(m)
+	ds.reportDeletionConflict(m, err)
 	ds.content = ds.drawContent(m)
 }
 
 func (ds *DashboardScreen) deleteCard(m *Model) {
-	_ = m.clientService.DeleteCard(context.Background(), ds.cardsState[ds.tableCursor].ID)
+	item := ds.cardsState[ds.tableCursor]
+	err := m.clientService.DeleteCard(context.Background(), item.ID, item.Version)
 	ds.tableCursor = max(ds.tableCursor-1, 0)
 	ds.loadActual(m)
+	ds.reportDeletionConflict(m, err)
 	ds.content = ds.drawContent(m)
 }
 
 func (ds *DashboardScreen) deleteFile(m *Model) {
-	_ = m.clientService.DeleteFile(context.Background(), ds.filesState[ds.tableCursor].Name)
+	_ = m.clientService.DeleteFile(context.Background(), ds.filesState[ds.tableCursor].ID)
+	ds.tableCursor = max(ds.tableCursor-1, 0)
+	ds.loadActual(m)
+	ds.content = ds.drawContent(m)
+}
+
+func (ds *DashboardScreen) deleteNote(m *Model) {
+	item := ds.notesState[ds.tableCursor]
+	err := m.clientService.DeleteNote(context.Background(), item.ID, item.Version)
 	ds.tableCursor = max(ds.tableCursor-1, 0)
 	ds.loadActual(m)
+	ds.reportDeletionConflict(m, err)
 	ds.content = ds.drawContent(m)
 }
 
+func (ds *DashboardScreen) restoreFromTrash(m *Model) {
+	if ds.tableCursor >= len(ds.trashState) {
+		return
+	}
+	item := ds.trashState[ds.tableCursor]
+	err := m.clientService.RestoreFromTrash(context.Background(), item.ItemType, trashItemID(item))
+	ds.tableCursor = max(ds.tableCursor-1, 0)
+	ds.loadActual(m)
+	ds.reportTrashError(m, err)
+	ds.content = ds.drawContent(m)
+}
+
+func (ds *DashboardScreen) emptyTrash(m *Model) {
+	err := m.clientService.EmptyTrash(context.Background())
+	ds.tableCursor = 0
+	ds.loadActual(m)
+	ds.reportTrashError(m, err)
+	ds.content = ds.drawContent(m)
+}
+
+// reportTrashError shows why trash was not changed, e.g. file with the same name was uploaded after deletion.
+func (ds *DashboardScreen) reportTrashError(m *Model, err error) {
+	if err != nil {
+		ds.updateMsg = "GophKeeper: " + err.Error()
+		m.err = errors.New(ds.updateMsg)
+	}
+}
+
+// reportDeletionConflict warns that item was kept because another client changed it, reloaded list shows the change.
+func (ds *DashboardScreen) reportDeletionConflict(m *Model, err error) {
+	if serverCopy(err) != nil {
+		ds.updateMsg = "GophKeeper: item was changed by another client and was not deleted, check it and retry"
+		m.err = errors.New(ds.updateMsg)
+	}
+}
+
 func (ds *DashboardScreen) handleF3Key(m *Model) (tea.Model, tea.Cmd) {
 	switch ds.cursor {
 	case credentials:
@@ -373,6 +584,13 @@ func (ds *DashboardScreen) handleF3Key(m *Model) (tea.Model, tea.Cmd) {
 		ds.deleteCard(m)
 	case files:
 		ds.deleteFile(m)
+	case notes:
+		ds.deleteNote(m)
+	case trash:
+		ds.emptyTrash(m)
+	case security:
+		m.totpScreen.reset(totpDisable)
+		m.state = TOTPForm
 	default:
 		return m, nil
 	}
@@ -384,6 +602,15 @@ func (ds *DashboardScreen) handleF4Key(m *Model) (tea.Model, tea.Cmd) {
 		_ = clipboard.WriteAll(ds.credentialsState[ds.tableCursor].Identity)
 	} else if ds.cursor == cards {
 		_ = clipboard.WriteAll(ds.cardsState[ds.tableCursor].Number)
+	} else if ds.cursor == notes {
+		_ = clipboard.WriteAll(ds.notesState[ds.tableCursor].Body)
+	} else if ds.cursor == files && ds.tableCursor < len(ds.filesState) {
+		file := ds.filesState[ds.tableCursor]
+		m.fileMetadataScreen.reset(file)
+		m.state = FileMetadataForm
+	} else if ds.cursor == security {
+		m.deleteAccountScreen.reset()
+		m.state = DeleteAccountForm
 	}
 	return m, nil
 }
@@ -393,17 +620,37 @@ func (ds *DashboardScreen) handleF5Key(m *Model) (tea.Model, tea.Cmd) {
 		_ = clipboard.WriteAll(ds.credentialsState[ds.tableCursor].Password)
 	} else if ds.cursor == cards {
 		_ = clipboard.WriteAll(ds.cardsState[ds.tableCursor].ExpirationDate)
+	} else if ds.cursor == security {
+		m.passwordScreen.open(m, true)
 	}
 	return m, nil
 }
 
 func (ds *DashboardScreen) handleF6Key(m *Model) (tea.Model, tea.Cmd) {
-	if ds.cursor == cards {
+	if ds.cursor == credentials && ds.tableCursor < len(ds.credentialsState) {
+		ds.copyTOTPCode(m, ds.credentialsState[ds.tableCursor].TOTP)
+	} else if ds.cursor == cards {
 		_ = clipboard.WriteAll(ds.cardsState[ds.tableCursor].HolderName)
 	}
 	return m, nil
 }
 
+// copyTOTPCode - copy current code of credentials seed, code is computed locally so it works offline
+func (ds *DashboardScreen) copyTOTPCode(m *Model, seed string) {
+	if seed == "" {
+		ds.updateMsg = "GophKeeper: credentials have no TOTP seed"
+		m.err = errors.New(ds.updateMsg)
+		return
+	}
+	code, _, err := totp.CurrentCode(seed, time.Now())
+	if err != nil {
+		ds.updateMsg = "GophKeeper: " + err.Error()
+		m.err = errors.New(ds.updateMsg)
+		return
+	}
+	_ = clipboard.WriteAll(code)
+}
+
 func (ds *DashboardScreen) handleF7Key(m *Model) (tea.Model, tea.Cmd) {
 	if ds.cursor == cards {
 		_ = clipboard.WriteAll(ds.cardsState[ds.tableCursor].CVV)
@@ -411,6 +658,17 @@ func (ds *DashboardScreen) handleF7Key(m *Model) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (ds *DashboardScreen) handleF8Key(m *Model) (tea.Model, tea.Cmd) {
+	if ds.cursor == credentials && ds.tableCursor < len(ds.credentialsState) {
+		m.historyScreen.open(m, models.CredentialsItem, ds.credentialsState[ds.tableCursor])
+	} else if ds.cursor == cards && ds.tableCursor < len(ds.cardsState) {
+		m.historyScreen.open(m, models.CardItem, ds.cardsState[ds.tableCursor])
+	} else if ds.cursor == notes && ds.tableCursor < len(ds.notesState) {
+		m.historyScreen.open(m, models.NoteItem, ds.notesState[ds.tableCursor])
+	}
+	return m, nil
+}
+
 func (ds *DashboardScreen) handleShiftRightKey(m *Model) {
 	if m.err != nil {
 		ds.loadActual(m)
@@ -434,12 +692,10 @@ func (ds *DashboardScreen) handleEnterKey(m *Model) (tea.Model, tea.Cmd) {
 func (ds *DashboardScreen) loadActual(m *Model) {
 	ds.updateMsg = ""
 	switch ds.cursor {
-	case credentials:
-		ds.credentialsState, _ = m.clientService.GetCredentials(context.Background())
-	case cards:
-		ds.cardsState, _ = m.clientService.GetCards(context.Background())
-	case files:
-		ds.filesState, _ = m.clientService.GetFiles(context.Background())
+	case credentials, cards, files, notes:
+		ds.syncVault(m)
+	case trash:
+		ds.trashState, _ = m.clientService.ListTrash(context.Background())
 	default:
 		ds.updateMsg = ""
 	}
@@ -460,3 +716,86 @@ func (ds *DashboardScreen) View(m *Model) string {
 		contentStyle.Render(ds.content),
 	)
 }
+
+// syncVault brings all lists of vault items up to date with server
+func (ds *DashboardScreen) syncVault(m *Model) {
+	vault, err := m.clientService.Sync(context.Background())
+	if errors.Is(err, client.ErrSessionExpired) {
+		ds.sessionExpired = err
+	}
+	if err != nil {
+		return
+	}
+	ds.credentialsState, ds.cardsState, ds.filesState, ds.notesState = vault.Credentials, vault.Cards, vault.Files, vault.Notes
+}
+
+// upsertItem replaces item with the same key in list or appends it as a new one
+func upsertItem[T any](items []T, item T, key func(T) string) []T {
+	for i := range items {
+		if key(items[i]) == key(item) {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// removeItem drops item with given key from list
+func removeItem[T any](items []T, id string, key func(T) string) []T {
+	return slices.DeleteFunc(items, func(item T) bool { return key(item) == id })
+}
+
+// changeMessage - notification about change made on another device
+func changeMessage(event models.ChangeEvent) string {
+	operations := map[string]string{
+		models.ChangeCreated: "created",
+		models.ChangeUpdated: "updated",
+		models.ChangeDeleted: "deleted",
+	}
+	device := event.Device
+	if device == "" {
+		device = "another device"
+	}
+	return fmt.Sprintf("GophKeeper: %s %s on %s", event.ItemType, operations[event.Operation], device)
+}
+
+// applyChange applies change made by another session to lists of vault items, so they are up to date without refresh.
+// Changes which come without item are synced with server.
+func (ds *DashboardScreen) applyChange(m *Model, event models.ChangeEvent) {
+	credentialsID := func(item models.Credentials) string { return item.ID }
+	cardID := func(item models.Card) string { return item.ID }
+	noteID := func(item models.Note) string { return item.ID }
+	fileID := func(item models.File) string { return item.ID }
+
+	switch item := event.Item.(type) {
+	case models.Credentials:
+		ds.credentialsState = upsertItem(ds.credentialsState, item, credentialsID)
+	case models.Card:
+		ds.cardsState = upsertItem(ds.cardsState, item, cardID)
+	case models.Note:
+		ds.notesState = upsertItem(ds.notesState, item, noteID)
+	case models.File:
+		ds.filesState = upsertItem(ds.filesState, item, fileID)
+	default:
+		if event.Operation != models.ChangeDeleted {
+			ds.syncVault(m)
+			break
+		}
+		switch event.ItemType {
+		case models.CredentialsItem:
+			ds.credentialsState = removeItem(ds.credentialsState, event.ItemID, credentialsID)
+		case models.CardItem:
+			ds.cardsState = removeItem(ds.cardsState, event.ItemID, cardID)
+		case models.NoteItem:
+			ds.notesState = removeItem(ds.notesState, event.ItemID, noteID)
+		case models.FileItem:
+			ds.filesState = removeItem(ds.filesState, event.ItemID, fileID)
+		}
+	}
+
+	ds.updateMsg = changeMessage(event)
+	if ds.tableNavigation {
+		ds.tableCursor = max(min(ds.tableCursor, ds.getListAmount(m)-1), 0)
+		ds.content = ds.drawContent(m)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/PaBah/GophKeeper/internal/client"
 	"github.com/PaBah/GophKeeper/internal/mock"
@@ -60,16 +62,12 @@ func TestDashboardScreen_HandleKeyMsg(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	gm := mock.NewMockGRPCClientProvider(ctrl)
 	gm.EXPECT().
-		GetCredentials(gomock.Any()).
-		Return([]models.Credentials{models.Credentials{}}, nil).
-		AnyTimes()
-	gm.EXPECT().
-		GetCards(gomock.Any()).
-		Return([]models.Card{models.Card{}}, nil).
-		AnyTimes()
-	gm.EXPECT().
-		GetFiles(gomock.Any()).
-		Return([]models.File{models.File{}}, nil).
+		Sync(gomock.Any()).
+		Return(models.ChangeSet{
+			Credentials: []models.Credentials{models.Credentials{}},
+			Cards:       []models.Card{models.Card{}},
+			Files:       []models.File{models.File{}},
+		}, nil).
 		AnyTimes()
 
 	clientMock = gm
@@ -89,9 +87,13 @@ func TestDashboardScreen_HandleKeyMsg(t *testing.T) {
 func TestDashboardScreen_LoadActual(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	gm := mock.NewMockGRPCClientProvider(ctrl)
-	gm.EXPECT().GetCredentials(gomock.Any()).Return([]models.Credentials{models.Credentials{}}, nil).AnyTimes()
-	gm.EXPECT().GetCards(gomock.Any()).Return([]models.Card{models.Card{}}, nil).AnyTimes()
-	gm.EXPECT().GetFiles(gomock.Any()).Return([]models.File{models.File{}}, nil).AnyTimes()
+	gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{
+		Credentials: []models.Credentials{models.Credentials{}},
+		Cards:       []models.Card{models.Card{}},
+		Files:       []models.File{models.File{}},
+		Notes:       []models.Note{models.Note{}},
+	}, nil).AnyTimes()
+	gm.EXPECT().ListTrash(gomock.Any()).Return([]models.TrashItem{{ItemType: models.NoteItem, Item: models.Note{}}}, nil).AnyTimes()
 
 	tests := []struct {
 		name       string
@@ -101,6 +103,8 @@ func TestDashboardScreen_LoadActual(t *testing.T) {
 		{name: "credentials", mockClient: gm, cursor: credentials},
 		{name: "cards", mockClient: gm, cursor: cards},
 		{name: "files", mockClient: gm, cursor: files},
+		{name: "notes", mockClient: gm, cursor: notes},
+		{name: "trash", mockClient: gm, cursor: trash},
 	}
 
 	for _, tt := range tests {
@@ -401,11 +405,13 @@ func TestDashboardScreen_handleF3Key(t *testing.T) {
 	var clientMock client.GRPCClientProvider
 	ctrl := gomock.NewController(t)
 	gm := mock.NewMockGRPCClientProvider(ctrl)
-	gm.EXPECT().GetCredentials(gomock.Any()).Return([]models.Credentials{models.Credentials{}}, nil).AnyTimes()
-	gm.EXPECT().GetCards(gomock.Any()).Return([]models.Card{models.Card{Number: "5424003791772490"}}, nil).AnyTimes()
-	gm.EXPECT().GetFiles(gomock.Any()).Return([]models.File{models.File{}}, nil).AnyTimes()
-	gm.EXPECT().DeleteCredentials(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	gm.EXPECT().DeleteCard(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{
+		Credentials: []models.Credentials{models.Credentials{}},
+		Cards:       []models.Card{models.Card{Number: "5424003791772490"}},
+		Files:       []models.File{models.File{}},
+	}, nil).AnyTimes()
+	gm.EXPECT().DeleteCredentials(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	gm.EXPECT().DeleteCard(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	gm.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	clientMock = gm
 
@@ -473,8 +479,8 @@ func TestDashboardScreen_handleF2Key(t *testing.T) {
 			}
 			m := NewModel(Dashboard)
 			m.clientService = clientMock
-			m.credentialsScreen.inputs = []textinput.Model{textinput.New(), textinput.New(), textinput.New()}
-			m.cardsScreen.inputs = []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New()}
+			m.credentialsScreen.inputs = []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New(), textinput.New()}
+			m.cardsScreen.inputs = []textinput.Model{textinput.New(), textinput.New(), textinput.New(), textinput.New(), textinput.New()}
 			ds.credentialsState = []models.Credentials{models.Credentials{ServiceName: "test", Identity: "test", Password: "<PASSWORD>"}}
 			ds.cardsState = []models.Card{models.Card{Number: "1111111111111111", HolderName: "test", ExpirationDate: "12/34", CVV: "123"}}
 			ds.filesState = []models.File{models.File{Name: "test.test", Size: "12 Kb"}}
@@ -485,3 +491,75 @@ func TestDashboardScreen_handleF2Key(t *testing.T) {
 		})
 	}
 }
+
+func TestDashboardScreen_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	m := NewModel(Dashboard)
+	m.clientService = gm
+	ds := m.dashboardScreen
+	ds.cursor = trash
+	ds.tableNavigation = true
+	ds.trashState = []models.TrashItem{
+		{ItemType: models.CardItem, Item: models.Card{ID: "1", Number: "5424003791772490"}},
+		{ItemType: models.FileItem, Item: models.File{ID: "8f14e45f", Name: "report.pdf"}},
+	}
+	if name := trashItemName(ds.trashState[0]); name != "*2490" {
+		t.Errorf("trashItemName() = %v, want last digits of card", name)
+	}
+
+	ds.tableCursor = 1
+	gm.EXPECT().RestoreFromTrash(gomock.Any(), models.FileItem, "8f14e45f").
+		Return(errors.New("file with the same name already exists"))
+	gm.EXPECT().ListTrash(gomock.Any()).Return(ds.trashState, nil)
+	ds.handleF1Key(&m)
+	if m.err == nil {
+		t.Errorf("restoreFromTrash() error = nil, want reason why file was kept in trash")
+	}
+
+	gm.EXPECT().EmptyTrash(gomock.Any()).Return(nil)
+	gm.EXPECT().ListTrash(gomock.Any()).Return([]models.TrashItem{}, nil)
+	ds.handleF3Key(&m)
+	if len(ds.trashState) != 0 || ds.updateMsg != "" {
+		t.Errorf("emptyTrash() trash = %v, message = %v, want empty trash", ds.trashState, ds.updateMsg)
+	}
+}
+
+func TestDashboardScreen_TOTP(t *testing.T) {
+	if got := formatTOTP("", time.Unix(59, 0)); got != "-" {
+		t.Errorf("formatTOTP() of empty seed = %q, want -", got)
+	}
+	if got := formatTOTP("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", time.Unix(59, 0)); got != "287082 (1s)" {
+		t.Errorf("formatTOTP() = %q, want 287082 (1s)", got)
+	}
+	if got := formatTOTP("not a secret!", time.Unix(59, 0)); got != "invalid seed" {
+		t.Errorf("formatTOTP() of invalid seed = %q, want invalid seed", got)
+	}
+
+	m := NewModel(Dashboard)
+	ds := m.dashboardScreen
+	ds.cursor = credentials
+	ds.tableNavigation = true
+	ds.credentialsState = []models.Credentials{{ID: "1", ServiceName: "github"}}
+	ds.refreshTOTP(&m)
+	if !strings.Contains(ds.content, "TOTP") || !strings.Contains(ds.content, "github") {
+		t.Errorf("refreshTOTP() content = %v, want credentials with TOTP column", ds.content)
+	}
+
+	_, _ = ds.handleF6Key(&m)
+	if m.err == nil || ds.updateMsg == "" {
+		t.Errorf("F6 on credentials without seed error = nil")
+	}
+
+	ds.cursor = cards
+	ds.content = ""
+	ds.refreshTOTP(&m)
+	if ds.content != "" {
+		t.Errorf("refreshTOTP() redraws content = %v, want only list of credentials redrawn", ds.content)
+	}
+
+	updated, cmd := m.Update(totpTickMsg(time.Now()))
+	if cmd == nil || updated.(Model).state != Dashboard {
+		t.Errorf("Update() of tick did not schedule next tick")
+	}
+}
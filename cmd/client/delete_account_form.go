@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	deleteAccountTitle = "Deleting account erases all credentials, cards, notes and files on server and this device.\n" +
+		"It can not be undone. Please, enter your password and type DELETE to confirm:"
+	// deleteAccountConfirmation - word user types to confirm deletion
+	deleteAccountConfirmation = "DELETE"
+)
+
+// DeleteAccountScreen - erasure of account, password is asked again along with typed confirmation
+type DeleteAccountScreen struct {
+	passwordInput     textinput.Model
+	confirmationInput textinput.Model
+	focusIndex        int
+}
+
+func NewDeleteAccountScreen() *DeleteAccountScreen {
+	password := textinput.New()
+	password.Placeholder = "Password"
+	password.EchoMode = textinput.EchoPassword
+	password.EchoCharacter = '•'
+	password.Width = 30
+
+	confirmation := textinput.New()
+	confirmation.Placeholder = deleteAccountConfirmation
+	confirmation.Width = 30
+
+	form := &DeleteAccountScreen{passwordInput: password, confirmationInput: confirmation}
+	form.reset()
+	return form
+}
+
+// reset clears entered values and focuses password input.
+func (form *DeleteAccountScreen) reset() {
+	form.passwordInput.SetValue("")
+	form.confirmationInput.SetValue("")
+	form.focusIndex = 0
+	form.updateFocus()
+}
+
+func (form *DeleteAccountScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.Type {
+	case tea.KeyEnter:
+		if form.focusIndex < 2 {
+			form.focusIndex++
+			form.updateFocus()
+			return m, nil
+		}
+		form.submit(m)
+		return m, nil
+	case tea.KeyUp:
+		if form.focusIndex > 0 {
+			form.focusIndex--
+		}
+		form.updateFocus()
+		return m, nil
+	case tea.KeyDown:
+		if form.focusIndex < 2 {
+			form.focusIndex++
+		}
+		form.updateFocus()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	switch form.focusIndex {
+	case 0:
+		form.passwordInput, cmd = form.passwordInput.Update(msg)
+	case 1:
+		form.confirmationInput, cmd = form.confirmationInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (form *DeleteAccountScreen) updateFocus() {
+	form.passwordInput.Blur()
+	form.confirmationInput.Blur()
+	switch form.focusIndex {
+	case 0:
+		form.passwordInput.Focus()
+	case 1:
+		form.confirmationInput.Focus()
+	}
+}
+
+func (form *DeleteAccountScreen) validateInputs() error {
+	if form.passwordInput.Value() == "" {
+		return errors.New("no value: Password is required")
+	}
+	if form.confirmationInput.Value() != deleteAccountConfirmation {
+		return fmt.Errorf("incorrect value: Type %s to confirm deletion", deleteAccountConfirmation)
+	}
+	return nil
+}
+
+// submit deletes account and returns to initial screen, decrypted items of dashboard are dropped with it.
+func (form *DeleteAccountScreen) submit(m *Model) {
+	if m.err = form.validateInputs(); m.err != nil {
+		return
+	}
+	deletedAt, err := m.clientService.DeleteAccount(context.Background(), form.passwordInput.Value())
+	form.reset()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.dashboardScreen = NewDashboardScreen()
+	m.err = fmt.Errorf("GophKeeper: account is deleted at %s, all its data is erased", deletedAt.Local().Format(time.DateTime))
+	m.state = Initial
+}
+
+func (form *DeleteAccountScreen) View(m *Model) string {
+	submitButton := buttonBlurredStyle.Render("Delete account")
+	if form.focusIndex == 2 {
+		submitButton = buttonStyle.Render("Delete account")
+	}
+
+	ui := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(deleteAccountTitle),
+		form.passwordInput.View(),
+		form.confirmationInput.View(),
+		submitButton,
+	)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeleteAccountScreen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = security
+	model.dashboardScreen.notesState = []models.Note{{ID: "1", Title: "wifi", Body: "secret"}}
+
+	_, _ = model.dashboardScreen.handleF4Key(&model)
+	if model.state != DeleteAccountForm {
+		t.Fatalf("F4 on security state = %v, want %v", model.state, DeleteAccountForm)
+	}
+
+	model.deleteAccountScreen.passwordInput.SetValue("password")
+	model.deleteAccountScreen.confirmationInput.SetValue("delete")
+	model.deleteAccountScreen.focusIndex = 2
+	_, _ = model.deleteAccountScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || !strings.Contains(model.err.Error(), deleteAccountConfirmation) {
+		t.Errorf("submit() without confirmation error = %v", model.err)
+	}
+
+	model.deleteAccountScreen.confirmationInput.SetValue(deleteAccountConfirmation)
+	gm.EXPECT().DeleteAccount(gomock.Any(), "password").DoAndReturn(
+		func(ctx context.Context, password string) (time.Time, error) {
+			return time.Time{}, errors.New("DeleteAccount: password is not valid")
+		})
+	_, _ = model.deleteAccountScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || model.state != DeleteAccountForm || model.deleteAccountScreen.passwordInput.Value() != "" {
+		t.Errorf("submit() error = %v, state = %v, want wrong password cleared", model.err, model.state)
+	}
+
+	model.deleteAccountScreen.passwordInput.SetValue("password")
+	model.deleteAccountScreen.confirmationInput.SetValue(deleteAccountConfirmation)
+	model.deleteAccountScreen.focusIndex = 2
+	gm.EXPECT().DeleteAccount(gomock.Any(), "password").DoAndReturn(
+		func(ctx context.Context, password string) (time.Time, error) {
+			return time.Now(), nil
+		})
+	_, _ = model.deleteAccountScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.state != Initial || model.err == nil || !strings.Contains(model.err.Error(), "account is deleted") {
+		t.Fatalf("submit() error = %v, state = %v, want %v", model.err, model.state, Initial)
+	}
+	if len(model.dashboardScreen.notesState) != 0 {
+		t.Errorf("submit() kept %d notes of deleted account", len(model.dashboardScreen.notesState))
+	}
+
+	model.state = DeleteAccountForm
+	gm.EXPECT().TakeReplayConflict().Return(nil, nil)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if updated.(Model).state != Dashboard {
+		t.Errorf("shift+tab state = %v, want %v", updated.(Model).state, Dashboard)
+	}
+}
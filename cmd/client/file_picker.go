@@ -21,7 +21,7 @@ func (fp *FilePicker) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	if didSelect, path := fp.filepicker.DidSelectFile(msg); didSelect {
 		log.Println("Selected file:", path)
-		m.clientService.UploadFile(context.Background(), path)
+		m.clientService.UploadFile(context.Background(), path, nil)
 		m.dashboardScreen.loadActual(m)
 		m.dashboardScreen.content = m.dashboardScreen.drawContent(m)
 		m.state = Dashboard
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// itemFields - names and values of fields of credentials, card or note as they are shown to user
+func itemFields(item any) (names, values []string) {
+	switch item := item.(type) {
+	case models.Credentials:
+		return []string{"Service name", "Identity", "Password", "TOTP seed", "Metadata"},
+			[]string{item.ServiceName, item.Identity, item.Password, item.TOTP, formatMetadata(item.Metadata)}
+	case models.Card:
+		return []string{"Number", "Expiration date", "Holder name", "CVV", "Metadata"},
+			[]string{item.Number, item.ExpirationDate, item.HolderName, item.CVV, formatMetadata(item.Metadata)}
+	case models.Note:
+		return []string{"Title", "Body", "Metadata"},
+			[]string{item.Title, item.Body, formatMetadata(item.Metadata)}
+	}
+	return nil, nil
+}
+
+// itemVersion - ID and version of credentials, card or note
+func itemVersion(item any) (id string, version int64) {
+	switch item := item.(type) {
+	case models.Credentials:
+		return item.ID, item.Version
+	case models.Card:
+		return item.ID, item.Version
+	case models.Note:
+		return item.ID, item.Version
+	}
+	return "", 0
+}
+
+// HistoryScreen - previous revisions of item, selected revision is compared with the state which replaced it
+type HistoryScreen struct {
+	itemType  string
+	current   any
+	revisions []models.Revision
+	cursor    int
+}
+
+func NewHistoryScreen() *HistoryScreen {
+	return &HistoryScreen{}
+}
+
+// open loads history of current item and shows it, dashboard reports error if history can not be loaded.
+func (form *HistoryScreen) open(m *Model, itemType string, current any) {
+	id, _ := itemVersion(current)
+	revisions, err := m.clientService.ListItemHistory(context.Background(), itemType, id)
+	if err != nil {
+		m.dashboardScreen.updateMsg = "GophKeeper: history is not available: " + err.Error()
+		m.err = errors.New(m.dashboardScreen.updateMsg)
+		return
+	}
+	form.itemType = itemType
+	form.current = current
+	form.revisions = revisions
+	form.cursor = 0
+	m.state = HistoryForm
+}
+
+// next - state of item which replaced selected revision and its label
+func (form *HistoryScreen) next() (any, string) {
+	if form.cursor == 0 {
+		return form.current, "Current"
+	}
+	newer := form.revisions[form.cursor-1]
+	return newer.Item, fmt.Sprintf("Revision v%d", newer.Version)
+}
+
+func (form *HistoryScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "up":
+		form.cursor = max(form.cursor-1, 0)
+	case "down":
+		form.cursor = max(min(form.cursor+1, len(form.revisions)-1), 0)
+	case "enter":
+		form.restore(m)
+	}
+	return m, nil
+}
+
+// restore replaces current item by selected revision, history is reloaded if item was changed by another client.
+func (form *HistoryScreen) restore(m *Model) {
+	if len(form.revisions) == 0 {
+		return
+	}
+	id, version := itemVersion(form.current)
+	err := m.clientService.RestoreItemRevision(context.Background(), form.itemType, id, form.revisions[form.cursor].ID, version)
+	if current := serverCopy(err); current != nil {
+		form.open(m, form.itemType, current)
+		m.err = errors.New("GophKeeper: item was changed by another client, check history and retry")
+		return
+	}
+	m.err = err
+	if err == nil {
+		m.dashboardScreen.loadActual(m)
+		m.dashboardScreen.content = m.dashboardScreen.drawContent(m)
+		m.state = Dashboard
+	}
+}
+
+func (form *HistoryScreen) View(m *Model) string {
+	if len(form.revisions) == 0 {
+		return lipgloss.NewStyle().Padding(1, 2).Render(titleStyle.Render("Item has no previous revisions"))
+	}
+
+	list := []string{headerStyle.Render("Revisions")}
+	for i, revision := range form.revisions {
+		line := fmt.Sprintf("v%d  %s", revision.Version, revision.RevisedAt.Format(time.RFC3339))
+		if revision.Deleted {
+			line += "  (deleted)"
+		}
+		if i == form.cursor {
+			list = append(list, activeMenu.Render("> "+line))
+		} else {
+			list = append(list, inactiveMenu.Render("  "+line))
+		}
+	}
+
+	selected := form.revisions[form.cursor]
+	next, nextLabel := form.next()
+	names, values := itemFields(selected.Item)
+	_, nextValues := itemFields(next)
+	rows := []string{borderStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+		headerStyle.Render("Field"),
+		headerStyle.Render(fmt.Sprintf("Revision v%d", selected.Version)),
+		headerStyle.Render(nextLabel),
+	))}
+	for i, name := range names {
+		var nextValue string
+		if i < len(nextValues) {
+			nextValue = nextValues[i]
+		}
+		style := cellStyle
+		if values[i] != nextValue {
+			style = selectedCellStyle
+		}
+		rows = append(rows, borderStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top,
+			cellStyle.Render(name),
+			style.Render(values[i]),
+			style.Render(nextValue),
+		)))
+	}
+
+	ui := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("History of changes, changed fields are highlighted"),
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			contentStyle.Render(lipgloss.JoinVertical(lipgloss.Left, list...)),
+			lipgloss.JoinVertical(lipgloss.Left, rows...),
+		),
+		buttonStyle.Render("Restore selected revision"),
+	)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
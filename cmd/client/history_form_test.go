@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHistoryScreen_RestoreNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = notes
+	model.dashboardScreen.tableNavigation = true
+	current := models.Note{ID: "1", Title: "wifi", Body: "third", Version: 3}
+	model.dashboardScreen.notesState = []models.Note{current}
+
+	revisions := []models.Revision{
+		{ID: "r2", ItemID: "1", Version: 2, RevisedAt: time.Now(), Item: models.Note{ID: "1", Title: "wifi", Body: "second", Version: 2}},
+		{ID: "r1", ItemID: "1", Version: 1, RevisedAt: time.Now(), Item: models.Note{ID: "1", Title: "home", Body: "second", Version: 1}},
+	}
+	gm.EXPECT().ListItemHistory(gomock.Any(), models.NoteItem, "1").Return(revisions, nil)
+	_, _ = model.dashboardScreen.Update(&model, tea.KeyMsg{Type: tea.KeyF8})
+	if model.state != HistoryForm {
+		t.Fatalf("F8 state = %v, want %v", model.state, HistoryForm)
+	}
+
+	_, _ = model.historyScreen.Update(&model, tea.KeyMsg{Type: tea.KeyDown})
+	if next, label := model.historyScreen.next(); label != "Revision v2" || !reflect.DeepEqual(next, revisions[0].Item) {
+		t.Errorf("next() = %v, %v, want revision v2 which replaced v1", next, label)
+	}
+	if view := model.historyScreen.View(&model); !strings.Contains(view, "Revision v1") || !strings.Contains(view, "home") {
+		t.Errorf("View() = %v, want selected revision v1", view)
+	}
+
+	gm.EXPECT().RestoreItemRevision(gomock.Any(), models.NoteItem, "1", "r1", int64(3)).Return(nil)
+	gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{Notes: []models.Note{{ID: "1", Title: "home", Body: "second", Version: 4}}}, nil)
+	_, _ = model.historyScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Errorf("restore() error = %v, state = %v, want revision restored", model.err, model.state)
+	}
+}
+
+func TestHistoryScreen_RestoreConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+
+	revisions := []models.Revision{{ID: "r1", ItemID: "1", Version: 1, Item: models.Card{ID: "1", Number: "1111", Version: 1}}}
+	gm.EXPECT().ListItemHistory(gomock.Any(), models.CardItem, "1").Return(revisions, nil)
+	model.historyScreen.open(&model, models.CardItem, models.Card{ID: "1", Number: "2222", Version: 2})
+
+	theirs := models.Card{ID: "1", Number: "3333", Version: 3}
+	gm.EXPECT().RestoreItemRevision(gomock.Any(), models.CardItem, "1", "r1", int64(2)).
+		Return(fmt.Errorf("RestoreItemRevision: %w", &client.ConflictError{Server: theirs}))
+	gm.EXPECT().ListItemHistory(gomock.Any(), models.CardItem, "1").Return(revisions, nil)
+	_, _ = model.historyScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || model.state != HistoryForm || !reflect.DeepEqual(model.historyScreen.current, theirs) {
+		t.Errorf("restore() error = %v, state = %v, want history of server copy", model.err, model.state)
+	}
+
+	gm.EXPECT().ListItemHistory(gomock.Any(), models.CardItem, "1").Return(nil, errors.New("offline"))
+	model.state = Dashboard
+	model.historyScreen.open(&model, models.CardItem, theirs)
+	if model.err == nil || model.state != Dashboard {
+		t.Errorf("open() error = %v, state = %v, want error on dashboard", model.err, model.state)
+	}
+}
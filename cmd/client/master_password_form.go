@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	unlockVaultTitle = "Please, enter your master password to unlock the vault:"
+	setupVaultTitle  = "Please, create a master password, it encrypts your data and is never sent to server:"
+)
+
+type MasterPasswordForm struct {
+	passwordInput textinput.Model
+	confirmInput  textinput.Model
+	setupMode     bool
+	focusIndex    int
+	title         string
+}
+
+func NewMasterPasswordForm() *MasterPasswordForm {
+	password := textinput.New()
+	password.Placeholder = "Master password"
+	password.EchoMode = textinput.EchoPassword
+	password.EchoCharacter = '•'
+	password.Width = 30
+	password.Focus()
+
+	confirm := textinput.New()
+	confirm.Placeholder = "Repeat master password"
+	confirm.EchoMode = textinput.EchoPassword
+	confirm.EchoCharacter = '•'
+	confirm.Width = 30
+
+	return &MasterPasswordForm{
+		passwordInput: password,
+		confirmInput:  confirm,
+		title:         unlockVaultTitle,
+	}
+}
+
+// reset prepares form for unlocking vault of freshly authorized user.
+func (form *MasterPasswordForm) reset() {
+	form.passwordInput.SetValue("")
+	form.confirmInput.SetValue("")
+	form.setupMode = false
+	form.title = unlockVaultTitle
+	form.focusIndex = 0
+	form.updateFocus()
+}
+
+// Update processes incoming messages and updates the state of the MasterPasswordForm.
+func (form *MasterPasswordForm) Update(m *Model, msg tea.Msg) (*Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return form.handleKeyMsg(m, msg)
+	default:
+		return m, nil
+	}
+}
+
+func (form *MasterPasswordForm) handleKeyMsg(m *Model, msg tea.KeyMsg) (*Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		return form.handleEnterKey(m)
+	case tea.KeyUp:
+		if form.focusIndex > 0 {
+			form.focusIndex--
+		}
+		return form.updateInputs(m, tea.KeyMsg{})
+	case tea.KeyDown:
+		if form.focusIndex < form.submitIndex() {
+			form.focusIndex++
+		}
+		return form.updateInputs(m, tea.KeyMsg{})
+	default:
+		return form.updateInputs(m, msg)
+	}
+}
+
+// submitIndex returns focus index of Submit button, confirmation input is shown only while creating vault.
+func (form *MasterPasswordForm) submitIndex() int {
+	if form.setupMode {
+		return 2
+	}
+	return 1
+}
+
+func (form *MasterPasswordForm) handleEnterKey(m *Model) (*Model, tea.Cmd) {
+	if form.focusIndex < form.submitIndex() {
+		form.focusIndex++
+		return form.updateInputs(m, tea.KeyMsg{})
+	}
+
+	m.err = form.validateInputs()
+	if m.err == nil {
+		m.err = form.process(m)
+	}
+
+	switch {
+	case errors.Is(m.err, client.ErrVaultNotInitialized):
+		m.err = nil
+		form.setupMode = true
+		form.title = setupVaultTitle
+		form.focusIndex = 1
+	case m.err == nil:
+		m.state = Dashboard
+		m.dashboardScreen.tableNavigation = false
+	default:
+		form.passwordInput.SetValue("")
+		form.confirmInput.SetValue("")
+		form.focusIndex = 0
+	}
+	return form.updateInputs(m, tea.KeyMsg{})
+}
+
+func (form *MasterPasswordForm) validateInputs() error {
+	if form.passwordInput.Value() == "" {
+		return errors.New("no value: Master password is required")
+	}
+	if form.setupMode && form.passwordInput.Value() != form.confirmInput.Value() {
+		return errors.New("incorrect value: Master passwords do not match")
+	}
+	return nil
+}
+
+func (form *MasterPasswordForm) process(m *Model) error {
+	if form.setupMode {
+		return m.clientService.InitVault(context.Background(), form.passwordInput.Value())
+	}
+	return m.clientService.UnlockVault(context.Background(), form.passwordInput.Value())
+}
+
+func (form *MasterPasswordForm) updateInputs(m *Model, msg tea.Msg) (*Model, tea.Cmd) {
+	form.updateFocus()
+	var cmd tea.Cmd
+	switch form.focusIndex {
+	case 0:
+		form.passwordInput, cmd = form.passwordInput.Update(msg)
+	case 1:
+		if form.setupMode {
+			form.confirmInput, cmd = form.confirmInput.Update(msg)
+		}
+	}
+	return m, cmd
+}
+
+func (form *MasterPasswordForm) updateFocus() {
+	form.passwordInput.Blur()
+	form.confirmInput.Blur()
+	switch form.focusIndex {
+	case 0:
+		form.passwordInput.Focus()
+	case 1:
+		if form.setupMode {
+			form.confirmInput.Focus()
+		}
+	}
+}
+
+// View renders the MasterPasswordForm with confirmation input when new vault is created.
+func (form *MasterPasswordForm) View(m *Model) string {
+	submitButton := buttonBlurredStyle.Render("Submit")
+	if form.focusIndex == form.submitIndex() {
+		submitButton = buttonStyle.Render("Submit")
+	}
+
+	fields := []string{titleStyle.Render(form.title), form.passwordInput.View()}
+	if form.setupMode {
+		fields = append(fields, form.confirmInput.View())
+	}
+	fields = append(fields, submitButton)
+
+	ui := lipgloss.JoinVertical(lipgloss.Left, fields...)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMasterPasswordForm_HandleEnterKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		setupMode     bool
+		password      string
+		confirm       string
+		mock          func(gm *mock.MockGRPCClientProvider)
+		expectedState State
+		expectedSetup bool
+		errNil        bool
+	}{
+		{
+			name:          "Empty master password",
+			mock:          func(gm *mock.MockGRPCClientProvider) {},
+			expectedState: MasterPassword,
+			errNil:        false,
+		},
+		{
+			name:     "Vault unlocked",
+			password: "master",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(nil)
+			},
+			expectedState: Dashboard,
+			errNil:        true,
+		},
+		{
+			name:     "Wrong master password",
+			password: "wrong",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UnlockVault(gomock.Any(), "wrong").Return(errors.New("wrong master password"))
+			},
+			expectedState: MasterPassword,
+			errNil:        false,
+		},
+		{
+			name:     "Vault is not initialized",
+			password: "master",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UnlockVault(gomock.Any(), "master").Return(client.ErrVaultNotInitialized)
+			},
+			expectedState: MasterPassword,
+			expectedSetup: true,
+			errNil:        true,
+		},
+		{
+			name:          "Master passwords do not match",
+			setupMode:     true,
+			password:      "master",
+			confirm:       "other",
+			mock:          func(gm *mock.MockGRPCClientProvider) {},
+			expectedState: MasterPassword,
+			expectedSetup: true,
+			errNil:        false,
+		},
+		{
+			name:      "Vault initialized",
+			setupMode: true,
+			password:  "master",
+			confirm:   "master",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().InitVault(gomock.Any(), "master").Return(nil)
+			},
+			expectedState: Dashboard,
+			expectedSetup: true,
+			errNil:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := mock.NewMockGRPCClientProvider(ctrl)
+			tt.mock(gm)
+			model := NewModel(MasterPassword)
+			model.clientService = gm
+
+			form := model.masterPasswordScreen
+			form.setupMode = tt.setupMode
+			form.passwordInput.SetValue(tt.password)
+			form.confirmInput.SetValue(tt.confirm)
+			form.focusIndex = form.submitIndex()
+
+			_, _ = form.handleEnterKey(&model)
+			if (model.err == nil) != tt.errNil {
+				t.Errorf("handleEnterKey() error = %v, wantNil %v", model.err, tt.errNil)
+			}
+			if model.state != tt.expectedState {
+				t.Errorf("handleEnterKey() state = %v, want %v", model.state, tt.expectedState)
+			}
+			if form.setupMode != tt.expectedSetup {
+				t.Errorf("handleEnterKey() setupMode = %v, want %v", form.setupMode, tt.expectedSetup)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	metadataPairSeparator  = ";"
+	metadataValueSeparator = ":"
+)
+
+// newMetadataInput creates input for item metadata written as "key: value; key: value".
+func newMetadataInput() textinput.Model {
+	metadata := textinput.New()
+	metadata.Placeholder = "Metadata (key: value; key: value)"
+	metadata.Width = 200
+	return metadata
+}
+
+// parseMetadata parses item metadata written as "key: value; key: value".
+func parseMetadata(value string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(value, metadataPairSeparator) {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, found := strings.Cut(pair, metadataValueSeparator)
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("incorrect value: Metadata entry %q must look like key: value", strings.TrimSpace(pair))
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, fmt.Errorf("incorrect value: Metadata key %q is duplicated", key)
+		}
+		metadata[key] = strings.TrimSpace(val)
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return metadata, nil
+}
+
+// formatMetadata renders item metadata sorted by keys, so it can be edited in metadata input.
+func formatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+metadataValueSeparator+" "+metadata[key])
+	}
+	return strings.Join(pairs, metadataPairSeparator+" ")
+}
+
+// FileMetadataScreen - form for editing metadata of uploaded file
+type FileMetadataScreen struct {
+	metadataInput textinput.Model
+	fileID        string
+	fileName      string
+}
+
+func NewFileMetadataScreen() *FileMetadataScreen {
+	metadata := newMetadataInput()
+	metadata.Focus()
+
+	return &FileMetadataScreen{metadataInput: metadata}
+}
+
+// reset prepares form for editing metadata of file.
+func (form *FileMetadataScreen) reset(file models.File) {
+	form.fileID, form.fileName = file.ID, file.Name
+	form.metadataInput.SetValue(formatMetadata(file.Metadata))
+	form.metadataInput.Focus()
+}
+
+func (form *FileMetadataScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if keyMsg.Type == tea.KeyEnter {
+		m.err = form.validateAndSubmit(m)
+		if m.err == nil {
+			m.dashboardScreen.loadActual(m)
+			m.dashboardScreen.content = m.dashboardScreen.drawContent(m)
+			m.state = Dashboard
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	form.metadataInput, cmd = form.metadataInput.Update(msg)
+	return m, cmd
+}
+
+func (form *FileMetadataScreen) validateAndSubmit(m *Model) error {
+	metadata, err := parseMetadata(form.metadataInput.Value())
+	if err != nil {
+		return err
+	}
+	return m.clientService.UpdateFileMetadata(context.Background(), form.fileID, metadata)
+}
+
+func (form *FileMetadataScreen) View(m *Model) string {
+	ui := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Please, enter metadata of "+form.fileName),
+		form.metadataInput.View(),
+		buttonStyle.Render("Submit"),
+	)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{name: "Empty", value: "  ", expected: nil},
+		{name: "Single pair", value: "env: prod", expected: map[string]string{"env": "prod"}},
+		{name: "Several pairs", value: "env: prod; url: https://example.com;", expected: map[string]string{"env": "prod", "url": "https://example.com"}},
+		{name: "Empty value", value: "archived:", expected: map[string]string{"archived": ""}},
+		{name: "No separator", value: "env prod", wantErr: true},
+		{name: "Empty key", value: ": prod", wantErr: true},
+		{name: "Duplicated key", value: "env: prod; env: dev", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, err := parseMetadata(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(metadata, tt.expected) {
+				t.Errorf("parseMetadata() = %v, want %v", metadata, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatMetadata(t *testing.T) {
+	metadata := map[string]string{"url": "https://example.com", "env": "prod"}
+	formatted := formatMetadata(metadata)
+	if formatted != "env: prod; url: https://example.com" {
+		t.Errorf("formatMetadata() = %q", formatted)
+	}
+	parsed, err := parseMetadata(formatted)
+	if err != nil || !reflect.DeepEqual(parsed, metadata) {
+		t.Errorf("parseMetadata(formatMetadata()) = %v, %v, want %v", parsed, err, metadata)
+	}
+	if formatMetadata(nil) != "" {
+		t.Errorf("formatMetadata() of empty metadata should be empty")
+	}
+}
+
+func TestFileMetadataScreen_Submit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		value         string
+		mock          func(gm *mock.MockGRPCClientProvider)
+		expectedState State
+		errNil        bool
+	}{
+		{
+			name:          "Malformed metadata",
+			value:         "year",
+			mock:          func(gm *mock.MockGRPCClientProvider) {},
+			expectedState: FileMetadataForm,
+		},
+		{
+			name:  "Update metadata",
+			value: "year: 2024",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UpdateFileMetadata(gomock.Any(), "8f14e45f", map[string]string{"year": "2024"}).Return(nil)
+				gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{Files: []models.File{{ID: "8f14e45f", Name: "report.pdf"}}}, nil)
+			},
+			expectedState: Dashboard,
+			errNil:        true,
+		},
+		{
+			name:  "Update error",
+			value: "year: 2024",
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UpdateFileMetadata(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("update error"))
+			},
+			expectedState: FileMetadataForm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := mock.NewMockGRPCClientProvider(ctrl)
+			tt.mock(gm)
+			model := NewModel(FileMetadataForm)
+			model.clientService = gm
+			model.dashboardScreen.cursor = files
+			form := model.fileMetadataScreen
+			form.reset(models.File{ID: "8f14e45f", Name: "report.pdf"})
+			form.metadataInput.SetValue(tt.value)
+
+			_, _ = form.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+			if model.state != tt.expectedState {
+				t.Errorf("state = %v, want %v", model.state, tt.expectedState)
+			}
+			if (model.err == nil) != tt.errNil {
+				t.Errorf("err = %v, want nil %v", model.err, tt.errNil)
+			}
+		})
+	}
+}
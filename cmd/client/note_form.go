@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type NoteScreen struct {
+	titleInput    textinput.Model
+	bodyInput     textarea.Model
+	metadataInput textinput.Model
+	updateID      string
+	base          models.Note
+	createMode    bool
+	focused       noteFormInput
+	title         string
+}
+
+type noteFormInput int
+
+const (
+	noteTitle       noteFormInput = 0
+	noteBody        noteFormInput = 1
+	noteMetadata    noteFormInput = 2
+	totalNoteFields noteFormInput = 3
+)
+
+func NewNoteScreen() *NoteScreen {
+	title := textinput.New()
+	title.Placeholder = "Title"
+	title.Width = 200
+	title.Focus()
+
+	body := textarea.New()
+	body.Placeholder = "Text you want to keep secret"
+	body.ShowLineNumbers = false
+	body.SetWidth(80)
+	body.SetHeight(10)
+	body.CharLimit = 0
+
+	return &NoteScreen{
+		titleInput:    title,
+		bodyInput:     body,
+		metadataInput: newMetadataInput(),
+		focused:       noteTitle,
+		title:         "Please, enter the note I should keep",
+	}
+}
+
+// reset prepares form for creation of new note or editing of existing one.
+func (form *NoteScreen) reset(note models.Note, createMode bool) {
+	form.createMode = createMode
+	form.updateID = note.ID
+	form.base = note
+	form.titleInput.SetValue(note.Title)
+	form.bodyInput.SetValue(note.Body)
+	form.metadataInput.SetValue(formatMetadata(note.Metadata))
+	form.focused = noteTitle
+	form.updateFocus()
+}
+
+func (form *NoteScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return form.handleKeyMsg(m, msg)
+	default:
+		return m, nil
+	}
+}
+
+func (form *NoteScreen) handleKeyMsg(m *Model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+s":
+		return form.submit(m)
+	case "tab":
+		return form.moveFocus(m, form.focused+1)
+	case "enter":
+		switch form.focused {
+		case totalNoteFields:
+			return form.submit(m)
+		case noteTitle:
+			return form.moveFocus(m, noteBody)
+		case noteMetadata:
+			return form.moveFocus(m, totalNoteFields)
+		}
+	case "down":
+		switch form.focused {
+		case noteTitle:
+			return form.moveFocus(m, noteBody)
+		case noteMetadata:
+			return form.moveFocus(m, totalNoteFields)
+		}
+	case "up":
+		switch form.focused {
+		case noteMetadata:
+			return form.moveFocus(m, noteBody)
+		case totalNoteFields:
+			return form.moveFocus(m, noteMetadata)
+		}
+	}
+	return form.updateInputs(m, msg)
+}
+
+func (form *NoteScreen) submit(m *Model) (tea.Model, tea.Cmd) {
+	m.err = form.validateAndSubmit(m)
+	if m.err == nil {
+		m.dashboardScreen.loadActual(m)
+		m.dashboardScreen.content = m.dashboardScreen.drawContent(m)
+		m.state = Dashboard
+	}
+	return m, nil
+}
+
+func (form *NoteScreen) validateAndSubmit(m *Model) error {
+	if form.titleInput.Value() == "" {
+		return errors.New("no value: Title is required")
+	}
+	metadata, err := parseMetadata(form.metadataInput.Value())
+	if err != nil {
+		return err
+	}
+
+	if form.createMode {
+		return m.clientService.CreateNote(context.Background(), form.titleInput.Value(), form.bodyInput.Value(), metadata)
+	}
+
+	edited := models.Note{
+		ID:       form.updateID,
+		Title:    form.titleInput.Value(),
+		Body:     form.bodyInput.Value(),
+		Metadata: metadata,
+		Version:  form.base.Version,
+	}
+	_, err = m.clientService.UpdateNote(context.Background(), edited)
+	m.conflictScreen.openNote(m, form.base, edited, err)
+	return err
+}
+
+func (form *NoteScreen) moveFocus(m *Model, focused noteFormInput) (tea.Model, tea.Cmd) {
+	if focused > totalNoteFields {
+		focused = totalNoteFields
+	}
+	form.focused = focused
+	form.updateFocus()
+	return m, nil
+}
+
+func (form *NoteScreen) updateFocus() {
+	form.titleInput.Blur()
+	form.bodyInput.Blur()
+	form.metadataInput.Blur()
+	switch form.focused {
+	case noteTitle:
+		form.titleInput.Focus()
+	case noteBody:
+		form.bodyInput.Focus()
+	case noteMetadata:
+		form.metadataInput.Focus()
+	}
+}
+
+func (form *NoteScreen) updateInputs(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch form.focused {
+	case noteTitle:
+		form.titleInput, cmd = form.titleInput.Update(msg)
+	case noteBody:
+		form.bodyInput, cmd = form.bodyInput.Update(msg)
+	case noteMetadata:
+		form.metadataInput, cmd = form.metadataInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (form *NoteScreen) View(m *Model) string {
+	submitButton := buttonBlurredStyle.Render("Submit")
+	if form.focused == totalNoteFields {
+		submitButton = buttonStyle.Render("Submit")
+	}
+
+	ui := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(form.title),
+		form.titleInput.View(),
+		form.bodyInput.View(),
+		form.metadataInput.View(),
+		submitButton,
+	)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
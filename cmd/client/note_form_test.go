@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNoteScreen_HandleKeyMsg(t *testing.T) {
+	form := NewNoteScreen()
+	model := NewModel(NoteForm)
+
+	tests := []struct {
+		name     string
+		key      tea.KeyMsg
+		expected noteFormInput
+	}{
+		{name: "Enter on title", key: tea.KeyMsg{Type: tea.KeyEnter}, expected: noteBody},
+		{name: "Enter in body", key: tea.KeyMsg{Type: tea.KeyEnter}, expected: noteBody},
+		{name: "Tab in body", key: tea.KeyMsg{Type: tea.KeyTab}, expected: noteMetadata},
+		{name: "Enter in metadata", key: tea.KeyMsg{Type: tea.KeyEnter}, expected: totalNoteFields},
+		{name: "Tab on submit", key: tea.KeyMsg{Type: tea.KeyTab}, expected: totalNoteFields},
+		{name: "Up on submit", key: tea.KeyMsg{Type: tea.KeyUp}, expected: noteMetadata},
+		{name: "Up in metadata", key: tea.KeyMsg{Type: tea.KeyUp}, expected: noteBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _ = form.handleKeyMsg(&model, tt.key)
+			if form.focused != tt.expected {
+				t.Errorf("handleKeyMsg() focused = %v, want %v", form.focused, tt.expected)
+			}
+		})
+	}
+	if form.bodyInput.Value() != "\n" {
+		t.Errorf("enter in body should insert new line, got %q", form.bodyInput.Value())
+	}
+}
+
+func TestNoteScreen_Submit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name          string
+		note          models.Note
+		createMode    bool
+		mock          func(gm *mock.MockGRPCClientProvider)
+		expectedState State
+		errNil        bool
+	}{
+		{
+			name:          "Empty title",
+			note:          models.Note{Body: "secret"},
+			createMode:    true,
+			mock:          func(gm *mock.MockGRPCClientProvider) {},
+			expectedState: NoteForm,
+		},
+		{
+			name:       "Create note",
+			note:       models.Note{Title: "wifi", Body: "line 1\nline 2"},
+			createMode: true,
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().CreateNote(gomock.Any(), "wifi", "line 1\nline 2", nil).Return(nil)
+				gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{Notes: []models.Note{{ID: "1", Title: "wifi"}}}, nil)
+			},
+			expectedState: Dashboard,
+			errNil:        true,
+		},
+		{
+			name: "Update note",
+			note: models.Note{ID: "1", Title: "wifi", Body: "new secret", Metadata: map[string]string{"env": "home"}},
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UpdateNote(gomock.Any(), models.Note{ID: "1", Title: "wifi", Body: "new secret",
+					Metadata: map[string]string{"env": "home"}}).
+					Return(models.Note{ID: "1", Title: "wifi", Body: "new secret"}, nil)
+				gm.EXPECT().Sync(gomock.Any()).Return(models.ChangeSet{Notes: []models.Note{{ID: "1", Title: "wifi"}}}, nil)
+			},
+			expectedState: Dashboard,
+			errNil:        true,
+		},
+		{
+			name: "Update error",
+			note: models.Note{ID: "1", Title: "wifi"},
+			mock: func(gm *mock.MockGRPCClientProvider) {
+				gm.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(models.Note{}, errors.New("update error"))
+			},
+			expectedState: NoteForm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := mock.NewMockGRPCClientProvider(ctrl)
+			tt.mock(gm)
+			model := NewModel(NoteForm)
+			model.clientService = gm
+			model.dashboardScreen.cursor = notes
+
+			model.notesScreen.reset(tt.note, tt.createMode)
+			_, _ = model.notesScreen.handleKeyMsg(&model, tea.KeyMsg{Type: tea.KeyCtrlS})
+			if (model.err == nil) != tt.errNil {
+				t.Errorf("submit() error = %v, wantNil %v", model.err, tt.errNil)
+			}
+			if model.state != tt.expectedState {
+				t.Errorf("submit() state = %v, want %v", model.state, tt.expectedState)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	changePasswordTitle       = "Please, enter your current password and a new one, other devices will be signed out:"
+	changeMasterPasswordTitle = "Please, enter your current master password and a new one, stored items stay readable:"
+)
+
+// PasswordScreen - change of account password or of master password of vault, one does not affect the other
+type PasswordScreen struct {
+	inputs     []textinput.Model
+	focusIndex int
+	master     bool
+}
+
+func NewPasswordScreen() *PasswordScreen {
+	var inputs []textinput.Model
+	for _, placeholder := range []string{"Current password", "New password", "Repeat new password"} {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.EchoMode = textinput.EchoPassword
+		input.EchoCharacter = '•'
+		input.Width = 30
+		inputs = append(inputs, input)
+	}
+	form := &PasswordScreen{inputs: inputs}
+	form.reset()
+	return form
+}
+
+// open shows form for change of master password of vault or of account password.
+func (form *PasswordScreen) open(m *Model, master bool) {
+	form.master = master
+	form.reset()
+	m.state = PasswordForm
+}
+
+// reset clears entered passwords and focuses current password input.
+func (form *PasswordScreen) reset() {
+	for i := range form.inputs {
+		form.inputs[i].SetValue("")
+	}
+	form.focusIndex = 0
+	form.updateFocus()
+}
+
+func (form *PasswordScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.Type {
+	case tea.KeyEnter:
+		if form.focusIndex < len(form.inputs) {
+			form.focusIndex++
+			form.updateFocus()
+			return m, nil
+		}
+		form.submit(m)
+		return m, nil
+	case tea.KeyUp:
+		if form.focusIndex > 0 {
+			form.focusIndex--
+		}
+		form.updateFocus()
+		return m, nil
+	case tea.KeyDown:
+		if form.focusIndex < len(form.inputs) {
+			form.focusIndex++
+		}
+		form.updateFocus()
+		return m, nil
+	}
+
+	if form.focusIndex == len(form.inputs) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	form.inputs[form.focusIndex], cmd = form.inputs[form.focusIndex].Update(msg)
+	return m, cmd
+}
+
+func (form *PasswordScreen) updateFocus() {
+	for i := range form.inputs {
+		if i == form.focusIndex {
+			form.inputs[i].Focus()
+		} else {
+			form.inputs[i].Blur()
+		}
+	}
+}
+
+func (form *PasswordScreen) validateInputs() error {
+	if form.inputs[0].Value() == "" {
+		return errors.New("no value: Current password is required")
+	}
+	if form.inputs[1].Value() == "" {
+		return errors.New("no value: New password is required")
+	}
+	if form.inputs[1].Value() != form.inputs[2].Value() {
+		return errors.New("incorrect value: New passwords do not match")
+	}
+	return nil
+}
+
+// submit changes chosen password, result is reported on dashboard and form is cleared on failure for the next try.
+func (form *PasswordScreen) submit(m *Model) {
+	if m.err = form.validateInputs(); m.err != nil {
+		return
+	}
+	if form.master {
+		err := m.clientService.ChangeMasterPassword(context.Background(), form.inputs[0].Value(), form.inputs[1].Value())
+		form.reset()
+		if err != nil {
+			m.err = err
+			return
+		}
+		m.dashboardScreen.updateMsg = "GophKeeper: master password is changed"
+		m.state = Dashboard
+		return
+	}
+	revoked, err := m.clientService.ChangePassword(context.Background(), form.inputs[0].Value(), form.inputs[1].Value())
+	form.reset()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.dashboardScreen.updateMsg = fmt.Sprintf("GophKeeper: password is changed, %d other devices are signed out", revoked)
+	m.state = Dashboard
+}
+
+func (form *PasswordScreen) View(m *Model) string {
+	submitButton := buttonBlurredStyle.Render("Submit")
+	if form.focusIndex == len(form.inputs) {
+		submitButton = buttonStyle.Render("Submit")
+	}
+
+	title := changePasswordTitle
+	if form.master {
+		title = changeMasterPasswordTitle
+	}
+	fields := []string{titleStyle.Render(title)}
+	for _, input := range form.inputs {
+		fields = append(fields, input.View())
+	}
+	fields = append(fields, submitButton)
+
+	ui := lipgloss.JoinVertical(lipgloss.Left, fields...)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
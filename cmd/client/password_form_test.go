@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/mock"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPasswordScreen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = security
+
+	_, _ = model.dashboardScreen.handleF2Key(&model)
+	if model.state != PasswordForm {
+		t.Fatalf("F2 on security state = %v, want %v", model.state, PasswordForm)
+	}
+
+	model.passwordScreen.focusIndex = len(model.passwordScreen.inputs)
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil {
+		t.Errorf("submit() of empty form error = nil")
+	}
+
+	model.passwordScreen.inputs[0].SetValue("old")
+	model.passwordScreen.inputs[1].SetValue("new")
+	model.passwordScreen.inputs[2].SetValue("other")
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || !strings.Contains(model.err.Error(), "do not match") {
+		t.Errorf("submit() of different passwords error = %v", model.err)
+	}
+
+	model.passwordScreen.inputs[2].SetValue("new")
+	gm.EXPECT().ChangePassword(gomock.Any(), "old", "new").DoAndReturn(
+		func(ctx context.Context, oldPassword, newPassword string) (int, error) {
+			return 0, errors.New("ChangePassword: old password is not valid")
+		})
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || model.state != PasswordForm || model.passwordScreen.inputs[0].Value() != "" {
+		t.Errorf("submit() error = %v, state = %v, want wrong password cleared", model.err, model.state)
+	}
+
+	for i, value := range []string{"old", "new", "new"} {
+		model.passwordScreen.inputs[i].SetValue(value)
+	}
+	model.passwordScreen.focusIndex = len(model.passwordScreen.inputs)
+	gm.EXPECT().ChangePassword(gomock.Any(), "old", "new").DoAndReturn(
+		func(ctx context.Context, oldPassword, newPassword string) (int, error) {
+			return 2, nil
+		})
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Fatalf("submit() error = %v, state = %v, want %v", model.err, model.state, Dashboard)
+	}
+	if !strings.Contains(model.dashboardScreen.updateMsg, "2 other devices") {
+		t.Errorf("submit() message = %q", model.dashboardScreen.updateMsg)
+	}
+
+	model.state = PasswordForm
+	gm.EXPECT().TakeReplayConflict().Return(nil, nil)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if updated.(Model).state != Dashboard {
+		t.Errorf("shift+tab state = %v, want %v", updated.(Model).state, Dashboard)
+	}
+}
+
+func TestPasswordScreen_MasterPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = security
+
+	_, _ = model.dashboardScreen.handleF5Key(&model)
+	if model.state != PasswordForm || !model.passwordScreen.master {
+		t.Fatalf("F5 on security state = %v, master = %v, want %v of master password", model.state, model.passwordScreen.master, PasswordForm)
+	}
+	if !strings.Contains(model.passwordScreen.View(&model), "master password") {
+		t.Errorf("View() does not ask for master password")
+	}
+
+	for i, value := range []string{"old", "new", "new"} {
+		model.passwordScreen.inputs[i].SetValue(value)
+	}
+	model.passwordScreen.focusIndex = len(model.passwordScreen.inputs)
+	gm.EXPECT().ChangeMasterPassword(gomock.Any(), "old", "new").Return(errors.New("ChangeMasterPassword: wrong master password"))
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || model.state != PasswordForm || !model.passwordScreen.master {
+		t.Errorf("submit() error = %v, state = %v, want wrong master password reported", model.err, model.state)
+	}
+
+	for i, value := range []string{"old", "new", "new"} {
+		model.passwordScreen.inputs[i].SetValue(value)
+	}
+	model.passwordScreen.focusIndex = len(model.passwordScreen.inputs)
+	gm.EXPECT().ChangeMasterPassword(gomock.Any(), "old", "new").Return(nil)
+	_, _ = model.passwordScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Fatalf("submit() error = %v, state = %v, want %v", model.err, model.state, Dashboard)
+	}
+	if !strings.Contains(model.dashboardScreen.updateMsg, "master password is changed") {
+		t.Errorf("submit() message = %q", model.dashboardScreen.updateMsg)
+	}
+
+	_, _ = model.dashboardScreen.handleF2Key(&model)
+	if model.passwordScreen.master {
+		t.Errorf("F2 on security opens master password change")
+	}
+}
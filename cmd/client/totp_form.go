@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/skip2/go-qrcode"
+)
+
+type totpMode int
+
+// Modes of TOTPScreen
+const (
+	// totpVerify - second step of sign in
+	totpVerify totpMode = iota
+	// totpEnroll - provisioning of authenticator app, enrollment is confirmed by its first code
+	totpEnroll
+	// totpDisable - turning two-factor authentication off
+	totpDisable
+)
+
+var totpTitles = map[totpMode]string{
+	totpVerify:  "Please, enter code of your authenticator app or one of recovery codes:",
+	totpEnroll:  "Scan QR code with your authenticator app and enter code it shows:",
+	totpDisable: "Please, enter code of your authenticator app or one of recovery codes to disable two-factor authentication:",
+}
+
+// TOTPScreen - input of code of authenticator app for sign in, enrollment and disabling of two-factor authentication
+type TOTPScreen struct {
+	codeInput  textinput.Model
+	mode       totpMode
+	enrollment models.TOTPEnrollment
+}
+
+func NewTOTPScreen() *TOTPScreen {
+	code := textinput.New()
+	code.Placeholder = "Code"
+	code.CharLimit = 16
+	code.Width = 30
+	code.Focus()
+
+	return &TOTPScreen{codeInput: code}
+}
+
+// reset prepares empty code input for given mode.
+func (form *TOTPScreen) reset(mode totpMode) {
+	form.mode = mode
+	form.enrollment = models.TOTPEnrollment{}
+	form.codeInput.SetValue("")
+	form.codeInput.Focus()
+}
+
+// enroll generates secret of authenticator app and shows it, dashboard reports error if secret can not be generated.
+func (form *TOTPScreen) enroll(m *Model) {
+	enrollment, err := m.clientService.EnrollTOTP(context.Background())
+	if err != nil {
+		m.dashboardScreen.updateMsg = "GophKeeper: two-factor authentication can not be enabled: " + err.Error()
+		m.err = errors.New(m.dashboardScreen.updateMsg)
+		return
+	}
+	form.reset(totpEnroll)
+	form.enrollment = enrollment
+	m.state = TOTPForm
+}
+
+// backState - screen shift+tab returns to
+func (form *TOTPScreen) backState() State {
+	if form.mode == totpVerify {
+		return SignIn
+	}
+	return Dashboard
+}
+
+func (form *TOTPScreen) Update(m *Model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if keyMsg.Type == tea.KeyEnter {
+		form.submit(m)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	form.codeInput, cmd = form.codeInput.Update(msg)
+	return m, cmd
+}
+
+// submit sends entered code, wrong code is cleared so user can enter the next one.
+func (form *TOTPScreen) submit(m *Model) {
+	code := strings.TrimSpace(form.codeInput.Value())
+	if code == "" {
+		m.err = errors.New("no value: Code is required")
+		return
+	}
+
+	switch form.mode {
+	case totpVerify:
+		m.err = m.clientService.VerifyTOTP(code)
+	case totpEnroll:
+		m.err = m.clientService.ConfirmTOTP(context.Background(), code)
+	case totpDisable:
+		m.err = m.clientService.DisableTOTP(context.Background(), code)
+	}
+	if m.err != nil {
+		form.codeInput.SetValue("")
+		return
+	}
+
+	switch form.mode {
+	case totpVerify:
+		m.signInScreen.subscribeToChanges(m)
+		m.masterPasswordScreen.reset()
+		m.state = MasterPassword
+	case totpEnroll:
+		m.dashboardScreen.updateMsg = "GophKeeper: two-factor authentication is enabled"
+		m.state = Dashboard
+	case totpDisable:
+		m.dashboardScreen.updateMsg = "GophKeeper: two-factor authentication is disabled"
+		m.state = Dashboard
+	}
+	form.reset(form.mode)
+}
+
+// qrCode - provisioning URI drawn by text, URI itself is shown when it does not fit QR code
+func qrCode(uri string) string {
+	code, err := qrcode.New(uri, qrcode.Low)
+	if err != nil {
+		return uri
+	}
+	return code.ToSmallString(false)
+}
+
+// View renders code input, while enrolling it is preceded by QR code, secret and recovery codes.
+func (form *TOTPScreen) View(m *Model) string {
+	fields := []string{titleStyle.Render(totpTitles[form.mode])}
+	if form.mode == totpEnroll {
+		fields = append(fields,
+			qrCode(form.enrollment.ProvisioningURI),
+			"Secret: "+form.enrollment.Secret,
+			"",
+			"Recovery codes, keep them safe, each of them replaces code once:",
+			strings.Join(form.enrollment.RecoveryCodes, "  "),
+			"",
+		)
+	}
+	fields = append(fields, form.codeInput.View(), buttonStyle.Render("Submit"))
+
+	ui := lipgloss.JoinVertical(lipgloss.Left, fields...)
+	return lipgloss.NewStyle().Align(lipgloss.Center).Padding(1, 2).Render(ui)
+}
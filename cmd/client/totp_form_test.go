@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/client"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTOTPScreen_SignIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(SignIn)
+	model.clientService = gm
+	model.signInScreen.emailInput.SetValue("test@example.com")
+	model.signInScreen.passwordInput.SetValue("password")
+	model.signInScreen.focusIndex = 2
+
+	gm.EXPECT().SignIn("test@example.com", "password").Return(fmt.Errorf("SignIn: %w", client.ErrTOTPRequired))
+	_, _ = model.signInScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != TOTPForm {
+		t.Fatalf("SignIn error = %v, state = %v, want %v", model.err, model.state, TOTPForm)
+	}
+
+	_, _ = model.totpScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil {
+		t.Errorf("submit() of empty code error = nil")
+	}
+
+	model.totpScreen.codeInput.SetValue("000000")
+	gm.EXPECT().VerifyTOTP("000000").Return(errors.New("two-factor authentication code is not valid"))
+	_, _ = model.totpScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err == nil || model.state != TOTPForm || model.totpScreen.codeInput.Value() != "" {
+		t.Errorf("submit() error = %v, state = %v, want wrong code cleared", model.err, model.state)
+	}
+
+	model.totpScreen.codeInput.SetValue("123456")
+	gm.EXPECT().VerifyTOTP("123456").Return(nil)
+	gm.EXPECT().SubscribeToChanges(gomock.Any()).Return(nil, errors.New("offline"))
+	_, _ = model.totpScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != MasterPassword {
+		t.Errorf("submit() error = %v, state = %v, want %v", model.err, model.state, MasterPassword)
+	}
+
+	model.state = TOTPForm
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if updated.(Model).state != SignIn {
+		t.Errorf("shift+tab state = %v, want %v", updated.(Model).state, SignIn)
+	}
+}
+
+func TestTOTPScreen_Enroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = security
+
+	gm.EXPECT().EnrollTOTP(gomock.Any()).Return(models.TOTPEnrollment{}, errors.New("already enabled"))
+	_, _ = model.dashboardScreen.Update(&model, tea.KeyMsg{Type: tea.KeyF1})
+	if model.err == nil || model.state != Dashboard {
+		t.Errorf("F1 error = %v, state = %v, want error on dashboard", model.err, model.state)
+	}
+
+	enrollment := models.TOTPEnrollment{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/GophKeeper:test@example.com?issuer=GophKeeper&secret=JBSWY3DPEHPK3PXP",
+		RecoveryCodes:   []string{"aaaaa-bbbbb", "ccccc-ddddd"},
+	}
+	gm.EXPECT().EnrollTOTP(gomock.Any()).Return(enrollment, nil)
+	_, _ = model.dashboardScreen.Update(&model, tea.KeyMsg{Type: tea.KeyF1})
+	if model.state != TOTPForm {
+		t.Fatalf("F1 state = %v, want %v", model.state, TOTPForm)
+	}
+	view := model.totpScreen.View(&model)
+	for _, want := range []string{"JBSWY3DPEHPK3PXP", "aaaaa-bbbbb", "ccccc-ddddd", "█"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() = %v, want %q", view, want)
+		}
+	}
+
+	model.totpScreen.codeInput.SetValue("123456")
+	gm.EXPECT().ConfirmTOTP(gomock.Any(), "123456").Return(nil)
+	_, _ = model.totpScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Errorf("submit() error = %v, state = %v, want %v", model.err, model.state, Dashboard)
+	}
+	if strings.Contains(model.totpScreen.View(&model), "JBSWY3DPEHPK3PXP") {
+		t.Errorf("secret is kept after enrollment")
+	}
+}
+
+func TestTOTPScreen_Disable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	gm := mock.NewMockGRPCClientProvider(ctrl)
+	model := NewModel(Dashboard)
+	model.clientService = gm
+	model.dashboardScreen.cursor = security
+
+	_, _ = model.dashboardScreen.Update(&model, tea.KeyMsg{Type: tea.KeyF3})
+	if model.state != TOTPForm || model.totpScreen.mode != totpDisable {
+		t.Fatalf("F3 state = %v, want %v", model.state, TOTPForm)
+	}
+
+	model.totpScreen.codeInput.SetValue("aaaaa-bbbbb")
+	gm.EXPECT().DisableTOTP(gomock.Any(), "aaaaa-bbbbb").Return(nil)
+	_, _ = model.totpScreen.Update(&model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil || model.state != Dashboard {
+		t.Errorf("submit() error = %v, state = %v, want %v", model.err, model.state, Dashboard)
+	}
+	if model.dashboardScreen.updateMsg == "" {
+		t.Errorf("disabling is not reported on dashboard")
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/PaBah/GophKeeper/internal/config"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
+	"github.com/PaBah/GophKeeper/internal/utils"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExportVault - handler streaming snapshot of user's vault for backup, items and files are sent as stored, so
+// server never sees their content. Items in trash are not exported.
+func (s *GrpcServer) ExportVault(in *pb.ExportVaultRequest, stream pb.GophKeeperService_ExportVaultServer) error {
+	ctx := stream.Context()
+	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	credentials, err := s.storage.GetCredentials(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "credentials can not be retrieved")
+	}
+	for _, item := range credentials {
+		entry := &pb.ExportVaultResponse_Credentials{Credentials: credentialsMessage(item)}
+		if err = stream.Send(&pb.ExportVaultResponse{Entry: entry}); err != nil {
+			return err
+		}
+	}
+	cards, err := s.storage.GetCards(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cards can not be retrieved")
+	}
+	for _, item := range cards {
+		if err = stream.Send(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Card{Card: cardMessage(item)}}); err != nil {
+			return err
+		}
+	}
+	notes, err := s.storage.GetNotes(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "notes can not be retrieved")
+	}
+	for _, item := range notes {
+		if err = stream.Send(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Note{Note: noteMessage(item)}}); err != nil {
+			return err
+		}
+	}
+
+	buffer := make([]byte, downloadChunkSize)
+	for _, file := range s.listFiles(ctx) {
+		if err = stream.Send(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_File{File: file}}); err != nil {
+			return err
+		}
+		object, err := s.blobs.GetObject(ctx, userID, file.Name, 0, 0)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to open object: %v", err)
+		}
+		err = sendChunks(object, buffer, func(chunk []byte) error {
+			return stream.Send(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Chunk{Chunk: chunk}})
+		})
+		_ = object.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendChunks - stream content of reader by chunks of buffer size
+func sendChunks(content io.Reader, buffer []byte, send func(chunk []byte) error) error {
+	for {
+		n, err := io.ReadFull(content, buffer)
+		if n > 0 {
+			if err := send(buffer[:n]); err != nil {
+				return status.Errorf(codes.Internal, "failed to send chunk: %v", err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "block can not be read: %v", err)
+		}
+	}
+}
+
+// ImportVault - handler restoring items and files of vault backup, they come sealed by vault key of user. Entries
+// which can not be restored, like duplicates or invalid cards, are reported in response and import goes on, storage
+// failures break import. Restored entries are kept if stream breaks, client skips them as duplicates when import
+// is repeated.
+func (s *GrpcServer) ImportVault(stream pb.GophKeeperService_ImportVaultServer) error {
+	ctx := stream.Context()
+	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	response := &pb.ImportVaultResponse{}
+	// received - number of received entries of every type, rejections refer to entries by it
+	received := make(map[pb.ItemType]int32)
+	reject := func(itemType pb.ItemType, reason string) {
+		response.Rejected = append(response.Rejected, &pb.ImportVaultRejection{
+			ItemType: itemType,
+			Index:    received[itemType] - 1,
+			Reason:   reason,
+		})
+	}
+
+	// file - upload of file which receives chunks, it is completed by next entry or end of stream
+	var file *uploadSession
+	// skipChunks - chunks of rejected file are dropped
+	var skipChunks bool
+	defer func() {
+		if file != nil {
+			s.closeUpload(ctx, file, true)
+			file.mu.Unlock()
+		}
+	}()
+	completeFile := func() error {
+		if file == nil {
+			return nil
+		}
+		upload := file
+		file = nil
+		defer upload.mu.Unlock()
+		if _, err := upload.complete(ctx, s.blobs, upload.digest); err != nil {
+			s.closeUpload(ctx, upload, true)
+			return err
+		}
+		s.closeUpload(ctx, upload, false)
+		s.fileChanged(ctx, uploadedFile(upload))
+		response.Files++
+		return nil
+	}
+
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, isChunk := in.Entry.(*pb.ImportVaultRequest_Chunk); !isChunk {
+			skipChunks = false
+			if err = completeFile(); err != nil {
+				return err
+			}
+		}
+
+		switch entry := in.Entry.(type) {
+		case *pb.ImportVaultRequest_Credentials:
+			received[pb.ItemType_ITEM_TYPE_CREDENTIALS]++
+			if !validItemID(entry.Credentials.Id) {
+				reject(pb.ItemType_ITEM_TYPE_CREDENTIALS, "invalid credentials ID")
+				continue
+			}
+			credentials := models.NewCredentials(entry.Credentials.ServiceName, entry.Credentials.Identity, entry.Credentials.Password)
+			credentials.ID = entry.Credentials.Id
+			credentials.TOTP = entry.Credentials.Totp
+			credentials.Metadata = entry.Credentials.Metadata
+			created, err := s.storage.CreateCredentials(ctx, credentials)
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				reject(pb.ItemType_ITEM_TYPE_CREDENTIALS, "credentials with such service name and identity already exist")
+				continue
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "credentials can not be imported")
+			}
+			s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, created))
+			response.Credentials++
+		case *pb.ImportVaultRequest_Card:
+			received[pb.ItemType_ITEM_TYPE_CARD]++
+			if !vault.IsSealed(entry.Card.Number) && utils.ValidateLuhn(entry.Card.Number) != nil {
+				reject(pb.ItemType_ITEM_TYPE_CARD, "invalid card number")
+				continue
+			}
+			if !validItemID(entry.Card.Id) {
+				reject(pb.ItemType_ITEM_TYPE_CARD, "invalid card ID")
+				continue
+			}
+			card := models.NewCard(entry.Card.Number, entry.Card.ExpirationDate, entry.Card.HolderName, entry.Card.Cvv)
+			card.ID = entry.Card.Id
+			card.Metadata = entry.Card.Metadata
+			created, err := s.storage.CreateCard(ctx, card)
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				reject(pb.ItemType_ITEM_TYPE_CARD, "card already exists")
+				continue
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "card can not be imported")
+			}
+			s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, created))
+			response.Cards++
+		case *pb.ImportVaultRequest_Note:
+			received[pb.ItemType_ITEM_TYPE_NOTE]++
+			if !validItemID(entry.Note.Id) {
+				reject(pb.ItemType_ITEM_TYPE_NOTE, "invalid note ID")
+				continue
+			}
+			note := models.NewNote(entry.Note.Title, entry.Note.Body)
+			note.ID = entry.Note.Id
+			note.Metadata = entry.Note.Metadata
+			created, err := s.storage.CreateNote(ctx, note)
+			if errors.Is(err, storage.ErrAlreadyExists) {
+				reject(pb.ItemType_ITEM_TYPE_NOTE, "note already exists")
+				continue
+			}
+			if err != nil {
+				return status.Errorf(codes.Internal, "note can not be imported")
+			}
+			s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, created))
+			response.Notes++
+		case *pb.ImportVaultRequest_File:
+			received[pb.ItemType_ITEM_TYPE_FILE]++
+			if entry.File.FileKey == "" || entry.File.EncryptedName == "" || entry.File.Digest == "" {
+				reject(pb.ItemType_ITEM_TYPE_FILE, "only encrypted files can be imported")
+				skipChunks = true
+				continue
+			}
+			name := entry.File.Name
+			if name == "" {
+				name = uuid.NewString()
+			}
+			file, err = s.openUpload(ctx, userID, &pb.UploadFileRequest{
+				Filename:      name,
+				Metadata:      entry.File.Metadata,
+				Digest:        entry.File.Digest,
+				FileKey:       entry.File.FileKey,
+				EncryptedName: entry.File.EncryptedName,
+			})
+			if err != nil {
+				return err
+			}
+		case *pb.ImportVaultRequest_Chunk:
+			if skipChunks {
+				continue
+			}
+			if file == nil {
+				return status.Error(codes.InvalidArgument, "chunk does not follow file entry")
+			}
+			checksum := sha256.Sum256(entry.Chunk)
+			err = file.write(ctx, s.blobs, &pb.UploadFileRequest{
+				Data:     entry.Chunk,
+				Offset:   file.committed.Load() + int64(len(file.buffer)),
+				Checksum: hex.EncodeToString(checksum[:]),
+			})
+			if err != nil {
+				return err
+			}
+		default:
+			return status.Error(codes.InvalidArgument, "empty import entry")
+		}
+	}
+	if err := completeFile(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(response)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/blobstore"
+	"github.com/PaBah/GophKeeper/internal/config"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// signedUpContext - context of new account signed up on server
+func signedUpContext(t *testing.T, srv *GrpcServer, email string) context.Context {
+	signedUp, err := srv.SignUp(context.Background(), &pb.SignUpRequest{Email: email, Password: "password"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	return context.WithValue(context.Background(), config.USERIDCONTEXTKEY, auth.GetUserID(signedUp.Token, "testing secret"))
+}
+
+func TestExportImportVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs := newTestBlobStore(t)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, storage.NewMemoryStorage(), blobs)
+	source := signedUpContext(t, srv, "source@example.com")
+	if _, err := srv.CreateCredentials(source, &pb.CreateCredentialsRequest{ServiceName: "mail", Identity: "gk1:id", Password: "gk1:pw"}); err != nil {
+		t.Fatalf("CreateCredentials() error = %v", err)
+	}
+	if _, err := srv.CreateCard(source, &pb.CreateCardRequest{Number: "gk1:number", ExpirationDate: "gk1:exp", HolderName: "gk1:holder", Cvv: "gk1:cvv"}); err != nil {
+		t.Fatalf("CreateCard() error = %v", err)
+	}
+	if _, err := srv.CreateNote(source, &pb.CreateNoteRequest{Title: "todo", Body: "gk1:body"}); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+	data := bytes.Repeat([]byte("encrypted "), downloadChunkSize/5)
+	sourceID := source.Value(config.USERIDCONTEXTKEY).(string)
+	metadata := map[string]string{objectFileKeyKey: "gk1:key", objectNameKey: "gk1:name"}
+	uploadID, _ := blobs.NewMultipartUpload(source, sourceID, "random", metadata)
+	part, _ := blobs.PutPart(source, sourceID, "random", uploadID, 1, bytes.NewReader(data), int64(len(data)))
+	if _, err := blobs.CompleteMultipartUpload(source, sourceID, "random", uploadID, []blobstore.Part{part}); err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+
+	var exported []*pb.ExportVaultResponse
+	exportStream := mock.NewMockGophKeeperService_ExportVaultServer(ctrl)
+	exportStream.EXPECT().Context().Return(source).AnyTimes()
+	exportStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(response *pb.ExportVaultResponse) error {
+		// buffer of chunks is reused once message is sent
+		if chunk, ok := response.Entry.(*pb.ExportVaultResponse_Chunk); ok {
+			response = &pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Chunk{Chunk: bytes.Clone(chunk.Chunk)}}
+		}
+		exported = append(exported, response)
+		return nil
+	}).AnyTimes()
+	if err := srv.ExportVault(&pb.ExportVaultRequest{}, exportStream); err != nil {
+		t.Fatalf("ExportVault() error = %v", err)
+	}
+
+	// backup is restored into other account as client does it, items and files are sent as they were exported
+	var requests []*pb.ImportVaultRequest
+	var received []byte
+	for _, response := range exported {
+		switch entry := response.Entry.(type) {
+		case *pb.ExportVaultResponse_Credentials:
+			requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Credentials{Credentials: &pb.CreateCredentialsRequest{
+				ServiceName: entry.Credentials.ServiceName, Identity: entry.Credentials.Identity, Password: entry.Credentials.Password}}})
+		case *pb.ExportVaultResponse_Card:
+			requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Card{Card: &pb.CreateCardRequest{
+				Number: entry.Card.Number, ExpirationDate: entry.Card.ExpirationDate, HolderName: entry.Card.HolderName, Cvv: entry.Card.Cvv}}})
+		case *pb.ExportVaultResponse_Note:
+			requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Note{Note: &pb.CreateNoteRequest{
+				Title: entry.Note.Title, Body: entry.Note.Body}}})
+		case *pb.ExportVaultResponse_File:
+			digest := sha256.Sum256(data)
+			requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_File{File: &pb.ImportVaultFile{
+				FileKey: entry.File.FileKey, EncryptedName: entry.File.EncryptedName, Digest: hex.EncodeToString(digest[:])}}})
+		case *pb.ExportVaultResponse_Chunk:
+			received = append(received, entry.Chunk...)
+			requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Chunk{Chunk: entry.Chunk}})
+		}
+	}
+	if len(requests) != 6 || !bytes.Equal(received, data) {
+		t.Fatalf("ExportVault() sent %d entries and %d bytes of file, want 3 items, file and 2 chunks", len(exported), len(received))
+	}
+
+	target := signedUpContext(t, srv, "target@example.com")
+	importStream := mock.NewMockGophKeeperService_ImportVaultServer(ctrl)
+	importStream.EXPECT().Context().Return(target).AnyTimes()
+	calls := make([]any, 0, len(requests)+1)
+	for _, request := range requests {
+		calls = append(calls, importStream.EXPECT().Recv().Return(request, nil))
+	}
+	gomock.InOrder(append(calls, importStream.EXPECT().Recv().Return(nil, io.EOF))...)
+	var imported *pb.ImportVaultResponse
+	importStream.EXPECT().SendAndClose(gomock.Any()).DoAndReturn(func(response *pb.ImportVaultResponse) error {
+		imported = response
+		return nil
+	})
+	if err := srv.ImportVault(importStream); err != nil {
+		t.Fatalf("ImportVault() error = %v", err)
+	}
+	if imported.Credentials != 1 || imported.Cards != 1 || imported.Notes != 1 || imported.Files != 1 {
+		t.Errorf("ImportVault() = %v, want one item of every type", imported)
+	}
+
+	notes, err := srv.GetNotes(target, &pb.GetNotesRequest{})
+	if err != nil || len(notes.Notes) != 1 || notes.Notes[0].Body != "gk1:body" {
+		t.Errorf("GetNotes() of target = %v, %v, want imported note", notes, err)
+	}
+	files := srv.listFiles(target)
+	if len(files) != 1 || files[0].FileKey != "gk1:key" || files[0].EncryptedName != "gk1:name" {
+		t.Fatalf("listFiles() of target = %v, want imported file", files)
+	}
+	object, err := blobs.GetObject(target, target.Value(config.USERIDCONTEXTKEY).(string), files[0].Name, 0, 0)
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	defer object.Close()
+	if stored, _ := io.ReadAll(object); !bytes.Equal(stored, data) {
+		t.Errorf("ImportVault() stored %d bytes, want %d", len(stored), len(data))
+	}
+}
+
+func TestImportVault_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, storage.NewMemoryStorage(), newTestBlobStore(t))
+	ctx := signedUpContext(t, srv, "demo@example.com")
+
+	tests := []struct {
+		name    string
+		request *pb.ImportVaultRequest
+	}{
+		{name: "Empty", request: &pb.ImportVaultRequest{}},
+		{name: "ChunkWithoutFile", request: &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Chunk{Chunk: []byte("data")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := mock.NewMockGophKeeperService_ImportVaultServer(ctrl)
+			stream.EXPECT().Context().Return(ctx).AnyTimes()
+			stream.EXPECT().Recv().Return(tt.request, nil)
+			if err := srv.ImportVault(stream); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("ImportVault() error = %v, want %v", err, codes.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestImportVault_Rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user")
+	newStream := func(requests ...*pb.ImportVaultRequest) *mock.MockGophKeeperService_ImportVaultServer {
+		stream := mock.NewMockGophKeeperService_ImportVaultServer(ctrl)
+		stream.EXPECT().Context().Return(ctx).AnyTimes()
+		calls := make([]any, 0, len(requests)+1)
+		for _, request := range requests {
+			calls = append(calls, stream.EXPECT().Recv().Return(request, nil))
+		}
+		gomock.InOrder(append(calls, stream.EXPECT().Recv().Return(nil, io.EOF).MaxTimes(1))...)
+		return stream
+	}
+	credentials := func(serviceName string) *pb.ImportVaultRequest {
+		return &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Credentials{Credentials: &pb.CreateCredentialsRequest{
+			ServiceName: serviceName, Identity: "gk1:identity", Password: "gk1:password"}}}
+	}
+	note := &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Note{Note: &pb.CreateNoteRequest{Title: "wifi", Body: "gk1:body"}}}
+
+	// rejected entries do not stop import of next ones
+	stream := newStream(
+		credentials("mail"),
+		credentials("forum"),
+		&pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Card{Card: &pb.CreateCardRequest{Number: "1234"}}},
+		&pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_File{File: &pb.ImportVaultFile{Digest: "digest"}}},
+		&pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Chunk{Chunk: []byte("plain")}},
+		note,
+	)
+	gomock.InOrder(
+		repo.EXPECT().CreateCredentials(gomock.Any(), gomock.Any()).Return(models.Credentials{}, storage.ErrAlreadyExists),
+		repo.EXPECT().CreateCredentials(gomock.Any(), gomock.Any()).Return(models.Credentials{ID: "2", ServiceName: "forum"}, nil),
+	)
+	repo.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(models.Note{ID: "3", Title: "wifi"}, nil)
+	var imported *pb.ImportVaultResponse
+	stream.EXPECT().SendAndClose(gomock.Any()).DoAndReturn(func(response *pb.ImportVaultResponse) error {
+		imported = response
+		return nil
+	})
+	if err := srv.ImportVault(stream); err != nil {
+		t.Fatalf("ImportVault() error = %v", err)
+	}
+	if imported.Credentials != 1 || imported.Cards != 0 || imported.Notes != 1 || imported.Files != 0 {
+		t.Errorf("ImportVault() = %v, want one credentials and note", imported)
+	}
+	want := []struct {
+		itemType pb.ItemType
+		index    int32
+	}{
+		{pb.ItemType_ITEM_TYPE_CREDENTIALS, 0},
+		{pb.ItemType_ITEM_TYPE_CARD, 0},
+		{pb.ItemType_ITEM_TYPE_FILE, 0},
+	}
+	if len(imported.Rejected) != len(want) {
+		t.Fatalf("ImportVault() rejected = %v, want %d entries", imported.Rejected, len(want))
+	}
+	for i, rejection := range imported.Rejected {
+		if rejection.ItemType != want[i].itemType || rejection.Index != want[i].index || rejection.Reason == "" {
+			t.Errorf("ImportVault() rejected[%d] = %v, want %v entry %d", i, rejection, want[i].itemType, want[i].index)
+		}
+	}
+
+	// failure of storage breaks import instead of being counted as imported
+	repo.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(models.Note{}, errors.New("db is down"))
+	if err := srv.ImportVault(newStream(note)); status.Code(err) != codes.Internal {
+		t.Errorf("ImportVault() error = %v, want %v", err, codes.Internal)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"flag"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/PaBah/GophKeeper/internal/config"
 	"github.com/PaBah/GophKeeper/internal/logger"
@@ -24,16 +26,29 @@ func isFlagPassed(name string) bool {
 func ParseFlags(options *config.ServerConfig) {
 	var specified bool
 	var logsLevel, databaseDSN, gRPCAddress, configFilePath, minIOAdress, minIOLogin, minIOPassword string
+	var masterKey, masterKeyFile, previousMasterKeyFiles, trashRetention, blobStore, blobStorePath string
+	var signInAttempts, signInLockout string
 
 	flag.StringVar(&configFilePath, "c", "", "path to config file")
 	flag.StringVar(&options.GRPCAddress, "g", ":3200", "host:port on which gRPC run")
-	flag.StringVar(&options.DatabaseDSN, "d", "host=localhost user=paulbahush dbname=gophkeeper password=", "database DSN address")
+	flag.StringVar(&options.DatabaseDSN, "d", "host=localhost user=paulbahush dbname=gophkeeper password=", "database DSN address, sqlite://path/to/file.db selects embedded SQLite")
 	flag.StringVar(&options.LogsLevel, "l", "debug", "logs level")
 	flag.StringVar(&options.MinIOAddress, "m", "127.0.0.1:9000", "address of minio")
 	flag.StringVar(&options.MinIOLogin, "k", "admin", "login for minio")
 	flag.StringVar(&options.MinIOPassword, "p", "password123", "password for minio")
+	flag.StringVar(&options.BlobStore, "blob-store", "minio", "storage of users' files: minio or fs")
+	flag.StringVar(&options.BlobStorePath, "blob-store-path", "files", "directory which keeps users' files in fs storage")
+	flag.StringVar(&options.MasterKeyFile, "master-key-file", "", "path to file with base64 encoded master key")
+	flag.StringVar(&previousMasterKeyFiles, "previous-master-key-files", "", "comma separated paths to master keys which are rotated out")
+	flag.BoolVar(&options.RotateKeys, "rotate-keys", false, "re-wrap all data keys by master key and exit")
+	flag.StringVar(&options.TrashRetention, "trash-retention", "720h", "how long deleted items are kept in trash before purge")
+	flag.IntVar(&options.SignInAttempts, "sign-in-attempts", 5, "failed sign in attempts in a row which lock account")
+	flag.StringVar(&options.SignInLockout, "sign-in-lockout", "15m", "how long account stays locked after too many failed sign in attempts")
+	flag.BoolVar(&options.Demo, "demo", false, "keep data in memory and files in blob-store-path directory, data is lost on exit")
 	flag.Parse()
 
+	options.PreviousMasterKeyFiles = splitList(previousMasterKeyFiles)
+
 	var fileConfig config.ServerConfig
 	if configFilePath != "" {
 		file, err := os.Open(configFilePath)
@@ -61,6 +76,27 @@ func ParseFlags(options *config.ServerConfig) {
 				if !isFlagPassed("p") {
 					options.MinIOPassword = fileConfig.MinIOPassword
 				}
+				if !isFlagPassed("blob-store") && fileConfig.BlobStore != "" {
+					options.BlobStore = fileConfig.BlobStore
+				}
+				if !isFlagPassed("blob-store-path") && fileConfig.BlobStorePath != "" {
+					options.BlobStorePath = fileConfig.BlobStorePath
+				}
+				if !isFlagPassed("master-key-file") {
+					options.MasterKeyFile = fileConfig.MasterKeyFile
+				}
+				if !isFlagPassed("previous-master-key-files") {
+					options.PreviousMasterKeyFiles = fileConfig.PreviousMasterKeyFiles
+				}
+				if !isFlagPassed("trash-retention") && fileConfig.TrashRetention != "" {
+					options.TrashRetention = fileConfig.TrashRetention
+				}
+				if !isFlagPassed("sign-in-attempts") && fileConfig.SignInAttempts != 0 {
+					options.SignInAttempts = fileConfig.SignInAttempts
+				}
+				if !isFlagPassed("sign-in-lockout") && fileConfig.SignInLockout != "" {
+					options.SignInLockout = fileConfig.SignInLockout
+				}
 			}
 		}
 	}
@@ -94,4 +130,73 @@ func ParseFlags(options *config.ServerConfig) {
 	if specified {
 		options.MinIOPassword = minIOPassword
 	}
+
+	blobStore, specified = os.LookupEnv("BLOB_STORE")
+	if specified {
+		options.BlobStore = blobStore
+	}
+
+	blobStorePath, specified = os.LookupEnv("BLOB_STORE_PATH")
+	if specified {
+		options.BlobStorePath = blobStorePath
+	}
+
+	masterKey, specified = os.LookupEnv("MASTER_KEY")
+	if specified {
+		options.MasterKey = masterKey
+	}
+
+	masterKeyFile, specified = os.LookupEnv("MASTER_KEY_FILE")
+	if specified {
+		options.MasterKeyFile = masterKeyFile
+	}
+
+	previousMasterKeyFiles, specified = os.LookupEnv("PREVIOUS_MASTER_KEY_FILES")
+	if specified {
+		options.PreviousMasterKeyFiles = splitList(previousMasterKeyFiles)
+	}
+
+	trashRetention, specified = os.LookupEnv("TRASH_RETENTION")
+	if specified {
+		options.TrashRetention = trashRetention
+	}
+
+	signInAttempts, specified = os.LookupEnv("SIGN_IN_ATTEMPTS")
+	if specified {
+		if attempts, err := strconv.Atoi(signInAttempts); err == nil {
+			options.SignInAttempts = attempts
+		}
+	}
+
+	signInLockout, specified = os.LookupEnv("SIGN_IN_LOCKOUT")
+	if specified {
+		options.SignInLockout = signInLockout
+	}
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// LoadMasterKeys - read master keys from files, key passed directly through ENV has priority over file
+func LoadMasterKeys(options *config.ServerConfig) error {
+	if options.MasterKey == "" && options.MasterKeyFile != "" {
+		key, err := os.ReadFile(options.MasterKeyFile)
+		if err != nil {
+			return err
+		}
+		options.MasterKey = strings.TrimSpace(string(key))
+	}
+
+	for _, path := range options.PreviousMasterKeyFiles {
+		key, err := os.ReadFile(strings.TrimSpace(path))
+		if err != nil {
+			return err
+		}
+		options.PreviousMasterKeys = append(options.PreviousMasterKeys, strings.TrimSpace(string(key)))
+	}
+	return nil
 }
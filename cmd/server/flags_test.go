@@ -17,8 +17,8 @@ func TestParseFlags(t *testing.T) {
 	}{
 		{
 			name:          "got from ENV",
-			expectedValue: []string{":8888", "test", "info", "minio:9000", "test", "test", "test"},
-			envValues:     []string{":8888", "test", "info", "minio:9000", "test", "test", "test"},
+			expectedValue: []string{":8888", "test", "info", "minio:9000", "test", "test", "48h", "fs", "/var/lib/gophkeeper"},
+			envValues:     []string{":8888", "test", "info", "minio:9000", "test", "test", "48h", "fs", "/var/lib/gophkeeper"},
 		},
 	}
 	for _, tt := range tests {
@@ -31,6 +31,9 @@ func TestParseFlags(t *testing.T) {
 				os.Setenv("MINIO_ADDRESS", tt.envValues[3])
 				os.Setenv("MINIO_LOGIN", tt.envValues[4])
 				os.Setenv("MINIO_PASSWORD", tt.envValues[5])
+				os.Setenv("TRASH_RETENTION", tt.envValues[6])
+				os.Setenv("BLOB_STORE", tt.envValues[7])
+				os.Setenv("BLOB_STORE_PATH", tt.envValues[8])
 			}
 			ParseFlags(options)
 			assert.Equal(t, options.GRPCAddress, tt.expectedValue[0], "Правльно распаршеный GRPC_ADDRESS")
@@ -39,6 +42,9 @@ func TestParseFlags(t *testing.T) {
 			assert.Equal(t, options.MinIOAddress, tt.expectedValue[3], "Правльно распаршеный MINIO_ADDRESS")
 			assert.Equal(t, options.MinIOLogin, tt.expectedValue[4], "Правльно распаршеный MINIO_LOGIN")
 			assert.Equal(t, options.MinIOPassword, tt.expectedValue[5], "Правльно распаршеный MINIO_PASSWORD")
+			assert.Equal(t, options.TrashRetention, tt.expectedValue[6], "Правльно распаршеный TRASH_RETENTION")
+			assert.Equal(t, options.BlobStore, tt.expectedValue[7], "Правльно распаршеный BLOB_STORE")
+			assert.Equal(t, options.BlobStorePath, tt.expectedValue[8], "Правльно распаршеный BLOB_STORE_PATH")
 		})
 	}
 }
@@ -85,3 +91,22 @@ func TestIsFlagPassed(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadMasterKeys(t *testing.T) {
+	dir := t.TempDir()
+	currentPath, previousPath := dir+"/current.key", dir+"/previous.key"
+	assert.NoError(t, os.WriteFile(currentPath, []byte("current\n"), 0600))
+	assert.NoError(t, os.WriteFile(previousPath, []byte("previous\n"), 0600))
+
+	options := &config.ServerConfig{MasterKeyFile: currentPath, PreviousMasterKeyFiles: []string{previousPath}}
+	assert.NoError(t, LoadMasterKeys(options))
+	assert.Equal(t, "current", options.MasterKey)
+	assert.Equal(t, []string{"previous"}, options.PreviousMasterKeys)
+
+	options = &config.ServerConfig{MasterKey: "from env", MasterKeyFile: currentPath}
+	assert.NoError(t, LoadMasterKeys(options))
+	assert.Equal(t, "from env", options.MasterKey, "key from ENV should have priority over file")
+
+	options = &config.ServerConfig{MasterKeyFile: dir + "/missing.key"}
+	assert.Error(t, LoadMasterKeys(options))
+}
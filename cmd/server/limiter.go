@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/config"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/middlewares"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults of sign in throttling, they are used when server is configured without them
+const (
+	defaultSignInAttempts = 5
+	defaultSignInLockout  = 15 * time.Minute
+)
+
+const (
+	// accountKeyPrefix, ipKeyPrefix - kinds of keys failed sign in attempts are counted for
+	accountKeyPrefix = "account:"
+	ipKeyPrefix      = "ip:"
+	// reauthKeyPrefix - kind of key failed confirmations of signed in user by password or code are counted for
+	reauthKeyPrefix = "reauth:"
+	// ipAttemptsFactor - address is locked after more failures than account, since users share addresses behind NAT
+	ipAttemptsFactor = 4
+	// signInBackoff - delay after second failure in a row, it doubles with every next failure until key is locked
+	signInBackoff = time.Second
+	// retryAfterKey - trailer with seconds client has to wait before next sign in attempt
+	retryAfterKey = "retry-after"
+)
+
+// accountKey - key of failed sign in attempts made for account with given email
+func accountKey(email string) string {
+	return accountKeyPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+// signInKeys - keys failed sign in attempts are counted for: account and address of client, if it is known
+func signInKeys(ctx context.Context, email string) []string {
+	keys := []string{accountKey(email)}
+	if ip := middlewares.RequestSession(ctx).IP; ip != "" {
+		keys = append(keys, ipKeyPrefix+ip)
+	}
+	return keys
+}
+
+// reauthKeys - key failed confirmations of signed in user are counted for, it is kept apart from sign in keys,
+// so whoever holds a session can not lock owner out of sign in by guessing
+func reauthKeys(ctx context.Context) []string {
+	return []string{reauthKeyPrefix + ctx.Value(config.USERIDCONTEXTKEY).(string)}
+}
+
+// signInPolicy - number of failures in a row which locks account and how long lock lasts, failures older than lockout
+// are forgotten as well
+func (s *GrpcServer) signInPolicy() (attempts int, lockout time.Duration) {
+	attempts, lockout = s.config.SignInAttempts, defaultSignInLockout
+	if attempts <= 0 {
+		attempts = defaultSignInAttempts
+	}
+	if configured, err := time.ParseDuration(s.config.SignInLockout); err == nil && configured > 0 {
+		lockout = configured
+	}
+	return
+}
+
+// signInDelay - how long key is locked after its failure, backoff grows exponentially until limit of failures
+// is reached and key is locked for whole lockout
+func signInDelay(failures, limit int, lockout time.Duration) time.Duration {
+	if failures >= limit || failures-2 >= 32 {
+		return lockout
+	}
+	if failures < 2 {
+		return 0
+	}
+	return min(signInBackoff<<(failures-2), lockout)
+}
+
+// checkSignInLock - refuse sign in while any of keys is locked, client is told when to retry
+func (s *GrpcServer) checkSignInLock(ctx context.Context, keys []string) error {
+	now := time.Now()
+	for _, key := range keys {
+		attempts, err := s.storage.GetSignInAttempts(ctx, key)
+		if err != nil {
+			logger.Log().Error("sign in attempts can not be checked", zap.Error(err))
+			return status.Errorf(codes.Internal, "sign in attempts can not be checked")
+		}
+		if attempts.LockedUntil.After(now) {
+			return tooManyAttempts(ctx, attempts.LockedUntil.Sub(now))
+		}
+	}
+	return nil
+}
+
+// recordSignInFailure - count failed attempt for every key and lock keys which failed too often
+func (s *GrpcServer) recordSignInFailure(ctx context.Context, keys []string) {
+	limit, lockout := s.signInPolicy()
+	now := time.Now()
+	for _, key := range keys {
+		attempts, err := s.storage.RecordSignInFailure(ctx, key, now, now.Add(-lockout))
+		if err != nil {
+			logger.Log().Error("failed sign in attempt can not be recorded", zap.Error(err))
+			continue
+		}
+		keyLimit := limit
+		if strings.HasPrefix(key, ipKeyPrefix) {
+			keyLimit *= ipAttemptsFactor
+		}
+		delay := signInDelay(attempts.Failures, keyLimit, lockout)
+		if delay == 0 {
+			continue
+		}
+		if err = s.storage.LockSignIn(ctx, key, now.Add(delay)); err != nil {
+			logger.Log().Error("sign in can not be locked", zap.Error(err))
+		}
+	}
+}
+
+// resetSignInAttempts - forget failures of account after successful sign in, failures of address are kept,
+// so attacker can not reset them by signing in to own account
+func (s *GrpcServer) resetSignInAttempts(ctx context.Context, email string) {
+	s.resetAttempts(ctx, accountKey(email))
+}
+
+// resetReauthAttempts - forget failed confirmations of signed in user after successful one
+func (s *GrpcServer) resetReauthAttempts(ctx context.Context) {
+	s.resetAttempts(ctx, reauthKeys(ctx)[0])
+}
+
+func (s *GrpcServer) resetAttempts(ctx context.Context, key string) {
+	if err := s.storage.ResetSignInAttempts(ctx, key); err != nil {
+		logger.Log().Error("sign in attempts can not be reset", zap.Error(err))
+	}
+}
+
+// PurgeSignInAttempts - delete attempts of keys whose failures are forgotten and lock is over
+func (s *GrpcServer) PurgeSignInAttempts(ctx context.Context) (int, error) {
+	_, lockout := s.signInPolicy()
+	return s.storage.PurgeSignInAttempts(ctx, time.Now().Add(-lockout))
+}
+
+// RunSignInAttemptsPurger - purge sign in attempts every interval until context is done
+func (s *GrpcServer) RunSignInAttemptsPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := s.PurgeSignInAttempts(ctx)
+		if err != nil {
+			logger.Log().Error("sign in attempts can not be purged", zap.Error(err))
+		} else if purged > 0 {
+			logger.Log().Info("sign in attempts are purged", zap.Int("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tooManyAttempts - error of locked sign in, seconds to wait are sent in retry-after trailer
+func tooManyAttempts(ctx context.Context, retryAfter time.Duration) error {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, strconv.FormatInt(seconds, 10)))
+	return status.Errorf(codes.ResourceExhausted, "too many failed sign in attempts, retry in %d seconds", seconds)
+}
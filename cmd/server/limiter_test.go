@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/config"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// trailerStream - server transport stream which keeps trailer set by handler
+type trailerStream struct {
+	trailer metadata.MD
+}
+
+func (s *trailerStream) Method() string                  { return "/gophkeeper.v1.GophKeeperService/SignIn" }
+func (s *trailerStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *trailerStream) SendHeader(md metadata.MD) error { return nil }
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestSignInDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 2, want: time.Second},
+		{failures: 3, want: 2 * time.Second},
+		{failures: 4, want: 4 * time.Second},
+		{failures: 5, want: time.Minute},
+		{failures: 40, want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := signInDelay(tt.failures, 5, time.Minute); got != tt.want {
+			t.Errorf("signInDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+	if got := signInDelay(4, 5, 3*time.Second); got != 3*time.Second {
+		t.Errorf("signInDelay() = %v, want backoff capped by lockout", got)
+	}
+}
+
+func TestSignIn_Lockout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret", SignInAttempts: 3, SignInLockout: "10m"},
+		store, newTestBlobStore(t))
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	if _, err := srv.SignUp(ctx, &pb.SignUpRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	// unlock drops locks of backoff or lockout, as if their time passed
+	unlock := func() {
+		for _, key := range []string{"account:demo@example.com", "ip:10.0.0.1"} {
+			if err := store.LockSignIn(ctx, key, time.Now().Add(-time.Second)); err != nil {
+				t.Fatalf("LockSignIn() error = %v", err)
+			}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		unlock()
+		if _, err := srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "wrong"}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("SignIn() with wrong password error = %v, want %v", err, codes.Unauthenticated)
+		}
+	}
+
+	stream := &trailerStream{}
+	_, err := srv.SignIn(grpc.NewContextWithServerTransportStream(ctx, stream),
+		&pb.SignInRequest{Email: "Demo@example.com", Password: "password"})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("SignIn() of locked account error = %v, want %v", err, codes.ResourceExhausted)
+	}
+	if retryAfter := stream.trailer.Get(retryAfterKey); len(retryAfter) != 1 || retryAfter[0] != "600" {
+		t.Errorf("SignIn() retry-after = %v, want 600", retryAfter)
+	}
+
+	unlock()
+	if _, err = srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Fatalf("SignIn() after lockout error = %v", err)
+	}
+	account, _ := store.GetSignInAttempts(ctx, "account:demo@example.com")
+	address, _ := store.GetSignInAttempts(ctx, "ip:10.0.0.1")
+	if account.Failures != 0 || address.Failures != 3 {
+		t.Errorf("SignIn() kept failures of account %d and address %d, want 0 and 3", account.Failures, address.Failures)
+	}
+}
+
+func TestSignIn_AddressLockout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret", SignInAttempts: 1, SignInLockout: "10m"},
+		store, newTestBlobStore(t))
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 5000}})
+	if _, err := srv.SignUp(ctx, &pb.SignUpRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+
+	// guessing passwords of different accounts locks address after limit multiplied by ipAttemptsFactor
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		if err := store.LockSignIn(ctx, "ip:10.0.0.2", time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("LockSignIn() error = %v", err)
+		}
+		if _, err := srv.SignIn(ctx, &pb.SignInRequest{Email: email, Password: "wrong"}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("SignIn() of %s error = %v, want %v", email, err, codes.Unauthenticated)
+		}
+	}
+	if _, err := srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("SignIn() from locked address error = %v, want %v", err, codes.ResourceExhausted)
+	}
+	other := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.3"), Port: 5000}})
+	if _, err := srv.SignIn(other, &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Errorf("SignIn() from other address error = %v", err)
+	}
+}
+
+func TestReauth_Lockout(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret", SignInAttempts: 3, SignInLockout: "10m"},
+		store, newTestBlobStore(t))
+	signedUp, err := srv.SignUp(context.Background(), &pb.SignUpRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	userID := auth.GetUserID(signedUp.Token, "testing secret")
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, userID)
+	ctx = context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, auth.GetSessionID(signedUp.Token, "testing secret"))
+	reauthKey := reauthKeyPrefix + userID
+
+	for i := 0; i < 3; i++ {
+		if err = store.LockSignIn(ctx, reauthKey, time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("LockSignIn() error = %v", err)
+		}
+		if _, err = srv.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "changed"}); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("ChangePassword() with wrong password error = %v, want %v", err, codes.PermissionDenied)
+		}
+	}
+	if _, err = srv.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("DeleteAccount() after failed confirmations error = %v, want %v", err, codes.ResourceExhausted)
+	}
+	if _, err = srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Errorf("SignIn() after failed confirmations error = %v, want owner to keep signing in", err)
+	}
+	if account, _ := store.GetSignInAttempts(ctx, "account:demo@example.com"); account.Failures != 0 {
+		t.Errorf("ChangePassword() counted %d failures of sign in, want 0", account.Failures)
+	}
+
+	if err = store.LockSignIn(ctx, reauthKey, time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("LockSignIn() error = %v", err)
+	}
+	if _, err = srv.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "password", NewPassword: "changed"}); err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if attempts, _ := store.GetSignInAttempts(ctx, reauthKey); attempts.Failures != 0 {
+		t.Errorf("ChangePassword() kept %d failed confirmations, want 0", attempts.Failures)
+	}
+}
+
+func TestPurgeSignInAttempts(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret", SignInLockout: "10m"}, store, newTestBlobStore(t))
+	ctx := context.Background()
+	now := time.Now()
+	if _, err := store.RecordSignInFailure(ctx, "account:old@example.com", now.Add(-time.Hour), now.Add(-2*time.Hour)); err != nil {
+		t.Fatalf("RecordSignInFailure() error = %v", err)
+	}
+	if _, err := store.RecordSignInFailure(ctx, "account:new@example.com", now, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("RecordSignInFailure() error = %v", err)
+	}
+
+	purged, err := srv.PurgeSignInAttempts(ctx)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeSignInAttempts() = %d, %v, want 1 purged", purged, err)
+	}
+	if attempts, _ := store.GetSignInAttempts(ctx, "account:new@example.com"); attempts.Failures != 1 {
+		t.Errorf("PurgeSignInAttempts() purged recent failures")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/PaBah/GophKeeper/internal/middlewares"
 	"github.com/PaBah/GophKeeper/internal/tls"
@@ -16,12 +17,20 @@ import (
 	"google.golang.org/grpc/credentials"
 
 	//"github.com/PaBah/GophKeeper/cmd/server"
+	"github.com/PaBah/GophKeeper/internal/blobstore"
 	"github.com/PaBah/GophKeeper/internal/config"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/logger"
 	"github.com/PaBah/GophKeeper/internal/storage"
 )
 
+const (
+	// trashPurgeInterval - how often items kept in trash longer than retention are purged
+	trashPurgeInterval = time.Hour
+	// signInAttemptsPurgeInterval - how often forgotten failed sign in attempts are purged
+	signInAttemptsPurgeInterval = time.Hour
+)
+
 var (
 	buildVersion string = "N/A"
 	buildDate    string = "N/A"
@@ -40,20 +49,73 @@ func main() {
 		return
 	}
 
+	if err := LoadMasterKeys(serverConfig); err != nil {
+		logger.Log().Error("master keys can not be loaded", zap.Error(err))
+		return
+	}
+
+	var keys *storage.KeyManager
+	if serverConfig.MasterKey != "" {
+		var err error
+		keys, err = storage.NewKeyManager(serverConfig.MasterKey, serverConfig.PreviousMasterKeys...)
+		if err != nil {
+			logger.Log().Error("master keys are invalid", zap.Error(err))
+			return
+		}
+	} else {
+		logger.Log().Warn("master key is not configured, sensitive data is stored unencrypted")
+	}
+
 	var store storage.Repository
-	dbStore, _ := storage.NewDBStorage(context.Background(), serverConfig.DatabaseDSN)
+	if serverConfig.Demo {
+		logger.Log().Warn("demo mode, data is kept in memory and lost on exit",
+			zap.String("files", serverConfig.BlobStorePath))
+		store = storage.NewMemoryStorage()
+	} else {
+		dbStore, err := storage.NewDBStorage(context.Background(), serverConfig.DatabaseDSN, keys)
+		if err != nil {
+			logger.Log().Error("data base can not be opened", zap.Error(err))
+			return
+		}
 
-	store = &dbStore
-	defer dbStore.Close()
+		store = &dbStore
+		defer dbStore.Close()
 
-	newGRPCServer := NewGrpcServer(serverConfig, store)
+		if serverConfig.RotateKeys {
+			rotateKeys(&dbStore)
+			return
+		}
+	}
+
+	trashRetention, err := time.ParseDuration(serverConfig.TrashRetention)
+	if err != nil {
+		logger.Log().Error("trash retention is invalid", zap.Error(err))
+		return
+	}
+
+	if lockout, err := time.ParseDuration(serverConfig.SignInLockout); err != nil || lockout <= 0 || serverConfig.SignInAttempts <= 0 {
+		logger.Log().Error("sign in lockout is invalid", zap.String("lockout", serverConfig.SignInLockout),
+			zap.Int("attempts", serverConfig.SignInAttempts))
+		return
+	}
+
+	blobs, err := newBlobStore(serverConfig)
+	if err != nil {
+		logger.Log().Error("file storage can not be opened", zap.Error(err))
+		return
+	}
+
+	newGRPCServer := NewGrpcServer(serverConfig, store, blobs)
 
 	logger.Log().Info("Start gRPC server on", zap.String("address", serverConfig.GRPCAddress))
-	interceptors := middlewares.NewGRPCServerMiddleware(serverConfig.Secret)
+	interceptors := middlewares.NewGRPCServerMiddleware(serverConfig.Secret, store)
 	authInterceptor := []grpc.UnaryServerInterceptor{interceptors.AuthInterceptor}
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	defer stop()
 
+	go newGRPCServer.RunTrashPurger(ctx, trashRetention, trashPurgeInterval)
+	go newGRPCServer.RunSignInAttemptsPurger(ctx, signInAttemptsPurgeInterval)
+
 	go func() {
 		listen, err := net.Listen("tcp", serverConfig.GRPCAddress)
 		if err != nil {
@@ -82,3 +144,36 @@ func main() {
 
 	<-ctx.Done()
 }
+
+// newBlobStore - storage of users' files selected by configuration, demo mode always keeps files in local directory
+func newBlobStore(options *config.ServerConfig) (blobstore.BlobStore, error) {
+	if options.Demo {
+		return blobstore.NewFSStore(options.BlobStorePath)
+	}
+	switch options.BlobStore {
+	case "minio", "":
+		return blobstore.NewMinIOStore(options.MinIOAddress, options.MinIOLogin, options.MinIOPassword)
+	case "fs":
+		return blobstore.NewFSStore(options.BlobStorePath)
+	}
+	return nil, fmt.Errorf("unknown blob store %q", options.BlobStore)
+}
+
+// rotateKeys - re-wrap data keys by current master key and encrypt rows stored before encryption was enabled or
+// sealed before values were bound to their rows.
+// Servers must be restarted with new master key and old one in previous keys before rotation is run.
+func rotateKeys(dbStore *storage.DBStorage) {
+	rotated, err := dbStore.RotateMasterKey(context.Background())
+	if err != nil {
+		logger.Log().Error("data keys can not be rotated", zap.Error(err))
+		return
+	}
+	logger.Log().Info("data keys are rotated", zap.Int("count", rotated))
+
+	encrypted, err := dbStore.EncryptPlaintextRows(context.Background())
+	if err != nil {
+		logger.Log().Error("plaintext rows can not be encrypted", zap.Error(err))
+		return
+	}
+	logger.Log().Info("plaintext rows are encrypted", zap.Int("count", encrypted))
+}
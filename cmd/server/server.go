@@ -1,54 +1,108 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"io"
-	"log"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
-
 	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/blobstore"
 	"github.com/PaBah/GophKeeper/internal/config"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/middlewares"
 	"github.com/PaBah/GophKeeper/internal/models"
 	"github.com/PaBah/GophKeeper/internal/storage"
 	"github.com/PaBah/GophKeeper/internal/utils"
+	"github.com/PaBah/GophKeeper/internal/vault"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Object user metadata which keeps item metadata, SHA-256 digest, wrapped encryption key and encrypted name of files,
+// deleted files keep name they are restored to
+const (
+	objectMetadataKey    = "Metadata"
+	objectDigestKey      = "Sha256"
+	objectFileKeyKey     = "Filekey"
+	objectNameKey        = "Filename"
+	objectTrashedNameKey = "Trashedname"
+	userMetadataPrefix   = "X-Amz-Meta-"
 )
 
+// preservedObjectMetadata - object user metadata which is kept when item metadata is replaced
+var preservedObjectMetadata = []string{objectDigestKey, objectFileKeyKey, objectNameKey}
+
+// downloadChunkSize - size of chunks file is streamed by
+const downloadChunkSize = 1 << 20
+
+// maxPasswordLength - bcrypt ignores bytes of password after this length, so longer passwords are refused
+const maxPasswordLength = 72
+
+// trashPrefix - prefix of objects of deleted files, they are kept in user's bucket until trash is purged under name
+// suffixed with time of deletion, so files deleted with the same name do not overwrite each other
+const trashPrefix = ".trash/"
+
 type GrpcServer struct {
 	pb.UnimplementedGophKeeperServiceServer
-	config      *config.ServerConfig
-	storage     storage.Repository
-	minioClient *minio.Client
+	config  *config.ServerConfig
+	storage storage.Repository
+	blobs   blobstore.BlobStore
+
+	uploads      map[string]*uploadSession
+	uploadsMutex *sync.Mutex
 
 	syncClients map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer
-	rwMutex     *sync.RWMutex
+	// subscriptions - closes SubscribeToChanges stream of session, when session is revoked
+	subscriptions map[string]context.CancelFunc
+	rwMutex       *sync.RWMutex
 }
 
 // SignIn - handler for Sign In
 func (s *GrpcServer) SignIn(ctx context.Context, in *pb.SignInRequest) (*pb.SignInResponse, error) {
 	response := &pb.SignInResponse{}
+	keys := signInKeys(ctx, in.Email)
+	if err := s.checkSignInLock(ctx, keys); err != nil {
+		return response, err
+	}
 	user, err := s.storage.AuthorizeUser(ctx, in.Email)
 
 	if err != nil || !utils.CheckPasswordHash(user.Password, in.Password) {
-		return response, status.Errorf(codes.Unavailable, "User with such credentials can not be logined")
+		s.recordSignInFailure(ctx, keys)
+		return response, status.Errorf(codes.Unauthenticated, "User with such credentials can not be logined")
+	}
+
+	secondFactor, err := s.storage.GetTOTP(context.WithValue(ctx, config.USERIDCONTEXTKEY, user.ID))
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be checked")
+	}
+	if secondFactor.Enabled {
+		response.PartialToken, err = auth.BuildPartialToken(user.ID, s.config.Secret)
+		if err != nil {
+			return response, status.Errorf(codes.Internal, "Can not build auth token")
+		}
+		response.TotpRequired = true
+		return response, nil
 	}
 
-	sessionID := uuid.New().String()
-	JWTToken, err := auth.BuildJWTString(user.ID, sessionID, s.config.Secret)
+	tokens, err := s.startSession(ctx, user.ID)
 	if err != nil {
 		return response, status.Errorf(codes.Internal, "Can not build auth token")
 	}
+	s.resetSignInAttempts(ctx, in.Email)
 
-	response.Token = JWTToken
+	response.Token, response.RefreshToken, response.ExpiresAt = tokens.token, tokens.refreshToken, tokens.expiresAt
 	return response, nil
 }
 
@@ -63,18 +117,252 @@ func (s *GrpcServer) SignUp(ctx context.Context, in *pb.SignUpRequest) (*pb.Sign
 		return response, status.Errorf(codes.InvalidArgument, "User with such email already exists")
 	}
 
-	sessionID := uuid.New().String()
-	JWTToken, err := auth.BuildJWTString(createdUser.ID, sessionID, s.config.Secret)
+	err = s.blobs.MakeBucket(ctx, createdUser.ID)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "file storage can not be created")
+	}
+
+	tokens, err := s.startSession(ctx, createdUser.ID)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "JWT token can not be built")
+	}
+
+	response.Token, response.RefreshToken, response.ExpiresAt = tokens.token, tokens.refreshToken, tokens.expiresAt
+	return response, nil
+}
+
+// RefreshToken - handler for exchange of refresh token for new access token and refresh token of the same session
+func (s *GrpcServer) RefreshToken(ctx context.Context, in *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	response := &pb.RefreshTokenResponse{}
+
+	refreshToken, tokenHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "refresh token can not be generated")
+	}
+	session, err := s.storage.RefreshSession(ctx, auth.HashRefreshToken(in.RefreshToken), tokenHash, time.Now().Add(auth.RefreshTokenExp))
+	if errors.Is(err, storage.ErrNotFound) {
+		return response, status.Errorf(codes.Unauthenticated, "refresh token is expired or revoked")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "session can not be refreshed")
+	}
+
+	tokens, err := s.sessionTokens(session, refreshToken)
 	if err != nil {
 		return response, status.Errorf(codes.Internal, "JWT token can not be built")
 	}
 
-	err = s.minioClient.MakeBucket(context.Background(), createdUser.ID, minio.MakeBucketOptions{})
+	response.Token, response.RefreshToken, response.ExpiresAt = tokens.token, tokens.refreshToken, tokens.expiresAt
+	return response, nil
+}
+
+// sessionTokens - short-lived access token of session and refresh token which prolongs the session
+type sessionTokens struct {
+	token        string
+	refreshToken string
+	expiresAt    string
+}
+
+// startSession - open new session of user on device which sent request
+func (s *GrpcServer) startSession(ctx context.Context, userID string) (sessionTokens, error) {
+	refreshToken, tokenHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	session := middlewares.RequestSession(ctx)
+	session.ID, session.UserID = uuid.New().String(), userID
+	session.LastSeenAt, session.ExpiresAt = time.Now(), time.Now().Add(auth.RefreshTokenExp)
+	if err = s.storage.CreateSession(ctx, session, tokenHash); err != nil {
+		return sessionTokens{}, err
+	}
+	return s.sessionTokens(session, refreshToken)
+}
+
+// sessionTokens - build access token of session, its expiration time is reported to client in RFC3339
+func (s *GrpcServer) sessionTokens(session models.Session, refreshToken string) (sessionTokens, error) {
+	expiresAt := time.Now().Add(auth.AccessTokenExp)
+	token, err := auth.BuildJWTString(session.UserID, session.ID, s.config.Secret)
+	if err != nil {
+		return sessionTokens{}, err
+	}
+	return sessionTokens{token: token, refreshToken: refreshToken, expiresAt: expiresAt.Format(time.RFC3339)}, nil
+}
+
+// ListSessions - handler for listing not expired sessions of user, the session which requested it is marked current
+func (s *GrpcServer) ListSessions(ctx context.Context, in *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	response := &pb.ListSessionsResponse{}
+	sessionID, _ := ctx.Value(config.SESSIONIDCONTEXTKEY).(string)
+
+	sessions, err := s.storage.ListSessions(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "sessions can not be listed")
+	}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, &pb.ListSessionsResponse_Session{
+			Id:            session.ID,
+			Device:        session.Device,
+			ClientVersion: session.ClientVersion,
+			Ip:            session.IP,
+			CreatedAt:     session.CreatedAt.Format(time.RFC3339),
+			LastSeenAt:    session.LastSeenAt.Format(time.RFC3339),
+			ExpiresAt:     session.ExpiresAt.Format(time.RFC3339),
+			Current:       session.ID == sessionID,
+		})
+	}
+	return response, nil
+}
+
+// RevokeSession - handler for signing out session of user, its tokens are not accepted and its stream of changes
+// is closed at once
+func (s *GrpcServer) RevokeSession(ctx context.Context, in *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	response := &pb.RevokeSessionResponse{}
+
+	err := s.storage.RevokeSession(ctx, in.Id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return response, status.Errorf(codes.NotFound, "session not found")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "session can not be revoked")
+	}
+
+	s.unsubscribe(in.Id)
+	return response, nil
+}
+
+// ChangePassword - handler for replacing password of user. Other sessions are signed out, since password is usually
+// changed because it could leak. Vault items stay readable: they are encrypted by key of master password,
+// which is not derived from password of account.
+func (s *GrpcServer) ChangePassword(ctx context.Context, in *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	response := &pb.ChangePasswordResponse{}
+	if in.NewPassword == "" {
+		return response, status.Errorf(codes.InvalidArgument, "new password is required")
+	}
+	if len(in.NewPassword) > maxPasswordLength {
+		return response, status.Errorf(codes.InvalidArgument, "new password is longer than %d bytes", maxPasswordLength)
+	}
+
+	user, err := s.storage.GetUser(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "password can not be changed")
+	}
+	keys := reauthKeys(ctx)
+	if err = s.checkSignInLock(ctx, keys); err != nil {
+		return response, err
+	}
+	if !utils.CheckPasswordHash(user.Password, in.OldPassword) {
+		s.recordSignInFailure(ctx, keys)
+		return response, status.Errorf(codes.PermissionDenied, "old password is not valid")
+	}
+
+	sessionID, _ := ctx.Value(config.SESSIONIDCONTEXTKEY).(string)
+	revoked, err := s.storage.ChangePassword(ctx, utils.PasswordHash(in.NewPassword), sessionID)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "password can not be changed")
+	}
+	s.resetSignInAttempts(ctx, user.Email)
+	s.resetReauthAttempts(ctx)
+	s.unsubscribe(revoked...)
+
+	response.RevokedSessions = int32(len(revoked))
+	return response, nil
+}
+
+// DeleteAccount - handler for erasure of account with all its items, files and sessions, password is asked again
+// so account can not be deleted from unattended device
+func (s *GrpcServer) DeleteAccount(ctx context.Context, in *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	response := &pb.DeleteAccountResponse{}
+
+	user, err := s.storage.GetUser(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "account can not be deleted")
+	}
+	keys := reauthKeys(ctx)
+	if err = s.checkSignInLock(ctx, keys); err != nil {
+		return response, err
+	}
+	if !utils.CheckPasswordHash(user.Password, in.Password) {
+		s.recordSignInFailure(ctx, keys)
+		return response, status.Errorf(codes.PermissionDenied, "password is not valid")
+	}
+
+	// files are removed first, so failure leaves account in place and deletion can be retried
+	if err = s.blobs.RemoveBucket(ctx, user.ID); err != nil {
+		logger.Log().Error("file storage of deleted account can not be removed", zap.Error(err))
+		return response, status.Errorf(codes.Internal, "account can not be deleted")
+	}
+	revoked, err := s.storage.DeleteAccount(ctx)
+	if err != nil {
+		logger.Log().Error("account can not be deleted", zap.Error(err))
+		return response, status.Errorf(codes.Internal, "account can not be deleted")
+	}
+	s.resetSignInAttempts(ctx, user.Email)
+	s.resetReauthAttempts(ctx)
+	s.unsubscribe(revoked...)
+
+	response.Email = user.Email
+	response.DeletedAt = time.Now().Format(time.RFC3339)
+	return response, nil
+}
+
+// unsubscribe - stop change streams of revoked sessions
+func (s *GrpcServer) unsubscribe(sessionIDs ...string) {
+	s.rwMutex.Lock()
+	defer s.rwMutex.Unlock()
+	for _, id := range sessionIDs {
+		if cancel, ok := s.subscriptions[id]; ok {
+			cancel()
+		}
+	}
+}
+
+// GetVaultParams - handler for retrieving user's vault key derivation parameters
+func (s *GrpcServer) GetVaultParams(ctx context.Context, in *pb.GetVaultParamsRequest) (*pb.GetVaultParamsResponse, error) {
+	response := &pb.GetVaultParamsResponse{}
+
+	params, err := s.storage.GetVaultParams(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "vault parameters can not be retrieved")
+	}
+
+	response.Salt = params.Salt
+	response.KeyCheck = params.KeyCheck
+	response.WrappedKey = params.WrappedKey
+	return response, nil
+}
+
+// InitVault - handler for saving user's vault key derivation parameters
+func (s *GrpcServer) InitVault(ctx context.Context, in *pb.InitVaultRequest) (*pb.InitVaultResponse, error) {
+	response := &pb.InitVaultResponse{}
+
+	err := s.storage.SetVaultParams(ctx, models.VaultParams{Salt: in.Salt, KeyCheck: in.KeyCheck})
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		return response, status.Errorf(codes.AlreadyExists, "vault is already initialized")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "vault can not be initialized")
+	}
+
+	return response, nil
+}
+
+// ChangeMasterPassword - handler for replacing user's vault key derivation parameters after master password
+// change. Vault key is only re-wrapped by client, so items stay encrypted by the same key. Change is rejected
+// if key check of vault does not match the one client unlocked vault with.
+func (s *GrpcServer) ChangeMasterPassword(ctx context.Context, in *pb.ChangeMasterPasswordRequest) (*pb.ChangeMasterPasswordResponse, error) {
+	response := &pb.ChangeMasterPasswordResponse{}
+
+	err := s.storage.ChangeVaultParams(ctx, in.OldKeyCheck, models.VaultParams{
+		Salt:       in.Salt,
+		KeyCheck:   in.KeyCheck,
+		WrappedKey: in.WrappedKey,
+	})
+	if errors.Is(err, storage.ErrVaultChanged) {
+		return response, status.Errorf(codes.FailedPrecondition, "vault was changed, unlock it again")
+	}
 	if err != nil {
-		log.Fatalln(err)
+		return response, status.Errorf(codes.Internal, "master password can not be changed")
 	}
 
-	response.Token = JWTToken
 	return response, nil
 }
 
@@ -82,17 +370,25 @@ func (s *GrpcServer) SignUp(ctx context.Context, in *pb.SignUpRequest) (*pb.Sign
 func (s *GrpcServer) CreateCredentials(ctx context.Context, in *pb.CreateCredentialsRequest) (*pb.CreateCredentialsResponse, error) {
 	response := &pb.CreateCredentialsResponse{}
 
+	if !validItemID(in.Id) {
+		return response, status.Errorf(codes.InvalidArgument, "invalid credentials ID")
+	}
 	credentials := models.NewCredentials(in.ServiceName, in.Identity, in.Password)
+	credentials.ID = in.Id
+	credentials.TOTP = in.Totp
+	credentials.Metadata = in.Metadata
 	createdCredentials, err := s.storage.CreateCredentials(ctx, credentials)
 
 	if errors.Is(err, storage.ErrAlreadyExists) {
 		return response, status.Errorf(codes.InvalidArgument, "User already created credentials with such service name and identity")
 	}
 
-	s.SendNotifications(ctx, 0, createdCredentials.ID)
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, createdCredentials))
 	response.Id = createdCredentials.ID
 	response.ServiceName = createdCredentials.ServiceName
 	response.UploadedAt = createdCredentials.UploadedAt.Format(time.RFC3339)
+	response.Metadata = createdCredentials.Metadata
+	response.Version = createdCredentials.Version
 
 	return response, nil
 }
@@ -108,17 +404,24 @@ func (s *GrpcServer) GetCredentials(ctx context.Context, in *pb.GetCredentialsRe
 	}
 
 	for _, credentialSet := range credentials {
-		response.Credentials = append(response.Credentials, &pb.GetCredentialsResponse_Credential{
-			Id:          credentialSet.ID,
-			ServiceName: credentialSet.ServiceName,
-			Identity:    credentialSet.Identity,
-			Password:    credentialSet.Password,
-			UploadedAt:  credentialSet.UploadedAt.Format(time.RFC3339),
-		})
+		response.Credentials = append(response.Credentials, credentialsMessage(credentialSet))
 	}
 	return response, nil
 }
 
+func credentialsMessage(credentials models.Credentials) *pb.GetCredentialsResponse_Credential {
+	return &pb.GetCredentialsResponse_Credential{
+		Id:          credentials.ID,
+		ServiceName: credentials.ServiceName,
+		Identity:    credentials.Identity,
+		Password:    credentials.Password,
+		Totp:        credentials.TOTP,
+		UploadedAt:  credentials.UploadedAt.Format(time.RFC3339),
+		Metadata:    credentials.Metadata,
+		Version:     credentials.Version,
+	}
+}
+
 // UpdateCredentials - handler for get credentials stored by user
 func (s *GrpcServer) UpdateCredentials(ctx context.Context, in *pb.UpdateCredentialsRequest) (*pb.UpdateCredentialsResponse, error) {
 	response := &pb.UpdateCredentialsResponse{}
@@ -128,15 +431,23 @@ func (s *GrpcServer) UpdateCredentials(ctx context.Context, in *pb.UpdateCredent
 		ServiceName: in.ServiceName,
 		Identity:    in.Identity,
 		Password:    in.Password,
+		TOTP:        in.Totp,
+		Metadata:    in.Metadata,
+		Version:     in.Version,
 	})
 
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.credentialsConflict(ctx, in.Id)
+	}
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, "credentials can not be updated")
 	}
-	s.SendNotifications(ctx, 0, createdCredentials.ID)
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_UPDATE, createdCredentials))
 	response.Id = createdCredentials.ID
 	response.ServiceName = createdCredentials.ServiceName
 	response.UploadedAt = createdCredentials.UploadedAt.Format(time.RFC3339)
+	response.Metadata = createdCredentials.Metadata
+	response.Version = createdCredentials.Version
 
 	return response, nil
 }
@@ -145,12 +456,15 @@ func (s *GrpcServer) UpdateCredentials(ctx context.Context, in *pb.UpdateCredent
 func (s *GrpcServer) DeleteCredentials(ctx context.Context, in *pb.DeleteCredentialsRequest) (*pb.DeleteCredentialsResponse, error) {
 	response := &pb.DeleteCredentialsResponse{}
 
-	err := s.storage.DeleteCredentials(ctx, in.Id)
+	err := s.storage.DeleteCredentials(ctx, in.Id, in.Version)
 
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.credentialsConflict(ctx, in.Id)
+	}
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, "credentials can not be deleted")
 	}
-	s.SendNotifications(ctx, 0, in.Id)
+	s.SendNotifications(ctx, tombstone(pb.ItemType_ITEM_TYPE_CREDENTIALS, in.Id, in.Version+1))
 	return response, nil
 }
 
@@ -158,20 +472,27 @@ func (s *GrpcServer) DeleteCredentials(ctx context.Context, in *pb.DeleteCredent
 func (s *GrpcServer) CreateCard(ctx context.Context, in *pb.CreateCardRequest) (*pb.CreateCardResponse, error) {
 	response := &pb.CreateCardResponse{}
 
-	if utils.ValidateLuhn(in.Number) != nil {
+	if !vault.IsSealed(in.Number) && utils.ValidateLuhn(in.Number) != nil {
 		return response, status.Errorf(codes.InvalidArgument, "invalid card number")
 	}
+	if !validItemID(in.Id) {
+		return response, status.Errorf(codes.InvalidArgument, "invalid card ID")
+	}
 
 	card := models.NewCard(in.Number, in.ExpirationDate, in.HolderName, in.Cvv)
+	card.ID = in.Id
+	card.Metadata = in.Metadata
 	createdCard, err := s.storage.CreateCard(ctx, card)
 
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, "card can not be created")
 	}
-	s.SendNotifications(ctx, 1, createdCard.ID)
-	response.LastDigits = createdCard.Number[12:]
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, createdCard))
+	response.LastDigits = lastDigits(createdCard.Number)
 	response.ExpirationDate = createdCard.ExpirationDate
 	response.UploadedAt = createdCard.UploadedAt.Format(time.RFC3339)
+	response.Metadata = createdCard.Metadata
+	response.Version = createdCard.Version
 
 	return response, nil
 }
@@ -186,23 +507,29 @@ func (s *GrpcServer) GetCards(ctx context.Context, in *pb.GetCardsRequest) (*pb.
 	}
 
 	for _, card := range cards {
-		response.Cards = append(response.Cards, &pb.GetCardsResponse_Card{
-			Id:             card.ID,
-			Number:         card.Number,
-			ExpirationDate: card.ExpirationDate,
-			HolderName:     card.HolderName,
-			Cvv:            card.CVV,
-			UploadedAt:     card.UploadedAt.Format(time.RFC3339),
-		})
+		response.Cards = append(response.Cards, cardMessage(card))
 	}
 	return response, nil
 }
 
+func cardMessage(card models.Card) *pb.GetCardsResponse_Card {
+	return &pb.GetCardsResponse_Card{
+		Id:             card.ID,
+		Number:         card.Number,
+		ExpirationDate: card.ExpirationDate,
+		HolderName:     card.HolderName,
+		Cvv:            card.CVV,
+		UploadedAt:     card.UploadedAt.Format(time.RFC3339),
+		Metadata:       card.Metadata,
+		Version:        card.Version,
+	}
+}
+
 // UpdateCard - handler for update Card stored by user
 func (s *GrpcServer) UpdateCard(ctx context.Context, in *pb.UpdateCardRequest) (*pb.UpdateCardResponse, error) {
 	response := &pb.UpdateCardResponse{}
 
-	if utils.ValidateLuhn(in.Number) != nil {
+	if !vault.IsSealed(in.Number) && utils.ValidateLuhn(in.Number) != nil {
 		return response, status.Errorf(codes.InvalidArgument, "invalid card number")
 	}
 
@@ -212,33 +539,337 @@ func (s *GrpcServer) UpdateCard(ctx context.Context, in *pb.UpdateCardRequest) (
 		ExpirationDate: in.ExpirationDate,
 		HolderName:     in.HolderName,
 		CVV:            in.Cvv,
+		Metadata:       in.Metadata,
+		Version:        in.Version,
 	})
 
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.cardConflict(ctx, in.Id)
+	}
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, "card can not be updated")
 	}
-	s.SendNotifications(ctx, 1, card.ID)
-	response.LastDigits = card.Number[12:]
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_UPDATE, card))
+	response.LastDigits = lastDigits(card.Number)
 	response.ExpirationDate = card.ExpirationDate
 	response.UploadedAt = card.UploadedAt.Format(time.RFC3339)
+	response.Metadata = card.Metadata
+	response.Version = card.Version
 
 	return response, nil
 }
 
+// validItemID - check that ID of new item chosen by client is UUID, empty ID is assigned by storage
+func validItemID(id string) bool {
+	if id == "" {
+		return true
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// lastDigits - return last digits of plain card number, sealed numbers are not visible to server
+func lastDigits(number string) string {
+	if vault.IsSealed(number) || len(number) < 12 {
+		return ""
+	}
+	return number[12:]
+}
+
 // DeleteCard - handler for deletion of user's Card
 func (s *GrpcServer) DeleteCard(ctx context.Context, in *pb.DeleteCardRequest) (*pb.DeleteCardResponse, error) {
 	response := &pb.DeleteCardResponse{}
 
-	err := s.storage.DeleteCard(ctx, in.Id)
+	err := s.storage.DeleteCard(ctx, in.Id, in.Version)
 
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.cardConflict(ctx, in.Id)
+	}
 	if err != nil {
 		return response, status.Errorf(codes.InvalidArgument, "card can not be deleted")
 	}
-	s.SendNotifications(ctx, 1, in.Id)
+	s.SendNotifications(ctx, tombstone(pb.ItemType_ITEM_TYPE_CARD, in.Id, in.Version+1))
+	return response, nil
+}
+
+// CreateNote - handler for creating Note records in DB
+func (s *GrpcServer) CreateNote(ctx context.Context, in *pb.CreateNoteRequest) (*pb.CreateNoteResponse, error) {
+	response := &pb.CreateNoteResponse{}
+
+	if !validItemID(in.Id) {
+		return response, status.Errorf(codes.InvalidArgument, "invalid note ID")
+	}
+	newNote := models.NewNote(in.Title, in.Body)
+	newNote.ID = in.Id
+	newNote.Metadata = in.Metadata
+	note, err := s.storage.CreateNote(ctx, newNote)
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "note can not be created")
+	}
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_CREATE, note))
+	response.Id = note.ID
+	response.Title = note.Title
+	response.UploadedAt = note.UploadedAt.Format(time.RFC3339)
+	response.Metadata = note.Metadata
+	response.Version = note.Version
+
+	return response, nil
+}
+
+// GetNotes - handler for get Notes stored by user
+func (s *GrpcServer) GetNotes(ctx context.Context, in *pb.GetNotesRequest) (*pb.GetNotesResponse, error) {
+	response := &pb.GetNotesResponse{}
+
+	notes, err := s.storage.GetNotes(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "notes can not be retrieved")
+	}
+
+	for _, note := range notes {
+		response.Notes = append(response.Notes, noteMessage(note))
+	}
+	return response, nil
+}
+
+func noteMessage(note models.Note) *pb.GetNotesResponse_Note {
+	return &pb.GetNotesResponse_Note{
+		Id:         note.ID,
+		Title:      note.Title,
+		Body:       note.Body,
+		UploadedAt: note.UploadedAt.Format(time.RFC3339),
+		Metadata:   note.Metadata,
+		Version:    note.Version,
+	}
+}
+
+// UpdateNote - handler for update Note stored by user
+func (s *GrpcServer) UpdateNote(ctx context.Context, in *pb.UpdateNoteRequest) (*pb.UpdateNoteResponse, error) {
+	response := &pb.UpdateNoteResponse{}
+
+	note, err := s.storage.UpdateNote(ctx, models.Note{
+		ID:       in.Id,
+		Title:    in.Title,
+		Body:     in.Body,
+		Metadata: in.Metadata,
+		Version:  in.Version,
+	})
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.noteConflict(ctx, in.Id)
+	}
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "note can not be updated")
+	}
+	s.SendNotifications(ctx, changeEvent(pb.ChangeOperation_CHANGE_OPERATION_UPDATE, note))
+	response.Id = note.ID
+	response.Title = note.Title
+	response.UploadedAt = note.UploadedAt.Format(time.RFC3339)
+	response.Metadata = note.Metadata
+	response.Version = note.Version
+
+	return response, nil
+}
+
+// DeleteNote - handler for deletion of user's Note
+func (s *GrpcServer) DeleteNote(ctx context.Context, in *pb.DeleteNoteRequest) (*pb.DeleteNoteResponse, error) {
+	response := &pb.DeleteNoteResponse{}
+
+	err := s.storage.DeleteNote(ctx, in.Id, in.Version)
+	if errors.Is(err, storage.ErrVersionConflict) {
+		return response, s.noteConflict(ctx, in.Id)
+	}
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "note can not be deleted")
+	}
+	s.SendNotifications(ctx, tombstone(pb.ItemType_ITEM_TYPE_NOTE, in.Id, in.Version+1))
+	return response, nil
+}
+
+// credentialsConflict - error of change made to outdated version of credentials
+func (s *GrpcServer) credentialsConflict(ctx context.Context, id string) error {
+	credentials, err := s.storage.GetCredentials(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "credentials can not be retrieved")
+	}
+	return versionConflict("credentials", id, credentials, func(item models.Credentials) (string, protoadapt.MessageV1) {
+		return item.ID, credentialsMessage(item)
+	})
+}
+
+// cardConflict - error of change made to outdated version of card
+func (s *GrpcServer) cardConflict(ctx context.Context, id string) error {
+	cards, err := s.storage.GetCards(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "cards can not be retrieved")
+	}
+	return versionConflict("card", id, cards, func(item models.Card) (string, protoadapt.MessageV1) {
+		return item.ID, cardMessage(item)
+	})
+}
+
+// noteConflict - error of change made to outdated version of note
+func (s *GrpcServer) noteConflict(ctx context.Context, id string) error {
+	notes, err := s.storage.GetNotes(ctx)
+	if err != nil {
+		return status.Errorf(codes.Internal, "notes can not be retrieved")
+	}
+	return versionConflict("note", id, notes, func(item models.Note) (string, protoadapt.MessageV1) {
+		return item.ID, noteMessage(item)
+	})
+}
+
+// versionConflict - Aborted status with current server copy of item in details, so client can resolve conflict.
+// Status has no details when item was deleted by another client.
+func versionConflict[T any](kind, id string, items []T, message func(T) (string, protoadapt.MessageV1)) error {
+	for _, item := range items {
+		itemID, current := message(item)
+		if itemID != id {
+			continue
+		}
+		st, err := status.New(codes.Aborted, kind+" was changed by another client").WithDetails(current)
+		if err != nil {
+			return status.Errorf(codes.Internal, "%s conflict can not be reported", kind)
+		}
+		return st.Err()
+	}
+	return status.Errorf(codes.Aborted, "%s was deleted by another client", kind)
+}
+
+// historyItems - item types which keep revisions
+var historyItems = map[pb.ItemType]string{
+	pb.ItemType_ITEM_TYPE_CREDENTIALS: models.CredentialsItem,
+	pb.ItemType_ITEM_TYPE_CARD:        models.CardItem,
+	pb.ItemType_ITEM_TYPE_NOTE:        models.NoteItem,
+}
+
+// ListItemHistory - return previous revisions of user's credentials, card or note
+func (s *GrpcServer) ListItemHistory(ctx context.Context, in *pb.ListItemHistoryRequest) (*pb.ListItemHistoryResponse, error) {
+	response := &pb.ListItemHistoryResponse{}
+	itemType, ok := historyItems[in.ItemType]
+	if !ok {
+		return response, status.Errorf(codes.InvalidArgument, "item type has no history")
+	}
+
+	revisions, err := s.storage.ListItemHistory(ctx, itemType, in.Id)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "history can not be retrieved")
+	}
+	for _, revision := range revisions {
+		message := &pb.ItemRevision{
+			Id:        revision.ID,
+			Version:   revision.Version,
+			RevisedAt: revision.RevisedAt.Format(time.RFC3339),
+			Deleted:   revision.Deleted,
+		}
+		switch revisionItem := revision.Item.(type) {
+		case models.Credentials:
+			message.Item = &pb.ItemRevision_Credential{Credential: credentialsMessage(revisionItem)}
+		case models.Card:
+			message.Item = &pb.ItemRevision_Card{Card: cardMessage(revisionItem)}
+		case models.Note:
+			message.Item = &pb.ItemRevision_Note{Note: noteMessage(revisionItem)}
+		}
+		response.Revisions = append(response.Revisions, message)
+	}
+	return response, nil
+}
+
+// RestoreItemRevision - replace user's item of given version by its previous revision
+func (s *GrpcServer) RestoreItemRevision(ctx context.Context, in *pb.RestoreItemRevisionRequest) (*pb.RestoreItemRevisionResponse, error) {
+	response := &pb.RestoreItemRevisionResponse{}
+	itemType, ok := historyItems[in.ItemType]
+	if !ok {
+		return response, status.Errorf(codes.InvalidArgument, "item type has no history")
+	}
+
+	version, err := s.storage.RestoreItemRevision(ctx, itemType, in.Id, in.RevisionId, in.Version)
+	if errors.Is(err, storage.ErrVersionConflict) {
+		switch itemType {
+		case models.CredentialsItem:
+			return response, s.credentialsConflict(ctx, in.Id)
+		case models.CardItem:
+			return response, s.cardConflict(ctx, in.Id)
+		default:
+			return response, s.noteConflict(ctx, in.Id)
+		}
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return response, status.Errorf(codes.NotFound, "revision not found")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "revision can not be restored")
+	}
+	s.SendNotifications(ctx, &pb.SubscribeToChangesResponse{
+		Id:        in.Id,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_UPDATE,
+		ItemType:  in.ItemType,
+		Version:   version,
+	})
+	response.Version = version
+
+	return response, nil
+}
+
+// Sync - handler for retrieving user's items changed since client's cursor
+func (s *GrpcServer) Sync(ctx context.Context, in *pb.SyncRequest) (*pb.SyncResponse, error) {
+	response := &pb.SyncResponse{}
+
+	changes, err := s.storage.Sync(ctx, in.Cursor)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "changes can not be retrieved")
+	}
+	response.Cursor, response.Full = changes.Cursor, changes.Full
+	for _, credentials := range changes.Credentials {
+		response.Credentials = append(response.Credentials, credentialsMessage(credentials))
+	}
+	for _, card := range changes.Cards {
+		response.Cards = append(response.Cards, cardMessage(card))
+	}
+	for _, note := range changes.Notes {
+		response.Notes = append(response.Notes, noteMessage(note))
+	}
+	for _, deleted := range changes.Deleted {
+		response.Deleted = append(response.Deleted, &pb.SyncResponse_DeletedItem{
+			ItemType: itemTypeMessage(deleted.ItemType),
+			Id:       deleted.ItemID,
+		})
+	}
+
+	if changes.Full {
+		response.Files = s.listFiles(ctx)
+		return response, nil
+	}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	for _, file := range changes.Files {
+		object, err := s.blobs.StatObject(ctx, userID, file.Name)
+		if errors.Is(err, blobstore.ErrNotFound) {
+			response.Deleted = append(response.Deleted, &pb.SyncResponse_DeletedItem{
+				ItemType: pb.ItemType_ITEM_TYPE_FILE,
+				Id:       file.Name,
+			})
+			continue
+		}
+		if err != nil {
+			return response, status.Errorf(codes.Internal, "changed files can not be retrieved")
+		}
+		response.Files = append(response.Files, fileMessage(file.Name, object))
+	}
 	return response, nil
 }
 
-// SubscribeToChanges - stream changes to clients
+// itemTypeMessage - item type of vault item as it is sent to client
+func itemTypeMessage(itemType string) pb.ItemType {
+	if itemType == models.FileItem {
+		return pb.ItemType_ITEM_TYPE_FILE
+	}
+	for message, item := range historyItems {
+		if item == itemType {
+			return message
+		}
+	}
+	return pb.ItemType_ITEM_TYPE_UNSPECIFIED
+}
+
+// SubscribeToChanges - stream changes to clients until client disconnects or its session is revoked
 func (s *GrpcServer) SubscribeToChanges(in *pb.SubscribeToChangesRequest, stream pb.GophKeeperService_SubscribeToChangesServer) error {
 	ctx, cancel := context.WithCancel(stream.Context())
 	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
@@ -248,124 +879,612 @@ func (s *GrpcServer) SubscribeToChanges(in *pb.SubscribeToChangesRequest, stream
 	if len(s.syncClients[userID]) == 0 {
 		s.syncClients[userID] = make(map[string]pb.GophKeeperService_SubscribeToChangesServer)
 	}
+	if s.subscriptions == nil {
+		s.subscriptions = make(map[string]context.CancelFunc)
+	}
 	s.syncClients[userID][sessionID] = stream
+	s.subscriptions[sessionID] = cancel
 	s.rwMutex.Unlock()
-	for {
-		time.Sleep(time.Minute)
+
+	<-ctx.Done()
+
+	s.rwMutex.Lock()
+	if s.syncClients[userID][sessionID] == stream {
+		delete(s.syncClients[userID], sessionID)
+		delete(s.subscriptions, sessionID)
 	}
+	s.rwMutex.Unlock()
+
+	if stream.Context().Err() == nil {
+		return status.Errorf(codes.Unauthenticated, "session is revoked")
+	}
+	return nil
 }
 
-// SendNotifications - stream all user session with update
-func (s *GrpcServer) SendNotifications(ctx context.Context, resource int, ID string) {
+// SendNotifications - stream change event to all other sessions of user, event is marked by device which made change
+func (s *GrpcServer) SendNotifications(ctx context.Context, event *pb.SubscribeToChangesResponse) {
 	sessionID, _ := ctx.Value(config.SESSIONIDCONTEXTKEY).(string)
 	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	event.Device = middlewares.RequestSession(ctx).Device
 	s.rwMutex.Lock()
 	for session, client := range s.syncClients[userID] {
 		if session != sessionID {
-			_ = client.Send(&pb.SubscribeToChangesResponse{
-				Source: int32(resource),
-				Id:     ID,
-			})
+			_ = client.Send(event)
 		}
 	}
 	s.rwMutex.Unlock()
 }
 
+// changeEvent - event about created or updated credentials, card or note with item itself
+func changeEvent(operation pb.ChangeOperation, item any) *pb.SubscribeToChangesResponse {
+	event := &pb.SubscribeToChangesResponse{Operation: operation}
+	switch item := item.(type) {
+	case models.Credentials:
+		event.ItemType, event.Id, event.Version = pb.ItemType_ITEM_TYPE_CREDENTIALS, item.ID, item.Version
+		event.Item = &pb.SubscribeToChangesResponse_Credential{Credential: credentialsMessage(item)}
+	case models.Card:
+		event.ItemType, event.Id, event.Version = pb.ItemType_ITEM_TYPE_CARD, item.ID, item.Version
+		event.Item = &pb.SubscribeToChangesResponse_Card{Card: cardMessage(item)}
+	case models.Note:
+		event.ItemType, event.Id, event.Version = pb.ItemType_ITEM_TYPE_NOTE, item.ID, item.Version
+		event.Item = &pb.SubscribeToChangesResponse_Note{Note: noteMessage(item)}
+	}
+	return event
+}
+
+// tombstone - event about deleted item
+func tombstone(itemType pb.ItemType, id string, version int64) *pb.SubscribeToChangesResponse {
+	return &pb.SubscribeToChangesResponse{
+		Id:        id,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_DELETE,
+		ItemType:  itemType,
+		Version:   version,
+	}
+}
+
+// UploadFile - handler for streamed file upload, data is committed to storage by parts and interrupted upload
+// is continued in the same session from offset reported by ResumeUpload
 func (s *GrpcServer) UploadFile(stream pb.GophKeeperService_UploadFileServer) (err error) {
-	var objectName string
-	var fileData []byte
-	userID, _ := stream.Context().Value(config.USERIDCONTEXTKEY).(string)
+	ctx := stream.Context()
+	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	in, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty upload")
+	}
+	if err != nil {
+		return
+	}
+
+	session, err := s.openUpload(ctx, userID, in)
+	if err != nil {
+		return
+	}
+	defer session.mu.Unlock()
 
+	err = stream.Send(&pb.UploadFileResponse{UploadId: session.id, Offset: session.committed.Load()})
+	if err != nil {
+		return
+	}
+
+	var digest string
 	for {
-		var in *pb.UploadFileRequest
+		if err = session.write(ctx, s.blobs, in); err != nil {
+			return
+		}
+		if in.Digest != "" {
+			digest = in.Digest
+		}
+
 		in, err = stream.Recv()
 		if err == io.EOF {
-			reader := io.NopCloser(bytes.NewReader(fileData))
-			_, err = s.minioClient.PutObject(context.Background(), userID, objectName, reader, int64(len(fileData)), minio.PutObjectOptions{})
-			if err != nil {
-				return
-			}
-			s.SendNotifications(stream.Context(), 2, objectName)
-			return stream.Send(&pb.UploadFileResponse{
-				Message: "File uploaded successfully",
-				Success: true,
-			})
+			break
 		}
 		if err != nil {
 			return
 		}
+	}
 
-		fileData = append(fileData, in.Data...)
-		objectName = in.Filename
+	sum, err := session.complete(ctx, s.blobs, digest)
+	if status.Code(err) == codes.DataLoss {
+		s.closeUpload(ctx, session, true)
 	}
+	if err != nil {
+		return
+	}
+	s.closeUpload(ctx, session, false)
+
+	s.fileChanged(ctx, uploadedFile(session))
+	return stream.Send(&pb.UploadFileResponse{
+		Message:  "File uploaded successfully",
+		Success:  true,
+		UploadId: session.id,
+		Offset:   session.committed.Load(),
+		Digest:   sum,
+	})
+}
+
+// uploadedFile - change event of file created by completed upload
+func uploadedFile(session *uploadSession) *pb.SubscribeToChangesResponse {
+	return &pb.SubscribeToChangesResponse{
+		Id:        session.objectName,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_CREATE,
+		ItemType:  pb.ItemType_ITEM_TYPE_FILE,
+		Item: &pb.SubscribeToChangesResponse_File{File: &pb.GetFilesResponse_File{
+			Name:          session.objectName,
+			Size:          utils.HumanReadableSize(uint64(session.committed.Load())),
+			UploadedAt:    time.Now().Format(time.RFC3339),
+			Metadata:      session.metadata,
+			FileKey:       session.fileKey,
+			EncryptedName: session.encryptedName,
+		}},
+	}
+}
+
+// ResumeUpload - handler reporting committed offset of interrupted upload
+func (s *GrpcServer) ResumeUpload(ctx context.Context, in *pb.ResumeUploadRequest) (*pb.ResumeUploadResponse, error) {
+	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	s.uploadsMutex.Lock()
+	session, ok := s.uploads[in.UploadId]
+	s.uploadsMutex.Unlock()
+	if !ok || session.userID != userID {
+		return nil, status.Error(codes.NotFound, "upload session not found")
+	}
+
+	return &pb.ResumeUploadResponse{
+		UploadId: session.id,
+		Filename: session.objectName,
+		Offset:   session.committed.Load(),
+	}, nil
 }
 
 func (s *GrpcServer) GetFiles(ctx context.Context, in *pb.GetFilesRequest) (*pb.GetFilesResponse, error) {
-	response := &pb.GetFilesResponse{}
+	return &pb.GetFilesResponse{Files: s.listFiles(ctx)}, nil
+}
+
+// listFiles - user's files which are not in trash
+func (s *GrpcServer) listFiles(ctx context.Context) (files []*pb.GetFilesResponse_File) {
+	objects, err := s.blobs.ListObjects(ctx, ctx.Value(config.USERIDCONTEXTKEY).(string), "")
+	if err != nil {
+		logger.Log().Error("files can not be listed", zap.Error(err))
+	}
+	for _, object := range objects {
+		if strings.HasPrefix(object.Key, trashPrefix) {
+			continue
+		}
+		files = append(files, fileMessage(object.Key, object))
+	}
+	return
+}
+
+// fileChanged - log change of user's file for delta sync and notify other sessions of user
+func (s *GrpcServer) fileChanged(ctx context.Context, event *pb.SubscribeToChangesResponse) {
+	if err := s.storage.RecordChange(ctx, models.FileItem, event.Id); err != nil {
+		logger.Log().Error("file change can not be recorded", zap.String("file", event.Id), zap.Error(err))
+	}
+	s.SendNotifications(ctx, event)
+}
+
+func fileMessage(name string, object blobstore.ObjectInfo) *pb.GetFilesResponse_File {
+	fileKey, _ := lookupObjectMetadata(object.UserMetadata, objectFileKeyKey)
+	encryptedName, _ := lookupObjectMetadata(object.UserMetadata, objectNameKey)
+	return &pb.GetFilesResponse_File{
+		Name:          name,
+		Size:          utils.HumanReadableSize(uint64(object.Size)),
+		UploadedAt:    object.LastModified.Format(time.RFC3339),
+		Metadata:      decodeObjectMetadata(object.UserMetadata),
+		FileKey:       fileKey,
+		EncryptedName: encryptedName,
+	}
+}
 
-	objectCh := s.minioClient.ListObjects(ctx, ctx.Value(config.USERIDCONTEXTKEY).(string), minio.ListObjectsOptions{})
-	for object := range objectCh {
-		response.Files = append(response.Files, &pb.GetFilesResponse_File{
-			Name:       object.Key,
+// DeleteFile - handler for moving user's file to trash
+func (s *GrpcServer) DeleteFile(ctx context.Context, in *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	response := &pb.DeleteFileResponse{}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	info, err := s.blobs.StatObject(ctx, userID, in.Name)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return response, status.Errorf(codes.NotFound, "file not found")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "file can not be deleted")
+	}
+	trashID := in.Name + "." + strconv.FormatInt(time.Now().UnixNano(), 10)
+	userMetadata := withObjectMetadata(withoutObjectMetadata(info.UserMetadata, objectTrashedNameKey),
+		objectTrashedNameKey, base64.StdEncoding.EncodeToString([]byte(in.Name)))
+	if err = s.moveObject(ctx, userID, in.Name, trashPrefix+trashID, userMetadata); err != nil {
+		return response, status.Errorf(codes.Internal, "file can not be deleted")
+	}
+	s.fileChanged(ctx, tombstone(pb.ItemType_ITEM_TYPE_FILE, in.Name, 0))
+	return response, nil
+}
+
+// trashedName - name deleted file is restored to, files deleted before trash IDs were used are kept under their name
+func trashedName(trashID string, userMetadata map[string]string) string {
+	value, ok := lookupObjectMetadata(userMetadata, objectTrashedNameKey)
+	if !ok {
+		return trashID
+	}
+	name, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return trashID
+	}
+	return string(name)
+}
+
+// moveObject - copy object to new name with given user metadata and remove original one
+func (s *GrpcServer) moveObject(ctx context.Context, bucket, from, to string, userMetadata map[string]string) error {
+	if err := s.blobs.CopyObject(ctx, bucket, from, to); err != nil {
+		return err
+	}
+	if _, err := s.blobs.ReplaceMetadata(ctx, bucket, to, userMetadata); err != nil {
+		_ = s.blobs.RemoveObject(ctx, bucket, to)
+		return err
+	}
+	return s.blobs.RemoveObject(ctx, bucket, from)
+}
+
+// UpdateFileMetadata - handler for replacing metadata of user's file, object is copied onto itself with new metadata
+func (s *GrpcServer) UpdateFileMetadata(ctx context.Context, in *pb.UpdateFileMetadataRequest) (*pb.UpdateFileMetadataResponse, error) {
+	response := &pb.UpdateFileMetadataResponse{}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	info, err := s.blobs.StatObject(ctx, userID, in.Name)
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "file metadata can not be updated")
+	}
+	userMetadata := encodeObjectMetadata(in.Metadata)
+	for _, key := range preservedObjectMetadata {
+		value, _ := lookupObjectMetadata(info.UserMetadata, key)
+		userMetadata = withObjectMetadata(userMetadata, key, value)
+	}
+
+	object, err := s.blobs.ReplaceMetadata(ctx, userID, in.Name, userMetadata)
+	if err != nil {
+		return response, status.Errorf(codes.InvalidArgument, "file metadata can not be updated")
+	}
+	s.fileChanged(ctx, &pb.SubscribeToChangesResponse{
+		Id:        in.Name,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_UPDATE,
+		ItemType:  pb.ItemType_ITEM_TYPE_FILE,
+		Item: &pb.SubscribeToChangesResponse_File{File: &pb.GetFilesResponse_File{
+			Name:       in.Name,
 			Size:       utils.HumanReadableSize(uint64(object.Size)),
 			UploadedAt: object.LastModified.Format(time.RFC3339),
+			Metadata:   in.Metadata,
+		}},
+	})
+	return response, nil
+}
+
+// ListTrash - handler for listing user's deleted credentials, cards, notes and files
+func (s *GrpcServer) ListTrash(ctx context.Context, in *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	response := &pb.ListTrashResponse{}
+
+	items, err := s.storage.ListTrash(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "trash can not be retrieved")
+	}
+	for _, item := range items {
+		message := &pb.TrashItem{DeletedAt: item.DeletedAt.Format(time.RFC3339)}
+		switch trashed := item.Item.(type) {
+		case models.Credentials:
+			message.ItemType = pb.ItemType_ITEM_TYPE_CREDENTIALS
+			message.Item = &pb.TrashItem_Credential{Credential: credentialsMessage(trashed)}
+		case models.Card:
+			message.ItemType = pb.ItemType_ITEM_TYPE_CARD
+			message.Item = &pb.TrashItem_Card{Card: cardMessage(trashed)}
+		case models.Note:
+			message.ItemType = pb.ItemType_ITEM_TYPE_NOTE
+			message.Item = &pb.TrashItem_Note{Note: noteMessage(trashed)}
+		}
+		response.Items = append(response.Items, message)
+	}
+
+	objects, err := s.blobs.ListObjects(ctx, ctx.Value(config.USERIDCONTEXTKEY).(string), trashPrefix)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "deleted files can not be retrieved")
+	}
+	for _, object := range objects {
+		trashID := strings.TrimPrefix(object.Key, trashPrefix)
+		file := fileMessage(trashedName(trashID, object.UserMetadata), object)
+		response.Items = append(response.Items, &pb.TrashItem{
+			Id:        trashID,
+			ItemType:  pb.ItemType_ITEM_TYPE_FILE,
+			DeletedAt: file.UploadedAt,
+			Item:      &pb.TrashItem_File{File: file},
 		})
 	}
 	return response, nil
 }
 
-func (s *GrpcServer) DeleteFile(ctx context.Context, in *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
-	response := &pb.DeleteFileResponse{}
+// RestoreFromTrash - handler for returning user's deleted item back
+func (s *GrpcServer) RestoreFromTrash(ctx context.Context, in *pb.RestoreFromTrashRequest) (*pb.RestoreFromTrashResponse, error) {
+	response := &pb.RestoreFromTrashResponse{}
+	if in.ItemType == pb.ItemType_ITEM_TYPE_FILE {
+		return response, s.restoreFile(ctx, in.Id)
+	}
+	itemType, ok := historyItems[in.ItemType]
+	if !ok {
+		return response, status.Errorf(codes.InvalidArgument, "item type has no trash")
+	}
 
-	err := s.minioClient.RemoveObject(ctx, ctx.Value(config.USERIDCONTEXTKEY).(string), in.Name, minio.RemoveObjectOptions{})
-	s.SendNotifications(ctx, 2, in.Name)
-	return response, err
+	err := s.storage.RestoreFromTrash(ctx, itemType, in.Id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return response, status.Errorf(codes.NotFound, "item is not in trash")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "item can not be restored")
+	}
+	s.SendNotifications(ctx, &pb.SubscribeToChangesResponse{
+		Id:        in.Id,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_CREATE,
+		ItemType:  in.ItemType,
+	})
+	return response, nil
 }
 
-func (s *GrpcServer) DownloadFile(in *pb.DownloadFileRequest, stream pb.GophKeeperService_DownloadFileServer) error {
-	object, err := s.minioClient.GetObject(
-		context.Background(), stream.Context().Value(config.USERIDCONTEXTKEY).(string),
-		in.Name, minio.GetObjectOptions{},
-	)
+// restoreFile - move deleted file back to its name, file uploaded with the same name after deletion is not overwritten
+func (s *GrpcServer) restoreFile(ctx context.Context, trashID string) error {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	info, err := s.blobs.StatObject(ctx, userID, trashPrefix+trashID)
 	if err != nil {
-		return status.Errorf(codes.Internal, "failed to open object: %v", err)
+		return status.Errorf(codes.NotFound, "file is not in trash")
 	}
-	defer object.Close()
+	name := trashedName(trashID, info.UserMetadata)
+	if _, err = s.blobs.StatObject(ctx, userID, name); err == nil {
+		return status.Errorf(codes.AlreadyExists, "file with the same name already exists")
+	}
+	userMetadata := withoutObjectMetadata(info.UserMetadata, objectTrashedNameKey)
+	if err = s.moveObject(ctx, userID, trashPrefix+trashID, name, userMetadata); err != nil {
+		return status.Errorf(codes.Internal, "file can not be restored")
+	}
+	s.fileChanged(ctx, &pb.SubscribeToChangesResponse{
+		Id:        name,
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_CREATE,
+		ItemType:  pb.ItemType_ITEM_TYPE_FILE,
+	})
+	return nil
+}
 
-	buffer := make([]byte, 1024)
-	for {
-		n, err := object.Read(buffer)
-		if err == io.EOF {
-			break
+// EmptyTrash - handler for permanent deletion of all user's items from trash
+func (s *GrpcServer) EmptyTrash(ctx context.Context, in *pb.EmptyTrashRequest) (*pb.EmptyTrashResponse, error) {
+	response := &pb.EmptyTrashResponse{}
+
+	if err := s.storage.EmptyTrash(ctx); err != nil {
+		return response, status.Errorf(codes.Internal, "trash can not be emptied")
+	}
+	if _, err := s.purgeFiles(ctx, ctx.Value(config.USERIDCONTEXTKEY).(string), time.Now()); err != nil {
+		return response, status.Errorf(codes.Internal, "deleted files can not be removed")
+	}
+	return response, nil
+}
+
+// purgeFiles - permanently remove files of bucket which were moved to trash before given time
+func (s *GrpcServer) purgeFiles(ctx context.Context, bucket string, before time.Time) (purged int, err error) {
+	objects, err := s.blobs.ListObjects(ctx, bucket, trashPrefix)
+	if err != nil {
+		return
+	}
+	for _, object := range objects {
+		if !object.LastModified.Before(before) {
+			continue
+		}
+		if err = s.blobs.RemoveObject(ctx, bucket, object.Key); err != nil {
+			return
+		}
+		purged++
+	}
+	return
+}
+
+// PurgeTrash - permanently delete items and files of all users which are kept in trash longer than retention
+func (s *GrpcServer) PurgeTrash(ctx context.Context, retention time.Duration) (purged int, err error) {
+	before := time.Now().Add(-retention)
+	purged, err = s.storage.PurgeTrash(ctx, before)
+	if err != nil {
+		return
+	}
+
+	buckets, err := s.blobs.ListBuckets(ctx)
+	if err != nil {
+		return
+	}
+	for _, bucket := range buckets {
+		var files int
+		files, err = s.purgeFiles(ctx, bucket, before)
+		purged += files
+		if err != nil {
+			return
 		}
+	}
+	return
+}
+
+// RunTrashPurger - purge trash every interval until context is done
+func (s *GrpcServer) RunTrashPurger(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := s.PurgeTrash(ctx, retention)
 		if err != nil {
-			return status.Errorf(codes.Internal, "block can not be read: %v", err)
+			logger.Log().Error("trash can not be purged", zap.Error(err))
+		} else if purged > 0 {
+			logger.Log().Info("trash is purged", zap.Int("count", purged))
 		}
 
-		if err := stream.Send(&pb.DownloadFileResponse{Data: buffer[:n]}); err != nil {
-			return status.Errorf(codes.Internal, "failed to send chunk: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
 	}
+}
 
-	return nil
+// encodeObjectMetadata - pack item metadata into single object user metadata entry,
+// as S3 headers are case-insensitive and restricted to ASCII
+func encodeObjectMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{objectMetadataKey: base64.StdEncoding.EncodeToString(encoded)}
+}
+
+// decodeObjectMetadata - unpack item metadata from object user metadata
+func decodeObjectMetadata(userMetadata map[string]string) map[string]string {
+	value, ok := lookupObjectMetadata(userMetadata, objectMetadataKey)
+	if !ok {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	var metadata map[string]string
+	if json.Unmarshal(decoded, &metadata) != nil {
+		return nil
+	}
+	return metadata
+}
+
+// withObjectMetadata - add entry to object user metadata, empty values are skipped
+func withObjectMetadata(userMetadata map[string]string, key, value string) map[string]string {
+	if value == "" {
+		return userMetadata
+	}
+	if userMetadata == nil {
+		userMetadata = make(map[string]string)
+	}
+	userMetadata[key] = value
+	return userMetadata
+}
+
+// withoutObjectMetadata - copy of object user metadata without entry, stores return keys without header prefix
+func withoutObjectMetadata(userMetadata map[string]string, name string) map[string]string {
+	var result map[string]string
+	for key, value := range userMetadata {
+		if !strings.EqualFold(key, name) {
+			result = withObjectMetadata(result, key, value)
+		}
+	}
+	return result
+}
+
+// objectDigest - file digest from object user metadata, empty for files uploaded without it
+func objectDigest(userMetadata map[string]string) string {
+	digest, _ := lookupObjectMetadata(userMetadata, objectDigestKey)
+	return digest
+}
+
+// lookupObjectMetadata - value of object user metadata entry, listing may return keys with header prefix
+func lookupObjectMetadata(userMetadata map[string]string, name string) (string, bool) {
+	for key, value := range userMetadata {
+		if len(key) >= len(userMetadataPrefix) && strings.EqualFold(key[:len(userMetadataPrefix)], userMetadataPrefix) {
+			key = key[len(userMetadataPrefix):]
+		}
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
 }
 
-// NewGrpcServer - creates new gRPC server instance
-func NewGrpcServer(config *config.ServerConfig, storage storage.Repository) *GrpcServer {
-	minioClient, err := minio.New(config.MinIOAddress, &minio.Options{
-		Creds:  credentials.NewStaticV4(config.MinIOLogin, config.MinIOPassword, ""),
-		Secure: false,
+// DownloadFile - handler for streamed download of user's file or its range, first message reports size and digest
+// of whole file so client can resume download and verify result
+func (s *GrpcServer) DownloadFile(in *pb.DownloadFileRequest, stream pb.GophKeeperService_DownloadFileServer) error {
+	ctx := stream.Context()
+	userID, _ := ctx.Value(config.USERIDCONTEXTKEY).(string)
+
+	info, err := s.blobs.StatObject(ctx, userID, in.Name)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "file not found")
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to open object: %v", err)
+	}
+	if in.Offset < 0 || in.Length < 0 || in.Offset > info.Size {
+		return status.Errorf(codes.OutOfRange, "range %d+%d is out of file of size %d", in.Offset, in.Length, info.Size)
+	}
+	length := info.Size - in.Offset
+	if in.Length > 0 {
+		length = min(length, in.Length)
+	}
+
+	digest := objectDigest(info.UserMetadata)
+	if digest == "" {
+		if digest, err = s.hashObject(ctx, userID, in.Name); err != nil {
+			return status.Errorf(codes.Internal, "failed to hash object: %v", err)
+		}
+	}
+	fileKey, _ := lookupObjectMetadata(info.UserMetadata, objectFileKeyKey)
+	encryptedName, _ := lookupObjectMetadata(info.UserMetadata, objectNameKey)
+	err = stream.Send(&pb.DownloadFileResponse{
+		Size:          info.Size,
+		Digest:        digest,
+		Offset:        in.Offset,
+		FileKey:       fileKey,
+		EncryptedName: encryptedName,
 	})
+	if err != nil || length == 0 {
+		return err
+	}
+
+	object, err := s.blobs.GetObject(ctx, userID, in.Name, in.Offset, length)
 	if err != nil {
-		log.Fatalln(err)
+		return status.Errorf(codes.Internal, "failed to open object: %v", err)
 	}
+	defer object.Close()
+
+	buffer := make([]byte, downloadChunkSize)
+	for offset := in.Offset; ; {
+		n, err := io.ReadFull(object, buffer)
+		if n > 0 {
+			if err := stream.Send(&pb.DownloadFileResponse{Data: buffer[:n], Offset: offset}); err != nil {
+				return status.Errorf(codes.Internal, "failed to send chunk: %v", err)
+			}
+			offset += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "block can not be read: %v", err)
+		}
+	}
+}
+
+// hashObject - SHA-256 digest of object which was uploaded without it
+func (s *GrpcServer) hashObject(ctx context.Context, bucket, key string) (string, error) {
+	object, err := s.blobs.GetObject(ctx, bucket, key, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	defer object.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, object); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
 
+// NewGrpcServer - creates new gRPC server instance, users' files are kept in blobs
+func NewGrpcServer(config *config.ServerConfig, storage storage.Repository, blobs blobstore.BlobStore) *GrpcServer {
 	s := GrpcServer{
-		config:      config,
-		storage:     storage,
-		minioClient: minioClient,
-		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
-		rwMutex:     &sync.RWMutex{},
+		config:        config,
+		storage:       storage,
+		blobs:         blobs,
+		uploads:       make(map[string]*uploadSession),
+		uploadsMutex:  &sync.Mutex{},
+		syncClients:   make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		subscriptions: make(map[string]context.CancelFunc),
+		rwMutex:       &sync.RWMutex{},
 	}
 	return &s
 }
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"io"
 	"reflect"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/blobstore"
 	"github.com/PaBah/GophKeeper/internal/config"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/mock"
@@ -15,6 +22,9 @@ import (
 	"github.com/PaBah/GophKeeper/internal/storage"
 	"github.com/PaBah/GophKeeper/internal/utils"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestNewGrpcServer(t *testing.T) {
@@ -36,7 +46,7 @@ func TestNewGrpcServer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			grpc := NewGrpcServer(tt.config, tt.storage)
+			grpc := NewGrpcServer(tt.config, tt.storage, newTestBlobStore(t))
 			if (grpc == nil) != tt.wantErr {
 				t.Errorf("NewGrpcServer() not exists, wantErr %v", tt.wantErr)
 			}
@@ -44,6 +54,67 @@ func TestNewGrpcServer(t *testing.T) {
 	}
 }
 
+func TestGrpcServer_MemoryStorage(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, store, newTestBlobStore(t))
+	ctx := context.Background()
+
+	if _, err := srv.SignUp(ctx, &pb.SignUpRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	if _, err := srv.SignUp(ctx, &pb.SignUpRequest{Email: "demo@example.com", Password: "password"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SignUp() of existing user error = %v, want %v", err, codes.InvalidArgument)
+	}
+	if _, err := srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "wrong"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("SignIn() with wrong password error = %v, want %v", err, codes.Unauthenticated)
+	}
+	signedIn, err := srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+
+	refreshed, err := srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: signedIn.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	if auth.GetSessionID(refreshed.Token, "testing secret") != auth.GetSessionID(signedIn.Token, "testing secret") {
+		t.Errorf("RefreshToken() must keep session of refreshed token")
+	}
+	if _, err = srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: signedIn.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("RefreshToken() with reused token error = %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err = srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("RefreshToken() of session revoked on reuse error = %v, want %v", err, codes.Unauthenticated)
+	}
+
+	user, err := store.AuthorizeUser(ctx, "demo@example.com")
+	if err != nil {
+		t.Fatalf("AuthorizeUser() error = %v", err)
+	}
+	ctx = context.WithValue(ctx, config.USERIDCONTEXTKEY, user.ID)
+
+	created, err := srv.CreateCredentials(ctx, &pb.CreateCredentialsRequest{ServiceName: "aws", Identity: "identity", Password: "password"})
+	if err != nil {
+		t.Fatalf("CreateCredentials() error = %v", err)
+	}
+	updated, err := srv.UpdateCredentials(ctx, &pb.UpdateCredentialsRequest{Id: created.Id, ServiceName: "aws", Identity: "identity", Password: "changed", Totp: "JBSWY3DPEHPK3PXP", Version: created.Version})
+	if err != nil {
+		t.Fatalf("UpdateCredentials() error = %v", err)
+	}
+	_, err = srv.UpdateCredentials(ctx, &pb.UpdateCredentialsRequest{Id: created.Id, ServiceName: "aws", Identity: "identity", Password: "stale", Version: created.Version})
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("UpdateCredentials() of stale version error = %v, want %v", err, codes.Aborted)
+	}
+
+	got, err := srv.GetCredentials(ctx, &pb.GetCredentialsRequest{})
+	if err != nil {
+		t.Fatalf("GetCredentials() error = %v", err)
+	}
+	if len(got.Credentials) != 1 || got.Credentials[0].Password != "changed" || got.Credentials[0].Totp != "JBSWY3DPEHPK3PXP" || got.Credentials[0].Version != updated.Version {
+		t.Errorf("GetCredentials() = %v, want updated credentials of version %d", got.Credentials, updated.Version)
+	}
+}
+
 func TestSignIn(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -68,7 +139,47 @@ func TestSignIn(t *testing.T) {
 			mock: func() {
 				password := utils.PasswordHash("password")
 				user := &models.User{ID: "user1", Email: "email@example.com", Password: password}
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").Return(models.SignInAttempts{}, nil)
+				repo.EXPECT().AuthorizeUser(gomock.Any(), "email@example.com").Return(*user, nil)
+				repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{}, nil)
+				repo.EXPECT().ResetSignInAttempts(gomock.Any(), "account:email@example.com").Return(nil)
+				repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, session models.Session, tokenHash string) error {
+						if session.UserID != "user1" || session.ID == "" || tokenHash == "" {
+							t.Errorf("CreateSession() session = %v, token hash = %q", session, tokenHash)
+						}
+						return nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name:    "SessionNotSaved",
+			request: &pb.SignInRequest{Email: "email@example.com", Password: "password"},
+			mock: func() {
+				password := utils.PasswordHash("password")
+				user := &models.User{ID: "user1", Email: "email@example.com", Password: password}
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").Return(models.SignInAttempts{}, nil)
+				repo.EXPECT().AuthorizeUser(gomock.Any(), "email@example.com").Return(*user, nil)
+				repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{}, nil)
+				repo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("db is down"))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "TOTPRequired",
+			request: &pb.SignInRequest{Email: "email@example.com", Password: "password"},
+			mock: func() {
+				password := utils.PasswordHash("password")
+				user := &models.User{ID: "user1", Email: "email@example.com", Password: password}
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").Return(models.SignInAttempts{}, nil)
 				repo.EXPECT().AuthorizeUser(gomock.Any(), "email@example.com").Return(*user, nil)
+				repo.EXPECT().GetTOTP(gomock.Any()).DoAndReturn(func(ctx context.Context) (models.TOTP, error) {
+					if ctx.Value(config.USERIDCONTEXTKEY) != "user1" {
+						t.Errorf("GetTOTP() user = %v, want user1", ctx.Value(config.USERIDCONTEXTKEY))
+					}
+					return models.TOTP{Secret: "secret", Enabled: true}, nil
+				})
 			},
 			wantErr: false,
 		},
@@ -76,7 +187,10 @@ func TestSignIn(t *testing.T) {
 			name:    "UnknownEmail",
 			request: &pb.SignInRequest{Email: "unknown@example.com", Password: "password"},
 			mock: func() {
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:unknown@example.com").Return(models.SignInAttempts{}, nil)
 				repo.EXPECT().AuthorizeUser(gomock.Any(), "unknown@example.com").Return(models.User{}, errors.New("user not found"))
+				repo.EXPECT().RecordSignInFailure(gomock.Any(), "account:unknown@example.com", gomock.Any(), gomock.Any()).
+					Return(models.SignInAttempts{Key: "account:unknown@example.com", Failures: 1}, nil)
 			},
 			wantErr: true,
 		},
@@ -86,7 +200,28 @@ func TestSignIn(t *testing.T) {
 			mock: func() {
 				password := utils.PasswordHash("password")
 				user := &models.User{ID: "user1", Email: "email@example.com", Password: password}
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").Return(models.SignInAttempts{}, nil)
 				repo.EXPECT().AuthorizeUser(gomock.Any(), "email@example.com").Return(*user, nil)
+				repo.EXPECT().RecordSignInFailure(gomock.Any(), "account:email@example.com", gomock.Any(), gomock.Any()).
+					Return(models.SignInAttempts{Key: "account:email@example.com", Failures: 2}, nil)
+				repo.EXPECT().LockSignIn(gomock.Any(), "account:email@example.com", gomock.Any()).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Locked",
+			request: &pb.SignInRequest{Email: "email@example.com", Password: "password"},
+			mock: func() {
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").
+					Return(models.SignInAttempts{Failures: 5, LockedUntil: time.Now().Add(time.Minute)}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "AttemptsNotChecked",
+			request: &pb.SignInRequest{Email: "email@example.com", Password: "password"},
+			mock: func() {
+				repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:email@example.com").Return(models.SignInAttempts{}, errors.New("db is down"))
 			},
 			wantErr: true,
 		},
@@ -103,6 +238,153 @@ func TestSignIn(t *testing.T) {
 	}
 }
 
+func TestRefreshToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, repo, nil)
+
+	tests := []struct {
+		name     string
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Successful",
+			mock: func() {
+				repo.EXPECT().RefreshSession(gomock.Any(), auth.HashRefreshToken("refresh"), gomock.Any(), gomock.Any()).
+					Return(models.Session{ID: "session1", UserID: "user1"}, nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "Revoked",
+			mock: func() {
+				repo.EXPECT().RefreshSession(gomock.Any(), auth.HashRefreshToken("refresh"), gomock.Any(), gomock.Any()).
+					Return(models.Session{}, storage.ErrNotFound)
+			},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name: "StorageError",
+			mock: func() {
+				repo.EXPECT().RefreshSession(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.Session{}, errors.New("db is down"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			response, err := srv.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "refresh"})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("RefreshToken() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if auth.GetUserID(response.Token, "testing secret") != "user1" || auth.GetSessionID(response.Token, "testing secret") != "session1" {
+				t.Errorf("RefreshToken() token is not issued for session of user")
+			}
+			if response.RefreshToken == "" || response.RefreshToken == "refresh" {
+				t.Errorf("RefreshToken() refresh token = %q, want new one", response.RefreshToken)
+			}
+			if _, err := time.Parse(time.RFC3339, response.ExpiresAt); err != nil {
+				t.Errorf("RefreshToken() expires at = %q, want RFC3339 time", response.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, repo, nil)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user1")
+	ctx = context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, "laptop-session")
+
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	repo.EXPECT().ListSessions(gomock.Any()).Return([]models.Session{
+		{ID: "laptop-session", Device: "laptop", ClientVersion: "v1.2.0", IP: "10.0.0.1", LastSeenAt: seen},
+		{ID: "phone-session", Device: "phone"},
+	}, nil)
+	response, err := srv.ListSessions(ctx, &pb.ListSessionsRequest{})
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(response.Sessions) != 2 {
+		t.Fatalf("ListSessions() sessions = %v, want 2", response.Sessions)
+	}
+	laptop, phone := response.Sessions[0], response.Sessions[1]
+	if !laptop.Current || phone.Current {
+		t.Errorf("ListSessions() current = %v, %v, want only session of request", laptop.Current, phone.Current)
+	}
+	if laptop.Device != "laptop" || laptop.ClientVersion != "v1.2.0" || laptop.Ip != "10.0.0.1" || laptop.LastSeenAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("ListSessions() session = %v", laptop)
+	}
+
+	repo.EXPECT().ListSessions(gomock.Any()).Return(nil, errors.New("db is down"))
+	if _, err = srv.ListSessions(ctx, &pb.ListSessionsRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("ListSessions() error = %v, want Internal", err)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, repo, nil)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user1")
+
+	phoneCtx := context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, "phone-session")
+	phone := mock.NewMockGophKeeperService_SubscribeToChangesServer(ctrl)
+	phone.EXPECT().Context().Return(phoneCtx).AnyTimes()
+	subscribed := make(chan error)
+	go func() {
+		subscribed <- srv.SubscribeToChanges(&pb.SubscribeToChangesRequest{}, phone)
+	}()
+	for {
+		srv.rwMutex.RLock()
+		_, ok := srv.subscriptions["phone-session"]
+		srv.rwMutex.RUnlock()
+		if ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	repo.EXPECT().RevokeSession(gomock.Any(), "phone-session").Return(nil)
+	if _, err := srv.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: "phone-session"}); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	select {
+	case err := <-subscribed:
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("SubscribeToChanges() error = %v, want Unauthenticated", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("SubscribeToChanges() stream of revoked session is not closed")
+	}
+	if len(srv.syncClients["user1"]) != 0 {
+		t.Errorf("SubscribeToChanges() revoked session still receives changes")
+	}
+
+	repo.EXPECT().RevokeSession(gomock.Any(), "phone-session").Return(storage.ErrNotFound)
+	if _, err := srv.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: "phone-session"}); status.Code(err) != codes.NotFound {
+		t.Errorf("RevokeSession() error = %v, want NotFound", err)
+	}
+	repo.EXPECT().RevokeSession(gomock.Any(), "phone-session").Return(errors.New("db is down"))
+	if _, err := srv.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: "phone-session"}); status.Code(err) != codes.Internal {
+		t.Errorf("RevokeSession() error = %v, want Internal", err)
+	}
+}
+
 func TestCreateCredentials(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -325,7 +607,7 @@ func TestDeleteCredentials(t *testing.T) {
 			name:    "SuccessfulDelete",
 			request: &pb.DeleteCredentialsRequest{Id: "1"},
 			mock: func() {
-				repo.EXPECT().DeleteCredentials(gomock.Any(), "1").Return(nil)
+				repo.EXPECT().DeleteCredentials(gomock.Any(), "1", int64(0)).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -333,7 +615,7 @@ func TestDeleteCredentials(t *testing.T) {
 			name:    "NonExistentId",
 			request: &pb.DeleteCredentialsRequest{Id: "2"},
 			mock: func() {
-				repo.EXPECT().DeleteCredentials(gomock.Any(), "2").Return(errors.New("credentials not found"))
+				repo.EXPECT().DeleteCredentials(gomock.Any(), "2", int64(0)).Return(errors.New("credentials not found"))
 			},
 			wantErr: true,
 		},
@@ -607,7 +889,7 @@ func TestDeleteCards(t *testing.T) {
 			name:    "SuccessfulDelete",
 			request: &pb.DeleteCardRequest{Id: "1"},
 			mock: func() {
-				repo.EXPECT().DeleteCard(gomock.Any(), "1").Return(nil)
+				repo.EXPECT().DeleteCard(gomock.Any(), "1", int64(0)).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -615,7 +897,7 @@ func TestDeleteCards(t *testing.T) {
 			name:    "NonExistentId",
 			request: &pb.DeleteCardRequest{Id: "2"},
 			mock: func() {
-				repo.EXPECT().DeleteCard(gomock.Any(), "2").Return(errors.New("credentials not found"))
+				repo.EXPECT().DeleteCard(gomock.Any(), "2", int64(0)).Return(errors.New("credentials not found"))
 			},
 			wantErr: true,
 		},
@@ -631,3 +913,1110 @@ func TestDeleteCards(t *testing.T) {
 		})
 	}
 }
+
+func TestGetVaultParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	tests := []struct {
+		name        string
+		mock        func()
+		wantErr     bool
+		expectedRes *pb.GetVaultParamsResponse
+	}{
+		{
+			name: "Initialized",
+			mock: func() {
+				repo.EXPECT().GetVaultParams(gomock.Any()).Return(models.VaultParams{Salt: "salt", KeyCheck: "check"}, nil)
+			},
+			wantErr:     false,
+			expectedRes: &pb.GetVaultParamsResponse{Salt: "salt", KeyCheck: "check"},
+		},
+		{
+			name: "MasterPasswordChanged",
+			mock: func() {
+				repo.EXPECT().GetVaultParams(gomock.Any()).
+					Return(models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"}, nil)
+			},
+			wantErr:     false,
+			expectedRes: &pb.GetVaultParamsResponse{Salt: "salt", KeyCheck: "check", WrappedKey: "key"},
+		},
+		{
+			name: "StorageError",
+			mock: func() {
+				repo.EXPECT().GetVaultParams(gomock.Any()).Return(models.VaultParams{}, errors.New("storage error"))
+			},
+			wantErr:     true,
+			expectedRes: &pb.GetVaultParamsResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := srv.GetVaultParams(context.Background(), &pb.GetVaultParamsRequest{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetVaultParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(res, tt.expectedRes) {
+				t.Errorf("GetVaultParams() = %v, want %v", res, tt.expectedRes)
+			}
+		})
+	}
+}
+
+func TestInitVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Initialized",
+			mock: func() {
+				repo.EXPECT().SetVaultParams(gomock.Any(), models.VaultParams{Salt: "salt", KeyCheck: "check"}).Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "AlreadyInitialized",
+			mock: func() {
+				repo.EXPECT().SetVaultParams(gomock.Any(), gomock.Any()).Return(storage.ErrAlreadyExists)
+			},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name: "StorageError",
+			mock: func() {
+				repo.EXPECT().SetVaultParams(gomock.Any(), gomock.Any()).Return(errors.New("storage error"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := srv.InitVault(context.Background(), &pb.InitVaultRequest{Salt: "salt", KeyCheck: "check"})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("InitVault() error = %v, wantCode %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestChangeMasterPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	tests := []struct {
+		name     string
+		mock     func()
+		wantCode codes.Code
+	}{
+		{
+			name: "Changed",
+			mock: func() {
+				repo.EXPECT().ChangeVaultParams(gomock.Any(), "old check",
+					models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"}).Return(nil)
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "VaultChanged",
+			mock: func() {
+				repo.EXPECT().ChangeVaultParams(gomock.Any(), gomock.Any(), gomock.Any()).Return(storage.ErrVaultChanged)
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "StorageError",
+			mock: func() {
+				repo.EXPECT().ChangeVaultParams(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("storage error"))
+			},
+			wantCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := srv.ChangeMasterPassword(context.Background(), &pb.ChangeMasterPasswordRequest{
+				OldKeyCheck: "old check",
+				Salt:        "salt",
+				KeyCheck:    "check",
+				WrappedKey:  "key",
+			})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("ChangeMasterPassword() error = %v, wantCode %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCreateCard_Sealed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	card := models.NewCard("gk1:number", "gk1:date", "gk1:holder", "gk1:cvv")
+	repo.EXPECT().CreateCard(gomock.Any(), card).Return(card, nil)
+
+	resp, err := srv.CreateCard(context.Background(), &pb.CreateCardRequest{
+		Number:         card.Number,
+		ExpirationDate: card.ExpirationDate,
+		HolderName:     card.HolderName,
+		Cvv:            card.CVV,
+	})
+	if err != nil {
+		t.Fatalf("CreateCard() error = %v", err)
+	}
+	if resp.LastDigits != "" {
+		t.Errorf("CreateCard() LastDigits = %v, want empty for sealed card", resp.LastDigits)
+	}
+}
+
+func TestCreateNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	uploadedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		request     *pb.CreateNoteRequest
+		mock        func()
+		wantErr     bool
+		expectedRes *pb.CreateNoteResponse
+	}{
+		{
+			name:    "SuccessfulCreate",
+			request: &pb.CreateNoteRequest{Title: "wifi", Body: "line 1\nline 2"},
+			mock: func() {
+				repo.EXPECT().CreateNote(gomock.Any(), models.NewNote("wifi", "line 1\nline 2")).
+					Return(models.Note{ID: "1", Title: "wifi", Body: "line 1\nline 2", UploadedAt: uploadedAt}, nil)
+			},
+			expectedRes: &pb.CreateNoteResponse{Id: "1", Title: "wifi", UploadedAt: uploadedAt.Format(time.RFC3339)},
+		},
+		{
+			name:    "CreateWithMetadata",
+			request: &pb.CreateNoteRequest{Title: "wifi", Body: "secret", Metadata: map[string]string{"env": "home"}},
+			mock: func() {
+				note := models.NewNote("wifi", "secret")
+				note.Metadata = map[string]string{"env": "home"}
+				repo.EXPECT().CreateNote(gomock.Any(), note).
+					Return(models.Note{ID: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt, Metadata: note.Metadata}, nil)
+			},
+			expectedRes: &pb.CreateNoteResponse{Id: "1", Title: "wifi", UploadedAt: uploadedAt.Format(time.RFC3339),
+				Metadata: map[string]string{"env": "home"}},
+		},
+		{
+			name:    "CreateWithClientID",
+			request: &pb.CreateNoteRequest{Id: "0b9f2a4e-3c1d-4f5e-9a6b-7c8d9e0f1a2b", Title: "wifi", Body: "secret"},
+			mock: func() {
+				note := models.NewNote("wifi", "secret")
+				note.ID = "0b9f2a4e-3c1d-4f5e-9a6b-7c8d9e0f1a2b"
+				repo.EXPECT().CreateNote(gomock.Any(), note).
+					Return(models.Note{ID: note.ID, Title: "wifi", Body: "secret", UploadedAt: uploadedAt}, nil)
+			},
+			expectedRes: &pb.CreateNoteResponse{Id: "0b9f2a4e-3c1d-4f5e-9a6b-7c8d9e0f1a2b", Title: "wifi",
+				UploadedAt: uploadedAt.Format(time.RFC3339)},
+		},
+		{
+			name:        "InvalidID",
+			request:     &pb.CreateNoteRequest{Id: "../1", Title: "wifi"},
+			mock:        func() {},
+			wantErr:     true,
+			expectedRes: &pb.CreateNoteResponse{},
+		},
+		{
+			name:    "StorageError",
+			request: &pb.CreateNoteRequest{Title: "wifi"},
+			mock: func() {
+				repo.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(models.Note{}, errors.New("storage error"))
+			},
+			wantErr:     true,
+			expectedRes: &pb.CreateNoteResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := srv.CreateNote(context.Background(), tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateNote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(res, tt.expectedRes) {
+				t.Errorf("CreateNote() = %v, want %v", res, tt.expectedRes)
+			}
+		})
+	}
+}
+
+func TestGetNotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	uploadedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		mock        func()
+		wantErr     bool
+		expectedRes *pb.GetNotesResponse
+	}{
+		{
+			name: "ValidGet",
+			mock: func() {
+				repo.EXPECT().GetNotes(gomock.Any()).
+					Return([]models.Note{{ID: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt,
+						Metadata: map[string]string{"env": "home"}}}, nil)
+			},
+			expectedRes: &pb.GetNotesResponse{Notes: []*pb.GetNotesResponse_Note{
+				{Id: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt.Format(time.RFC3339),
+					Metadata: map[string]string{"env": "home"}},
+			}},
+		},
+		{
+			name: "StorageError",
+			mock: func() {
+				repo.EXPECT().GetNotes(gomock.Any()).Return(nil, errors.New("storage error"))
+			},
+			wantErr:     true,
+			expectedRes: &pb.GetNotesResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := srv.GetNotes(context.Background(), &pb.GetNotesRequest{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetNotes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(res, tt.expectedRes) {
+				t.Errorf("GetNotes() = %v, want %v", res, tt.expectedRes)
+			}
+		})
+	}
+}
+
+func TestUpdateNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	uploadedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		request     *pb.UpdateNoteRequest
+		mock        func()
+		wantErr     bool
+		expectedRes *pb.UpdateNoteResponse
+	}{
+		{
+			name:    "SuccessfulUpdate",
+			request: &pb.UpdateNoteRequest{Id: "1", Title: "wifi", Body: "new secret"},
+			mock: func() {
+				repo.EXPECT().UpdateNote(gomock.Any(), models.Note{ID: "1", Title: "wifi", Body: "new secret"}).
+					Return(models.Note{ID: "1", Title: "wifi", Body: "new secret", UploadedAt: uploadedAt}, nil)
+			},
+			expectedRes: &pb.UpdateNoteResponse{Id: "1", Title: "wifi", UploadedAt: uploadedAt.Format(time.RFC3339)},
+		},
+		{
+			name:    "UpdateFail",
+			request: &pb.UpdateNoteRequest{Id: "notExists"},
+			mock: func() {
+				repo.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(models.Note{}, errors.New("update error"))
+			},
+			wantErr:     true,
+			expectedRes: &pb.UpdateNoteResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := srv.UpdateNote(context.Background(), tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateNote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(res, tt.expectedRes) {
+				t.Errorf("UpdateNote() = %v, want %v", res, tt.expectedRes)
+			}
+		})
+	}
+}
+
+func TestUpdateNote_VersionConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	uploadedAt := time.Now()
+
+	repo.EXPECT().UpdateNote(gomock.Any(), models.Note{ID: "1", Title: "wifi", Body: "mine", Version: 1}).
+		Return(models.Note{}, storage.ErrVersionConflict)
+	repo.EXPECT().GetNotes(gomock.Any()).
+		Return([]models.Note{{ID: "1", Title: "wifi", Body: "theirs", UploadedAt: uploadedAt, Version: 2}}, nil)
+	_, err := srv.UpdateNote(context.Background(), &pb.UpdateNoteRequest{Id: "1", Title: "wifi", Body: "mine", Version: 1})
+	st := status.Convert(err)
+	if st.Code() != codes.Aborted || len(st.Details()) != 1 {
+		t.Fatalf("UpdateNote() error = %v, want Aborted with current note", err)
+	}
+	current, ok := st.Details()[0].(*pb.GetNotesResponse_Note)
+	if !ok || current.Body != "theirs" || current.Version != 2 {
+		t.Errorf("UpdateNote() conflict details = %v, want current note", st.Details()[0])
+	}
+
+	repo.EXPECT().DeleteNote(gomock.Any(), "1", int64(2)).Return(storage.ErrVersionConflict)
+	repo.EXPECT().GetNotes(gomock.Any()).Return([]models.Note{}, nil)
+	_, err = srv.DeleteNote(context.Background(), &pb.DeleteNoteRequest{Id: "1", Version: 2})
+	st = status.Convert(err)
+	if st.Code() != codes.Aborted || len(st.Details()) != 0 {
+		t.Errorf("DeleteNote() error = %v, want Aborted without details for deleted note", err)
+	}
+}
+
+func TestItemHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	revisedAt := time.Now()
+
+	repo.EXPECT().ListItemHistory(gomock.Any(), models.CardItem, "1").Return([]models.Revision{
+		{ID: "r1", ItemID: "1", ItemType: models.CardItem, Version: 1, RevisedAt: revisedAt, Deleted: true,
+			Item: models.Card{ID: "1", Number: "1234", Version: 1}},
+	}, nil)
+	history, err := srv.ListItemHistory(context.Background(), &pb.ListItemHistoryRequest{ItemType: pb.ItemType_ITEM_TYPE_CARD, Id: "1"})
+	if err != nil || len(history.Revisions) != 1 {
+		t.Fatalf("ListItemHistory() = %v, %v, want one revision", history, err)
+	}
+	if revision := history.Revisions[0]; revision.Id != "r1" || !revision.Deleted || revision.GetCard().GetNumber() != "1234" ||
+		revision.RevisedAt != revisedAt.Format(time.RFC3339) {
+		t.Errorf("ListItemHistory() revision = %v, want deleted card revision", revision)
+	}
+
+	_, err = srv.ListItemHistory(context.Background(), &pb.ListItemHistoryRequest{Id: "1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListItemHistory() error = %v, want InvalidArgument for unspecified item type", err)
+	}
+
+	repo.EXPECT().RestoreItemRevision(gomock.Any(), models.CardItem, "1", "r1", int64(2)).Return(int64(3), nil)
+	restored, err := srv.RestoreItemRevision(context.Background(),
+		&pb.RestoreItemRevisionRequest{ItemType: pb.ItemType_ITEM_TYPE_CARD, Id: "1", RevisionId: "r1", Version: 2})
+	if err != nil || restored.Version != 3 {
+		t.Errorf("RestoreItemRevision() = %v, %v, want version 3", restored, err)
+	}
+
+	repo.EXPECT().RestoreItemRevision(gomock.Any(), models.CardItem, "1", "r9", int64(3)).Return(int64(0), storage.ErrNotFound)
+	_, err = srv.RestoreItemRevision(context.Background(),
+		&pb.RestoreItemRevisionRequest{ItemType: pb.ItemType_ITEM_TYPE_CARD, Id: "1", RevisionId: "r9", Version: 3})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreItemRevision() error = %v, want NotFound", err)
+	}
+
+	repo.EXPECT().RestoreItemRevision(gomock.Any(), models.CardItem, "1", "r1", int64(2)).Return(int64(0), storage.ErrVersionConflict)
+	repo.EXPECT().GetCards(gomock.Any()).Return([]models.Card{{ID: "1", Number: "5678", Version: 3}}, nil)
+	_, err = srv.RestoreItemRevision(context.Background(),
+		&pb.RestoreItemRevisionRequest{ItemType: pb.ItemType_ITEM_TYPE_CARD, Id: "1", RevisionId: "r1", Version: 2})
+	if st := status.Convert(err); st.Code() != codes.Aborted || len(st.Details()) != 1 {
+		t.Errorf("RestoreItemRevision() error = %v, want Aborted with current card", err)
+	}
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	repo.EXPECT().RestoreFromTrash(gomock.Any(), models.NoteItem, "1").Return(nil)
+	_, err := srv.RestoreFromTrash(context.Background(), &pb.RestoreFromTrashRequest{ItemType: pb.ItemType_ITEM_TYPE_NOTE, Id: "1"})
+	if err != nil {
+		t.Errorf("RestoreFromTrash() error = %v, want note restored", err)
+	}
+
+	repo.EXPECT().RestoreFromTrash(gomock.Any(), models.CredentialsItem, "2").Return(storage.ErrNotFound)
+	_, err = srv.RestoreFromTrash(context.Background(), &pb.RestoreFromTrashRequest{ItemType: pb.ItemType_ITEM_TYPE_CREDENTIALS, Id: "2"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RestoreFromTrash() error = %v, want NotFound", err)
+	}
+
+	_, err = srv.RestoreFromTrash(context.Background(), &pb.RestoreFromTrashRequest{Id: "3"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RestoreFromTrash() error = %v, want InvalidArgument for unspecified item type", err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	repo.EXPECT().Sync(gomock.Any(), int64(5)).Return(models.ChangeSet{
+		Cursor:  9,
+		Notes:   []models.Note{{ID: "1", Title: "wifi", Body: "secret", Version: 2}},
+		Deleted: []models.Change{{ItemType: models.CardItem, ItemID: "2"}},
+	}, nil)
+	response, err := srv.Sync(ctx, &pb.SyncRequest{Cursor: 5})
+	if err != nil {
+		t.Fatalf("Sync() error = %v, want changes", err)
+	}
+	if response.Cursor != 9 || response.Full || len(response.Notes) != 1 || response.Notes[0].Id != "1" {
+		t.Errorf("Sync() = %v, want changed note with new cursor", response)
+	}
+	if len(response.Deleted) != 1 || response.Deleted[0].ItemType != pb.ItemType_ITEM_TYPE_CARD || response.Deleted[0].Id != "2" {
+		t.Errorf("Sync() deleted = %v, want deleted card", response.Deleted)
+	}
+
+	repo.EXPECT().Sync(gomock.Any(), int64(9)).Return(models.ChangeSet{}, errors.New("db error"))
+	_, err = srv.Sync(ctx, &pb.SyncRequest{Cursor: 9})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Sync() error = %v, want Internal", err)
+	}
+}
+
+func TestSendNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	own := mock.NewMockGophKeeperService_SubscribeToChangesServer(ctrl)
+	other := mock.NewMockGophKeeperService_SubscribeToChangesServer(ctrl)
+	srv := &GrpcServer{
+		storage: repo,
+		config:  &config.ServerConfig{Secret: "testing secret"},
+		syncClients: map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer{
+			"test": {"laptop-session": own, "phone-session": other},
+		},
+		rwMutex: &sync.RWMutex{},
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	ctx = context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, "laptop-session")
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(string(config.DEVICEHEADER), "laptop"))
+
+	note := models.Note{ID: "1", Title: "wifi", Body: "secret", Version: 2}
+	repo.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(note, nil)
+	other.EXPECT().Send(&pb.SubscribeToChangesResponse{
+		Id:        "1",
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_UPDATE,
+		ItemType:  pb.ItemType_ITEM_TYPE_NOTE,
+		Version:   2,
+		Item:      &pb.SubscribeToChangesResponse_Note{Note: noteMessage(note)},
+		Device:    "laptop",
+	}).Return(nil)
+	if _, err := srv.UpdateNote(ctx, &pb.UpdateNoteRequest{Id: "1", Title: "wifi", Body: "secret", Version: 1}); err != nil {
+		t.Fatalf("UpdateNote() error = %v", err)
+	}
+
+	repo.EXPECT().DeleteCard(gomock.Any(), "2", int64(3)).Return(nil)
+	other.EXPECT().Send(&pb.SubscribeToChangesResponse{
+		Id:        "2",
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_DELETE,
+		ItemType:  pb.ItemType_ITEM_TYPE_CARD,
+		Version:   4,
+		Device:    "laptop",
+	}).Return(nil)
+	if _, err := srv.DeleteCard(ctx, &pb.DeleteCardRequest{Id: "2", Version: 3}); err != nil {
+		t.Fatalf("DeleteCard() error = %v", err)
+	}
+}
+
+func TestDeleteNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := &GrpcServer{
+		storage:     repo,
+		config:      &config.ServerConfig{Secret: "testing secret"},
+		syncClients: make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:     &sync.RWMutex{},
+	}
+
+	tests := []struct {
+		name    string
+		request *pb.DeleteNoteRequest
+		mock    func()
+		wantErr bool
+	}{
+		{
+			name:    "SuccessfulDelete",
+			request: &pb.DeleteNoteRequest{Id: "1"},
+			mock: func() {
+				repo.EXPECT().DeleteNote(gomock.Any(), "1", int64(0)).Return(nil)
+			},
+		},
+		{
+			name:    "NonExistentId",
+			request: &pb.DeleteNoteRequest{Id: "2"},
+			mock: func() {
+				repo.EXPECT().DeleteNote(gomock.Any(), "2", int64(0)).Return(errors.New("note not found"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			_, err := srv.DeleteNote(context.Background(), tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteNote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestObjectMetadata(t *testing.T) {
+	metadata := map[string]string{"Project": "gophkeeper", "owner": "Павел"}
+
+	encoded := encodeObjectMetadata(metadata)
+	if len(encoded) != 1 {
+		t.Fatalf("encodeObjectMetadata() = %v, want single entry", encoded)
+	}
+	if !reflect.DeepEqual(decodeObjectMetadata(encoded), metadata) {
+		t.Errorf("decodeObjectMetadata() = %v, want %v", decodeObjectMetadata(encoded), metadata)
+	}
+
+	listed := map[string]string{"X-Amz-Meta-Metadata": encoded[objectMetadataKey], "content-type": "text/plain"}
+	if !reflect.DeepEqual(decodeObjectMetadata(listed), metadata) {
+		t.Errorf("decodeObjectMetadata() of listed object = %v, want %v", decodeObjectMetadata(listed), metadata)
+	}
+
+	if encodeObjectMetadata(nil) != nil {
+		t.Errorf("encodeObjectMetadata() of empty metadata should be nil")
+	}
+	if decodeObjectMetadata(map[string]string{"X-Amz-Meta-Metadata": "not base64"}) != nil {
+		t.Errorf("decodeObjectMetadata() of malformed value should be nil")
+	}
+
+	digested := withObjectMetadata(encodeObjectMetadata(metadata), objectDigestKey, "abc")
+	if objectDigest(digested) != "abc" || !reflect.DeepEqual(decodeObjectMetadata(digested), metadata) {
+		t.Errorf("withObjectMetadata() = %v, want digest next to metadata", digested)
+	}
+	if objectDigest(map[string]string{"X-Amz-Meta-Sha256": "abc"}) != "abc" {
+		t.Errorf("objectDigest() of listed object should be found")
+	}
+	if withObjectMetadata(nil, objectDigestKey, "") != nil || objectDigest(nil) != "" {
+		t.Errorf("objectDigest() of object without digest should be empty")
+	}
+
+	file := fileMessage("d4c1", blobstore.ObjectInfo{UserMetadata: map[string]string{
+		"X-Amz-Meta-Filekey":  "gk1:key",
+		"X-Amz-Meta-Filename": "gk1:name",
+	}})
+	if file.FileKey != "gk1:key" || file.EncryptedName != "gk1:name" {
+		t.Errorf("fileMessage() = %v, want wrapped key and encrypted name", file)
+	}
+}
+
+func newTestBlobStore(t *testing.T) *blobstore.FSStore {
+	blobs, err := blobstore.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFSStore() error = %v", err)
+	}
+	if err = blobs.MakeBucket(context.Background(), "user"); err != nil {
+		t.Fatalf("MakeBucket() error = %v", err)
+	}
+	return blobs
+}
+
+func uploadChunk(uploadID string, offset int64, data []byte) *pb.UploadFileRequest {
+	checksum := sha256.Sum256(data)
+	return &pb.UploadFileRequest{
+		Data:     data,
+		Filename: "file.txt",
+		UploadId: uploadID,
+		Offset:   offset,
+		Checksum: hex.EncodeToString(checksum[:]),
+	}
+}
+
+func TestUploadFile_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	blobs := newTestBlobStore(t)
+	srv := &GrpcServer{
+		storage:      repo,
+		config:       &config.ServerConfig{Secret: "testing secret"},
+		blobs:        blobs,
+		uploads:      make(map[string]*uploadSession),
+		uploadsMutex: &sync.Mutex{},
+		syncClients:  make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:      &sync.RWMutex{},
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user")
+
+	data := bytes.Repeat([]byte("gophkeeper"), uploadPartSize/5)
+	first, second := data[:uploadPartSize+1], data[uploadPartSize+1:]
+	digest := sha256.Sum256(data)
+	start := uploadChunk("", 0, first[:uploadPartSize/2])
+	start.Digest = hex.EncodeToString(digest[:])
+
+	stream := mock.NewMockGophKeeperService_UploadFileServer(ctrl)
+	stream.EXPECT().Context().Return(ctx).AnyTimes()
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(start, nil),
+		stream.EXPECT().Recv().Return(uploadChunk("", uploadPartSize/2, first[uploadPartSize/2:]), nil),
+		stream.EXPECT().Recv().Return(nil, status.Error(codes.Canceled, "connection lost")),
+	)
+	var uploadID string
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(response *pb.UploadFileResponse) error {
+		uploadID = response.UploadId
+		return nil
+	})
+	if err := srv.UploadFile(stream); status.Code(err) != codes.Canceled {
+		t.Fatalf("UploadFile() error = %v, want Canceled", err)
+	}
+
+	resumed, err := srv.ResumeUpload(ctx, &pb.ResumeUploadRequest{UploadId: uploadID})
+	if err != nil {
+		t.Fatalf("ResumeUpload() error = %v", err)
+	}
+	if resumed.Offset != int64(len(first)) || resumed.Filename != "file.txt" {
+		t.Errorf("ResumeUpload() = %v, want offset %d", resumed, len(first))
+	}
+	if _, err = srv.ResumeUpload(context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "other"),
+		&pb.ResumeUploadRequest{UploadId: uploadID}); status.Code(err) != codes.NotFound {
+		t.Errorf("ResumeUpload() of other user error = %v, want NotFound", err)
+	}
+
+	finish := &pb.UploadFileRequest{UploadId: uploadID, Digest: hex.EncodeToString(digest[:])}
+	stream = mock.NewMockGophKeeperService_UploadFileServer(ctrl)
+	stream.EXPECT().Context().Return(ctx).AnyTimes()
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(uploadChunk(uploadID, int64(len(first)), second), nil),
+		stream.EXPECT().Recv().Return(finish, nil),
+		stream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+	var responses []*pb.UploadFileResponse
+	stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(response *pb.UploadFileResponse) error {
+		responses = append(responses, response)
+		return nil
+	}).Times(2)
+	repo.EXPECT().RecordChange(gomock.Any(), models.FileItem, "file.txt").Return(nil)
+
+	if err = srv.UploadFile(stream); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+	if responses[0].Offset != int64(len(first)) {
+		t.Errorf("UploadFile() resumed at %d, want %d", responses[0].Offset, len(first))
+	}
+	if !responses[1].Success || responses[1].Digest != finish.Digest || responses[1].Offset != int64(len(data)) {
+		t.Errorf("UploadFile() final response = %v", responses[1])
+	}
+	object, err := blobs.GetObject(ctx, "user", "file.txt", 0, 0)
+	if err != nil {
+		t.Fatalf("GetObject() error = %v", err)
+	}
+	defer object.Close()
+	if stored, _ := io.ReadAll(object); !bytes.Equal(stored, data) {
+		t.Errorf("UploadFile() stored %d bytes, want %d", len(stored), len(data))
+	}
+	info, _ := blobs.StatObject(ctx, "user", "file.txt")
+	if objectDigest(info.UserMetadata) != finish.Digest {
+		t.Errorf("UploadFile() stored metadata = %v, want digest", info.UserMetadata)
+	}
+	if _, err = srv.ResumeUpload(ctx, &pb.ResumeUploadRequest{UploadId: uploadID}); status.Code(err) != codes.NotFound {
+		t.Errorf("ResumeUpload() of completed upload error = %v, want NotFound", err)
+	}
+}
+
+func TestUploadFile_Corrupted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user")
+	tests := []struct {
+		name     string
+		requests []*pb.UploadFileRequest
+		wantCode codes.Code
+	}{
+		{
+			name: "ChunkChecksum",
+			requests: []*pb.UploadFileRequest{
+				{Data: []byte("secret"), Filename: "file.txt", Checksum: "broken"},
+			},
+			wantCode: codes.DataLoss,
+		},
+		{
+			name: "ChunkOffset",
+			requests: []*pb.UploadFileRequest{
+				uploadChunk("", 0, []byte("secret")),
+				uploadChunk("", 10, []byte("data")),
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "FileDigest",
+			requests: []*pb.UploadFileRequest{
+				uploadChunk("", 0, []byte("secret")),
+				{Digest: "broken"},
+			},
+			wantCode: codes.DataLoss,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blobs := newTestBlobStore(t)
+			srv := &GrpcServer{
+				blobs:        blobs,
+				uploads:      make(map[string]*uploadSession),
+				uploadsMutex: &sync.Mutex{},
+			}
+
+			stream := mock.NewMockGophKeeperService_UploadFileServer(ctrl)
+			stream.EXPECT().Context().Return(ctx).AnyTimes()
+			stream.EXPECT().Send(gomock.Any()).Return(nil)
+			var calls []any
+			for _, request := range tt.requests {
+				calls = append(calls, stream.EXPECT().Recv().Return(request, nil))
+			}
+			calls = append(calls, stream.EXPECT().Recv().Return(nil, io.EOF).MaxTimes(1))
+			gomock.InOrder(calls...)
+
+			if err := srv.UploadFile(stream); status.Code(err) != tt.wantCode {
+				t.Errorf("UploadFile() error = %v, want %v", err, tt.wantCode)
+			}
+			if _, err := blobs.StatObject(ctx, "user", "file.txt"); !errors.Is(err, blobstore.ErrNotFound) {
+				t.Errorf("UploadFile() of corrupted file should not store it, error = %v", err)
+			}
+			if tt.name == "FileDigest" && len(srv.uploads) != 0 {
+				t.Errorf("UploadFile() with wrong digest should abort upload")
+			}
+		})
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs := newTestBlobStore(t)
+	srv := &GrpcServer{blobs: blobs}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user")
+
+	data := []byte("gophkeeper keeps secrets")
+	uploadID, _ := blobs.NewMultipartUpload(ctx, "user", "file.txt", map[string]string{objectFileKeyKey: "gk1:key"})
+	part, _ := blobs.PutPart(ctx, "user", "file.txt", uploadID, 1, bytes.NewReader(data), int64(len(data)))
+	if _, err := blobs.CompleteMultipartUpload(ctx, "user", "file.txt", uploadID, []blobstore.Part{part}); err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+	digest := sha256.Sum256(data)
+
+	tests := []struct {
+		name     string
+		request  *pb.DownloadFileRequest
+		want     string
+		wantCode codes.Code
+	}{
+		{name: "WholeFile", request: &pb.DownloadFileRequest{Name: "file.txt"}, want: string(data)},
+		{name: "Range", request: &pb.DownloadFileRequest{Name: "file.txt", Offset: 4, Length: 6}, want: "keeper"},
+		{name: "Rest", request: &pb.DownloadFileRequest{Name: "file.txt", Offset: 17}, want: "secrets"},
+		{name: "End", request: &pb.DownloadFileRequest{Name: "file.txt", Offset: int64(len(data))}},
+		{name: "OutOfRange", request: &pb.DownloadFileRequest{Name: "file.txt", Offset: 100}, wantCode: codes.OutOfRange},
+		{name: "Missing", request: &pb.DownloadFileRequest{Name: "missing.txt"}, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var responses []*pb.DownloadFileResponse
+			stream := mock.NewMockGophKeeperService_DownloadFileServer(ctrl)
+			stream.EXPECT().Context().Return(ctx).AnyTimes()
+			stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(response *pb.DownloadFileResponse) error {
+				responses = append(responses, response)
+				return nil
+			}).AnyTimes()
+
+			err := srv.DownloadFile(tt.request, stream)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("DownloadFile() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			header := responses[0]
+			if header.Size != int64(len(data)) || header.Digest != hex.EncodeToString(digest[:]) || header.FileKey != "gk1:key" {
+				t.Errorf("DownloadFile() header = %v", header)
+			}
+			var received []byte
+			for _, response := range responses[1:] {
+				received = append(received, response.Data...)
+			}
+			if string(received) != tt.want {
+				t.Errorf("DownloadFile() = %q, want %q", received, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, store, newTestBlobStore(t))
+
+	signedUp, err := srv.SignUp(context.Background(), &pb.SignUpRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	other, err := srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, auth.GetUserID(signedUp.Token, "testing secret"))
+	ctx = context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, auth.GetSessionID(signedUp.Token, "testing secret"))
+	unsubscribed := false
+	srv.subscriptions[auth.GetSessionID(other.Token, "testing secret")] = func() { unsubscribed = true }
+
+	if _, err = srv.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "changed"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ChangePassword() with wrong old password error = %v, want %v", err, codes.PermissionDenied)
+	}
+	if _, err = srv.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "password", NewPassword: string(make([]byte, 73))}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ChangePassword() with too long password error = %v, want %v", err, codes.InvalidArgument)
+	}
+	changed, err := srv.ChangePassword(ctx, &pb.ChangePasswordRequest{OldPassword: "password", NewPassword: "changed"})
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if changed.RevokedSessions != 1 || !unsubscribed {
+		t.Errorf("ChangePassword() revoked %d sessions, unsubscribed %v, want other session signed out", changed.RevokedSessions, unsubscribed)
+	}
+
+	if _, err = srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: other.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("RefreshToken() of other session error = %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err = srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: signedUp.RefreshToken}); err != nil {
+		t.Errorf("RefreshToken() of current session error = %v", err)
+	}
+	if _, err = srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("SignIn() with old password error = %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err = srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "changed"}); err != nil {
+		t.Errorf("SignIn() with new password error = %v", err)
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	blobs := newTestBlobStore(t)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, store, blobs)
+
+	signedUp, err := srv.SignUp(context.Background(), &pb.SignUpRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	userID := auth.GetUserID(signedUp.Token, "testing secret")
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, userID)
+	ctx = context.WithValue(ctx, config.SESSIONIDCONTEXTKEY, auth.GetSessionID(signedUp.Token, "testing secret"))
+	if _, err = srv.CreateNote(ctx, &pb.CreateNoteRequest{Title: "todo", Body: "buy milk"}); err != nil {
+		t.Fatalf("CreateNote() error = %v", err)
+	}
+	unsubscribed := false
+	srv.subscriptions[auth.GetSessionID(signedUp.Token, "testing secret")] = func() { unsubscribed = true }
+
+	if _, err = srv.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "wrong"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("DeleteAccount() with wrong password error = %v, want %v", err, codes.PermissionDenied)
+	}
+	deleted, err := srv.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"})
+	if err != nil {
+		t.Fatalf("DeleteAccount() error = %v", err)
+	}
+	if deleted.Email != "demo@example.com" || deleted.DeletedAt == "" || !unsubscribed {
+		t.Errorf("DeleteAccount() = %v, unsubscribed %v, want confirmation and closed stream", deleted, unsubscribed)
+	}
+
+	buckets, err := blobs.ListBuckets(context.Background())
+	if err != nil {
+		t.Fatalf("ListBuckets() error = %v", err)
+	}
+	if slices.Contains(buckets, userID) {
+		t.Errorf("DeleteAccount() kept bucket %s", userID)
+	}
+	if _, err = srv.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: signedUp.RefreshToken}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("RefreshToken() of deleted account error = %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err = srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "password"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("SignIn() to deleted account error = %v, want %v", err, codes.Unauthenticated)
+	}
+	if _, err = srv.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: "password"}); status.Code(err) != codes.Internal {
+		t.Errorf("DeleteAccount() of deleted account error = %v, want %v", err, codes.Internal)
+	}
+	if _, err = srv.SignUp(context.Background(), &pb.SignUpRequest{Email: "demo@example.com", Password: "password"}); err != nil {
+		t.Errorf("SignUp() with email of deleted account error = %v", err)
+	}
+}
+
+// putTestObject - store object in test blob store by multipart upload
+func putTestObject(t *testing.T, blobs blobstore.BlobStore, key string, data []byte, userMetadata map[string]string) {
+	ctx := context.Background()
+	uploadID, err := blobs.NewMultipartUpload(ctx, "user", key, userMetadata)
+	if err != nil {
+		t.Fatalf("NewMultipartUpload() error = %v", err)
+	}
+	part, err := blobs.PutPart(ctx, "user", key, uploadID, 1, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("PutPart() error = %v", err)
+	}
+	if _, err = blobs.CompleteMultipartUpload(ctx, "user", key, uploadID, []blobstore.Part{part}); err != nil {
+		t.Fatalf("CompleteMultipartUpload() error = %v", err)
+	}
+}
+
+func TestDeleteFile_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	blobs := newTestBlobStore(t)
+	srv := &GrpcServer{
+		storage:      repo,
+		config:       &config.ServerConfig{Secret: "testing secret"},
+		blobs:        blobs,
+		uploads:      make(map[string]*uploadSession),
+		uploadsMutex: &sync.Mutex{},
+		syncClients:  make(map[string]map[string]pb.GophKeeperService_SubscribeToChangesServer),
+		rwMutex:      &sync.RWMutex{},
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user")
+	repo.EXPECT().RecordChange(gomock.Any(), models.FileItem, "report.txt").Return(nil).AnyTimes()
+	repo.EXPECT().ListTrash(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	// files deleted with the same name are both kept in trash
+	for _, content := range []string{"first", "second"} {
+		putTestObject(t, blobs, "report.txt", []byte(content), encodeObjectMetadata(map[string]string{"version": content}))
+		if _, err := srv.DeleteFile(ctx, &pb.DeleteFileRequest{Name: "report.txt"}); err != nil {
+			t.Fatalf("DeleteFile() error = %v", err)
+		}
+	}
+	if _, err := srv.DeleteFile(ctx, &pb.DeleteFileRequest{Name: "report.txt"}); status.Code(err) != codes.NotFound {
+		t.Errorf("DeleteFile() of missing file error = %v, want NotFound", err)
+	}
+
+	trash, err := srv.ListTrash(ctx, &pb.ListTrashRequest{})
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+	if len(trash.Items) != 2 || trash.Items[0].Id == trash.Items[1].Id {
+		t.Fatalf("ListTrash() = %v, want two deleted files with different IDs", trash.Items)
+	}
+	for _, item := range trash.Items {
+		if item.GetFile().Name != "report.txt" {
+			t.Errorf("ListTrash() file name = %q, want original name", item.GetFile().Name)
+		}
+	}
+
+	restored := trash.Items[0]
+	if _, err = srv.RestoreFromTrash(ctx, &pb.RestoreFromTrashRequest{ItemType: pb.ItemType_ITEM_TYPE_FILE, Id: restored.Id}); err != nil {
+		t.Fatalf("RestoreFromTrash() error = %v", err)
+	}
+	info, err := blobs.StatObject(ctx, "user", "report.txt")
+	if err != nil {
+		t.Fatalf("StatObject() error = %v", err)
+	}
+	if _, trashed := lookupObjectMetadata(info.UserMetadata, objectTrashedNameKey); trashed {
+		t.Errorf("restored file metadata = %v, want no trashed name", info.UserMetadata)
+	}
+	if metadata := decodeObjectMetadata(info.UserMetadata); metadata["version"] != restored.GetFile().Metadata["version"] {
+		t.Errorf("restored file metadata = %v, want %v", metadata, restored.GetFile().Metadata)
+	}
+	_, err = srv.RestoreFromTrash(ctx, &pb.RestoreFromTrashRequest{ItemType: pb.ItemType_ITEM_TYPE_FILE, Id: trash.Items[1].Id})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("RestoreFromTrash() over existing file error = %v, want AlreadyExists", err)
+	}
+
+	_, err = srv.openUpload(ctx, "user", &pb.UploadFileRequest{Filename: trashPrefix + trash.Items[1].Id})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("openUpload() into trash error = %v, want InvalidArgument", err)
+	}
+}
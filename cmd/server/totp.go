@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/config"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
+	"github.com/PaBah/GophKeeper/internal/totp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errInvalidCode - code is neither current code of authenticator app nor not used recovery code
+var errInvalidCode = errors.New("code is not valid")
+
+// VerifyTOTP - handler for the second step of sign in, partial token issued by SignIn is exchanged for session
+// when code of authenticator app or recovery code is valid
+func (s *GrpcServer) VerifyTOTP(ctx context.Context, in *pb.VerifyTOTPRequest) (*pb.VerifyTOTPResponse, error) {
+	response := &pb.VerifyTOTPResponse{}
+
+	userID := auth.GetPartialUserID(in.PartialToken, s.config.Secret)
+	if userID == "" {
+		return response, status.Errorf(codes.Unauthenticated, "sign in is expired, enter password again")
+	}
+	ctx = context.WithValue(ctx, config.USERIDCONTEXTKEY, userID)
+
+	secondFactor, err := s.storage.GetTOTP(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be checked")
+	}
+	if !secondFactor.Enabled {
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	// codes are guessed easier than password, so their failures lock account the same way
+	keys := signInKeys(ctx, secondFactor.Account)
+	if err = s.checkSignInLock(ctx, keys); err != nil {
+		return response, err
+	}
+	err = s.checkSecondFactor(ctx, secondFactor, in.Code)
+	if errors.Is(err, errInvalidCode) {
+		s.recordSignInFailure(ctx, keys)
+		return response, status.Errorf(codes.Unauthenticated, "two-factor authentication code is not valid")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be checked")
+	}
+
+	tokens, err := s.startSession(ctx, userID)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "Can not build auth token")
+	}
+	s.resetSignInAttempts(ctx, secondFactor.Account)
+
+	response.Token, response.RefreshToken, response.ExpiresAt = tokens.token, tokens.refreshToken, tokens.expiresAt
+	return response, nil
+}
+
+// EnrollTOTP - handler for starting enrollment of authenticator app, second factor is required on sign in
+// only after it is confirmed by ConfirmTOTP
+func (s *GrpcServer) EnrollTOTP(ctx context.Context, in *pb.EnrollTOTPRequest) (*pb.EnrollTOTPResponse, error) {
+	response := &pb.EnrollTOTPResponse{}
+
+	secondFactor, err := s.storage.GetTOTP(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be enrolled")
+	}
+	if secondFactor.Enabled {
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	key, err := totp.Generate(secondFactor.Account)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be enrolled")
+	}
+	recoveryCodes, err := totp.NewRecoveryCodes()
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be enrolled")
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = totp.HashRecoveryCode(code)
+	}
+
+	err = s.storage.SetTOTP(ctx, key.Secret, hashes)
+	if errors.Is(err, storage.ErrAlreadyExists) {
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be enrolled")
+	}
+
+	response.Secret, response.ProvisioningUri, response.RecoveryCodes = key.Secret, key.URI, recoveryCodes
+	return response, nil
+}
+
+// ConfirmTOTP - handler for finishing enrollment, code proves authenticator app was provisioned
+func (s *GrpcServer) ConfirmTOTP(ctx context.Context, in *pb.ConfirmTOTPRequest) (*pb.ConfirmTOTPResponse, error) {
+	response := &pb.ConfirmTOTPResponse{}
+
+	secondFactor, err := s.storage.GetTOTP(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be confirmed")
+	}
+	switch {
+	case secondFactor.Enabled:
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	case secondFactor.Secret == "":
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enrolled")
+	case !totp.Validate(in.Code, secondFactor.Secret):
+		return response, status.Errorf(codes.InvalidArgument, "two-factor authentication code is not valid")
+	}
+
+	if err = s.storage.EnableTOTP(ctx); err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be confirmed")
+	}
+	return response, nil
+}
+
+// DisableTOTP - handler for turning second factor off, it is confirmed by code of authenticator app or recovery code
+func (s *GrpcServer) DisableTOTP(ctx context.Context, in *pb.DisableTOTPRequest) (*pb.DisableTOTPResponse, error) {
+	response := &pb.DisableTOTPResponse{}
+
+	secondFactor, err := s.storage.GetTOTP(ctx)
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be disabled")
+	}
+	if !secondFactor.Enabled {
+		return response, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+	keys := reauthKeys(ctx)
+	if err = s.checkSignInLock(ctx, keys); err != nil {
+		return response, err
+	}
+	err = s.checkSecondFactor(ctx, secondFactor, in.Code)
+	if errors.Is(err, errInvalidCode) {
+		s.recordSignInFailure(ctx, keys)
+		return response, status.Errorf(codes.InvalidArgument, "two-factor authentication code is not valid")
+	}
+	if err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be disabled")
+	}
+
+	if err = s.storage.DisableTOTP(ctx); err != nil {
+		return response, status.Errorf(codes.Internal, "two-factor authentication can not be disabled")
+	}
+	s.resetReauthAttempts(ctx)
+	return response, nil
+}
+
+// checkSecondFactor - accept current code of authenticator app or spend one of recovery codes
+func (s *GrpcServer) checkSecondFactor(ctx context.Context, secondFactor models.TOTP, code string) error {
+	if totp.Validate(code, secondFactor.Secret) {
+		return nil
+	}
+	err := s.storage.UseRecoveryCode(ctx, totp.HashRecoveryCode(code))
+	if errors.Is(err, storage.ErrNotFound) {
+		return errInvalidCode
+	}
+	return err
+}
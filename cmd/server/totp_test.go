@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/auth"
+	"github.com/PaBah/GophKeeper/internal/config"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
+	"github.com/PaBah/GophKeeper/internal/totp"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTOTP_SignIn(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, store, newTestBlobStore(t))
+	ctx := context.Background()
+
+	signedUp, err := srv.SignUp(ctx, &pb.SignUpRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignUp() error = %v", err)
+	}
+	ctx = context.WithValue(ctx, config.USERIDCONTEXTKEY, auth.GetUserID(signedUp.Token, "testing secret"))
+
+	if _, err = srv.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{Code: "123456"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ConfirmTOTP() before enrollment error = %v, want FailedPrecondition", err)
+	}
+	enrolled, err := srv.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{})
+	if err != nil {
+		t.Fatalf("EnrollTOTP() error = %v", err)
+	}
+	if enrolled.Secret == "" || enrolled.ProvisioningUri == "" || len(enrolled.RecoveryCodes) != totp.RecoveryCodesCount {
+		t.Fatalf("EnrollTOTP() = %v, want secret, provisioning URI and recovery codes", enrolled)
+	}
+
+	signedIn, err := srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil || signedIn.TotpRequired {
+		t.Fatalf("SignIn() before confirmation = %v, %v, want session", signedIn, err)
+	}
+
+	if _, err = srv.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{Code: "000000"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ConfirmTOTP() with wrong code error = %v, want InvalidArgument", err)
+	}
+	code, _ := totp.GenerateCode(enrolled.Secret, time.Now())
+	if _, err = srv.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{Code: code}); err != nil {
+		t.Fatalf("ConfirmTOTP() error = %v", err)
+	}
+	if _, err = srv.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("EnrollTOTP() of enabled TOTP error = %v, want FailedPrecondition", err)
+	}
+
+	signedIn, err = srv.SignIn(context.Background(), &pb.SignInRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("SignIn() error = %v", err)
+	}
+	if !signedIn.TotpRequired || signedIn.Token != "" || signedIn.PartialToken == "" {
+		t.Fatalf("SignIn() = %v, want partial token", signedIn)
+	}
+	if auth.GetUserID(signedIn.PartialToken, "testing secret") != "" {
+		t.Errorf("SignIn() partial token is accepted as access token")
+	}
+
+	if _, err = srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: signedUp.Token, Code: code}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("VerifyTOTP() with access token error = %v, want Unauthenticated", err)
+	}
+	if _, err = srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: signedIn.PartialToken, Code: "000000"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("VerifyTOTP() with wrong code error = %v, want Unauthenticated", err)
+	}
+	verified, err := srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: signedIn.PartialToken, Code: code})
+	if err != nil {
+		t.Fatalf("VerifyTOTP() error = %v", err)
+	}
+	if auth.GetUserID(verified.Token, "testing secret") == "" || verified.RefreshToken == "" {
+		t.Errorf("VerifyTOTP() = %v, want session tokens", verified)
+	}
+
+	recoveryCode := enrolled.RecoveryCodes[0]
+	if _, err = srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: signedIn.PartialToken, Code: recoveryCode}); err != nil {
+		t.Errorf("VerifyTOTP() with recovery code error = %v", err)
+	}
+	if _, err = srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: signedIn.PartialToken, Code: recoveryCode}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("VerifyTOTP() with used recovery code error = %v, want Unauthenticated", err)
+	}
+
+	if _, err = srv.DisableTOTP(ctx, &pb.DisableTOTPRequest{Code: recoveryCode}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("DisableTOTP() with used recovery code error = %v, want InvalidArgument", err)
+	}
+	reauthKey := reauthKeyPrefix + auth.GetUserID(signedUp.Token, "testing secret")
+	if attempts, _ := store.GetSignInAttempts(ctx, reauthKey); attempts.Failures != 1 {
+		t.Errorf("DisableTOTP() with wrong code counted %d failures, want 1", attempts.Failures)
+	}
+	if _, err = srv.DisableTOTP(ctx, &pb.DisableTOTPRequest{Code: code}); err != nil {
+		t.Fatalf("DisableTOTP() error = %v", err)
+	}
+	if _, err = srv.DisableTOTP(ctx, &pb.DisableTOTPRequest{Code: code}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DisableTOTP() of disabled TOTP error = %v, want FailedPrecondition", err)
+	}
+	signedIn, err = srv.SignIn(ctx, &pb.SignInRequest{Email: "demo@example.com", Password: "password"})
+	if err != nil || signedIn.TotpRequired {
+		t.Errorf("SignIn() after disabling = %v, %v, want session", signedIn, err)
+	}
+}
+
+func TestTOTP_StorageErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	srv := NewGrpcServer(&config.ServerConfig{Secret: "testing secret"}, repo, nil)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "user1")
+	partialToken, _ := auth.BuildPartialToken("user1", "testing secret")
+
+	repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{}, errors.New("db is down"))
+	if _, err := srv.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{}); status.Code(err) != codes.Internal {
+		t.Errorf("EnrollTOTP() error = %v, want Internal", err)
+	}
+
+	repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{Account: "demo@example.com"}, nil)
+	repo.EXPECT().SetTOTP(gomock.Any(), gomock.Any(), gomock.Any()).Return(storage.ErrAlreadyExists)
+	if _, err := srv.EnrollTOTP(ctx, &pb.EnrollTOTPRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("EnrollTOTP() enabled concurrently error = %v, want FailedPrecondition", err)
+	}
+
+	repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{}, nil)
+	if _, err := srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: partialToken, Code: "123456"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("VerifyTOTP() of disabled TOTP error = %v, want FailedPrecondition", err)
+	}
+
+	repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{Account: "demo@example.com", Secret: "JBSWY3DPEHPK3PXP", Enabled: true}, nil)
+	repo.EXPECT().GetSignInAttempts(gomock.Any(), "account:demo@example.com").Return(models.SignInAttempts{}, nil)
+	repo.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).Return(errors.New("db is down"))
+	if _, err := srv.VerifyTOTP(ctx, &pb.VerifyTOTPRequest{PartialToken: partialToken, Code: "aaaaa-bbbbb"}); status.Code(err) != codes.Internal {
+		t.Errorf("VerifyTOTP() error = %v, want Internal", err)
+	}
+
+	repo.EXPECT().GetTOTP(gomock.Any()).Return(models.TOTP{Secret: "JBSWY3DPEHPK3PXP"}, nil)
+	repo.EXPECT().EnableTOTP(gomock.Any()).Return(errors.New("db is down"))
+	code, _ := totp.GenerateCode("JBSWY3DPEHPK3PXP", time.Now())
+	if _, err := srv.ConfirmTOTP(ctx, &pb.ConfirmTOTPRequest{Code: code}); status.Code(err) != codes.Internal {
+		t.Errorf("ConfirmTOTP() error = %v, want Internal", err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/PaBah/GophKeeper/internal/blobstore"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// uploadPartSize - minimal size of multipart upload part accepted by MinIO
+	uploadPartSize = 5 << 20
+	// uploadSessionTTL - time after which idle upload session is aborted
+	uploadSessionTTL = 24 * time.Hour
+)
+
+// uploadSession - state of file upload which survives interrupted streams
+type uploadSession struct {
+	// mu - held by stream which currently writes to session
+	mu sync.Mutex
+
+	id         string
+	userID     string
+	objectName string
+	metadata   map[string]string
+	// fileKey, encryptedName - wrapped key and sealed name of file encrypted by client
+	fileKey       string
+	encryptedName string
+	uploadID      string
+	// digest - file digest announced on upload start and stored in object metadata
+	digest    string
+	updatedAt atomic.Int64
+
+	parts     []blobstore.Part
+	committed atomic.Int64
+	// committedHash - marshaled SHA-256 state of committed data
+	committedHash []byte
+
+	hash   hash.Hash
+	buffer []byte
+}
+
+// rewind - drops data which was not committed by previous stream
+func (u *uploadSession) rewind() error {
+	u.buffer = nil
+	u.hash = sha256.New()
+	if u.committedHash == nil {
+		return nil
+	}
+	return u.hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(u.committedHash)
+}
+
+// write - checks chunk and commits parts which are large enough
+func (u *uploadSession) write(ctx context.Context, blobs blobstore.BlobStore, in *pb.UploadFileRequest) error {
+	u.updatedAt.Store(time.Now().Unix())
+	if len(in.Data) == 0 {
+		return nil
+	}
+
+	checksum := sha256.Sum256(in.Data)
+	if hex.EncodeToString(checksum[:]) != in.Checksum {
+		return status.Errorf(codes.DataLoss, "checksum mismatch of chunk at offset %d", in.Offset)
+	}
+	if expected := u.committed.Load() + int64(len(u.buffer)); in.Offset != expected {
+		return status.Errorf(codes.FailedPrecondition, "chunk offset %d does not match expected %d", in.Offset, expected)
+	}
+
+	u.hash.Write(in.Data)
+	u.buffer = append(u.buffer, in.Data...)
+	if len(u.buffer) < uploadPartSize {
+		return nil
+	}
+	return u.flush(ctx, blobs)
+}
+
+// flush - uploads buffered data as next part
+func (u *uploadSession) flush(ctx context.Context, blobs blobstore.BlobStore) error {
+	part, err := blobs.PutPart(ctx, u.userID, u.objectName, u.uploadID, len(u.parts)+1,
+		bytes.NewReader(u.buffer), int64(len(u.buffer)))
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "part upload failed: %v", err)
+	}
+
+	state, err := u.hash.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	u.parts = append(u.parts, part)
+	u.committed.Add(int64(len(u.buffer)))
+	u.committedHash = state
+	u.buffer = nil
+	return nil
+}
+
+// complete - commits rest of data and finishes multipart upload if it matches digest
+func (u *uploadSession) complete(ctx context.Context, blobs blobstore.BlobStore, digest string) (string, error) {
+	if len(u.buffer) > 0 || len(u.parts) == 0 {
+		if err := u.flush(ctx, blobs); err != nil {
+			return "", err
+		}
+	}
+
+	sum := hex.EncodeToString(u.hash.Sum(nil))
+	if digest != sum || (u.digest != "" && u.digest != sum) {
+		return sum, status.Errorf(codes.DataLoss, "file digest %s does not match uploaded data %s", digest, sum)
+	}
+
+	_, err := blobs.CompleteMultipartUpload(ctx, u.userID, u.objectName, u.uploadID, u.parts)
+	if err != nil {
+		return sum, status.Errorf(codes.Unavailable, "upload completion failed: %v", err)
+	}
+	return sum, nil
+}
+
+// openUpload - creates new upload session or acquires existing one of user
+func (s *GrpcServer) openUpload(ctx context.Context, userID string, in *pb.UploadFileRequest) (*uploadSession, error) {
+	if in.UploadId != "" {
+		s.uploadsMutex.Lock()
+		session, ok := s.uploads[in.UploadId]
+		s.uploadsMutex.Unlock()
+		if !ok || session.userID != userID {
+			return nil, status.Error(codes.NotFound, "upload session not found")
+		}
+		if !session.mu.TryLock() {
+			return nil, status.Error(codes.Aborted, "upload is already in progress")
+		}
+		if err := session.rewind(); err != nil {
+			session.mu.Unlock()
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return session, nil
+	}
+
+	if in.Filename == "" || in.Offset != 0 {
+		return nil, status.Error(codes.InvalidArgument, "new upload must start with filename at offset 0")
+	}
+	if strings.HasPrefix(in.Filename, trashPrefix) {
+		return nil, status.Error(codes.InvalidArgument, "filename is reserved for deleted files")
+	}
+	s.expireUploads(ctx)
+
+	userMetadata := encodeObjectMetadata(in.Metadata)
+	userMetadata = withObjectMetadata(userMetadata, objectDigestKey, in.Digest)
+	userMetadata = withObjectMetadata(userMetadata, objectFileKeyKey, in.FileKey)
+	userMetadata = withObjectMetadata(userMetadata, objectNameKey, in.EncryptedName)
+	uploadID, err := s.blobs.NewMultipartUpload(ctx, userID, in.Filename, userMetadata)
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "upload start failed: %v", err)
+	}
+
+	session := &uploadSession{
+		id:            uuid.NewString(),
+		userID:        userID,
+		objectName:    in.Filename,
+		metadata:      in.Metadata,
+		fileKey:       in.FileKey,
+		encryptedName: in.EncryptedName,
+		uploadID:      uploadID,
+		digest:        in.Digest,
+		hash:          sha256.New(),
+	}
+	session.updatedAt.Store(time.Now().Unix())
+	session.mu.Lock()
+
+	s.uploadsMutex.Lock()
+	s.uploads[session.id] = session
+	s.uploadsMutex.Unlock()
+	return session, nil
+}
+
+// closeUpload - forgets upload session, multipart upload is aborted unless it was completed
+func (s *GrpcServer) closeUpload(ctx context.Context, session *uploadSession, abort bool) {
+	s.uploadsMutex.Lock()
+	delete(s.uploads, session.id)
+	s.uploadsMutex.Unlock()
+
+	if abort {
+		err := s.blobs.AbortMultipartUpload(ctx, session.userID, session.objectName, session.uploadID)
+		if err != nil {
+			logger.Log().Error("multipart upload abort failed", zap.String("upload", session.id), zap.Error(err))
+		}
+	}
+}
+
+// expireUploads - aborts upload sessions which were idle longer than uploadSessionTTL
+func (s *GrpcServer) expireUploads(ctx context.Context) {
+	deadline := time.Now().Add(-uploadSessionTTL).Unix()
+
+	s.uploadsMutex.Lock()
+	var expired []*uploadSession
+	for _, session := range s.uploads {
+		if session.updatedAt.Load() < deadline && session.mu.TryLock() {
+			expired = append(expired, session)
+		}
+	}
+	s.uploadsMutex.Unlock()
+
+	for _, session := range expired {
+		s.closeUpload(ctx, session, true)
+		session.mu.Unlock()
+	}
+}
@@ -2,5 +2,7 @@ package db
 
 import "embed"
 
-//go:embed migrations/*.sql
+// MigrationsFS - migrations of Postgres in migrations directory and of embedded SQLite in migrations/sqlite
+//
+//go:embed migrations/*.sql migrations/sqlite/*.sql
 var MigrationsFS embed.FS
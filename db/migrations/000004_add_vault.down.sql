@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP COLUMN IF EXISTS vault_salt,
+    DROP COLUMN IF EXISTS vault_key_check;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS vault_salt VARCHAR,
+    ADD COLUMN IF NOT EXISTS vault_key_check VARCHAR;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS item_metadata (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id uuid NOT NULL,
+    item_type VARCHAR NOT NULL,
+    key VARCHAR NOT NULL,
+    value VARCHAR NOT NULL,
+    user_id uuid references users(id),
+    UNIQUE (item_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS item_metadata_user_id_item_type_idx ON item_metadata(user_id, item_type);
@@ -0,0 +1,3 @@
+ALTER TABLE credentials ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE cards ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE notes ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
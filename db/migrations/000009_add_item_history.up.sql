@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS credentials_history (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id uuid NOT NULL,
+    service_name VARCHAR NOT NULL,
+    identity VARCHAR NOT NULL,
+    password VARCHAR NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP WITH TIME ZONE,
+    revised_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id uuid references users(id)
+);
+
+CREATE TABLE IF NOT EXISTS cards_history (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id uuid NOT NULL,
+    number VARCHAR NOT NULL,
+    expiration_date VARCHAR NOT NULL,
+    holder_name VARCHAR NOT NULL,
+    cvv VARCHAR NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP WITH TIME ZONE,
+    revised_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id uuid references users(id)
+);
+
+CREATE TABLE IF NOT EXISTS notes_history (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id uuid NOT NULL,
+    title VARCHAR NOT NULL,
+    body TEXT NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP WITH TIME ZONE,
+    revised_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id uuid references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS credentials_history_user_id_item_id_idx ON credentials_history(user_id, item_id);
+CREATE INDEX IF NOT EXISTS cards_history_user_id_item_id_idx ON cards_history(user_id, item_id);
+CREATE INDEX IF NOT EXISTS notes_history_user_id_item_id_idx ON notes_history(user_id, item_id);
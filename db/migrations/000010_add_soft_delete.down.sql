@@ -0,0 +1,3 @@
+ALTER TABLE notes DROP COLUMN deleted_at;
+ALTER TABLE cards DROP COLUMN deleted_at;
+ALTER TABLE credentials DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE credentials ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE cards ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notes ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
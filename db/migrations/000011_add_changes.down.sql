@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS item_metadata_changes ON item_metadata;
+DROP TRIGGER IF EXISTS notes_changes ON notes;
+DROP TRIGGER IF EXISTS cards_changes ON cards;
+DROP TRIGGER IF EXISTS credentials_changes ON credentials;
+DROP FUNCTION IF EXISTS log_item_metadata_change();
+DROP FUNCTION IF EXISTS log_item_change();
+DROP TABLE IF EXISTS changes;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS changes (
+    seq BIGSERIAL PRIMARY KEY,
+    item_type VARCHAR NOT NULL,
+    item_id VARCHAR NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id uuid references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS changes_user_id_seq_idx ON changes(user_id, seq);
+
+CREATE OR REPLACE FUNCTION log_item_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (item_type, item_id, user_id) VALUES (TG_ARGV[0], OLD.id::text, OLD.user_id);
+        RETURN OLD;
+    END IF;
+    INSERT INTO changes (item_type, item_id, user_id) VALUES (TG_ARGV[0], NEW.id::text, NEW.user_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION log_item_metadata_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO changes (item_type, item_id, user_id) VALUES (OLD.item_type, OLD.item_id::text, OLD.user_id);
+        RETURN OLD;
+    END IF;
+    INSERT INTO changes (item_type, item_id, user_id) VALUES (NEW.item_type, NEW.item_id::text, NEW.user_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER credentials_changes AFTER INSERT OR UPDATE OR DELETE ON credentials
+    FOR EACH ROW EXECUTE FUNCTION log_item_change('credentials');
+CREATE TRIGGER cards_changes AFTER INSERT OR UPDATE OR DELETE ON cards
+    FOR EACH ROW EXECUTE FUNCTION log_item_change('card');
+CREATE TRIGGER notes_changes AFTER INSERT OR UPDATE OR DELETE ON notes
+    FOR EACH ROW EXECUTE FUNCTION log_item_change('note');
+CREATE TRIGGER item_metadata_changes AFTER INSERT OR UPDATE OR DELETE ON item_metadata
+    FOR EACH ROW EXECUTE FUNCTION log_item_metadata_change();
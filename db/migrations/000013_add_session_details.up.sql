@@ -0,0 +1,5 @@
+ALTER TABLE sessions ADD COLUMN client_version VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN ip VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE sessions SET last_seen_at=refreshed_at;
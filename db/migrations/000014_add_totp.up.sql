@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN totp_secret VARCHAR;
+ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    user_id uuid NOT NULL references users(id),
+    code_hash VARCHAR NOT NULL,
+    PRIMARY KEY (user_id, code_hash)
+);
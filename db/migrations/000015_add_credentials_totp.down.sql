@@ -0,0 +1,2 @@
+ALTER TABLE credentials_history DROP COLUMN totp;
+ALTER TABLE credentials DROP COLUMN totp;
@@ -0,0 +1,2 @@
+ALTER TABLE credentials ADD COLUMN totp VARCHAR NOT NULL DEFAULT '';
+ALTER TABLE credentials_history ADD COLUMN totp VARCHAR NOT NULL DEFAULT '';
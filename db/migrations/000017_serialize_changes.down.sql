@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS changes_serialize ON changes;
+DROP FUNCTION IF EXISTS serialize_change();
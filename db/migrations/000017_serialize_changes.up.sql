@@ -0,0 +1,13 @@
+-- seq of BIGSERIAL is taken before commit, so concurrent transactions of one user may commit out of seq order
+-- and sync cursor would pass changes which are not visible yet. Writers of user's changes are serialised until
+-- commit and seq is taken only under the lock, so seq order of user's changes is their commit order.
+CREATE OR REPLACE FUNCTION serialize_change() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtextextended('changes:' || COALESCE(NEW.user_id::text, ''), 0));
+    NEW.seq := nextval(pg_get_serial_sequence('changes', 'seq'));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER changes_serialize BEFORE INSERT ON changes
+    FOR EACH ROW EXECUTE FUNCTION serialize_change();
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS credentials (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    service_name VARCHAR NOT NULL,
+    identity VARCHAR NOT NULL,
+    password VARCHAR NOT NULL,
+    user_id TEXT references users(id),
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
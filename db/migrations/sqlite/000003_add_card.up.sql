@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS cards (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    number VARCHAR NOT NULL,
+    expiration_date VARCHAR NOT NULL,
+    holder_name VARCHAR NOT NULL,
+    cvv VARCHAR NOT NULL,
+    user_id TEXT references users(id),
+    uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
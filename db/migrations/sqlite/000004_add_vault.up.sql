@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN vault_salt VARCHAR;
+ALTER TABLE users ADD COLUMN vault_key_check VARCHAR;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN data_key VARCHAR;
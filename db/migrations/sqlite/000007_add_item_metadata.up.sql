@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS item_metadata (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    item_id TEXT NOT NULL,
+    item_type VARCHAR NOT NULL,
+    key VARCHAR NOT NULL,
+    value VARCHAR NOT NULL,
+    user_id TEXT references users(id),
+    UNIQUE (item_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS item_metadata_user_id_item_type_idx ON item_metadata(user_id, item_type);
@@ -0,0 +1,3 @@
+ALTER TABLE notes DROP COLUMN version;
+ALTER TABLE cards DROP COLUMN version;
+ALTER TABLE credentials DROP COLUMN version;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notes_history;
+DROP TABLE IF EXISTS cards_history;
+DROP TABLE IF EXISTS credentials_history;
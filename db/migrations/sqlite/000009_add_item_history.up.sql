@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS credentials_history (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    item_id TEXT NOT NULL,
+    service_name VARCHAR NOT NULL,
+    identity VARCHAR NOT NULL,
+    password VARCHAR NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP,
+    revised_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id TEXT references users(id)
+);
+
+CREATE TABLE IF NOT EXISTS cards_history (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    item_id TEXT NOT NULL,
+    number VARCHAR NOT NULL,
+    expiration_date VARCHAR NOT NULL,
+    holder_name VARCHAR NOT NULL,
+    cvv VARCHAR NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP,
+    revised_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id TEXT references users(id)
+);
+
+CREATE TABLE IF NOT EXISTS notes_history (
+    id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+    item_id TEXT NOT NULL,
+    title VARCHAR NOT NULL,
+    body TEXT NOT NULL,
+    metadata JSON,
+    version BIGINT NOT NULL,
+    uploaded_at TIMESTAMP,
+    revised_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    user_id TEXT references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS credentials_history_user_id_item_id_idx ON credentials_history(user_id, item_id);
+CREATE INDEX IF NOT EXISTS cards_history_user_id_item_id_idx ON cards_history(user_id, item_id);
+CREATE INDEX IF NOT EXISTS notes_history_user_id_item_id_idx ON notes_history(user_id, item_id);
@@ -0,0 +1,3 @@
+ALTER TABLE credentials ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE cards ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE notes ADD COLUMN deleted_at TIMESTAMP;
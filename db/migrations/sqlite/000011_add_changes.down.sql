@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS item_metadata_changes_delete;
+DROP TRIGGER IF EXISTS item_metadata_changes_update;
+DROP TRIGGER IF EXISTS item_metadata_changes_insert;
+DROP TRIGGER IF EXISTS notes_changes_delete;
+DROP TRIGGER IF EXISTS notes_changes_update;
+DROP TRIGGER IF EXISTS notes_changes_insert;
+DROP TRIGGER IF EXISTS cards_changes_delete;
+DROP TRIGGER IF EXISTS cards_changes_update;
+DROP TRIGGER IF EXISTS cards_changes_insert;
+DROP TRIGGER IF EXISTS credentials_changes_delete;
+DROP TRIGGER IF EXISTS credentials_changes_update;
+DROP TRIGGER IF EXISTS credentials_changes_insert;
+DROP TABLE IF EXISTS changes;
@@ -0,0 +1,69 @@
+CREATE TABLE IF NOT EXISTS changes (
+    seq INTEGER PRIMARY KEY AUTOINCREMENT,
+    item_type VARCHAR NOT NULL,
+    item_id VARCHAR NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id TEXT references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS changes_user_id_seq_idx ON changes(user_id, seq);
+
+CREATE TRIGGER IF NOT EXISTS credentials_changes_insert AFTER INSERT ON credentials
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('credentials', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS credentials_changes_update AFTER UPDATE ON credentials
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('credentials', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS credentials_changes_delete AFTER DELETE ON credentials
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('credentials', OLD.id, OLD.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_changes_insert AFTER INSERT ON cards
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('card', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_changes_update AFTER UPDATE ON cards
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('card', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_changes_delete AFTER DELETE ON cards
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('card', OLD.id, OLD.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_changes_insert AFTER INSERT ON notes
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('note', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_changes_update AFTER UPDATE ON notes
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('note', NEW.id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_changes_delete AFTER DELETE ON notes
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES ('note', OLD.id, OLD.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_metadata_changes_insert AFTER INSERT ON item_metadata
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES (NEW.item_type, NEW.item_id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_metadata_changes_update AFTER UPDATE ON item_metadata
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES (NEW.item_type, NEW.item_id, NEW.user_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS item_metadata_changes_delete AFTER DELETE ON item_metadata
+BEGIN
+    INSERT INTO changes (item_type, item_id, user_id) VALUES (OLD.item_type, OLD.item_id, OLD.user_id);
+END;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN vault_key VARCHAR;
@@ -0,0 +1,359 @@
+// Package archive reads and writes password protected backups of vault, archive does not depend on account it was
+// exported from, so it can be restored into any account.
+//
+// Archive of version 1 is laid out as follows:
+//
+//	gophkeeper-archive\n
+//	{"version":1,"salt":"<base64 salt>"}\n
+//	<payload encrypted by vault.EncryptStream>
+//
+// Payload is encrypted with key derived by vault.DeriveKey from archive password and salt of header, it is
+// independent of master password of account. Decrypted payload is tar stream with entries in this order:
+//
+//	manifest.json    - Manifest: format, version, time of export, account, counts of items and list of files
+//	credentials.json - JSON array of models.Credentials
+//	cards.json       - JSON array of models.Card
+//	notes.json       - JSON array of models.Note
+//	files/000001     - decrypted content of files listed by manifest, in the same order
+//
+// Items and files are stored decrypted inside payload, they are sealed again by vault key of account on import.
+package archive
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+)
+
+// Parameters of archive format
+const (
+	Magic   = "gophkeeper-archive"
+	Version = 1
+
+	manifestEntry    = "manifest.json"
+	credentialsEntry = "credentials.json"
+	cardsEntry       = "cards.json"
+	notesEntry       = "notes.json"
+	filesDir         = "files/"
+)
+
+var (
+	// ErrFormat - error when data is not GophKeeper archive or its version is not supported
+	ErrFormat = errors.New("not a GophKeeper archive")
+	// ErrDecrypt - error when payload of archive can not be decrypted
+	ErrDecrypt = errors.New("archive password is wrong or archive is damaged")
+)
+
+// header - unencrypted part of archive needed to derive its key
+type header struct {
+	Version int    `json:"version"`
+	Salt    string `json:"salt"`
+}
+
+// File - file of vault in archive
+type File struct {
+	// Path - name of tar entry with content
+	Path       string            `json:"path"`
+	Name       string            `json:"name"`
+	Size       int64             `json:"size"`
+	SHA256     string            `json:"sha256"`
+	UploadedAt time.Time         `json:"uploaded_at"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	// Source - local file with decrypted content, it is read by Write and set to extracted file by Read
+	Source string `json:"-"`
+}
+
+// Manifest - description of archive contents
+type Manifest struct {
+	Format      string    `json:"format"`
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	Account     string    `json:"account"`
+	Credentials int       `json:"credentials"`
+	Cards       int       `json:"cards"`
+	Notes       int       `json:"notes"`
+	Files       []File    `json:"files"`
+}
+
+// Vault - contents of archive
+type Vault struct {
+	Manifest    Manifest
+	Credentials []models.Credentials
+	Cards       []models.Card
+	Notes       []models.Note
+	// dir - temporary directory with files extracted by Read
+	dir string
+}
+
+// Write - build archive of vault encrypted by password into dst. Files are read from their Source, manifest of
+// contents is filled with counts of items and paths, sizes and digests of files.
+func Write(dst io.Writer, password string, contents *Vault) error {
+	manifest := &contents.Manifest
+	manifest.Format = Magic
+	manifest.Version = Version
+	manifest.Credentials = len(contents.Credentials)
+	manifest.Cards = len(contents.Cards)
+	manifest.Notes = len(contents.Notes)
+	for i := range manifest.Files {
+		file := &manifest.Files[i]
+		size, digest, err := fileDigest(file.Source)
+		if err != nil {
+			return err
+		}
+		file.Path = fmt.Sprintf("%s%06d", filesDir, i+1)
+		file.Size = size
+		file.SHA256 = digest
+	}
+
+	salt, err := vault.NewSalt()
+	if err != nil {
+		return err
+	}
+	key, err := vault.DeriveKey(password, salt)
+	if err != nil {
+		return err
+	}
+	rawHeader, err := json.Marshal(header{Version: Version, Salt: salt})
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(dst, "%s\n%s\n", Magic, rawHeader); err != nil {
+		return err
+	}
+
+	payload, payloadWriter := io.Pipe()
+	go func() {
+		payloadWriter.CloseWithError(writePayload(payloadWriter, manifest, contents))
+	}()
+	err = vault.EncryptStream(dst, payload, key)
+	_ = payload.CloseWithError(io.ErrClosedPipe)
+	return err
+}
+
+// writePayload - write tar stream of archive
+func writePayload(dst io.Writer, manifest *Manifest, contents *Vault) error {
+	tw := tar.NewWriter(dst)
+	entries := []struct {
+		name  string
+		value any
+	}{
+		{manifestEntry, manifest},
+		{credentialsEntry, nonNil(contents.Credentials)},
+		{cardsEntry, nonNil(contents.Cards)},
+		{notesEntry, nonNil(contents.Notes)},
+	}
+	for _, entry := range entries {
+		data, err := json.MarshalIndent(entry.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err = tw.WriteHeader(tarHeader(entry.name, int64(len(data)), manifest.CreatedAt)); err != nil {
+			return err
+		}
+		if _, err = tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range manifest.Files {
+		if err := writeFile(tw, file, manifest.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeFile(tw *tar.Writer, file File, modTime time.Time) error {
+	source, err := os.Open(file.Source)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	if err = tw.WriteHeader(tarHeader(file.Path, file.Size, modTime)); err != nil {
+		return err
+	}
+	if _, err = io.Copy(tw, source); err != nil {
+		return fmt.Errorf("file %s can not be archived: %w", file.Name, err)
+	}
+	return nil
+}
+
+func tarHeader(name string, size int64, modTime time.Time) *tar.Header {
+	return &tar.Header{Name: name, Mode: 0o600, Size: size, ModTime: modTime, Format: tar.FormatPAX}
+}
+
+// nonNil - keep empty lists of items as JSON arrays
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func fileDigest(path string) (int64, string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer source.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, source)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Read - decrypt archive by password and extract its files into temporary directory, Vault has to be closed to
+// remove them. Nothing is kept if archive can not be read completely.
+func Read(src io.Reader, password string) (*Vault, error) {
+	reader := bufio.NewReader(src)
+	magic, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSuffix(magic, "\n") != Magic {
+		return nil, ErrFormat
+	}
+	rawHeader, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, ErrFormat
+	}
+	var head header
+	if err = json.Unmarshal(rawHeader, &head); err != nil {
+		return nil, ErrFormat
+	}
+	if head.Version != Version {
+		return nil, fmt.Errorf("%w: version %d is not supported", ErrFormat, head.Version)
+	}
+	key, err := vault.DeriveKey(password, head.Salt)
+	if err != nil {
+		return nil, ErrFormat
+	}
+
+	dir, err := os.MkdirTemp("", "gophkeeper-import-")
+	if err != nil {
+		return nil, err
+	}
+	contents := &Vault{dir: dir}
+
+	payload, payloadWriter := io.Pipe()
+	decrypted := make(chan error, 1)
+	go func() {
+		err := vault.DecryptStream(payloadWriter, reader, key)
+		if err != nil {
+			err = ErrDecrypt
+		}
+		payloadWriter.CloseWithError(err)
+		decrypted <- err
+	}()
+
+	err = contents.readPayload(payload)
+	// tar stream ends before padding of payload, the rest is read to authenticate the last chunk
+	if _, drainErr := io.Copy(io.Discard, payload); err == nil {
+		err = drainErr
+	}
+	_ = payload.CloseWithError(io.ErrClosedPipe)
+	if decryptErr := <-decrypted; decryptErr != nil {
+		err = decryptErr
+	}
+	if err != nil {
+		_ = contents.Close()
+		return nil, err
+	}
+	return contents, nil
+}
+
+// readPayload - read entries of tar stream in order they are written
+func (v *Vault) readPayload(src io.Reader) error {
+	tr := tar.NewReader(src)
+	targets := []struct {
+		name  string
+		value any
+	}{
+		{manifestEntry, &v.Manifest},
+		{credentialsEntry, &v.Credentials},
+		{cardsEntry, &v.Cards},
+		{notesEntry, &v.Notes},
+	}
+	for _, target := range targets {
+		entry, err := tr.Next()
+		if err != nil {
+			return payloadError(err)
+		}
+		if entry.Name != target.name {
+			return fmt.Errorf("%w: unexpected entry %s", ErrFormat, entry.Name)
+		}
+		if err = json.NewDecoder(tr).Decode(target.value); err != nil {
+			return fmt.Errorf("%w: %s can not be decoded: %v", ErrFormat, entry.Name, err)
+		}
+	}
+	if v.Manifest.Format != Magic || v.Manifest.Version != Version {
+		return fmt.Errorf("%w: unexpected manifest", ErrFormat)
+	}
+
+	for i := range v.Manifest.Files {
+		file := &v.Manifest.Files[i]
+		entry, err := tr.Next()
+		if err != nil {
+			return payloadError(err)
+		}
+		if entry.Name != file.Path || !strings.HasPrefix(file.Path, filesDir) {
+			return fmt.Errorf("%w: unexpected entry %s", ErrFormat, entry.Name)
+		}
+		if file.Source, err = v.extract(tr, file); err != nil {
+			return err
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected entries after files", ErrFormat)
+	}
+	return nil
+}
+
+// extract - copy file content into temporary directory checking it against manifest
+func (v *Vault) extract(src io.Reader, file *File) (string, error) {
+	path := filepath.Join(v.dir, filepath.Base(file.Path))
+	target, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer target.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(target, hash), src)
+	if err != nil {
+		return "", payloadError(err)
+	}
+	if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return "", fmt.Errorf("%w: file %s does not match manifest", ErrFormat, file.Name)
+	}
+	return path, nil
+}
+
+// payloadError - report end of payload inside tar stream as damaged archive
+func payloadError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: payload is truncated", ErrFormat)
+	}
+	return err
+}
+
+// Close - remove files extracted by Read
+func (v *Vault) Close() error {
+	if v.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(v.dir)
+}
@@ -0,0 +1,92 @@
+package archive
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T) *Vault {
+	source := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(source, bytes.Repeat([]byte("report "), 20000), 0o600))
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	return &Vault{
+		Manifest: Manifest{
+			CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			Account:   "user@example.com",
+			Files: []File{
+				{Name: "report.pdf", Source: source, Metadata: map[string]string{"tag": "work"}},
+				{Name: "empty.txt", Source: empty},
+			},
+		},
+		Credentials: []models.Credentials{{ID: "1", ServiceName: "mail", Identity: "user", Password: "secret"}},
+		Notes:       []models.Note{{ID: "2", Title: "todo", Body: "buy milk"}},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	contents := newTestVault(t)
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, "archive password", contents))
+	assert.True(t, strings.HasPrefix(buffer.String(), Magic+"\n{\"version\":1,"))
+	assert.NotContains(t, buffer.String(), "buy milk", "payload should be encrypted")
+
+	restored, err := Read(bytes.NewReader(buffer.Bytes()), "archive password")
+	require.NoError(t, err)
+	defer restored.Close()
+
+	assert.Equal(t, Magic, restored.Manifest.Format)
+	assert.Equal(t, "user@example.com", restored.Manifest.Account)
+	assert.Equal(t, 1, restored.Manifest.Credentials)
+	assert.Equal(t, 0, restored.Manifest.Cards)
+	assert.Equal(t, contents.Credentials, restored.Credentials)
+	assert.Empty(t, restored.Cards)
+	assert.Equal(t, contents.Notes, restored.Notes)
+	require.Len(t, restored.Manifest.Files, 2)
+	for i, file := range restored.Manifest.Files {
+		assert.Equal(t, contents.Manifest.Files[i].Name, file.Name)
+		assert.Equal(t, contents.Manifest.Files[i].Metadata, file.Metadata)
+		expected, _ := os.ReadFile(contents.Manifest.Files[i].Source)
+		actual, err := os.ReadFile(file.Source)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(expected, actual), "file %s", file.Name)
+		assert.Equal(t, int64(len(expected)), file.Size)
+	}
+
+	dir := filepath.Dir(restored.Manifest.Files[0].Source)
+	require.NoError(t, restored.Close())
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "extracted files should be removed on Close")
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, "archive password", newTestVault(t)))
+	data := buffer.Bytes()
+
+	_, err := Read(bytes.NewReader(data), "wrong password")
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	tampered := bytes.Clone(data)
+	tampered[len(tampered)-20] ^= 0xFF
+	_, err = Read(bytes.NewReader(tampered), "archive password")
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = Read(bytes.NewReader(data[:len(data)-100]), "archive password")
+	assert.ErrorIs(t, err, ErrDecrypt, "truncated archive should not be accepted")
+
+	_, err = Read(strings.NewReader("id,name\n1,mail\n"), "archive password")
+	assert.ErrorIs(t, err, ErrFormat)
+
+	_, err = Read(strings.NewReader(Magic+"\n{\"version\":2,\"salt\":\"c2FsdA==\"}\n"), "archive password")
+	assert.ErrorIs(t, err, ErrFormat)
+}
@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -12,19 +16,27 @@ type Claims struct {
 	jwt.RegisteredClaims
 	UserID    string
 	SessionID string
+	// TOTPPending - user passed password check and has to confirm sign in by TOTP code
+	TOTPPending bool `json:",omitempty"`
 }
 
 // Parameters for JWT tokens generation/parsing
 const (
-	// TokenExp - JWT token expiration time in microseconds
-	TokenExp = time.Hour * 3
+	// AccessTokenExp - JWT access token expiration time, client refreshes it by refresh token
+	AccessTokenExp = time.Minute * 15
+	// RefreshTokenExp - how long session is kept without refresh
+	RefreshTokenExp = time.Hour * 24 * 30
+	// PartialTokenExp - how long sign in waits for TOTP code
+	PartialTokenExp = time.Minute * 5
+
+	refreshTokenLength = 32
 )
 
 // BuildJWTString - generate JWT string from UserID
 func BuildJWTString(userID, sessionID, secretKey string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenExp)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExp)),
 		},
 		UserID:    userID,
 		SessionID: sessionID,
@@ -35,21 +47,50 @@ func BuildJWTString(userID, sessionID, secretKey string) (string, error) {
 	return tokenString, err
 }
 
-// GetUserID - parse JWT string and return UserID
-func GetUserID(tokenString, secretKey string) string {
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims,
-		func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return []byte(secretKey), nil
-		})
-	if err != nil {
+// BuildPartialToken - generate JWT string which only proves password of user, it is exchanged for session
+// when TOTP code is verified
+func BuildPartialToken(userID, secretKey string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(PartialTokenExp)),
+		},
+		UserID:      userID,
+		TOTPPending: true,
+	})
+
+	return token.SignedString([]byte(secretKey))
+}
+
+// GetPartialUserID - parse partial JWT string and return UserID, tokens of sessions are not accepted
+func GetPartialUserID(tokenString, secretKey string) string {
+	claims, ok := parseClaims(tokenString, secretKey)
+	if !ok || !claims.TOTPPending {
 		return ""
 	}
 
-	if !token.Valid {
+	return claims.UserID
+}
+
+// NewRefreshToken - generate random refresh token and its hash, which is the only thing server keeps
+func NewRefreshToken() (token, hash string, err error) {
+	raw := make([]byte, refreshTokenLength)
+	if _, err = rand.Read(raw); err != nil {
+		return
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken - hash under which refresh token is stored
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetUserID - parse JWT string and return UserID, partial tokens are not accepted
+func GetUserID(tokenString, secretKey string) string {
+	claims, ok := parseClaims(tokenString, secretKey)
+	if !ok || claims.TOTPPending {
 		return ""
 	}
 
@@ -58,6 +99,16 @@ func GetUserID(tokenString, secretKey string) string {
 
 // GetSessionID - parse JWT string and return SessionID
 func GetSessionID(tokenString, secretKey string) string {
+	claims, ok := parseClaims(tokenString, secretKey)
+	if !ok {
+		return ""
+	}
+
+	return claims.SessionID
+}
+
+// parseClaims - parse JWT string signed by secretKey
+func parseClaims(tokenString, secretKey string) (*Claims, bool) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims,
 		func(t *jwt.Token) (interface{}, error) {
@@ -66,15 +117,11 @@ func GetSessionID(tokenString, secretKey string) string {
 			}
 			return []byte(secretKey), nil
 		})
-	if err != nil {
-		return ""
+	if err != nil || !token.Valid {
+		return nil, false
 	}
 
-	if !token.Valid {
-		return ""
-	}
-
-	return claims.SessionID
+	return claims, true
 }
 
 func IsValidToken(tokenString string, secret string) (bool, error) {
@@ -153,3 +153,47 @@ func TestIsValidToken(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRefreshToken(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token == "" || hash == token {
+		t.Errorf("Refresh token must not be empty or stored as is, got token %q, hash %q", token, hash)
+	}
+	if hash != HashRefreshToken(token) {
+		t.Errorf("Hash mismatch. Expected: %s, Got: %s", HashRefreshToken(token), hash)
+	}
+
+	other, _, err := NewRefreshToken()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if other == token {
+		t.Errorf("Refresh tokens must be unique, got %q twice", token)
+	}
+}
+
+func TestPartialToken(t *testing.T) {
+	secretKey := "mysecret"
+
+	partial, err := BuildPartialToken("user1", secretKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := GetPartialUserID(partial, secretKey); got != "user1" {
+		t.Errorf("GetPartialUserID() = %q, want user1", got)
+	}
+	if got := GetUserID(partial, secretKey); got != "" {
+		t.Errorf("GetUserID() of partial token = %q, want it rejected", got)
+	}
+	if got := GetPartialUserID(partial, "other"); got != "" {
+		t.Errorf("GetPartialUserID() with wrong secret = %q, want it rejected", got)
+	}
+
+	full, _ := BuildJWTString("user1", "session1", secretKey)
+	if got := GetPartialUserID(full, secretKey); got != "" {
+		t.Errorf("GetPartialUserID() of session token = %q, want it rejected", got)
+	}
+}
@@ -0,0 +1,59 @@
+// Package blobstore keeps users' files. Every user has own bucket, objects are addressed by key inside it.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	// ErrNotFound - error when object, bucket or upload does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey - error when bucket name or object key can not be used
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// ObjectInfo - description of stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	// UserMetadata - metadata stored along with object, keys are given without protocol prefixes
+	UserMetadata map[string]string
+}
+
+// Part - committed part of multipart upload
+type Part struct {
+	Number int
+	ETag   string
+	Size   int64
+}
+
+// BlobStore - storage of users' files
+type BlobStore interface {
+	MakeBucket(ctx context.Context, bucket string) error
+	ListBuckets(ctx context.Context) ([]string, error)
+	// RemoveBucket - remove bucket with all its objects and unfinished uploads, missing bucket is not an error
+	RemoveBucket(ctx context.Context, bucket string) error
+
+	// StatObject - description of object
+	StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error)
+	// ListObjects - objects with keys starting with prefix, including ones in nested prefixes
+	ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
+	// GetObject - content of object starting from offset, length <= 0 means up to end of object
+	GetObject(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error)
+	// CopyObject - copy object along with its metadata to new key
+	CopyObject(ctx context.Context, bucket, from, to string) error
+	// ReplaceMetadata - replace whole user metadata of object
+	ReplaceMetadata(ctx context.Context, bucket, key string, userMetadata map[string]string) (ObjectInfo, error)
+	RemoveObject(ctx context.Context, bucket, key string) error
+
+	// NewMultipartUpload - start upload of object by parts, object appears only when upload is completed
+	NewMultipartUpload(ctx context.Context, bucket, key string, userMetadata map[string]string) (uploadID string, err error)
+	PutPart(ctx context.Context, bucket, key, uploadID string, number int, data io.Reader, size int64) (Part, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error)
+	AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error
+}
@@ -0,0 +1,394 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Layout of bucket directory of FSStore
+const (
+	dataDir     = "data"
+	metadataDir = "metadata"
+	uploadsDir  = "uploads"
+	partFormat  = "part-%05d"
+)
+
+// FSStore - BlobStore keeping objects in local directory, every bucket is subdirectory of root. Content of object
+// is kept under data and its description under metadata directory of bucket, files are replaced atomically.
+type FSStore struct {
+	root string
+}
+
+// fsMetadata - description of object stored next to its content
+type fsMetadata struct {
+	ETag         string            `json:"etag"`
+	UserMetadata map[string]string `json:"user_metadata,omitempty"`
+}
+
+// NewFSStore - creates BlobStore in root directory
+func NewFSStore(root string) (*FSStore, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	return &FSStore{root: root}, nil
+}
+
+// bucketPath - directory of bucket, bucket names can not leave root
+func (s *FSStore) bucketPath(bucket string) (string, error) {
+	if bucket == "" || bucket == "." || bucket == ".." || strings.ContainsAny(bucket, `/\`) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, bucket), nil
+}
+
+// objectPath - file of object in one of bucket directories, keys are slash separated relative paths
+func (s *FSStore) objectPath(bucket, dir, key string) (string, error) {
+	bucketPath, err := s.bucketPath(bucket)
+	if err != nil {
+		return "", err
+	}
+	if key == "" || strings.Contains(key, `\`) || path.Clean("/"+key) != "/"+key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(bucketPath, dir, filepath.FromSlash(key)), nil
+}
+
+// writeFile - atomically replace file with content of reader
+func writeFile(name string, content io.Reader) (written int64, checksum string, err error) {
+	if err = os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		return
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	written, err = io.Copy(io.MultiWriter(file, hash), content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+	return written, hex.EncodeToString(hash.Sum(nil)), os.Rename(file.Name(), name)
+}
+
+func writeJSON(name string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, _, err = writeFile(name, strings.NewReader(string(encoded)))
+	return err
+}
+
+func readJSON(name string, value any) error {
+	encoded, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, value)
+}
+
+func (s *FSStore) MakeBucket(_ context.Context, bucket string) error {
+	bucketPath, err := s.bucketPath(bucket)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(filepath.Join(bucketPath, dataDir), 0700)
+}
+
+func (s *FSStore) ListBuckets(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+	var buckets []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			buckets = append(buckets, entry.Name())
+		}
+	}
+	return buckets, nil
+}
+
+func (s *FSStore) RemoveBucket(_ context.Context, bucket string) error {
+	bucketPath, err := s.bucketPath(bucket)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(bucketPath)
+}
+
+func (s *FSStore) StatObject(_ context.Context, bucket, key string) (ObjectInfo, error) {
+	dataPath, err := s.objectPath(bucket, dataDir, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	metadataPath, _ := s.objectPath(bucket, metadataDir, key)
+
+	stat, err := os.Stat(dataPath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && stat.IsDir()) {
+		return ObjectInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	var metadata fsMetadata
+	if err = readJSON(metadataPath, &metadata); err != nil && !errors.Is(err, ErrNotFound) {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		LastModified: stat.ModTime(),
+		ETag:         metadata.ETag,
+		UserMetadata: metadata.UserMetadata,
+	}, nil
+}
+
+func (s *FSStore) ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	bucketPath, err := s.bucketPath(bucket)
+	if err != nil {
+		return nil, err
+	}
+	dataPath := filepath.Join(bucketPath, dataDir)
+
+	var objects []ObjectInfo
+	err = filepath.WalkDir(dataPath, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		relative, err := filepath.Rel(dataPath, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		object, err := s.StatObject(ctx, bucket, key)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return objects, err
+}
+
+// fileSection - part of file which is closed along with reader
+type fileSection struct {
+	io.Reader
+	file *os.File
+}
+
+func (f fileSection) Close() error {
+	return f.file.Close()
+}
+
+func (s *FSStore) GetObject(_ context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	dataPath, err := s.objectPath(bucket, dataDir, key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(dataPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	var reader io.Reader = file
+	if length > 0 {
+		reader = io.LimitReader(file, length)
+	}
+	return fileSection{Reader: reader, file: file}, nil
+}
+
+func (s *FSStore) CopyObject(ctx context.Context, bucket, from, to string) error {
+	object, err := s.StatObject(ctx, bucket, from)
+	if err != nil {
+		return err
+	}
+	content, err := s.GetObject(ctx, bucket, from, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return s.putObject(bucket, to, content, object.UserMetadata)
+}
+
+// putObject - store object content and metadata
+func (s *FSStore) putObject(bucket, key string, content io.Reader, userMetadata map[string]string) error {
+	dataPath, err := s.objectPath(bucket, dataDir, key)
+	if err != nil {
+		return err
+	}
+	metadataPath, _ := s.objectPath(bucket, metadataDir, key)
+
+	_, checksum, err := writeFile(dataPath, content)
+	if err != nil {
+		return err
+	}
+	return writeJSON(metadataPath, fsMetadata{ETag: checksum, UserMetadata: userMetadata})
+}
+
+func (s *FSStore) ReplaceMetadata(ctx context.Context, bucket, key string, userMetadata map[string]string) (ObjectInfo, error) {
+	object, err := s.StatObject(ctx, bucket, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	metadataPath, _ := s.objectPath(bucket, metadataDir, key)
+	if err = writeJSON(metadataPath, fsMetadata{ETag: object.ETag, UserMetadata: userMetadata}); err != nil {
+		return ObjectInfo{}, err
+	}
+	object.UserMetadata = userMetadata
+	object.LastModified = time.Now()
+	return object, nil
+}
+
+func (s *FSStore) RemoveObject(_ context.Context, bucket, key string) error {
+	dataPath, err := s.objectPath(bucket, dataDir, key)
+	if err != nil {
+		return err
+	}
+	metadataPath, _ := s.objectPath(bucket, metadataDir, key)
+
+	if err = os.Remove(dataPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err = os.Remove(metadataPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// fsUpload - description of multipart upload
+type fsUpload struct {
+	Key          string            `json:"key"`
+	UserMetadata map[string]string `json:"user_metadata,omitempty"`
+}
+
+// uploadPath - directory of multipart upload
+func (s *FSStore) uploadPath(bucket, key, uploadID string) (string, error) {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", ErrNotFound
+	}
+	bucketPath, err := s.bucketPath(bucket)
+	if err != nil {
+		return "", err
+	}
+	uploadPath := filepath.Join(bucketPath, uploadsDir, uploadID)
+	var upload fsUpload
+	if err = readJSON(filepath.Join(uploadPath, "upload.json"), &upload); err != nil {
+		return "", err
+	}
+	if upload.Key != key {
+		return "", ErrNotFound
+	}
+	return uploadPath, nil
+}
+
+func (s *FSStore) NewMultipartUpload(_ context.Context, bucket, key string, userMetadata map[string]string) (string, error) {
+	if _, err := s.objectPath(bucket, dataDir, key); err != nil {
+		return "", err
+	}
+	bucketPath, _ := s.bucketPath(bucket)
+	if _, err := os.Stat(bucketPath); err != nil {
+		return "", ErrNotFound
+	}
+
+	uploadID := uuid.NewString()
+	uploadPath := filepath.Join(bucketPath, uploadsDir, uploadID)
+	if err := writeJSON(filepath.Join(uploadPath, "upload.json"), fsUpload{Key: key, UserMetadata: userMetadata}); err != nil {
+		return "", err
+	}
+	return uploadID, nil
+}
+
+func (s *FSStore) PutPart(_ context.Context, bucket, key, uploadID string, number int, data io.Reader, size int64) (Part, error) {
+	uploadPath, err := s.uploadPath(bucket, key, uploadID)
+	if err != nil {
+		return Part{}, err
+	}
+	written, checksum, err := writeFile(filepath.Join(uploadPath, fmt.Sprintf(partFormat, number)), io.LimitReader(data, size))
+	if err != nil {
+		return Part{}, err
+	}
+	if written != size {
+		return Part{}, io.ErrUnexpectedEOF
+	}
+	return Part{Number: number, ETag: checksum, Size: written}, nil
+}
+
+func (s *FSStore) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error) {
+	uploadPath, err := s.uploadPath(bucket, key, uploadID)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	var upload fsUpload
+	if err = readJSON(filepath.Join(uploadPath, "upload.json"), &upload); err != nil {
+		return ObjectInfo{}, err
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		file, err := os.Open(filepath.Join(uploadPath, fmt.Sprintf(partFormat, part.Number)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return ObjectInfo{}, fmt.Errorf("part %d: %w", part.Number, ErrNotFound)
+		}
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	if err = s.putObject(bucket, key, io.MultiReader(readers...), upload.UserMetadata); err != nil {
+		return ObjectInfo{}, err
+	}
+	if err = os.RemoveAll(uploadPath); err != nil {
+		return ObjectInfo{}, err
+	}
+	return s.StatObject(ctx, bucket, key)
+}
+
+func (s *FSStore) AbortMultipartUpload(_ context.Context, bucket, key, uploadID string) error {
+	uploadPath, err := s.uploadPath(bucket, key, uploadID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(uploadPath)
+}
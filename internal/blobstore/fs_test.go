@@ -0,0 +1,131 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFSStore(t *testing.T) *FSStore {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, store.MakeBucket(context.Background(), "user"))
+	return store
+}
+
+func readObject(t *testing.T, store BlobStore, key string, offset, length int64) string {
+	object, err := store.GetObject(context.Background(), "user", key, offset, length)
+	require.NoError(t, err)
+	defer object.Close()
+	content, err := io.ReadAll(object)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestFSStore_MultipartUpload(t *testing.T) {
+	ctx := context.Background()
+	store := newTestFSStore(t)
+
+	uploadID, err := store.NewMultipartUpload(ctx, "user", "report.txt", map[string]string{"Sha256": "abc"})
+	require.NoError(t, err)
+	first, err := store.PutPart(ctx, "user", "report.txt", uploadID, 1, strings.NewReader("gophkeeper "), 11)
+	require.NoError(t, err)
+	second, err := store.PutPart(ctx, "user", "report.txt", uploadID, 2, strings.NewReader("report"), 6)
+	require.NoError(t, err)
+
+	_, err = store.StatObject(ctx, "user", "report.txt")
+	require.ErrorIs(t, err, ErrNotFound, "object should appear only after upload is completed")
+
+	object, err := store.CompleteMultipartUpload(ctx, "user", "report.txt", uploadID, []Part{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, int64(17), object.Size)
+	assert.Equal(t, map[string]string{"Sha256": "abc"}, object.UserMetadata)
+	assert.Equal(t, "gophkeeper report", readObject(t, store, "report.txt", 0, 0))
+	assert.Equal(t, "keeper", readObject(t, store, "report.txt", 4, 6))
+	assert.Equal(t, "report", readObject(t, store, "report.txt", 11, 0))
+
+	_, err = store.PutPart(ctx, "user", "report.txt", uploadID, 3, strings.NewReader("late"), 4)
+	require.ErrorIs(t, err, ErrNotFound, "completed upload should be removed")
+
+	aborted, err := store.NewMultipartUpload(ctx, "user", "draft.txt", nil)
+	require.NoError(t, err)
+	_, err = store.PutPart(ctx, "user", "other.txt", aborted, 1, strings.NewReader("data"), 4)
+	require.ErrorIs(t, err, ErrNotFound, "upload belongs to other key")
+	_, err = store.PutPart(ctx, "user", "draft.txt", aborted, 1, strings.NewReader("da"), 4)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	require.NoError(t, store.AbortMultipartUpload(ctx, "user", "draft.txt", aborted))
+	_, err = store.CompleteMultipartUpload(ctx, "user", "draft.txt", aborted, nil)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFSStore_Objects(t *testing.T) {
+	ctx := context.Background()
+	store := newTestFSStore(t)
+	require.NoError(t, store.putObject("user", "report.txt", bytes.NewReader([]byte("report")), map[string]string{"Metadata": "e30="}))
+
+	require.NoError(t, store.CopyObject(ctx, "user", "report.txt", ".trash/report.txt"))
+	require.NoError(t, store.RemoveObject(ctx, "user", "report.txt"))
+	_, err := store.StatObject(ctx, "user", "report.txt")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	trashed, err := store.ListObjects(ctx, "user", ".trash/")
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, ".trash/report.txt", trashed[0].Key)
+	assert.Equal(t, map[string]string{"Metadata": "e30="}, trashed[0].UserMetadata)
+	assert.Equal(t, "report", readObject(t, store, ".trash/report.txt", 0, 0))
+
+	updated, err := store.ReplaceMetadata(ctx, "user", ".trash/report.txt", nil)
+	require.NoError(t, err)
+	assert.Nil(t, updated.UserMetadata)
+	all, err := store.ListObjects(ctx, "user", "")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Nil(t, all[0].UserMetadata)
+
+	buckets, err := store.ListBuckets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, buckets)
+}
+
+func TestFSStore_RemoveBucket(t *testing.T) {
+	ctx := context.Background()
+	store := newTestFSStore(t)
+	require.NoError(t, store.MakeBucket(ctx, "other"))
+	require.NoError(t, store.putObject("user", "report.txt", bytes.NewReader([]byte("report")), nil))
+	_, err := store.NewMultipartUpload(ctx, "user", "draft.txt", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, store.RemoveBucket(ctx, "user"))
+	require.NoError(t, store.RemoveBucket(ctx, "user"), "missing bucket is already removed")
+	_, err = store.ListObjects(ctx, "user", "")
+	require.ErrorIs(t, err, ErrNotFound)
+	buckets, err := store.ListBuckets(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, buckets)
+}
+
+func TestFSStore_InvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	store := newTestFSStore(t)
+
+	for _, key := range []string{"", "../other/data/file", "/etc/passwd", "a/../../b", "dir/", `..\file`} {
+		_, err := store.StatObject(ctx, "user", key)
+		assert.ErrorIs(t, err, ErrInvalidKey, "key %q", key)
+		_, err = store.NewMultipartUpload(ctx, "user", key, nil)
+		assert.ErrorIs(t, err, ErrInvalidKey, "key %q", key)
+	}
+	for _, bucket := range []string{"", "..", "a/b"} {
+		assert.ErrorIs(t, store.MakeBucket(ctx, bucket), ErrInvalidKey, "bucket %q", bucket)
+		assert.ErrorIs(t, store.RemoveBucket(ctx, bucket), ErrInvalidKey, "bucket %q", bucket)
+	}
+	_, err := store.GetObject(ctx, "user", "missing", 0, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = store.ListObjects(ctx, "missing", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
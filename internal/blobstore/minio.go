@@ -0,0 +1,217 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// userMetadataPrefix - prefix of user metadata keys returned by object listing
+const userMetadataPrefix = "X-Amz-Meta-"
+
+// MinIOStore - BlobStore over MinIO or other S3 compatible storage, user's bucket is named after user ID
+type MinIOStore struct {
+	client *minio.Client
+	core   minio.Core
+}
+
+// NewMinIOStore - creates BlobStore connected to MinIO
+func NewMinIOStore(address, login, password string) (*MinIOStore, error) {
+	client, err := minio.New(address, &minio.Options{
+		Creds:  credentials.NewStaticV4(login, password, ""),
+		Secure: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MinIOStore{client: client, core: minio.Core{Client: client}}, nil
+}
+
+// minioError - convert MinIO error to BlobStore one
+func minioError(err error) error {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket", "NoSuchUpload":
+		return ErrNotFound
+	}
+	return err
+}
+
+// ignoreNotFound - drop error of missing bucket or object, it is already removed
+func ignoreNotFound(err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// objectInfo - description of object with user metadata keys stripped of header prefix
+func objectInfo(object minio.ObjectInfo) ObjectInfo {
+	var userMetadata map[string]string
+	if len(object.UserMetadata) > 0 {
+		userMetadata = make(map[string]string, len(object.UserMetadata))
+		for key, value := range object.UserMetadata {
+			if len(key) >= len(userMetadataPrefix) && strings.EqualFold(key[:len(userMetadataPrefix)], userMetadataPrefix) {
+				key = key[len(userMetadataPrefix):]
+			}
+			userMetadata[key] = value
+		}
+	}
+	return ObjectInfo{
+		Key:          object.Key,
+		Size:         object.Size,
+		LastModified: object.LastModified,
+		ETag:         object.ETag,
+		UserMetadata: userMetadata,
+	}
+}
+
+func (s *MinIOStore) MakeBucket(ctx context.Context, bucket string) error {
+	return s.client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{})
+}
+
+func (s *MinIOStore) ListBuckets(ctx context.Context) ([]string, error) {
+	buckets, err := s.client.ListBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		names = append(names, bucket.Name)
+	}
+	return names, nil
+}
+
+func (s *MinIOStore) RemoveBucket(ctx context.Context, bucket string) error {
+	for upload := range s.client.ListIncompleteUploads(ctx, bucket, "", true) {
+		if upload.Err != nil {
+			return ignoreNotFound(minioError(upload.Err))
+		}
+		if err := s.client.RemoveIncompleteUpload(ctx, bucket, upload.Key); err != nil {
+			return minioError(err)
+		}
+	}
+	objects, err := s.ListObjects(ctx, bucket, "")
+	if err != nil {
+		return ignoreNotFound(err)
+	}
+	for _, object := range objects {
+		if err = s.RemoveObject(ctx, bucket, object.Key); err != nil {
+			return err
+		}
+	}
+	return ignoreNotFound(minioError(s.client.RemoveBucket(ctx, bucket)))
+}
+
+func (s *MinIOStore) StatObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	object, err := s.client.StatObject(ctx, bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, minioError(err)
+	}
+	return objectInfo(object), nil
+}
+
+func (s *MinIOStore) ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for object := range s.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{
+		Prefix:       prefix,
+		Recursive:    true,
+		WithMetadata: true,
+	}) {
+		if object.Err != nil {
+			return nil, minioError(object.Err)
+		}
+		objects = append(objects, objectInfo(object))
+	}
+	return objects, nil
+}
+
+func (s *MinIOStore) GetObject(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	options := minio.GetObjectOptions{}
+	if offset > 0 || length > 0 {
+		end := int64(0)
+		if length > 0 {
+			end = offset + length - 1
+		}
+		if err := options.SetRange(offset, end); err != nil {
+			return nil, err
+		}
+	}
+	object, err := s.client.GetObject(ctx, bucket, key, options)
+	if err != nil {
+		return nil, minioError(err)
+	}
+	return object, nil
+}
+
+func (s *MinIOStore) CopyObject(ctx context.Context, bucket, from, to string) error {
+	_, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: to},
+		minio.CopySrcOptions{Bucket: bucket, Object: from},
+	)
+	return minioError(err)
+}
+
+func (s *MinIOStore) ReplaceMetadata(ctx context.Context, bucket, key string, userMetadata map[string]string) (ObjectInfo, error) {
+	info, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: bucket, Object: key, UserMetadata: userMetadata, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: bucket, Object: key},
+	)
+	if err != nil {
+		return ObjectInfo{}, minioError(err)
+	}
+	return ObjectInfo{
+		Key:          key,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		ETag:         info.ETag,
+		UserMetadata: userMetadata,
+	}, nil
+}
+
+func (s *MinIOStore) RemoveObject(ctx context.Context, bucket, key string) error {
+	return minioError(s.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}))
+}
+
+func (s *MinIOStore) NewMultipartUpload(ctx context.Context, bucket, key string, userMetadata map[string]string) (string, error) {
+	uploadID, err := s.core.NewMultipartUpload(ctx, bucket, key, minio.PutObjectOptions{UserMetadata: userMetadata})
+	return uploadID, minioError(err)
+}
+
+func (s *MinIOStore) PutPart(ctx context.Context, bucket, key, uploadID string, number int, data io.Reader, size int64) (Part, error) {
+	content, err := io.ReadAll(io.LimitReader(data, size))
+	if err != nil {
+		return Part{}, err
+	}
+	checksum := sha256.Sum256(content)
+	part, err := s.core.PutObjectPart(ctx, bucket, key, uploadID, number, bytes.NewReader(content), int64(len(content)),
+		minio.PutObjectPartOptions{Sha256Hex: hex.EncodeToString(checksum[:])})
+	if err != nil {
+		return Part{}, minioError(err)
+	}
+	return Part{Number: part.PartNumber, ETag: part.ETag, Size: part.Size}, nil
+}
+
+func (s *MinIOStore) CompleteMultipartUpload(ctx context.Context, bucket, key, uploadID string, parts []Part) (ObjectInfo, error) {
+	completed := make([]minio.CompletePart, 0, len(parts))
+	var size int64
+	for _, part := range parts {
+		completed = append(completed, minio.CompletePart{PartNumber: part.Number, ETag: part.ETag})
+		size += part.Size
+	}
+	info, err := s.core.CompleteMultipartUpload(ctx, bucket, key, uploadID, completed, minio.PutObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, minioError(err)
+	}
+	return ObjectInfo{Key: key, Size: size, LastModified: info.LastModified, ETag: info.ETag}, nil
+}
+
+func (s *MinIOStore) AbortMultipartUpload(ctx context.Context, bucket, key, uploadID string) error {
+	return minioError(s.core.AbortMultipartUpload(ctx, bucket, key, uploadID))
+}
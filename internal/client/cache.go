@@ -0,0 +1,407 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Kinds of cached items, every kind is kept in own bucket
+const (
+	credentialsKind = "credentials"
+	cardsKind       = "cards"
+	notesKind       = "notes"
+	filesKind       = "files"
+)
+
+// Actions of operations made while server was unreachable
+const (
+	createAction = "create"
+	updateAction = "update"
+	deleteAction = "delete"
+)
+
+const localIDPrefix = "local-"
+
+var (
+	vaultBucket   = []byte("vault")
+	pendingBucket = []byte("pending")
+	saltKey       = []byte("salt")
+	keyCheckKey   = []byte("key_check")
+	wrappedKeyKey = []byte("wrapped_key")
+	refreshKey    = []byte("refresh_token")
+)
+
+var (
+	// ErrOffline - error when operation requires server which is unreachable
+	ErrOffline = errors.New("server is unreachable, operation is not available offline")
+	// ErrNoCache - error when server is unreachable and there is no local copy of the vault
+	ErrNoCache = errors.New("server is unreachable and there is no local copy of the vault")
+	// ErrCacheLocked - error when cached items are accessed before vault was unlocked
+	ErrCacheLocked = errors.New("local cache is locked")
+)
+
+// pendingOperation - create, update or delete made while server was unreachable
+type pendingOperation struct {
+	Kind   string          `json:"kind"`
+	Action string          `json:"action"`
+	ID     string          `json:"id"`
+	Item   json.RawMessage `json:"item,omitempty"`
+	// Base - item as it was cached before change, it is merged with server copy if change conflicts
+	Base json.RawMessage `json:"base,omitempty"`
+}
+
+// Cache - local copy of user's vault in bbolt file. Items and queued operations are encrypted by vault key,
+// only vault key derivation parameters are kept in plaintext so the vault can be unlocked offline.
+type Cache struct {
+	db     *bolt.DB
+	cipher *vault.Cipher
+}
+
+// cachePath - cache file of user on server, email is hashed to keep it out of file names
+func cachePath(dir, serverAddress, email string) string {
+	sum := sha256.Sum256([]byte(serverAddress + "\x00" + strings.ToLower(email)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".db")
+}
+
+// cacheExists - check if user already has local copy of the vault
+func cacheExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// OpenCache - open or create local cache file
+func OpenCache(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{string(vaultBucket), string(pendingBucket), credentialsKind, cardsKind, notesKind, filesKind} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Cache{db: db}, nil
+}
+
+// Close - close cache file
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// unlock - allow access to cached items with vault cipher
+func (c *Cache) unlock(cipher *vault.Cipher) {
+	c.cipher = cipher
+}
+
+// SaveVaultParams - keep vault key derivation parameters to unlock vault offline
+func (c *Cache) SaveVaultParams(params models.VaultParams) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(vaultBucket)
+		if err := bucket.Put(saltKey, []byte(params.Salt)); err != nil {
+			return err
+		}
+		if err := bucket.Put(keyCheckKey, []byte(params.KeyCheck)); err != nil {
+			return err
+		}
+		return bucket.Put(wrappedKeyKey, []byte(params.WrappedKey))
+	})
+}
+
+// VaultParams - vault key derivation parameters saved by last online unlock
+func (c *Cache) VaultParams() (params models.VaultParams, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(vaultBucket)
+		params.Salt = string(bucket.Get(saltKey))
+		params.KeyCheck = string(bucket.Get(keyCheckKey))
+		params.WrappedKey = string(bucket.Get(wrappedKeyKey))
+		return nil
+	})
+	return
+}
+
+// saveRefreshToken - keep refresh token of session sealed by vault key, empty token removes saved one
+func (c *Cache) saveRefreshToken(token string) error {
+	if token == "" {
+		return c.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(vaultBucket).Delete(refreshKey)
+		})
+	}
+	sealed, err := c.seal(token, vaultBucket, refreshKey)
+	if err != nil {
+		return err
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(vaultBucket).Put(refreshKey, sealed)
+	})
+}
+
+// refreshToken - refresh token of last online session, it is empty if there is no saved one
+func (c *Cache) refreshToken() (token string, err error) {
+	var sealed []byte
+	err = c.db.View(func(tx *bolt.Tx) error {
+		sealed = append(sealed, tx.Bucket(vaultBucket).Get(refreshKey)...)
+		return nil
+	})
+	if err != nil || len(sealed) == 0 {
+		return
+	}
+	err = c.open(sealed, &token, vaultBucket, refreshKey)
+	return
+}
+
+// seal - encrypt value bound to bucket and key it is stored under
+func (c *Cache) seal(value any, bucket, key []byte) ([]byte, error) {
+	if c.cipher == nil {
+		return nil, ErrCacheLocked
+	}
+	plain, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := c.cipher.Seal(string(plain), vault.Binding(string(bucket), string(key)))
+	return []byte(sealed), err
+}
+
+// open - decrypt value sealed under bucket and key
+func (c *Cache) open(sealed []byte, value any, bucket, key []byte) error {
+	if c.cipher == nil {
+		return ErrCacheLocked
+	}
+	plain, err := c.cipher.Open(string(sealed), vault.Binding(string(bucket), string(key)))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(plain), value)
+}
+
+// replace - store fresh copy of items of given kind received from server. Items with operations waiting
+// for replay keep their local copy, as server does not know about these operations yet.
+func (c *Cache) replace(kind string, items map[string]any) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(kind))
+		var stale []string
+		err := bucket.ForEach(func(id, _ []byte) error {
+			if _, ok := items[string(id)]; !ok {
+				stale = append(stale, string(id))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return c.update(tx, kind, items, stale)
+	})
+}
+
+// apply - store items of given kind changed on server since previous sync and remove deleted ones, items
+// with operations waiting for replay keep their local copy
+func (c *Cache) apply(kind string, items map[string]any, deleted []string) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return c.update(tx, kind, items, deleted)
+	})
+}
+
+func (c *Cache) update(tx *bolt.Tx, kind string, items map[string]any, deleted []string) error {
+	pending, err := c.pendingIDs(tx, kind)
+	if err != nil {
+		return err
+	}
+	bucket := tx.Bucket([]byte(kind))
+	for _, id := range deleted {
+		if pending[id] {
+			continue
+		}
+		if err = bucket.Delete([]byte(id)); err != nil {
+			return err
+		}
+	}
+	for id, item := range items {
+		if pending[id] {
+			continue
+		}
+		sealed, err := c.seal(item, []byte(kind), []byte(id))
+		if err != nil {
+			return err
+		}
+		if err = bucket.Put([]byte(id), sealed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingIDs - IDs of items of given kind with operations waiting for replay
+func (c *Cache) pendingIDs(tx *bolt.Tx, kind string) (ids map[string]bool, err error) {
+	ids = make(map[string]bool)
+	err = tx.Bucket(pendingBucket).ForEach(func(key, sealed []byte) error {
+		var op pendingOperation
+		if err := c.open(sealed, &op, pendingBucket, key); err != nil {
+			return err
+		}
+		if op.Kind == kind {
+			ids[op.ID] = true
+		}
+		return nil
+	})
+	return
+}
+
+// loadCached - read all cached items of given kind
+func loadCached[T any](c *Cache, kind string) (items []T, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(kind)).ForEach(func(id, sealed []byte) error {
+			var item T
+			if err := c.open(sealed, &item, []byte(kind), id); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+	})
+	return
+}
+
+// queue - apply operation to cached items and keep it for replay. Operations on items which were created
+// offline are merged into pending creation, as such items get their IDs only from server.
+func (c *Cache) queue(kind, action, id string, item any) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		items := tx.Bucket([]byte(kind))
+		pending := tx.Bucket(pendingBucket)
+
+		var base json.RawMessage
+		if cached := items.Get([]byte(id)); cached != nil && action != createAction {
+			if err := c.open(cached, &base, []byte(kind), []byte(id)); err != nil {
+				return err
+			}
+		}
+
+		var sealedItem []byte
+		if action == deleteAction {
+			if err := items.Delete([]byte(id)); err != nil {
+				return err
+			}
+		} else {
+			var err error
+			if sealedItem, err = c.seal(item, []byte(kind), []byte(id)); err != nil {
+				return err
+			}
+			if err = items.Put([]byte(id), sealedItem); err != nil {
+				return err
+			}
+		}
+
+		if strings.HasPrefix(id, localIDPrefix) && action != createAction {
+			return c.mergeIntoCreation(pending, kind, action, id, item)
+		}
+
+		plain, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		op := pendingOperation{Kind: kind, Action: action, ID: id, Base: base}
+		if item != nil {
+			op.Item = plain
+		}
+		seq, err := pending.NextSequence()
+		if err != nil {
+			return err
+		}
+		sealedOp, err := c.seal(op, pendingBucket, sequenceKey(seq))
+		if err != nil {
+			return err
+		}
+		return pending.Put(sequenceKey(seq), sealedOp)
+	})
+}
+
+func (c *Cache) mergeIntoCreation(pending *bolt.Bucket, kind, action, id string, item any) error {
+	cursor := pending.Cursor()
+	for key, sealed := cursor.First(); key != nil; key, sealed = cursor.Next() {
+		var op pendingOperation
+		if err := c.open(sealed, &op, pendingBucket, key); err != nil {
+			return err
+		}
+		if op.Kind != kind || op.ID != id || op.Action != createAction {
+			continue
+		}
+		if action == deleteAction {
+			return pending.Delete(key)
+		}
+		plain, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		op.Item = plain
+		resealed, err := c.seal(op, pendingBucket, key)
+		if err != nil {
+			return err
+		}
+		return pending.Put(key, resealed)
+	}
+	return nil
+}
+
+// pendingOperations - operations waiting for replay in order they were made
+func (c *Cache) pendingOperations() (keys [][]byte, ops []pendingOperation, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(pendingBucket).ForEach(func(key, sealed []byte) error {
+			var op pendingOperation
+			if err := c.open(sealed, &op, pendingBucket, key); err != nil {
+				return err
+			}
+			keys = append(keys, append([]byte(nil), key...))
+			ops = append(ops, op)
+			return nil
+		})
+	})
+	return
+}
+
+// done - remove replayed operation from queue
+func (c *Cache) done(key []byte) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(pendingBucket).Delete(key)
+	})
+}
+
+// PendingCount - number of operations waiting for replay
+func (c *Cache) PendingCount() (count int) {
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(pendingBucket).Stats().KeyN
+		return nil
+	})
+	return
+}
+
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// newLocalID - temporary ID of item created offline
+func newLocalID() string {
+	return localIDPrefix + uuid.New().String()
+}
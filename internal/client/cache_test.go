@@ -0,0 +1,127 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T) *Cache {
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache
+}
+
+func TestCache_VaultParams(t *testing.T) {
+	cache := newTestCache(t)
+
+	params, err := cache.VaultParams()
+	require.NoError(t, err)
+	require.Empty(t, params.Salt)
+
+	require.NoError(t, cache.SaveVaultParams(models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"}))
+	params, err = cache.VaultParams()
+	require.NoError(t, err)
+	require.Equal(t, models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"}, params)
+}
+
+func TestCache_Locked(t *testing.T) {
+	cache := newTestCache(t)
+
+	require.ErrorIs(t, cache.replace(notesKind, map[string]any{"1": models.Note{ID: "1"}}), ErrCacheLocked)
+	require.ErrorIs(t, cache.queue(notesKind, createAction, newLocalID(), models.Note{}), ErrCacheLocked)
+}
+
+func TestCache_ReplaceAndLoad(t *testing.T) {
+	cache := newTestCache(t)
+	_, _, cipher := newTestVault(t, "master")
+	cache.unlock(cipher)
+
+	require.NoError(t, cache.replace(notesKind, map[string]any{
+		"1": models.Note{ID: "1", Title: "wifi", Body: "secret"},
+		"2": models.Note{ID: "2", Title: "door", Body: "1234"},
+	}))
+	require.NoError(t, cache.replace(notesKind, map[string]any{
+		"2": models.Note{ID: "2", Title: "door", Body: "4321"},
+	}))
+
+	notes, err := loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.Equal(t, []models.Note{{ID: "2", Title: "door", Body: "4321"}}, notes)
+
+	_, _, otherCipher := newTestVault(t, "other")
+	cache.unlock(otherCipher)
+	_, err = loadCached[models.Note](cache, notesKind)
+	require.Error(t, err, "cached items must not be readable with another master password")
+}
+
+func TestCache_ReplaceKeepsPending(t *testing.T) {
+	cache := newTestCache(t)
+	_, _, cipher := newTestVault(t, "master")
+	cache.unlock(cipher)
+	require.NoError(t, cache.replace(notesKind, map[string]any{
+		"1": models.Note{ID: "1", Title: "wifi"},
+		"2": models.Note{ID: "2", Title: "door"},
+	}))
+
+	localID := newLocalID()
+	require.NoError(t, cache.queue(notesKind, createAction, localID, models.Note{ID: localID, Title: "draft"}))
+	require.NoError(t, cache.queue(notesKind, updateAction, "1", models.Note{ID: "1", Title: "home wifi"}))
+	require.NoError(t, cache.queue(notesKind, deleteAction, "2", nil))
+
+	require.NoError(t, cache.replace(notesKind, map[string]any{
+		"1": models.Note{ID: "1", Title: "wifi"},
+		"2": models.Note{ID: "2", Title: "door"},
+		"3": models.Note{ID: "3", Title: "alarm"},
+	}))
+	notes, err := loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.Note{
+		{ID: "1", Title: "home wifi"}, {ID: localID, Title: "draft"}, {ID: "3", Title: "alarm"},
+	}, notes, "items with pending operations keep local copy")
+
+	require.NoError(t, cache.apply(notesKind, map[string]any{
+		"1": models.Note{ID: "1", Title: "office wifi"},
+		"4": models.Note{ID: "4", Title: "garage"},
+	}, []string{"3", localID}))
+	notes, err = loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.Note{
+		{ID: "1", Title: "home wifi"}, {ID: localID, Title: "draft"}, {ID: "4", Title: "garage"},
+	}, notes)
+}
+
+func TestCache_Queue(t *testing.T) {
+	cache := newTestCache(t)
+	_, _, cipher := newTestVault(t, "master")
+	cache.unlock(cipher)
+	require.NoError(t, cache.replace(notesKind, map[string]any{"1": models.Note{ID: "1", Title: "wifi"}}))
+
+	localID := newLocalID()
+	require.NoError(t, cache.queue(notesKind, updateAction, "1", models.Note{ID: "1", Title: "home wifi"}))
+	require.NoError(t, cache.queue(notesKind, createAction, localID, models.Note{ID: localID, Title: "draft"}))
+	require.NoError(t, cache.queue(notesKind, updateAction, localID, models.Note{ID: localID, Title: "final"}))
+	require.Equal(t, 2, cache.PendingCount(), "update of item created offline is merged into its creation")
+
+	notes, err := loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.Note{{ID: "1", Title: "home wifi"}, {ID: localID, Title: "final"}}, notes)
+
+	keys, ops, err := cache.pendingOperations()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, updateAction, ops[0].Action)
+	require.Equal(t, createAction, ops[1].Action)
+	var created models.Note
+	require.NoError(t, decodeItem(ops[1], &created))
+	require.Equal(t, "final", created.Title)
+
+	require.NoError(t, cache.queue(notesKind, deleteAction, localID, nil))
+	require.Equal(t, 1, cache.PendingCount(), "deletion of item created offline cancels its creation")
+
+	require.NoError(t, cache.done(keys[0]))
+	require.Zero(t, cache.PendingCount())
+}
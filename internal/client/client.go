@@ -3,156 +3,627 @@ package client
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/PaBah/GophKeeper/internal/archive"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/importer"
 	"github.com/PaBah/GophKeeper/internal/logger"
 	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 )
 
+// ErrVaultNotInitialized - error when user has not created master password yet
+var ErrVaultNotInitialized = errors.New("vault is not initialized")
+
+// ErrTOTPRequired - error when password is correct and sign in has to be finished by VerifyTOTP
+var ErrTOTPRequired = errors.New("two-factor authentication code is required")
+
+// ErrSessionExpired - error when session of user signed in offline can not be restored, user has to sign in again
+var ErrSessionExpired = errors.New("session is expired, please sign in again")
+
 type Client interface {
 	SignUp(email, password string) error
 	SignIn(email, password string) error
 }
 
 type ClientService struct {
-	client        pb.GophKeeperServiceClient
-	token         string
-	sessionID     string
-	serverAddress string
-	conn          *grpc.ClientConn
-	isAvailable   bool
+	client       pb.GophKeeperServiceClient
+	token        string
+	refreshToken string
+	// tokenExpiresAt - when access token expires, it is refreshed in getCtx shortly before
+	tokenExpiresAt time.Time
+	tokenMutex     sync.Mutex
+	sessionID      string
+	serverAddress  string
+	conn           *grpc.ClientConn
+	isAvailable    bool
+	cipher         *vault.Cipher
+	cache          *Cache
+	cacheDir       string
+	email          string
+	synced         *syncState
+	device         string
+	// partialToken - proof of correct password, it is exchanged for session by VerifyTOTP
+	partialToken string
+	// replayConflict - offline change which waits for user as it conflicts with change of another client
+	replayConflict *replayConflict
+	// dropped - number of offline changes rejected by server in this session
+	dropped int
 }
 
 type GRPCClientProvider interface {
 	SignUp(email, password string) error
 	SignIn(email, password string) error
-	CreateCredentials(ctx context.Context, serviceName, identity, password string) error
+	UnlockVault(ctx context.Context, masterPassword string) error
+	InitVault(ctx context.Context, masterPassword string) error
+	ChangeMasterPassword(ctx context.Context, oldMasterPassword, newMasterPassword string) error
+	CreateCredentials(ctx context.Context, serviceName, identity, password, totp string, metadata map[string]string) error
 	GetCredentials(ctx context.Context) (credentials []models.Credentials, err error)
 	UpdateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error)
-	DeleteCredentials(ctx context.Context, credentialsID string) (err error)
-	CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string) error
+	DeleteCredentials(ctx context.Context, credentialsID string, version int64) (err error)
+	CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string, metadata map[string]string) error
 	GetCards(ctx context.Context) (cards []models.Card, err error)
 	GetFiles(ctx context.Context) (files []models.File, err error)
 	DeleteFile(ctx context.Context, name string) (err error)
+	UpdateFileMetadata(ctx context.Context, name string, metadata map[string]string) (err error)
 	UpdateCards(ctx context.Context, card models.Card) (updatedCard models.Card, err error)
-	DeleteCard(ctx context.Context, cardID string) (err error)
-	UploadFile(ctx context.Context, filePath string)
-	DownloadsFile(ctx context.Context, name string)
+	DeleteCard(ctx context.Context, cardID string, version int64) (err error)
+	CreateNote(ctx context.Context, title, body string, metadata map[string]string) error
+	GetNotes(ctx context.Context) (notes []models.Note, err error)
+	UpdateNote(ctx context.Context, note models.Note) (updatedNote models.Note, err error)
+	DeleteNote(ctx context.Context, noteID string, version int64) (err error)
+	ListItemHistory(ctx context.Context, itemType, id string) (revisions []models.Revision, err error)
+	RestoreItemRevision(ctx context.Context, itemType, id, revisionID string, version int64) (err error)
+	ListSessions(ctx context.Context) (sessions []models.Session, err error)
+	RevokeSession(ctx context.Context, id string) (err error)
+	ChangePassword(ctx context.Context, oldPassword, newPassword string) (revoked int, err error)
+	DeleteAccount(ctx context.Context, password string) (deletedAt time.Time, err error)
+	ExportVault(ctx context.Context, path, password string) (manifest archive.Manifest, err error)
+	ImportVault(ctx context.Context, path, password string, dryRun bool) (report models.ImportReport, err error)
+	ImportItems(ctx context.Context, items importer.Items, dryRun bool) (report models.ImportReport, err error)
+	VerifyTOTP(code string) error
+	EnrollTOTP(ctx context.Context) (enrollment models.TOTPEnrollment, err error)
+	ConfirmTOTP(ctx context.Context, code string) (err error)
+	DisableTOTP(ctx context.Context, code string) (err error)
+	ListTrash(ctx context.Context) (items []models.TrashItem, err error)
+	RestoreFromTrash(ctx context.Context, itemType, id string) (err error)
+	EmptyTrash(ctx context.Context) (err error)
+	UploadFile(ctx context.Context, filePath string, metadata map[string]string)
+	DownloadsFile(ctx context.Context, id string)
 	SubscribeToChanges(ctx context.Context) (grpc.ServerStreamingClient[pb.SubscribeToChangesResponse], error)
+	OpenChangeEvent(event *pb.SubscribeToChangesResponse) (models.ChangeEvent, error)
 	TryToConnect() bool
+	SyncStatus() (online bool, pending, dropped int)
+	TakeReplayConflict() (*models.ReplayConflict, error)
+	Sync(ctx context.Context) (vault models.ChangeSet, err error)
 }
 
-func NewClientService(serverAddress string) ClientService {
-	return ClientService{
+func NewClientService(serverAddress string) *ClientService {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	device, err := os.Hostname()
+	if err != nil {
+		device = "unknown device"
+	}
+	return &ClientService{
 		serverAddress: serverAddress,
+		cacheDir:      filepath.Join(configDir, "gophkeeper"),
+		device:        device,
 	}
 }
 
 // SignUp registers a new user with the provided email and password, and stores the authentication token.
 func (c *ClientService) SignUp(email, password string) error {
-	resp, err := c.client.SignUp(context.Background(), &pb.SignUpRequest{
+	resp, err := c.client.SignUp(c.deviceCtx(context.Background()), &pb.SignUpRequest{
 		Email:    email,
 		Password: password,
 	})
 	if err != nil {
 		return fmt.Errorf("SignUp: %w", err)
 	}
-	c.token = resp.GetToken()
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	c.isAvailable = true
+	c.openCache(email)
 
 	return nil
 }
 
+// SignIn authorizes user and opens local copy of user's vault. While server is unreachable user
+// is signed in offline if the vault was already cached, session is restored by refresh token of previous
+// session on reconnect.
 func (c *ClientService) SignIn(email, password string) error {
-	resp, err := c.client.SignIn(context.Background(), &pb.SignInRequest{
+	resp, err := c.client.SignIn(c.deviceCtx(context.Background()), &pb.SignInRequest{
 		Email:    email,
 		Password: password,
 	})
+	if isUnavailable(err) {
+		if c.cacheDir == "" || !cacheExists(cachePath(c.cacheDir, c.serverAddress, email)) {
+			return fmt.Errorf("SignIn: %w", ErrNoCache)
+		}
+		c.isAvailable = false
+		c.openCache(email)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("SignIn: %w", err)
 	}
-	c.token = resp.GetToken()
+	if resp.GetTotpRequired() {
+		c.partialToken = resp.GetPartialToken()
+		c.email = email
+		return fmt.Errorf("SignIn: %w", ErrTOTPRequired)
+	}
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	c.isAvailable = true
+	c.openCache(email)
 
 	return nil
 }
 
-func (c *ClientService) CreateCredentials(ctx context.Context, serviceName, identity, password string) error {
-	_, err := c.client.CreateCredentials(c.getCtx(ctx, c.token), &pb.CreateCredentialsRequest{
-		ServiceName: serviceName,
-		Identity:    identity,
-		Password:    password,
+// UnlockVault derives vault key from master password and checks it against key check stored on server.
+// Items stored before vault was initialized are encrypted right after unlock.
+func (c *ClientService) UnlockVault(ctx context.Context, masterPassword string) error {
+	var params models.VaultParams
+	resp, err := c.client.GetVaultParams(c.getCtx(ctx), &pb.GetVaultParamsRequest{})
+	switch {
+	case c.fallBackToCache(err):
+		params, err = c.cache.VaultParams()
+		if err != nil {
+			return fmt.Errorf("UnlockVault: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("UnlockVault: %w", err)
+	default:
+		params = models.VaultParams{Salt: resp.GetSalt(), KeyCheck: resp.GetKeyCheck(), WrappedKey: resp.GetWrappedKey()}
+	}
+	if params.Salt == "" {
+		return ErrVaultNotInitialized
+	}
+
+	key, err := vaultKey(masterPassword, params)
+	if err != nil {
+		return fmt.Errorf("UnlockVault: %w", err)
+	}
+	cipher, err := vault.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("UnlockVault: %w", err)
+	}
+	c.cipher = cipher
+	c.synced = nil
+	c.unlockCache(params)
+
+	if !c.isAvailable {
+		return nil
+	}
+	return c.sealLegacyItems(ctx)
+}
+
+// InitVault creates vault protected by master password and encrypts all items stored in plaintext before.
+func (c *ClientService) InitVault(ctx context.Context, masterPassword string) error {
+	salt, err := vault.NewSalt()
+	if err != nil {
+		return fmt.Errorf("InitVault: %w", err)
+	}
+	cipher, err := newVaultCipher(masterPassword, salt)
+	if err != nil {
+		return fmt.Errorf("InitVault: %w", err)
+	}
+	keyCheck, err := cipher.KeyCheck()
+	if err != nil {
+		return fmt.Errorf("InitVault: %w", err)
+	}
+
+	_, err = c.client.InitVault(c.getCtx(ctx), &pb.InitVaultRequest{
+		Salt:     salt,
+		KeyCheck: keyCheck,
 	})
 	if err != nil {
-		return fmt.Errorf("CreateCredentials: %w", err)
+		return fmt.Errorf("InitVault: %w", err)
+	}
+	c.cipher = cipher
+	c.synced = nil
+	c.unlockCache(models.VaultParams{Salt: salt, KeyCheck: keyCheck})
+
+	return c.sealLegacyItems(ctx)
+}
+
+// ChangeMasterPassword re-wraps vault key by key of new master password. Items stay encrypted by the same vault
+// key, so they are not re-encrypted. Other devices keep vault unlocked and get new parameters on next unlock.
+func (c *ClientService) ChangeMasterPassword(ctx context.Context, oldMasterPassword, newMasterPassword string) error {
+	resp, err := c.client.GetVaultParams(c.getCtx(ctx), &pb.GetVaultParamsRequest{})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		return fmt.Errorf("ChangeMasterPassword: %w", err)
+	}
+	params := models.VaultParams{Salt: resp.GetSalt(), KeyCheck: resp.GetKeyCheck(), WrappedKey: resp.GetWrappedKey()}
+	if params.Salt == "" {
+		return ErrVaultNotInitialized
+	}
+	key, err := vaultKey(oldMasterPassword, params)
+	if err != nil {
+		return fmt.Errorf("ChangeMasterPassword: %w", err)
+	}
+
+	changed, err := wrapVaultKey(newMasterPassword, key)
+	if err != nil {
+		return fmt.Errorf("ChangeMasterPassword: %w", err)
+	}
+	_, err = c.client.ChangeMasterPassword(c.getCtx(ctx), &pb.ChangeMasterPasswordRequest{
+		OldKeyCheck: params.KeyCheck,
+		Salt:        changed.Salt,
+		KeyCheck:    changed.KeyCheck,
+		WrappedKey:  changed.WrappedKey,
+	})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		return fmt.Errorf("ChangeMasterPassword: %w", err)
+	}
+
+	if c.cache != nil {
+		if err = c.cache.SaveVaultParams(changed); err != nil {
+			logger.Log().Error("could not cache vault parameters", zap.Error(err))
+		}
 	}
 	return nil
 }
 
-func (c *ClientService) GetCredentials(ctx context.Context) (credentials []models.Credentials, err error) {
-	resp, err := c.client.GetCredentials(c.getCtx(ctx, c.token), &pb.GetCredentialsRequest{})
+// vaultKey derives key of master password and checks it against key check. Vault key is wrapped by it after
+// master password was changed, before that derived key is the vault key itself.
+func vaultKey(masterPassword string, params models.VaultParams) ([]byte, error) {
+	key, err := vault.DeriveKey(masterPassword, params.Salt)
+	if err != nil {
+		return nil, err
+	}
+	cipher, err := vault.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if err = cipher.VerifyKeyCheck(params.KeyCheck); err != nil {
+		return nil, err
+	}
+	if params.WrappedKey == "" {
+		return key, nil
+	}
+	return cipher.UnwrapVaultKey(params.WrappedKey)
+}
+
+// wrapVaultKey builds vault parameters of master password with new salt which wrap vault key.
+func wrapVaultKey(masterPassword string, key []byte) (params models.VaultParams, err error) {
+	params.Salt, err = vault.NewSalt()
 	if err != nil {
-		err = fmt.Errorf("CreateCredentials: %w", err)
 		return
 	}
-	for _, cred := range resp.Credentials {
-		uploadedAt, _ := time.Parse(time.RFC3339, cred.UploadedAt)
-		credentials = append(credentials, models.Credentials{
+	cipher, err := newVaultCipher(masterPassword, params.Salt)
+	if err != nil {
+		return
+	}
+	params.KeyCheck, err = cipher.KeyCheck()
+	if err != nil {
+		return
+	}
+	params.WrappedKey, err = cipher.WrapVaultKey(key)
+	return
+}
+
+func newVaultCipher(masterPassword, salt string) (*vault.Cipher, error) {
+	key, err := vault.DeriveKey(masterPassword, salt)
+	if err != nil {
+		return nil, err
+	}
+	return vault.NewCipher(key)
+}
+
+// sealLegacyItems re-uploads items which were stored before vault was initialized in encrypted form.
+func (c *ClientService) sealLegacyItems(ctx context.Context) error {
+	credentialsResp, err := c.client.GetCredentials(c.getCtx(ctx), &pb.GetCredentialsRequest{})
+	if err != nil {
+		return fmt.Errorf("sealLegacyItems: %w", err)
+	}
+	for _, cred := range credentialsResp.Credentials {
+		if sealed(cred.Metadata, cred.Identity, cred.Password, cred.Totp) {
+			continue
+		}
+		// values sealed before are opened, so that item is not sealed twice
+		err = c.open(models.CredentialsItem, cred.Id, credentialsFields(&cred.Identity, &cred.Password, &cred.Totp))
+		if err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		if err = c.openMetadata(models.CredentialsItem, cred.Id, cred.Metadata); err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		_, err = c.updateCredentials(ctx, models.Credentials{
 			ID:          cred.Id,
 			ServiceName: cred.ServiceName,
 			Identity:    cred.Identity,
 			Password:    cred.Password,
-			UploadedAt:  uploadedAt,
+			TOTP:        cred.Totp,
+			Metadata:    cred.Metadata,
+			Version:     cred.Version,
+		})
+		if err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+	}
+
+	cardsResp, err := c.client.GetCards(c.getCtx(ctx), &pb.GetCardsRequest{})
+	if err != nil {
+		return fmt.Errorf("sealLegacyItems: %w", err)
+	}
+	for _, card := range cardsResp.Cards {
+		if sealed(card.Metadata, card.Number, card.ExpirationDate, card.HolderName, card.Cvv) {
+			continue
+		}
+		err = c.open(models.CardItem, card.Id, cardFields(&card.Number, &card.ExpirationDate, &card.HolderName, &card.Cvv))
+		if err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		if err = c.openMetadata(models.CardItem, card.Id, card.Metadata); err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		_, err = c.updateCards(ctx, models.Card{
+			ID:             card.Id,
+			Number:         card.Number,
+			ExpirationDate: card.ExpirationDate,
+			HolderName:     card.HolderName,
+			CVV:            card.Cvv,
+			Metadata:       card.Metadata,
+			Version:        card.Version,
 		})
+		if err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+	}
+
+	notesResp, err := c.client.GetNotes(c.getCtx(ctx), &pb.GetNotesRequest{})
+	if err != nil {
+		return fmt.Errorf("sealLegacyItems: %w", err)
+	}
+	for _, note := range notesResp.Notes {
+		if sealed(note.Metadata, note.Body) {
+			continue
+		}
+		if err = c.open(models.NoteItem, note.Id, map[string]*string{"body": &note.Body}); err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		if err = c.openMetadata(models.NoteItem, note.Id, note.Metadata); err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+		_, err = c.updateNote(ctx, models.Note{ID: note.Id, Title: note.Title, Body: note.Body, Metadata: note.Metadata, Version: note.Version})
+		if err != nil {
+			return fmt.Errorf("sealLegacyItems: %w", err)
+		}
+	}
+	return nil
+}
+
+// sealed checks that every secret value and metadata value of item is encrypted and bound to item.
+func sealed(metadata map[string]string, values ...string) bool {
+	for _, value := range values {
+		if !vault.IsBound(value) {
+			return false
+		}
+	}
+	for _, value := range metadata {
+		if !vault.IsBound(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// credentialsFields names secret values of credentials, names are bound to sealed values.
+func credentialsFields(identity, password, totp *string) map[string]*string {
+	return map[string]*string{"identity": identity, "password": password, "totp": totp}
+}
+
+// cardFields names secret values of card, names are bound to sealed values.
+func cardFields(number, expirationDate, holderName, cvv *string) map[string]*string {
+	return map[string]*string{"number": number, "expiration_date": expirationDate, "holder_name": holderName, "cvv": cvv}
+}
+
+// seal encrypts secret fields of item in place bound to item and field name, so server can not move them
+// to other item or field. Values are left as is while vault is locked.
+func (c *ClientService) seal(itemType, id string, fields map[string]*string) (err error) {
+	if c.cipher == nil {
+		return
+	}
+	for name, value := range fields {
+		*value, err = c.cipher.Seal(*value, vault.Binding(itemType, id, name))
+		if err != nil {
+			return
+		}
 	}
 	return
 }
 
-func (c *ClientService) UpdateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error) {
-	response, err := c.client.UpdateCredentials(c.getCtx(ctx, c.token), &pb.UpdateCredentialsRequest{
+// open decrypts secret fields of item in place, values are left as is while vault is locked.
+func (c *ClientService) open(itemType, id string, fields map[string]*string) (err error) {
+	if c.cipher == nil {
+		return
+	}
+	for name, value := range fields {
+		*value, err = c.cipher.Open(*value, vault.Binding(itemType, id, name))
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// sealMetadata returns copy of item metadata with encrypted values, keys are kept readable for search.
+func (c *ClientService) sealMetadata(itemType, id string, metadata map[string]string) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	sealed := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if err := c.seal(itemType, id, map[string]*string{metadataField(key): &value}); err != nil {
+			return nil, err
+		}
+		sealed[key] = value
+	}
+	return sealed, nil
+}
+
+// openMetadata decrypts values of item metadata in place.
+func (c *ClientService) openMetadata(itemType, id string, metadata map[string]string) error {
+	for key, value := range metadata {
+		if err := c.open(itemType, id, map[string]*string{metadataField(key): &value}); err != nil {
+			return err
+		}
+		metadata[key] = value
+	}
+	return nil
+}
+
+// metadataField - name of field metadata value is bound to
+func metadataField(key string) string {
+	return vault.Binding("metadata", key)
+}
+
+// createCredentials sends new credentials to server.
+func (c *ClientService) createCredentials(ctx context.Context, credentials models.Credentials) error {
+	request, err := c.credentialsRequest(credentials)
+	if err != nil {
+		return fmt.Errorf("CreateCredentials: %w", err)
+	}
+	_, err = c.client.CreateCredentials(c.getCtx(ctx), request)
+	if err != nil {
+		return fmt.Errorf("CreateCredentials: %w", err)
+	}
+	return nil
+}
+
+// credentialsRequest seals credentials for creation on server.
+func (c *ClientService) credentialsRequest(credentials models.Credentials) (*pb.CreateCredentialsRequest, error) {
+	id := uuid.NewString()
+	identity, password, totp, serviceName := credentials.Identity, credentials.Password, credentials.TOTP, credentials.ServiceName
+	if err := c.seal(models.CredentialsItem, id, credentialsFields(&identity, &password, &totp)); err != nil {
+		return nil, err
+	}
+	metadata, err := c.sealMetadata(models.CredentialsItem, id, credentials.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateCredentialsRequest{
+		Id:          id,
+		ServiceName: serviceName,
+		Identity:    identity,
+		Password:    password,
+		Totp:        totp,
+		Metadata:    metadata,
+	}, nil
+}
+
+// getCredentials receives credentials from server.
+func (c *ClientService) getCredentials(ctx context.Context) (credentials []models.Credentials, err error) {
+	resp, err := c.client.GetCredentials(c.getCtx(ctx), &pb.GetCredentialsRequest{})
+	if err != nil {
+		err = fmt.Errorf("CreateCredentials: %w", err)
+		return
+	}
+	for _, cred := range resp.Credentials {
+		var credentialSet models.Credentials
+		if credentialSet, err = c.openCredentials(cred); err != nil {
+			err = fmt.Errorf("GetCredentials: %w", err)
+			return
+		}
+		credentials = append(credentials, credentialSet)
+	}
+	return
+}
+
+// openCredentials decrypts credentials received from server.
+func (c *ClientService) openCredentials(cred *pb.GetCredentialsResponse_Credential) (credentials models.Credentials, err error) {
+	uploadedAt, _ := time.Parse(time.RFC3339, cred.UploadedAt)
+	credentials = models.Credentials{
+		ID:          cred.Id,
+		ServiceName: cred.ServiceName,
+		Identity:    cred.Identity,
+		Password:    cred.Password,
+		TOTP:        cred.Totp,
+		UploadedAt:  uploadedAt,
+		Version:     cred.Version,
+		Metadata:    cred.Metadata,
+	}
+	err = c.open(models.CredentialsItem, credentials.ID,
+		credentialsFields(&credentials.Identity, &credentials.Password, &credentials.TOTP))
+	if err == nil {
+		err = c.openMetadata(models.CredentialsItem, credentials.ID, credentials.Metadata)
+	}
+	return
+}
+
+// updateCredentials sends changed credentials to server.
+func (c *ClientService) updateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error) {
+	identity, password, totp := credentials.Identity, credentials.Password, credentials.TOTP
+	err = c.seal(models.CredentialsItem, credentials.ID, credentialsFields(&identity, &password, &totp))
+	if err != nil {
+		err = fmt.Errorf("UpdateCredentials: %w", err)
+		return
+	}
+	metadata, err := c.sealMetadata(models.CredentialsItem, credentials.ID, credentials.Metadata)
+	if err != nil {
+		err = fmt.Errorf("UpdateCredentials: %w", err)
+		return
+	}
+	response, err := c.client.UpdateCredentials(c.getCtx(ctx), &pb.UpdateCredentialsRequest{
 		Id:          credentials.ID,
 		ServiceName: credentials.ServiceName,
-		Identity:    credentials.Identity,
-		Password:    credentials.Password,
+		Identity:    identity,
+		Password:    password,
+		Totp:        totp,
+		Metadata:    metadata,
+		Version:     credentials.Version,
 	})
 	if err != nil {
-		err = fmt.Errorf("UpdateCredentials: %w", err)
+		err = fmt.Errorf("UpdateCredentials: %w", c.conflict(err))
 		return
 	}
 	updatedCredentials = credentials
 	updatedCredentials.ServiceName = response.ServiceName
 	uploadedAt, _ := time.Parse(time.RFC3339, response.UploadedAt)
 	updatedCredentials.UploadedAt = uploadedAt
+	updatedCredentials.Version = response.Version
 
 	return
 }
 
-func (c *ClientService) DeleteCredentials(ctx context.Context, credentialsID string) (err error) {
-	_, err = c.client.DeleteCredentials(c.getCtx(ctx, c.token), &pb.DeleteCredentialsRequest{
-		Id: credentialsID,
+// deleteCredentials removes credentials of given version on server.
+func (c *ClientService) deleteCredentials(ctx context.Context, credentialsID string, version int64) (err error) {
+	_, err = c.client.DeleteCredentials(c.getCtx(ctx), &pb.DeleteCredentialsRequest{
+		Id:      credentialsID,
+		Version: version,
 	})
-	if err != nil {
+	if err = c.deletionConflict(err); err != nil {
 		err = fmt.Errorf("DeleteCredentials: %w", err)
-		return
 	}
-
 	return
 }
 
-func (c *ClientService) CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string) error {
-	_, err := c.client.CreateCard(c.getCtx(ctx, c.token), &pb.CreateCardRequest{
-		Number:         number,
-		ExpirationDate: expirationDate,
-		HolderName:     holderName,
-		Cvv:            cvv,
-	})
+// createCard sends new card to server.
+func (c *ClientService) createCard(ctx context.Context, card models.Card) error {
+	request, err := c.cardRequest(card)
+	if err != nil {
+		return fmt.Errorf("CreateCard: %w", err)
+	}
+	_, err = c.client.CreateCard(c.getCtx(ctx), request)
 
 	if err != nil {
 		return fmt.Errorf("CreateCard: %w", err)
@@ -160,48 +631,111 @@ func (c *ClientService) CreateCard(ctx context.Context, number, expirationDate,
 	return nil
 }
 
-func (c *ClientService) GetCards(ctx context.Context) (cards []models.Card, err error) {
-	resp, err := c.client.GetCards(c.getCtx(ctx, c.token), &pb.GetCardsRequest{})
+// cardRequest seals card for creation on server.
+func (c *ClientService) cardRequest(card models.Card) (*pb.CreateCardRequest, error) {
+	id := uuid.NewString()
+	number, expirationDate, holderName, cvv := card.Number, card.ExpirationDate, card.HolderName, card.CVV
+	if err := c.seal(models.CardItem, id, cardFields(&number, &expirationDate, &holderName, &cvv)); err != nil {
+		return nil, err
+	}
+	metadata, err := c.sealMetadata(models.CardItem, id, card.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateCardRequest{
+		Id:             id,
+		Number:         number,
+		ExpirationDate: expirationDate,
+		HolderName:     holderName,
+		Cvv:            cvv,
+		Metadata:       metadata,
+	}, nil
+}
+
+// getCards receives cards from server.
+func (c *ClientService) getCards(ctx context.Context) (cards []models.Card, err error) {
+	resp, err := c.client.GetCards(c.getCtx(ctx), &pb.GetCardsRequest{})
 	if err != nil {
 		err = fmt.Errorf("GetCards: %w", err)
 		return
 	}
 	for _, card := range resp.Cards {
-		uploadedAt, _ := time.Parse(time.RFC3339, card.UploadedAt)
-		cards = append(cards, models.Card{
-			ID:             card.Id,
-			Number:         card.Number,
-			ExpirationDate: card.ExpirationDate,
-			HolderName:     card.HolderName,
-			CVV:            card.Cvv,
-			UploadedAt:     uploadedAt,
-		})
+		var openedCard models.Card
+		if openedCard, err = c.openCard(card); err != nil {
+			err = fmt.Errorf("GetCards: %w", err)
+			return
+		}
+		cards = append(cards, openedCard)
+	}
+	return
+}
+
+// openCard decrypts card received from server.
+func (c *ClientService) openCard(card *pb.GetCardsResponse_Card) (openedCard models.Card, err error) {
+	uploadedAt, _ := time.Parse(time.RFC3339, card.UploadedAt)
+	openedCard = models.Card{
+		ID:             card.Id,
+		Number:         card.Number,
+		ExpirationDate: card.ExpirationDate,
+		HolderName:     card.HolderName,
+		CVV:            card.Cvv,
+		UploadedAt:     uploadedAt,
+		Version:        card.Version,
+		Metadata:       card.Metadata,
+	}
+	err = c.open(models.CardItem, openedCard.ID,
+		cardFields(&openedCard.Number, &openedCard.ExpirationDate, &openedCard.HolderName, &openedCard.CVV))
+	if err == nil {
+		err = c.openMetadata(models.CardItem, openedCard.ID, openedCard.Metadata)
 	}
 	return
 }
 
-func (c *ClientService) GetFiles(ctx context.Context) (files []models.File, err error) {
-	resp, err := c.client.GetFiles(c.getCtx(ctx, c.token), &pb.GetFilesRequest{})
+// getFiles receives list of uploaded files from server.
+func (c *ClientService) getFiles(ctx context.Context) (files []models.File, err error) {
+	resp, err := c.client.GetFiles(c.getCtx(ctx), &pb.GetFilesRequest{})
 	if err != nil {
 		err = fmt.Errorf("GetFiles: %w", err)
 		return
 	}
-	for _, card := range resp.Files {
-		uploadedAt, _ := time.Parse(time.RFC3339, card.UploadedAt)
-		files = append(files, models.File{
-			Name:       card.Name,
-			Size:       card.Size,
-			UploadedAt: uploadedAt,
-		})
+	for _, file := range resp.Files {
+		var openedFile models.File
+		if openedFile, err = c.openFile(file); err != nil {
+			err = fmt.Errorf("GetFiles: %w", err)
+			return
+		}
+		files = append(files, openedFile)
 	}
 	return
 }
 
+func (c *ClientService) openFile(file *pb.GetFilesResponse_File) (openedFile models.File, err error) {
+	uploadedAt, _ := time.Parse(time.RFC3339, file.UploadedAt)
+	openedFile = models.File{
+		ID:         file.Name,
+		Name:       file.Name,
+		Size:       file.Size,
+		UploadedAt: uploadedAt,
+		Metadata:   file.Metadata,
+	}
+	if file.EncryptedName != "" {
+		openedFile.Name = file.EncryptedName
+		if err = c.open(models.FileItem, file.Name, map[string]*string{"name": &openedFile.Name}); err != nil {
+			return
+		}
+	}
+	err = c.openMetadata(models.FileItem, file.Name, openedFile.Metadata)
+	return
+}
+
 func (c *ClientService) DeleteFile(ctx context.Context, name string) (err error) {
-	_, err = c.client.DeleteFile(c.getCtx(ctx, c.token), &pb.DeleteFileRequest{
+	_, err = c.client.DeleteFile(c.getCtx(ctx), &pb.DeleteFileRequest{
 		Name: name,
 	})
 
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
 	if err != nil {
 		err = fmt.Errorf("DeleteFile: %w", err)
 	}
@@ -209,121 +743,407 @@ func (c *ClientService) DeleteFile(ctx context.Context, name string) (err error)
 	return
 }
 
-func (c *ClientService) UpdateCards(ctx context.Context, card models.Card) (updatedCard models.Card, err error) {
-	response, err := c.client.UpdateCard(c.getCtx(ctx, c.token), &pb.UpdateCardRequest{
-		Id:             card.ID,
-		Number:         card.Number,
-		ExpirationDate: card.ExpirationDate,
-		HolderName:     card.HolderName,
-		Cvv:            card.CVV,
+// UpdateFileMetadata replaces metadata of uploaded file.
+func (c *ClientService) UpdateFileMetadata(ctx context.Context, name string, metadata map[string]string) (err error) {
+	metadata, err = c.sealMetadata(models.FileItem, name, metadata)
+	if err != nil {
+		err = fmt.Errorf("UpdateFileMetadata: %w", err)
+		return
+	}
+	_, err = c.client.UpdateFileMetadata(c.getCtx(ctx), &pb.UpdateFileMetadataRequest{
+		Name:     name,
+		Metadata: metadata,
 	})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("UpdateFileMetadata: %w", err)
+	}
+
+	return
+}
+
+// updateCards sends changed card to server.
+func (c *ClientService) updateCards(ctx context.Context, card models.Card) (updatedCard models.Card, err error) {
+	number, expirationDate, holderName, cvv := card.Number, card.ExpirationDate, card.HolderName, card.CVV
+	if err = c.seal(models.CardItem, card.ID, cardFields(&number, &expirationDate, &holderName, &cvv)); err != nil {
+		err = fmt.Errorf("UpdateCards: %w", err)
+		return
+	}
+	metadata, err := c.sealMetadata(models.CardItem, card.ID, card.Metadata)
 	if err != nil {
 		err = fmt.Errorf("UpdateCards: %w", err)
 		return
 	}
+	response, err := c.client.UpdateCard(c.getCtx(ctx), &pb.UpdateCardRequest{
+		Id:             card.ID,
+		Number:         number,
+		ExpirationDate: expirationDate,
+		HolderName:     holderName,
+		Cvv:            cvv,
+		Metadata:       metadata,
+		Version:        card.Version,
+	})
+	if err != nil {
+		err = fmt.Errorf("UpdateCards: %w", c.conflict(err))
+		return
+	}
 	updatedCard = card
 	updatedCard.ExpirationDate = response.ExpirationDate
+	if err = c.open(models.CardItem, card.ID, map[string]*string{"expiration_date": &updatedCard.ExpirationDate}); err != nil {
+		err = fmt.Errorf("UpdateCards: %w", err)
+		return
+	}
 	uploadedAt, _ := time.Parse(time.RFC3339, response.UploadedAt)
 	updatedCard.UploadedAt = uploadedAt
+	updatedCard.Version = response.Version
 
 	return
 }
 
-func (c *ClientService) DeleteCard(ctx context.Context, cardID string) (err error) {
-	_, err = c.client.DeleteCard(c.getCtx(ctx, c.token), &pb.DeleteCardRequest{
-		Id: cardID,
+// deleteCard removes card of given version on server.
+func (c *ClientService) deleteCard(ctx context.Context, cardID string, version int64) (err error) {
+	_, err = c.client.DeleteCard(c.getCtx(ctx), &pb.DeleteCardRequest{
+		Id:      cardID,
+		Version: version,
 	})
-	if err != nil {
+	if err = c.deletionConflict(err); err != nil {
 		err = fmt.Errorf("DeleteCard: %w", err)
+	}
+	return
+}
+
+// createNote sends new note to server.
+func (c *ClientService) createNote(ctx context.Context, note models.Note) error {
+	request, err := c.noteRequest(note)
+	if err != nil {
+		return fmt.Errorf("CreateNote: %w", err)
+	}
+	_, err = c.client.CreateNote(c.getCtx(ctx), request)
+	if err != nil {
+		return fmt.Errorf("CreateNote: %w", err)
+	}
+	return nil
+}
+
+// noteRequest seals note for creation on server.
+func (c *ClientService) noteRequest(note models.Note) (*pb.CreateNoteRequest, error) {
+	id := uuid.NewString()
+	title, body := note.Title, note.Body
+	if err := c.seal(models.NoteItem, id, map[string]*string{"body": &body}); err != nil {
+		return nil, err
+	}
+	metadata, err := c.sealMetadata(models.NoteItem, id, note.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CreateNoteRequest{
+		Id:       id,
+		Title:    title,
+		Body:     body,
+		Metadata: metadata,
+	}, nil
+}
+
+// getNotes receives notes from server.
+func (c *ClientService) getNotes(ctx context.Context) (notes []models.Note, err error) {
+	resp, err := c.client.GetNotes(c.getCtx(ctx), &pb.GetNotesRequest{})
+	if err != nil {
+		err = fmt.Errorf("GetNotes: %w", err)
 		return
 	}
+	for _, note := range resp.Notes {
+		var openedNote models.Note
+		if openedNote, err = c.openNote(note); err != nil {
+			err = fmt.Errorf("GetNotes: %w", err)
+			return
+		}
+		notes = append(notes, openedNote)
+	}
+	return
+}
 
+// openNote decrypts note received from server.
+func (c *ClientService) openNote(note *pb.GetNotesResponse_Note) (openedNote models.Note, err error) {
+	uploadedAt, _ := time.Parse(time.RFC3339, note.UploadedAt)
+	openedNote = models.Note{
+		ID:         note.Id,
+		Title:      note.Title,
+		Body:       note.Body,
+		UploadedAt: uploadedAt,
+		Version:    note.Version,
+		Metadata:   note.Metadata,
+	}
+	if err = c.open(models.NoteItem, openedNote.ID, map[string]*string{"body": &openedNote.Body}); err == nil {
+		err = c.openMetadata(models.NoteItem, openedNote.ID, openedNote.Metadata)
+	}
 	return
 }
 
-func (c *ClientService) UploadFile(ctx context.Context, filePath string) {
-	file, err := os.Open(filePath)
+// updateNote sends changed note to server.
+func (c *ClientService) updateNote(ctx context.Context, note models.Note) (updatedNote models.Note, err error) {
+	body := note.Body
+	if err = c.seal(models.NoteItem, note.ID, map[string]*string{"body": &body}); err != nil {
+		err = fmt.Errorf("UpdateNote: %w", err)
+		return
+	}
+	metadata, err := c.sealMetadata(models.NoteItem, note.ID, note.Metadata)
+	if err != nil {
+		err = fmt.Errorf("UpdateNote: %w", err)
+		return
+	}
+	response, err := c.client.UpdateNote(c.getCtx(ctx), &pb.UpdateNoteRequest{
+		Id:       note.ID,
+		Title:    note.Title,
+		Body:     body,
+		Metadata: metadata,
+		Version:  note.Version,
+	})
 	if err != nil {
-		logger.Log().Error("could not open file:", zap.Error(err))
+		err = fmt.Errorf("UpdateNote: %w", c.conflict(err))
 		return
 	}
-	defer file.Close()
+	updatedNote = note
+	updatedNote.Title = response.Title
+	uploadedAt, _ := time.Parse(time.RFC3339, response.UploadedAt)
+	updatedNote.UploadedAt = uploadedAt
+	updatedNote.Version = response.Version
+
+	return
+}
 
-	stream, err := c.client.UploadFile(c.getCtx(ctx, c.token))
+// deleteNote removes note of given version on server.
+func (c *ClientService) deleteNote(ctx context.Context, noteID string, version int64) (err error) {
+	_, err = c.client.DeleteNote(c.getCtx(ctx), &pb.DeleteNoteRequest{
+		Id:      noteID,
+		Version: version,
+	})
+	if err = c.deletionConflict(err); err != nil {
+		err = fmt.Errorf("DeleteNote: %w", err)
+	}
+	return
+}
+
+// itemTypes - types of vault items as they are passed to server
+var itemTypes = map[string]pb.ItemType{
+	models.CredentialsItem: pb.ItemType_ITEM_TYPE_CREDENTIALS,
+	models.CardItem:        pb.ItemType_ITEM_TYPE_CARD,
+	models.NoteItem:        pb.ItemType_ITEM_TYPE_NOTE,
+	models.FileItem:        pb.ItemType_ITEM_TYPE_FILE,
+}
+
+// ListItemHistory - return previous revisions of credentials, card or note from the newest to the oldest one
+func (c *ClientService) ListItemHistory(ctx context.Context, itemType, id string) (revisions []models.Revision, err error) {
+	response, err := c.client.ListItemHistory(c.getCtx(ctx), &pb.ListItemHistoryRequest{
+		ItemType: itemTypes[itemType],
+		Id:       id,
+	})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
 	if err != nil {
-		logger.Log().Error("could not upload file:", zap.Error(err))
+		err = fmt.Errorf("ListItemHistory: %w", err)
 		return
 	}
 
-	buffer := make([]byte, 1024)
-	for {
-		n, err := file.Read(buffer)
-		if err == io.EOF {
-			break
+	revisions = make([]models.Revision, 0, len(response.Revisions))
+	for _, revision := range response.Revisions {
+		revisedAt, _ := time.Parse(time.RFC3339, revision.RevisedAt)
+		opened := models.Revision{
+			ID:        revision.Id,
+			ItemID:    id,
+			ItemType:  itemType,
+			Version:   revision.Version,
+			RevisedAt: revisedAt,
+			Deleted:   revision.Deleted,
+		}
+		switch item := revision.Item.(type) {
+		case *pb.ItemRevision_Credential:
+			opened.Item, err = c.openCredentials(item.Credential)
+		case *pb.ItemRevision_Card:
+			opened.Item, err = c.openCard(item.Card)
+		case *pb.ItemRevision_Note:
+			opened.Item, err = c.openNote(item.Note)
 		}
 		if err != nil {
-			logger.Log().Error("could not read file:", zap.Error(err))
-			return
+			return nil, fmt.Errorf("ListItemHistory: %w", err)
 		}
+		revisions = append(revisions, opened)
+	}
+	return
+}
 
-		if err := stream.Send(&pb.UploadFileRequest{
-			Data:     buffer[:n],
-			Filename: filepath.Base(filePath),
-		}); err != nil {
-			logger.Log().Error("could not send chunk:", zap.Error(err))
-			return
-		}
+// RestoreItemRevision - replace item of given version by its previous revision
+func (c *ClientService) RestoreItemRevision(ctx context.Context, itemType, id, revisionID string, version int64) (err error) {
+	_, err = c.client.RestoreItemRevision(c.getCtx(ctx), &pb.RestoreItemRevisionRequest{
+		ItemType:   itemTypes[itemType],
+		Id:         id,
+		RevisionId: revisionID,
+		Version:    version,
+	})
+
+	if c.fallBackToCache(err) {
+		err = ErrOffline
 	}
-	if err := stream.CloseSend(); err != nil {
-		logger.Log().Error("could not close stream:", zap.Error(err))
-		return
+	if err != nil {
+		err = fmt.Errorf("RestoreItemRevision: %w", c.conflict(err))
 	}
 
-	resp, err := stream.Recv()
+	return
+}
+
+// ListTrash - return deleted items which can be restored until they are purged
+func (c *ClientService) ListTrash(ctx context.Context) (items []models.TrashItem, err error) {
+	response, err := c.client.ListTrash(c.getCtx(ctx), &pb.ListTrashRequest{})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
 	if err != nil {
-		logger.Log().Error("could not receive response:", zap.Error(err))
+		err = fmt.Errorf("ListTrash: %w", err)
 		return
 	}
-	logger.Log().Info("Response from server:", zap.Any("response", resp))
+
+	items = make([]models.TrashItem, 0, len(response.Items))
+	for _, trashed := range response.Items {
+		deletedAt, _ := time.Parse(time.RFC3339, trashed.DeletedAt)
+		item := models.TrashItem{DeletedAt: deletedAt}
+		switch opened := trashed.Item.(type) {
+		case *pb.TrashItem_Credential:
+			item.ItemType = models.CredentialsItem
+			item.Item, err = c.openCredentials(opened.Credential)
+		case *pb.TrashItem_Card:
+			item.ItemType = models.CardItem
+			item.Item, err = c.openCard(opened.Card)
+		case *pb.TrashItem_Note:
+			item.ItemType = models.NoteItem
+			item.Item, err = c.openNote(opened.Note)
+		case *pb.TrashItem_File:
+			item.ItemType = models.FileItem
+			var file models.File
+			file, err = c.openFile(opened.File)
+			if trashed.Id != "" {
+				file.ID = trashed.Id
+			}
+			item.Item = file
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ListTrash: %w", err)
+		}
+		items = append(items, item)
+	}
+	return
 }
 
-func (c *ClientService) DownloadsFile(ctx context.Context, name string) {
-	req := &pb.DownloadFileRequest{Name: name}
+// RestoreFromTrash - return deleted item back, files are identified by ID they have in trash
+func (c *ClientService) RestoreFromTrash(ctx context.Context, itemType, id string) (err error) {
+	_, err = c.client.RestoreFromTrash(c.getCtx(ctx), &pb.RestoreFromTrashRequest{
+		ItemType: itemTypes[itemType],
+		Id:       id,
+	})
 
-	stream, err := c.client.DownloadFile(c.getCtx(ctx, c.token), req)
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
 	if err != nil {
-		logger.Log().Error("error downloading file: ", zap.Error(err))
-		return
+		err = fmt.Errorf("RestoreFromTrash: %w", err)
 	}
 
-	localFile, err := os.Create(name)
+	return
+}
+
+// EmptyTrash - permanently delete all items from trash
+func (c *ClientService) EmptyTrash(ctx context.Context) (err error) {
+	_, err = c.client.EmptyTrash(c.getCtx(ctx), &pb.EmptyTrashRequest{})
+
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
 	if err != nil {
-		logger.Log().Error("could not create local file: ", zap.Error(err))
-		return
+		err = fmt.Errorf("EmptyTrash: %w", err)
 	}
-	defer localFile.Close()
 
-	for {
-		resp, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
+	return
+}
+
+// UploadFile encrypts file with its metadata and name while vault is unlocked and uploads it, upload is resumed
+// from committed offset if connection is lost.
+func (c *ClientService) UploadFile(ctx context.Context, filePath string, metadata map[string]string) {
+	u := &upload{filename: filepath.Base(filePath)}
+	if c.cipher != nil {
+		encryptedPath, err := c.encryptFile(filePath, u)
 		if err != nil {
-			logger.Log().Error("error receiving chunk: ", zap.Error(err))
+			logger.Log().Error("could not encrypt file:", zap.Error(err))
 			return
 		}
+		defer os.Remove(encryptedPath)
+		filePath = encryptedPath
+	}
+	// metadata is bound to name file is stored under, which is chosen on encryption
+	metadata, err := c.sealMetadata(models.FileItem, u.filename, metadata)
+	if err != nil {
+		logger.Log().Error("could not encrypt file metadata:", zap.Error(err))
+		return
+	}
+	u.metadata = metadata
 
-		if _, err := localFile.Write(resp.Data); err != nil {
-			logger.Log().Error("error writing to local file: ", zap.Error(err))
-			return
-		}
+	resp, err := c.uploadFile(ctx, filePath, u)
+	if err != nil {
+		logger.Log().Error("could not upload file:", zap.Error(err))
+		return
 	}
+	logger.Log().Info("Response from server:", zap.Any("response", resp))
+}
 
-	logger.Log().Info("File downloaded successfully.")
+// DownloadsFile downloads file with ID into working directory under its original name and decrypts it,
+// interrupted download is resumed from partially received data.
+func (c *ClientService) DownloadsFile(ctx context.Context, id string) {
+	path, err := c.downloadFile(ctx, id, ".")
+	if err != nil {
+		logger.Log().Error("error downloading file: ", zap.Error(err))
+		return
+	}
+	logger.Log().Info("File downloaded successfully.", zap.String("path", path))
 }
 
 func (c *ClientService) SubscribeToChanges(ctx context.Context) (grpc.ServerStreamingClient[pb.SubscribeToChangesResponse], error) {
-	return c.client.SubscribeToChanges(c.getCtx(ctx, c.token), &pb.SubscribeToChangesRequest{})
+	return c.client.SubscribeToChanges(c.getCtx(ctx), &pb.SubscribeToChangesRequest{})
+}
+
+// changeOperations - operations of change events as they are received from server
+var changeOperations = map[pb.ChangeOperation]string{
+	pb.ChangeOperation_CHANGE_OPERATION_CREATE: models.ChangeCreated,
+	pb.ChangeOperation_CHANGE_OPERATION_UPDATE: models.ChangeUpdated,
+	pb.ChangeOperation_CHANGE_OPERATION_DELETE: models.ChangeDeleted,
+}
+
+// OpenChangeEvent decrypts item of change event received from subscription.
+func (c *ClientService) OpenChangeEvent(event *pb.SubscribeToChangesResponse) (change models.ChangeEvent, err error) {
+	change = models.ChangeEvent{
+		Operation: changeOperations[event.Operation],
+		ItemID:    event.Id,
+		Version:   event.Version,
+		Device:    event.Device,
+	}
+	for itemType, message := range itemTypes {
+		if message == event.ItemType {
+			change.ItemType = itemType
+		}
+	}
+	switch item := event.Item.(type) {
+	case *pb.SubscribeToChangesResponse_Credential:
+		change.Item, err = c.openCredentials(item.Credential)
+	case *pb.SubscribeToChangesResponse_Card:
+		change.Item, err = c.openCard(item.Card)
+	case *pb.SubscribeToChangesResponse_Note:
+		change.Item, err = c.openNote(item.Note)
+	case *pb.SubscribeToChangesResponse_File:
+		change.Item, err = c.openFile(item.File)
+	}
+	if err != nil {
+		err = fmt.Errorf("OpenChangeEvent: %w", err)
+	}
+	return
 }
 
 // TryToConnect attempts to establish a connection with the gRPC server.
@@ -344,10 +1164,13 @@ func (c *ClientService) TryToConnect() bool {
 	return true
 }
 
-func (c *ClientService) getCtx(ctx context.Context, jwt string) context.Context {
+// getCtx - outgoing context authorized by access token of session, expiring token is refreshed transparently
+func (c *ClientService) getCtx(ctx context.Context) context.Context {
 	md := metadata.New(map[string]string{
-		"authorization": jwt,
-		"session":       c.sessionID,
+		"authorization":  c.accessToken(ctx),
+		"session":        c.sessionID,
+		"device":         c.device,
+		"client-version": clientVersion(),
 	})
 
 	newCtx := metadata.NewOutgoingContext(ctx, md)
@@ -1,19 +1,27 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/mock"
 	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestClientService_SignUp(t *testing.T) {
@@ -211,7 +219,7 @@ func TestClientService_CreateCredentials(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			c := ClientService{client: client}
-			err := c.CreateCredentials(context.Background(), tt.serviceName, tt.identity, tt.password)
+			err := c.CreateCredentials(context.Background(), tt.serviceName, tt.identity, tt.password, "", nil)
 
 			if tt.expectedErrorMsg != "" {
 				require.NotNil(t, err)
@@ -446,7 +454,7 @@ func TestClientService_DeleteCredentials(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			c := ClientService{client: client}
-			err := c.DeleteCredentials(context.Background(), tt.credentialsID)
+			err := c.DeleteCredentials(context.Background(), tt.credentialsID, 1)
 
 			if tt.expectedErrorMessage != "" {
 				require.Error(t, err)
@@ -503,7 +511,7 @@ func TestClientService_CreateCard(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			c := ClientService{client: client}
-			err := c.CreateCard(context.Background(), tt.number, tt.expirationDate, tt.holderName, tt.cvv)
+			err := c.CreateCard(context.Background(), tt.number, tt.expirationDate, tt.holderName, tt.cvv, nil)
 
 			if tt.expectedErrorMessage != "" {
 				require.Error(t, err)
@@ -832,7 +840,8 @@ func TestClientService_DeleteCard(t *testing.T) {
 			inputCardID: "validID",
 			mock: func() {
 				client.EXPECT().DeleteCard(gomock.Any(), &pb.DeleteCardRequest{
-					Id: "validID",
+					Id:      "validID",
+					Version: 1,
 				}).Return(&pb.DeleteCardResponse{}, nil)
 			},
 		},
@@ -841,7 +850,8 @@ func TestClientService_DeleteCard(t *testing.T) {
 			inputCardID: "errorID",
 			mock: func() {
 				client.EXPECT().DeleteCard(gomock.Any(), &pb.DeleteCardRequest{
-					Id: "errorID",
+					Id:      "errorID",
+					Version: 1,
 				}).Return(nil, errors.New("DeleteCard test error"))
 			},
 			expectedErrorMessage: "DeleteCard: DeleteCard test error",
@@ -852,7 +862,7 @@ func TestClientService_DeleteCard(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			c := ClientService{client: client}
-			err := c.DeleteCard(context.Background(), tt.inputCardID)
+			err := c.DeleteCard(context.Background(), tt.inputCardID, 1)
 
 			if tt.expectedErrorMessage != "" {
 				require.Error(t, err)
@@ -864,12 +874,205 @@ func TestClientService_DeleteCard(t *testing.T) {
 	}
 }
 
+func TestClientService_CreateNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+
+	testTable := []struct {
+		name                 string
+		mock                 func()
+		expectedErrorMessage string
+	}{
+		{
+			name: "Valid create",
+			mock: func() {
+				client.EXPECT().CreateNote(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *pb.CreateNoteRequest, _ ...interface{}) (*pb.CreateNoteResponse, error) {
+						require.Equal(t, "wifi", in.Title)
+						require.Equal(t, "line 1\nline 2", in.Body)
+						require.NotEmpty(t, in.Id, "note ID should be chosen by client")
+						return &pb.CreateNoteResponse{}, nil
+					})
+			},
+		},
+		{
+			name: "Error creating note",
+			mock: func() {
+				client.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(nil, errors.New("CreateNote test error"))
+			},
+			expectedErrorMessage: "CreateNote: CreateNote test error",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			err := c.CreateNote(context.Background(), "wifi", "line 1\nline 2", nil)
+
+			if tt.expectedErrorMessage != "" {
+				require.EqualError(t, err, tt.expectedErrorMessage)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClientService_GetNotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	uploadedAt := time.Now().Truncate(time.Second)
+
+	testTable := []struct {
+		name                 string
+		mock                 func()
+		expectedNotes        []models.Note
+		expectedErrorMessage string
+	}{
+		{
+			name: "Valid get",
+			mock: func() {
+				client.EXPECT().GetNotes(gomock.Any(), &pb.GetNotesRequest{}).Return(&pb.GetNotesResponse{
+					Notes: []*pb.GetNotesResponse_Note{
+						{Id: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt.Format(time.RFC3339)},
+					},
+				}, nil)
+			},
+			expectedNotes: []models.Note{{ID: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt}},
+		},
+		{
+			name: "Error getting notes",
+			mock: func() {
+				client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(nil, errors.New("GetNotes test error"))
+			},
+			expectedErrorMessage: "GetNotes: GetNotes test error",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			notes, err := c.GetNotes(context.Background())
+
+			if tt.expectedErrorMessage != "" {
+				require.EqualError(t, err, tt.expectedErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, notes, len(tt.expectedNotes))
+			for i := range notes {
+				require.Equal(t, tt.expectedNotes[i].Body, notes[i].Body)
+				require.True(t, tt.expectedNotes[i].UploadedAt.Equal(notes[i].UploadedAt))
+			}
+		})
+	}
+}
+
+func TestClientService_UpdateNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	note := models.Note{ID: "1", Title: "wifi", Body: "new secret"}
+
+	testTable := []struct {
+		name                 string
+		mock                 func()
+		expectedErrorMessage string
+	}{
+		{
+			name: "Valid update",
+			mock: func() {
+				client.EXPECT().UpdateNote(gomock.Any(), &pb.UpdateNoteRequest{
+					Id:    "1",
+					Title: "wifi",
+					Body:  "new secret",
+				}).Return(&pb.UpdateNoteResponse{Id: "1", Title: "wifi", UploadedAt: time.Now().Format(time.RFC3339)}, nil)
+			},
+		},
+		{
+			name: "Error updating note",
+			mock: func() {
+				client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(nil, errors.New("UpdateNote test error"))
+			},
+			expectedErrorMessage: "UpdateNote: UpdateNote test error",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			updatedNote, err := c.UpdateNote(context.Background(), note)
+
+			if tt.expectedErrorMessage != "" {
+				require.EqualError(t, err, tt.expectedErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, note.Body, updatedNote.Body)
+			require.False(t, updatedNote.UploadedAt.IsZero())
+		})
+	}
+}
+
+func TestClientService_DeleteNote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+
+	testTable := []struct {
+		name                 string
+		mock                 func()
+		inputNoteID          string
+		expectedErrorMessage string
+	}{
+		{
+			name:        "Valid delete",
+			inputNoteID: "validID",
+			mock: func() {
+				client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "validID", Version: 1}).Return(&pb.DeleteNoteResponse{}, nil)
+			},
+		},
+		{
+			name:        "Error deleting note",
+			inputNoteID: "errorID",
+			mock: func() {
+				client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "errorID", Version: 1}).Return(nil, errors.New("DeleteNote test error"))
+			},
+			expectedErrorMessage: "DeleteNote: DeleteNote test error",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			err := c.DeleteNote(context.Background(), tt.inputNoteID, 1)
+
+			if tt.expectedErrorMessage != "" {
+				require.EqualError(t, err, tt.expectedErrorMessage)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestClientService_UploadFile(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	tempFile, _ := os.CreateTemp("", "tempfile_")
 	defer tempFile.Close()
 	_, _ = tempFile.WriteString("test")
+	testDigest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
 
 	client := mock.NewMockGophKeeperServiceClient(ctrl)
 
@@ -886,7 +1089,10 @@ func TestClientService_UploadFile(t *testing.T) {
 				client.EXPECT().UploadFile(gomock.Any()).Return(stream, nil).Times(1)
 				stream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
 				stream.EXPECT().CloseSend().Return(nil).Times(1)
-				stream.EXPECT().Recv().Return(&pb.UploadFileResponse{Message: "File uploaded successful."}, nil).Times(1)
+				gomock.InOrder(
+					stream.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil),
+					stream.EXPECT().Recv().Return(&pb.UploadFileResponse{Message: "File uploaded successful.", Digest: testDigest}, nil),
+				)
 			},
 		},
 		{
@@ -922,6 +1128,7 @@ func TestClientService_UploadFile(t *testing.T) {
 				stream := mock.NewMockGophKeeperService_UploadFileClient(ctrl)
 				client.EXPECT().UploadFile(gomock.Any()).Return(stream, nil).Times(1)
 				stream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+				stream.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil).Times(1)
 				stream.EXPECT().CloseSend().Return(errors.New("test")).Times(1)
 			},
 		},
@@ -933,7 +1140,10 @@ func TestClientService_UploadFile(t *testing.T) {
 				client.EXPECT().UploadFile(gomock.Any()).Return(stream, nil).Times(1)
 				stream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
 				stream.EXPECT().CloseSend().Return(nil).Times(1)
-				stream.EXPECT().Recv().Return(&pb.UploadFileResponse{}, errors.New("test")).Times(1)
+				gomock.InOrder(
+					stream.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil),
+					stream.EXPECT().Recv().Return(&pb.UploadFileResponse{}, errors.New("test")),
+				)
 			},
 		},
 	}
@@ -942,16 +1152,75 @@ func TestClientService_UploadFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
 			c := ClientService{client: client}
-			c.UploadFile(context.Background(), tt.input)
+			c.UploadFile(context.Background(), tt.input, nil)
 		})
 	}
 }
 
+func TestClientService_UploadFile_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	transferRetryDelay = 0
+
+	data := bytes.Repeat([]byte("gophkeeper"), uploadChunkSize/5)
+	path := filepath.Join(t.TempDir(), "file.txt")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	digest := sha256.Sum256(data)
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	var offsets []int64
+	send := func(request *pb.UploadFileRequest) error {
+		if len(request.Data) > 0 {
+			offsets = append(offsets, request.Offset)
+		}
+		return nil
+	}
+
+	interrupted := mock.NewMockGophKeeperService_UploadFileClient(ctrl)
+	gomock.InOrder(
+		interrupted.EXPECT().Send(gomock.Any()).DoAndReturn(send),
+		interrupted.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil),
+		interrupted.EXPECT().Send(gomock.Any()).Return(io.EOF),
+		interrupted.EXPECT().Recv().Return(nil, status.Error(codes.Unavailable, "connection lost")),
+	)
+	resumed := mock.NewMockGophKeeperService_UploadFileClient(ctrl)
+	resumed.EXPECT().Send(gomock.Any()).DoAndReturn(send).Times(2)
+	resumed.EXPECT().CloseSend().Return(nil)
+	gomock.InOrder(
+		resumed.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload", Offset: uploadChunkSize}, nil),
+		resumed.EXPECT().Recv().Return(&pb.UploadFileResponse{Success: true, Digest: hex.EncodeToString(digest[:])}, nil),
+	)
+	gomock.InOrder(
+		client.EXPECT().UploadFile(gomock.Any()).Return(interrupted, nil),
+		client.EXPECT().ResumeUpload(gomock.Any(), &pb.ResumeUploadRequest{UploadId: "upload"}).
+			Return(&pb.ResumeUploadResponse{UploadId: "upload", Offset: uploadChunkSize}, nil),
+		client.EXPECT().UploadFile(gomock.Any()).Return(resumed, nil),
+	)
+
+	c := ClientService{client: client}
+	resp, err := c.uploadFile(context.Background(), path, &upload{filename: "file.txt"})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, []int64{0, uploadChunkSize}, offsets)
+
+	stream := mock.NewMockGophKeeperService_UploadFileClient(ctrl)
+	client.EXPECT().UploadFile(gomock.Any()).Return(stream, nil)
+	stream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+	stream.EXPECT().CloseSend().Return(nil)
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil),
+		stream.EXPECT().Recv().Return(&pb.UploadFileResponse{Success: true, Digest: "other"}, nil),
+	)
+	_, err = c.uploadFile(context.Background(), path, &upload{filename: "file.txt"})
+	require.ErrorIs(t, err, ErrUploadDigestMismatch)
+}
+
 func TestClientService_DownloadsFile(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	testDataDigest := "916f0027a575074ce72a331777c3478d6513f786a591bd892da1a577bf2335f9"
 
 	testTable := []struct {
 		name                 string
@@ -965,8 +1234,11 @@ func TestClientService_DownloadsFile(t *testing.T) {
 			mock: func() {
 				stream := mock.NewMockGophKeeperService_DownloadFileClient(ctrl)
 				client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: "validFile"}).Return(stream, nil)
-				stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Data: []byte("test data")}, nil).Times(1)
-				stream.EXPECT().Recv().Return(nil, io.EOF)
+				gomock.InOrder(
+					stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Size: 9, Digest: testDataDigest}, nil),
+					stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Data: []byte("test data")}, nil),
+					stream.EXPECT().Recv().Return(nil, io.EOF),
+				)
 			},
 		},
 		{
@@ -986,11 +1258,12 @@ func TestClientService_DownloadsFile(t *testing.T) {
 			},
 		},
 		{
-			name:  "Can not Create",
-			input: "",
+			name:  "Invalid digest",
+			input: "validFile",
 			mock: func() {
 				stream := mock.NewMockGophKeeperService_DownloadFileClient(ctrl)
-				client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: ""}).Return(stream, nil)
+				client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: "validFile"}).Return(stream, nil)
+				stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Size: 9, Digest: "../file"}, nil)
 			},
 		},
 	}
@@ -1017,6 +1290,71 @@ func TestClientService_DownloadsFile(t *testing.T) {
 	}
 }
 
+func TestClientService_DownloadsFile_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	transferRetryDelay = 0
+
+	data := []byte("gophkeeper")
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client}
+	download := func(offset int64, chunks ...*pb.DownloadFileResponse) *gomock.Call {
+		stream := mock.NewMockGophKeeperService_DownloadFileClient(ctrl)
+		calls := []any{stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Size: int64(len(data)), Digest: digest, Offset: offset}, nil)}
+		for _, chunk := range chunks {
+			calls = append(calls, stream.EXPECT().Recv().Return(chunk, nil))
+		}
+		calls = append(calls, stream.EXPECT().Recv().Return(nil, io.EOF).MaxTimes(1))
+		gomock.InOrder(calls...)
+		return client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: "file.txt", Offset: offset}).DoAndReturn(
+			func(ctx context.Context, in *pb.DownloadFileRequest, opts ...grpc.CallOption) (pb.GophKeeperService_DownloadFileClient, error) {
+				if ctx.Err() != nil {
+					return nil, status.FromContextError(ctx.Err()).Err()
+				}
+				return stream, nil
+			})
+	}
+
+	interrupted := mock.NewMockGophKeeperService_DownloadFileClient(ctrl)
+	gomock.InOrder(
+		interrupted.EXPECT().Recv().Return(&pb.DownloadFileResponse{Size: int64(len(data)), Digest: digest}, nil),
+		interrupted.EXPECT().Recv().Return(&pb.DownloadFileResponse{Data: data[:4]}, nil),
+		interrupted.EXPECT().Recv().Return(nil, status.Error(codes.Unavailable, "connection lost")),
+	)
+	gomock.InOrder(
+		client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: "file.txt"}).Return(interrupted, nil),
+		download(4, &pb.DownloadFileResponse{Data: data[4:], Offset: 4}),
+	)
+	result, err := c.downloadFile(context.Background(), "file.txt", dir)
+	require.NoError(t, err)
+	require.Equal(t, path, result)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, data, content)
+	partial, _ := findPartial(path)
+	require.Empty(t, partial)
+
+	// partial data of file which was changed on server is discarded
+	require.NoError(t, os.WriteFile(partialPath(path, "0123"), []byte("stale"), 0600))
+	gomock.InOrder(
+		download(5),
+		download(0, &pb.DownloadFileResponse{Data: data}),
+	)
+	_, err = c.downloadFile(context.Background(), "file.txt", dir)
+	require.NoError(t, err)
+
+	download(0, &pb.DownloadFileResponse{Data: []byte("corrupted!")})
+	_, err = c.downloadFile(context.Background(), "file.txt", dir)
+	require.ErrorIs(t, err, ErrDownloadDigestMismatch)
+	partial, _ = findPartial(path)
+	require.Empty(t, partial)
+}
+
 func testChdir(t *testing.T, fn func() error) (err error) {
 	tDir, err := os.MkdirTemp("", "")
 	if err != nil {
@@ -1060,3 +1398,849 @@ func TestClientService_TryToConnect_Error(t *testing.T) {
 	isConnected := c.TryToConnect()
 	require.True(t, isConnected)
 }
+
+func newTestVault(t *testing.T, masterPassword string) (salt string, keyCheck string, cipher *vault.Cipher) {
+	salt, err := vault.NewSalt()
+	require.NoError(t, err)
+	cipher, err = newVaultCipher(masterPassword, salt)
+	require.NoError(t, err)
+	keyCheck, err = cipher.KeyCheck()
+	require.NoError(t, err)
+	return
+}
+
+func TestClientService_UnlockVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	salt, keyCheck, _ := newTestVault(t, "master")
+
+	testTable := []struct {
+		name           string
+		masterPassword string
+		mock           func()
+		expectedErr    error
+		expectedMsg    string
+	}{
+		{
+			name:           "Not initialized vault",
+			masterPassword: "master",
+			mock: func() {
+				client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(&pb.GetVaultParamsResponse{}, nil)
+			},
+			expectedErr: ErrVaultNotInitialized,
+		},
+		{
+			name:           "Wrong master password",
+			masterPassword: "wrong",
+			mock: func() {
+				client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).
+					Return(&pb.GetVaultParamsResponse{Salt: salt, KeyCheck: keyCheck}, nil)
+			},
+			expectedErr: vault.ErrWrongMasterPassword,
+		},
+		{
+			name:           "Server error",
+			masterPassword: "master",
+			mock: func() {
+				client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+			},
+			expectedMsg: "UnlockVault: test error",
+		},
+		{
+			name:           "Unlocked",
+			masterPassword: "master",
+			mock: func() {
+				client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).
+					Return(&pb.GetVaultParamsResponse{Salt: salt, KeyCheck: keyCheck}, nil)
+				client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(&pb.GetCredentialsResponse{}, nil)
+				client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{}, nil)
+				client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			err := c.UnlockVault(context.Background(), tt.masterPassword)
+
+			switch {
+			case tt.expectedErr != nil:
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.Nil(t, c.cipher)
+			case tt.expectedMsg != "":
+				require.EqualError(t, err, tt.expectedMsg)
+			default:
+				require.NoError(t, err)
+				require.NotNil(t, c.cipher)
+			}
+		})
+	}
+}
+
+func TestClientService_InitVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	var initRequest *pb.InitVaultRequest
+	var updatedCredentials *pb.UpdateCredentialsRequest
+	var updatedCard *pb.UpdateCardRequest
+	var updatedNote *pb.UpdateNoteRequest
+
+	client.EXPECT().InitVault(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.InitVaultRequest, _ ...interface{}) (*pb.InitVaultResponse, error) {
+			initRequest = in
+			return &pb.InitVaultResponse{}, nil
+		})
+	client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(&pb.GetCredentialsResponse{
+		Credentials: []*pb.GetCredentialsResponse_Credential{
+			{Id: "id1", ServiceName: "aws", Identity: "identity", Password: "password"},
+		},
+	}, nil)
+	client.EXPECT().UpdateCredentials(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateCredentialsRequest, _ ...interface{}) (*pb.UpdateCredentialsResponse, error) {
+			updatedCredentials = in
+			return &pb.UpdateCredentialsResponse{Id: in.Id, ServiceName: in.ServiceName}, nil
+		})
+	client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{
+		Cards: []*pb.GetCardsResponse_Card{
+			{Id: "id2", Number: "9426455762927963", ExpirationDate: "02/27", HolderName: "John Doe", Cvv: "123"},
+		},
+	}, nil)
+	client.EXPECT().UpdateCard(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateCardRequest, _ ...interface{}) (*pb.UpdateCardResponse, error) {
+			updatedCard = in
+			return &pb.UpdateCardResponse{ExpirationDate: in.ExpirationDate}, nil
+		})
+
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{
+		Notes: []*pb.GetNotesResponse_Note{{Id: "id3", Title: "wifi", Body: "secret"}},
+	}, nil)
+	client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateNoteRequest, _ ...interface{}) (*pb.UpdateNoteResponse, error) {
+			updatedNote = in
+			return &pb.UpdateNoteResponse{Id: in.Id, Title: in.Title}, nil
+		})
+
+	c := ClientService{client: client}
+	require.NoError(t, c.InitVault(context.Background(), "master"))
+
+	require.NotNil(t, c.cipher)
+	require.NotEmpty(t, initRequest.Salt)
+	require.NoError(t, c.cipher.VerifyKeyCheck(initRequest.KeyCheck))
+
+	require.Equal(t, "aws", updatedCredentials.ServiceName)
+	require.True(t, vault.IsSealed(updatedCredentials.Identity))
+	require.True(t, vault.IsSealed(updatedCredentials.Password))
+	password, err := c.cipher.Open(updatedCredentials.Password, vault.Binding(models.CredentialsItem, "id1", "password"))
+	require.NoError(t, err)
+	require.Equal(t, "password", password)
+
+	for _, value := range []string{updatedCard.Number, updatedCard.ExpirationDate, updatedCard.HolderName, updatedCard.Cvv} {
+		require.True(t, vault.IsSealed(value))
+	}
+
+	require.Equal(t, "wifi", updatedNote.Title)
+	require.True(t, vault.IsSealed(updatedNote.Body))
+}
+
+func TestClientService_ChangeMasterPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	salt, keyCheck, oldCipher := newTestVault(t, "old")
+	binding := vault.Binding(models.NoteItem, "id1", "body")
+	sealed, err := oldCipher.Seal("secret", binding)
+	require.NoError(t, err)
+
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).
+		Return(&pb.GetVaultParamsResponse{Salt: salt, KeyCheck: keyCheck}, nil)
+	c := ClientService{client: client}
+	require.ErrorIs(t, c.ChangeMasterPassword(context.Background(), "wrong", "new"), vault.ErrWrongMasterPassword)
+
+	var changeRequest *pb.ChangeMasterPasswordRequest
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).
+		Return(&pb.GetVaultParamsResponse{Salt: salt, KeyCheck: keyCheck}, nil)
+	client.EXPECT().ChangeMasterPassword(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.ChangeMasterPasswordRequest, _ ...interface{}) (*pb.ChangeMasterPasswordResponse, error) {
+			changeRequest = in
+			return &pb.ChangeMasterPasswordResponse{}, nil
+		})
+	require.NoError(t, c.ChangeMasterPassword(context.Background(), "old", "new"))
+	require.Equal(t, keyCheck, changeRequest.OldKeyCheck)
+	require.NotEqual(t, salt, changeRequest.Salt)
+
+	unlock := func(masterPassword string, params *pb.GetVaultParamsResponse) (*vault.Cipher, error) {
+		client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(params, nil)
+		client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(&pb.GetCredentialsResponse{}, nil).AnyTimes()
+		client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{}, nil).AnyTimes()
+		client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil).AnyTimes()
+		unlocked := ClientService{client: client}
+		err := unlocked.UnlockVault(context.Background(), masterPassword)
+		return unlocked.cipher, err
+	}
+	changed := &pb.GetVaultParamsResponse{
+		Salt:       changeRequest.Salt,
+		KeyCheck:   changeRequest.KeyCheck,
+		WrappedKey: changeRequest.WrappedKey,
+	}
+	_, err = unlock("old", changed)
+	require.ErrorIs(t, err, vault.ErrWrongMasterPassword)
+	cipher, err := unlock("new", changed)
+	require.NoError(t, err)
+	body, err := cipher.Open(sealed, binding)
+	require.NoError(t, err)
+	require.Equal(t, "secret", body, "items sealed before change should stay readable")
+
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(changed, nil)
+	client.EXPECT().ChangeMasterPassword(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.ChangeMasterPasswordRequest, _ ...interface{}) (*pb.ChangeMasterPasswordResponse, error) {
+			changeRequest = in
+			return &pb.ChangeMasterPasswordResponse{}, nil
+		})
+	require.NoError(t, c.ChangeMasterPassword(context.Background(), "new", "newer"))
+	cipher, err = unlock("newer", &pb.GetVaultParamsResponse{
+		Salt:       changeRequest.Salt,
+		KeyCheck:   changeRequest.KeyCheck,
+		WrappedKey: changeRequest.WrappedKey,
+	})
+	require.NoError(t, err)
+	body, err = cipher.Open(sealed, binding)
+	require.NoError(t, err)
+	require.Equal(t, "secret", body)
+
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(changed, nil)
+	client.EXPECT().ChangeMasterPassword(gomock.Any(), gomock.Any()).
+		Return(nil, status.Error(codes.FailedPrecondition, "vault was changed, unlock it again"))
+	require.Error(t, c.ChangeMasterPassword(context.Background(), "new", "newer"))
+}
+
+func TestClientService_SealLegacyItems_PartiallySealed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: client, cipher: cipher}
+	seal := func(value, itemType, id, field string) string {
+		sealed, err := cipher.Seal(value, vault.Binding(itemType, id, field))
+		require.NoError(t, err)
+		return sealed
+	}
+	var updatedCredentials *pb.UpdateCredentialsRequest
+	var updatedCard *pb.UpdateCardRequest
+
+	client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(&pb.GetCredentialsResponse{
+		Credentials: []*pb.GetCredentialsResponse_Credential{
+			{Id: "id1", ServiceName: "aws", Identity: seal("identity", models.CredentialsItem, "id1", "identity"), Password: "password",
+				Totp: seal("", models.CredentialsItem, "id1", "totp")},
+		},
+	}, nil)
+	client.EXPECT().UpdateCredentials(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateCredentialsRequest, _ ...interface{}) (*pb.UpdateCredentialsResponse, error) {
+			updatedCredentials = in
+			return &pb.UpdateCredentialsResponse{Id: in.Id, ServiceName: in.ServiceName}, nil
+		})
+	client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{
+		Cards: []*pb.GetCardsResponse_Card{
+			{Id: "id2", Number: seal("9426455762927963", models.CardItem, "id2", "number"),
+				ExpirationDate: seal("02/27", models.CardItem, "id2", "expiration_date"), HolderName: "John Doe",
+				Cvv: seal("123", models.CardItem, "id2", "cvv")},
+		},
+	}, nil)
+	client.EXPECT().UpdateCard(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateCardRequest, _ ...interface{}) (*pb.UpdateCardResponse, error) {
+			updatedCard = in
+			return &pb.UpdateCardResponse{ExpirationDate: in.ExpirationDate}, nil
+		})
+	// sealed note is not uploaded again
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{
+		Notes: []*pb.GetNotesResponse_Note{{Id: "id3", Title: "wifi", Body: seal("secret", models.NoteItem, "id3", "body"),
+			Metadata: map[string]string{"room": seal("1", models.NoteItem, "id3", metadataField("room"))}}},
+	}, nil)
+
+	require.NoError(t, c.sealLegacyItems(context.Background()))
+
+	for _, tt := range []struct {
+		value, binding, want string
+	}{
+		{updatedCredentials.Identity, vault.Binding(models.CredentialsItem, "id1", "identity"), "identity"},
+		{updatedCredentials.Password, vault.Binding(models.CredentialsItem, "id1", "password"), "password"},
+		{updatedCard.Number, vault.Binding(models.CardItem, "id2", "number"), "9426455762927963"},
+		{updatedCard.HolderName, vault.Binding(models.CardItem, "id2", "holder_name"), "John Doe"},
+		{updatedCard.Cvv, vault.Binding(models.CardItem, "id2", "cvv"), "123"},
+	} {
+		opened, err := cipher.Open(tt.value, tt.binding)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, opened, "value should be sealed once")
+	}
+}
+
+func TestClientService_EncryptedRoundTrip(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	var stored *pb.CreateCredentialsRequest
+
+	client.EXPECT().CreateCredentials(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.CreateCredentialsRequest, _ ...interface{}) (*pb.CreateCredentialsResponse, error) {
+			stored = in
+			return &pb.CreateCredentialsResponse{}, nil
+		})
+	client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *pb.GetCredentialsRequest, _ ...interface{}) (*pb.GetCredentialsResponse, error) {
+			return &pb.GetCredentialsResponse{Credentials: []*pb.GetCredentialsResponse_Credential{
+				{Id: stored.Id, ServiceName: stored.ServiceName, Identity: stored.Identity, Password: stored.Password,
+					Totp: stored.Totp, Metadata: stored.Metadata},
+			}}, nil
+		})
+
+	c := ClientService{client: client, cipher: cipher}
+	metadata := map[string]string{"env": "prod"}
+	require.NoError(t, c.CreateCredentials(context.Background(), "aws", "identity", "password", "JBSWY3DPEHPK3PXP", metadata))
+	require.Equal(t, "aws", stored.ServiceName)
+	require.NotContains(t, stored.Password, "password")
+	require.NotContains(t, stored.Identity, "identity")
+	require.True(t, vault.IsSealed(stored.Totp))
+	require.True(t, vault.IsSealed(stored.Metadata["env"]))
+	require.Equal(t, "prod", metadata["env"])
+
+	credentials, err := c.GetCredentials(context.Background())
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	require.Equal(t, "identity", credentials[0].Identity)
+	require.Equal(t, "password", credentials[0].Password)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", credentials[0].TOTP)
+	require.Equal(t, metadata, credentials[0].Metadata)
+
+	// values moved by server to other field or item are not opened
+	_, err = c.openCredentials(&pb.GetCredentialsResponse_Credential{Id: stored.Id, Identity: stored.Password})
+	require.Error(t, err)
+	_, err = c.openCredentials(&pb.GetCredentialsResponse_Credential{Id: "other", Password: stored.Password})
+	require.Error(t, err)
+}
+
+func TestClientService_UpdateFileMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: client, cipher: cipher}
+
+	client.EXPECT().UpdateFileMetadata(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateFileMetadataRequest, _ ...interface{}) (*pb.UpdateFileMetadataResponse, error) {
+			require.Equal(t, "report.pdf", in.Name)
+			require.True(t, vault.IsSealed(in.Metadata["year"]))
+			return &pb.UpdateFileMetadataResponse{}, nil
+		})
+	require.NoError(t, c.UpdateFileMetadata(context.Background(), "report.pdf", map[string]string{"year": "2024"}))
+
+	client.EXPECT().UpdateFileMetadata(gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+	err := c.UpdateFileMetadata(context.Background(), "report.pdf", nil)
+	require.EqualError(t, err, "UpdateFileMetadata: test error")
+}
+
+func TestClientService_Offline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	salt, keyCheck, _ := newTestVault(t, "master")
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	expiresAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	c := ClientService{client: client, cacheDir: t.TempDir(), serverAddress: ":3200"}
+
+	client.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.ErrorIs(t, c.SignIn("test@test.com", "password"), ErrNoCache)
+
+	// first online session fills the cache
+	client.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(&pb.SignInResponse{Token: "token", RefreshToken: "refresh", ExpiresAt: expiresAt}, nil)
+	require.NoError(t, c.SignIn("test@test.com", "password"))
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).
+		Return(&pb.GetVaultParamsResponse{Salt: salt, KeyCheck: keyCheck}, nil)
+	client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(&pb.GetCredentialsResponse{}, nil)
+	client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{}, nil)
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil)
+	require.NoError(t, c.UnlockVault(context.Background(), "master"))
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{Notes: []*pb.GetNotesResponse_Note{
+		{Id: "1", Title: "wifi", Body: "secret"},
+	}}, nil)
+	_, err := c.GetNotes(context.Background())
+	require.NoError(t, err)
+
+	// next session starts while server is unreachable
+	require.NoError(t, c.cache.Close())
+	c = ClientService{client: client, cacheDir: c.cacheDir, serverAddress: ":3200"}
+	client.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.NoError(t, c.SignIn("test@test.com", "password"))
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.Error(t, c.UnlockVault(context.Background(), "wrong"))
+	client.EXPECT().GetVaultParams(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.NoError(t, c.UnlockVault(context.Background(), "master"))
+
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	notes, err := c.GetNotes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []models.Note{{ID: "1", Title: "wifi", Body: "secret"}}, notes)
+
+	client.EXPECT().CreateNote(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.NoError(t, c.CreateNote(context.Background(), "door", "1234", nil))
+	client.EXPECT().RefreshToken(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	client.EXPECT().DeleteNote(gomock.Any(), gomock.Any()).Return(nil, unavailable)
+	require.NoError(t, c.DeleteNote(context.Background(), "1", 1))
+	online, pending, _ := c.SyncStatus()
+	require.False(t, online)
+	require.Equal(t, 2, pending)
+
+	// connectivity returns, queued changes are replayed in order before the request
+	var created *pb.CreateNoteRequest
+	gomock.InOrder(
+		client.EXPECT().RefreshToken(gomock.Any(), &pb.RefreshTokenRequest{RefreshToken: "refresh"}).
+			Return(&pb.RefreshTokenResponse{Token: "token", RefreshToken: "rotated", ExpiresAt: expiresAt}, nil),
+		client.EXPECT().CreateNote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.CreateNoteRequest, _ ...interface{}) (*pb.CreateNoteResponse, error) {
+				created = in
+				return &pb.CreateNoteResponse{Id: "2", Title: in.Title}, nil
+			}),
+		client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "1", Version: 1}).Return(&pb.DeleteNoteResponse{}, nil),
+		client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil),
+	)
+	_, err = c.GetNotes(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "door", created.Title)
+	require.True(t, vault.IsSealed(created.Body))
+	online, pending, _ = c.SyncStatus()
+	require.True(t, online)
+	require.Zero(t, pending)
+	refreshToken, err := c.cache.refreshToken()
+	require.NoError(t, err)
+	require.Equal(t, "rotated", refreshToken, "rotated refresh token is kept for next offline session")
+}
+
+func TestClientService_OfflineSessionExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	cache := newTestCache(t)
+	cache.unlock(cipher)
+	require.NoError(t, cache.saveRefreshToken("refresh"))
+	require.NoError(t, cache.queue(notesKind, createAction, "local-1", models.Note{Title: "door"}))
+	c := ClientService{client: client, cipher: cipher, cache: cache}
+
+	client.EXPECT().RefreshToken(gomock.Any(), &pb.RefreshTokenRequest{RefreshToken: "refresh"}).
+		Return(nil, status.Error(codes.Unauthenticated, "session is expired"))
+	_, err := c.Sync(context.Background())
+	require.ErrorIs(t, err, ErrSessionExpired)
+	require.Equal(t, 1, cache.PendingCount(), "offline change waits for next sign in")
+	refreshToken, err := cache.refreshToken()
+	require.NoError(t, err)
+	require.Empty(t, refreshToken, "expired refresh token is forgotten")
+
+	_, err = c.Sync(context.Background())
+	require.ErrorIs(t, err, ErrSessionExpired, "no request is made without refresh token")
+	require.ErrorIs(t, c.CreateNote(context.Background(), "wifi", "secret", nil), ErrSessionExpired)
+	_, err = c.GetNotes(context.Background())
+	require.ErrorIs(t, err, ErrSessionExpired)
+	require.ErrorIs(t, c.DeleteNote(context.Background(), "1", 1), ErrSessionExpired)
+	require.Equal(t, 1, cache.PendingCount(), "no change is made before user signs in again")
+}
+
+func TestClientService_VersionConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	sealedBody, err := cipher.Seal("their secret", vault.Binding(models.NoteItem, "1", "body"))
+	require.NoError(t, err)
+	conflict, err := status.New(codes.Aborted, "note was changed by another client").
+		WithDetails(&pb.GetNotesResponse_Note{Id: "1", Title: "wifi", Body: sealedBody, Version: 3})
+	require.NoError(t, err)
+
+	c := ClientService{client: client, cipher: cipher}
+	client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, in *pb.UpdateNoteRequest, _ ...interface{}) (*pb.UpdateNoteResponse, error) {
+			require.Equal(t, int64(2), in.Version)
+			return nil, conflict.Err()
+		})
+	_, err = c.UpdateNote(context.Background(), models.Note{ID: "1", Title: "wifi", Body: "my secret", Version: 2})
+	var conflictErr *ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	require.Equal(t, models.Note{ID: "1", Title: "wifi", Body: "their secret", Version: 3}, conflictErr.Server)
+
+	client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "1", Version: 2}).Return(nil, conflict.Err())
+	require.ErrorAs(t, c.DeleteNote(context.Background(), "1", 2), &conflictErr)
+
+	client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "1", Version: 3}).
+		Return(nil, status.Error(codes.Aborted, "note was deleted by another client"))
+	require.NoError(t, c.DeleteNote(context.Background(), "1", 3), "note is already deleted")
+}
+
+func TestClientService_ReplayKeepsVersions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	cache := newTestCache(t)
+	cache.unlock(cipher)
+	c := ClientService{client: client, cipher: cipher, cache: cache, token: "token"}
+
+	require.NoError(t, cache.queue(notesKind, updateAction, "1", models.Note{ID: "1", Title: "wifi", Version: 1}))
+	require.NoError(t, cache.queue(notesKind, updateAction, "1", models.Note{ID: "1", Title: "home wifi", Version: 1}))
+	require.NoError(t, cache.queue(notesKind, deleteAction, "1", models.Note{ID: "1", Version: 1}))
+
+	gomock.InOrder(
+		client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.UpdateNoteRequest, _ ...interface{}) (*pb.UpdateNoteResponse, error) {
+				require.Equal(t, int64(1), in.Version)
+				return &pb.UpdateNoteResponse{Id: "1", Title: in.Title, Version: 2}, nil
+			}),
+		client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, in *pb.UpdateNoteRequest, _ ...interface{}) (*pb.UpdateNoteResponse, error) {
+				require.Equal(t, int64(2), in.Version, "second offline change is based on the first one")
+				return &pb.UpdateNoteResponse{Id: "1", Title: in.Title, Version: 3}, nil
+			}),
+		client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "1", Version: 3}).Return(&pb.DeleteNoteResponse{}, nil),
+	)
+	require.NoError(t, c.replay(context.Background()))
+	require.Zero(t, cache.PendingCount())
+}
+
+func TestClientService_ReplayConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	cache := newTestCache(t)
+	cache.unlock(cipher)
+	c := ClientService{client: client, cipher: cipher, cache: cache, token: "token"}
+	sealedBody, err := cipher.Seal("their secret", vault.Binding(models.NoteItem, "1", "body"))
+	require.NoError(t, err)
+	conflict, err := status.New(codes.Aborted, "note was changed by another client").
+		WithDetails(&pb.GetNotesResponse_Note{Id: "1", Title: "wifi", Body: sealedBody, Version: 3})
+	require.NoError(t, err)
+
+	base := models.Note{ID: "1", Title: "wifi", Body: "secret", Version: 2}
+	mine := models.Note{ID: "1", Title: "wifi", Body: "my secret", Version: 2}
+	require.NoError(t, cache.replace(notesKind, map[string]any{"1": base}))
+	require.NoError(t, cache.queue(cardsKind, createAction, "local-1", models.Card{Number: "1"}))
+	require.NoError(t, cache.queue(notesKind, updateAction, "1", mine))
+	require.NoError(t, cache.queue(notesKind, deleteAction, "2", models.Note{ID: "2", Version: 1}))
+
+	gomock.InOrder(
+		client.EXPECT().CreateCard(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.InvalidArgument, "card number is not valid")),
+		client.EXPECT().UpdateNote(gomock.Any(), gomock.Any()).Return(nil, conflict.Err()),
+	)
+	require.NoError(t, c.replayPending(context.Background()))
+	online, pending, dropped := c.SyncStatus()
+	require.True(t, online)
+	require.Equal(t, 2, pending, "conflicting change and changes after it are kept")
+	require.Equal(t, 1, dropped, "rejected change is dropped")
+
+	require.NoError(t, c.replayPending(context.Background()))
+	replayConflict, err := c.TakeReplayConflict()
+	require.NoError(t, err)
+	require.Equal(t, models.NoteItem, replayConflict.ItemType)
+	require.Equal(t, base, replayConflict.Base)
+	require.Equal(t, mine, replayConflict.Mine)
+	var conflictErr *ConflictError
+	require.ErrorAs(t, replayConflict.Err, &conflictErr)
+	require.Equal(t, models.Note{ID: "1", Title: "wifi", Body: "their secret", Version: 3}, conflictErr.Server)
+
+	replayConflict, err = c.TakeReplayConflict()
+	require.NoError(t, err)
+	require.Nil(t, replayConflict)
+	client.EXPECT().DeleteNote(gomock.Any(), &pb.DeleteNoteRequest{Id: "2", Version: 1}).Return(&pb.DeleteNoteResponse{}, nil)
+	require.NoError(t, c.replayPending(context.Background()))
+	_, pending, _ = c.SyncStatus()
+	require.Zero(t, pending, "replay continues after conflict was taken")
+}
+
+func TestClientService_ItemHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	sealedPassword, err := cipher.Seal("old password", vault.Binding(models.CredentialsItem, "1", "password"))
+	require.NoError(t, err)
+	revisedAt := time.Now().Truncate(time.Second)
+
+	c := ClientService{client: client, cipher: cipher}
+	client.EXPECT().ListItemHistory(gomock.Any(), &pb.ListItemHistoryRequest{ItemType: pb.ItemType_ITEM_TYPE_CREDENTIALS, Id: "1"}).
+		Return(&pb.ListItemHistoryResponse{Revisions: []*pb.ItemRevision{{
+			Id: "r1", Version: 1, RevisedAt: revisedAt.Format(time.RFC3339),
+			Item: &pb.ItemRevision_Credential{Credential: &pb.GetCredentialsResponse_Credential{
+				Id: "1", ServiceName: "aws", Identity: "admin", Password: sealedPassword, Version: 1}},
+		}}}, nil)
+	revisions, err := c.ListItemHistory(context.Background(), models.CredentialsItem, "1")
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.True(t, revisedAt.Equal(revisions[0].RevisedAt))
+	require.Equal(t, models.Credentials{ID: "1", ServiceName: "aws", Identity: "admin", Password: "old password", Version: 1},
+		revisions[0].Item, "revision should be decrypted")
+
+	client.EXPECT().RestoreItemRevision(gomock.Any(), &pb.RestoreItemRevisionRequest{
+		ItemType: pb.ItemType_ITEM_TYPE_CREDENTIALS, Id: "1", RevisionId: "r1", Version: 2}).
+		Return(&pb.RestoreItemRevisionResponse{Version: 3}, nil)
+	require.NoError(t, c.RestoreItemRevision(context.Background(), models.CredentialsItem, "1", "r1", 2))
+
+	client.EXPECT().RestoreItemRevision(gomock.Any(), gomock.Any()).
+		Return(nil, status.Error(codes.Aborted, "credentials was deleted by another client"))
+	var conflictErr *ConflictError
+	require.ErrorAs(t, c.RestoreItemRevision(context.Background(), models.CredentialsItem, "1", "r1", 3), &conflictErr)
+}
+
+func TestClientService_Trash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	sealedNumber, err := cipher.Seal("1234567890123456", vault.Binding(models.CardItem, "1", "number"))
+	require.NoError(t, err)
+	deletedAt := time.Now().Truncate(time.Second)
+
+	c := ClientService{client: client, cipher: cipher}
+	client.EXPECT().ListTrash(gomock.Any(), &pb.ListTrashRequest{}).Return(&pb.ListTrashResponse{Items: []*pb.TrashItem{
+		{ItemType: pb.ItemType_ITEM_TYPE_CARD, DeletedAt: deletedAt.Format(time.RFC3339),
+			Item: &pb.TrashItem_Card{Card: &pb.GetCardsResponse_Card{Id: "1", Number: sealedNumber, Version: 2}}},
+		{ItemType: pb.ItemType_ITEM_TYPE_FILE, DeletedAt: deletedAt.Format(time.RFC3339), Id: "report.pdf.1700000000",
+			Item: &pb.TrashItem_File{File: &pb.GetFilesResponse_File{Name: "report.pdf", Size: "1 KB"}}},
+	}}, nil)
+	items, err := c.ListTrash(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, models.CardItem, items[0].ItemType)
+	require.Equal(t, models.Card{ID: "1", Number: "1234567890123456", Version: 2}, items[0].Item, "card should be decrypted")
+	require.Equal(t, models.FileItem, items[1].ItemType)
+	require.Equal(t, "report.pdf", items[1].Item.(models.File).Name)
+	require.Equal(t, "report.pdf.1700000000", items[1].Item.(models.File).ID, "file should be restored by trash ID")
+	require.True(t, deletedAt.Equal(items[1].DeletedAt))
+
+	client.EXPECT().RestoreFromTrash(gomock.Any(), &pb.RestoreFromTrashRequest{ItemType: pb.ItemType_ITEM_TYPE_FILE, Id: "report.pdf.1700000000"}).
+		Return(&pb.RestoreFromTrashResponse{}, nil)
+	require.NoError(t, c.RestoreFromTrash(context.Background(), models.FileItem, "report.pdf.1700000000"))
+
+	client.EXPECT().EmptyTrash(gomock.Any(), &pb.EmptyTrashRequest{}).Return(nil, status.Error(codes.Internal, "trash can not be emptied"))
+	require.Error(t, c.EmptyTrash(context.Background()))
+}
+
+func TestClientService_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	sealedBody, err := cipher.Seal("secret", vault.Binding(models.NoteItem, "n1", "body"))
+	require.NoError(t, err)
+	sealedNumber, err := cipher.Seal("1234567890123456", vault.Binding(models.CardItem, "c1", "number"))
+	require.NoError(t, err)
+	c := ClientService{client: client, cipher: cipher}
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 0}).Return(&pb.SyncResponse{Cursor: 5, Full: true,
+		Cards: []*pb.GetCardsResponse_Card{{Id: "c1", Number: sealedNumber, Version: 1}},
+		Notes: []*pb.GetNotesResponse_Note{{Id: "n1", Title: "wifi", Body: sealedBody, Version: 1}},
+		Files: []*pb.GetFilesResponse_File{{Name: "report.pdf", Size: "1 KB"}},
+	}, nil)
+	vault, err := c.Sync(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(5), vault.Cursor)
+	require.Equal(t, []models.Card{{ID: "c1", Number: "1234567890123456", Version: 1}}, vault.Cards, "card should be decrypted")
+	require.Equal(t, []models.Note{{ID: "n1", Title: "wifi", Body: "secret", Version: 1}}, vault.Notes)
+	require.Len(t, vault.Files, 1)
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 5}).Return(&pb.SyncResponse{Cursor: 8,
+		Notes: []*pb.GetNotesResponse_Note{{Id: "n1", Title: "home wifi", Body: sealedBody, Version: 2}},
+		Deleted: []*pb.SyncResponse_DeletedItem{
+			{ItemType: pb.ItemType_ITEM_TYPE_CARD, Id: "c1"},
+			{ItemType: pb.ItemType_ITEM_TYPE_FILE, Id: "report.pdf"},
+		},
+	}, nil)
+	vault, err = c.Sync(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, vault.Cards, "deleted card should be dropped")
+	require.Empty(t, vault.Files, "deleted file should be dropped")
+	require.Equal(t, []models.Note{{ID: "n1", Title: "home wifi", Body: "secret", Version: 2}}, vault.Notes, "changes should be merged")
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 8}).Return(nil, status.Error(codes.Internal, "changes can not be retrieved"))
+	_, err = c.Sync(context.Background())
+	require.Error(t, err)
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 8}).Return(&pb.SyncResponse{Cursor: 2, Full: true}, nil)
+	vault, err = c.Sync(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, vault.Notes, "full sync should replace synced copy")
+}
+
+func TestClientService_SyncKeepsPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	cache := newTestCache(t)
+	cache.unlock(cipher)
+	sealedBody, err := cipher.Seal("secret", vault.Binding(models.NoteItem, "n1", "body"))
+	require.NoError(t, err)
+	// replay waits for conflict to be taken, so queued changes stay in queue during sync
+	c := ClientService{client: client, cipher: cipher, cache: cache, replayConflict: &replayConflict{}}
+
+	localID := newLocalID()
+	require.NoError(t, cache.queue(notesKind, createAction, localID, models.Note{ID: localID, Title: "draft"}))
+	require.NoError(t, cache.queue(notesKind, updateAction, "n1", models.Note{ID: "n1", Title: "home wifi", Body: "mine"}))
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 0}).Return(&pb.SyncResponse{Cursor: 5, Full: true,
+		Notes: []*pb.GetNotesResponse_Note{
+			{Id: "n1", Title: "wifi", Body: sealedBody, Version: 1},
+			{Id: "n2", Title: "door", Body: "1234", Version: 1},
+		},
+	}, nil)
+	_, err = c.Sync(context.Background())
+	require.NoError(t, err)
+	cached, err := loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.Note{
+		{ID: localID, Title: "draft"},
+		{ID: "n1", Title: "home wifi", Body: "mine"},
+		{ID: "n2", Title: "door", Body: "1234", Version: 1},
+	}, cached, "items with pending changes should keep local copy")
+
+	client.EXPECT().Sync(gomock.Any(), &pb.SyncRequest{Cursor: 5}).Return(&pb.SyncResponse{Cursor: 7,
+		Notes:   []*pb.GetNotesResponse_Note{{Id: "n1", Title: "office wifi", Body: sealedBody, Version: 2}},
+		Deleted: []*pb.SyncResponse_DeletedItem{{ItemType: pb.ItemType_ITEM_TYPE_NOTE, Id: "n2"}},
+	}, nil)
+	_, err = c.Sync(context.Background())
+	require.NoError(t, err)
+	cached, err = loadCached[models.Note](cache, notesKind)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []models.Note{
+		{ID: localID, Title: "draft"},
+		{ID: "n1", Title: "home wifi", Body: "mine"},
+	}, cached, "delta should be applied to cached items")
+}
+
+func TestClientService_OpenChangeEvent(t *testing.T) {
+	_, _, cipher := newTestVault(t, "master")
+	sealedPassword, err := cipher.Seal("secret", vault.Binding(models.CredentialsItem, "1", "password"))
+	require.NoError(t, err)
+	c := ClientService{cipher: cipher}
+
+	change, err := c.OpenChangeEvent(&pb.SubscribeToChangesResponse{
+		Id:        "1",
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_CREATE,
+		ItemType:  pb.ItemType_ITEM_TYPE_CREDENTIALS,
+		Version:   1,
+		Item: &pb.SubscribeToChangesResponse_Credential{Credential: &pb.GetCredentialsResponse_Credential{
+			Id: "1", ServiceName: "aws", Identity: "admin", Password: sealedPassword, Version: 1}},
+		Device: "laptop",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ChangeEvent{Operation: models.ChangeCreated, ItemType: models.CredentialsItem, ItemID: "1", Version: 1,
+		Item:   models.Credentials{ID: "1", ServiceName: "aws", Identity: "admin", Password: "secret", Version: 1},
+		Device: "laptop"}, change, "credentials should be decrypted")
+
+	change, err = c.OpenChangeEvent(&pb.SubscribeToChangesResponse{
+		Id:        "report.pdf",
+		Operation: pb.ChangeOperation_CHANGE_OPERATION_DELETE,
+		ItemType:  pb.ItemType_ITEM_TYPE_FILE,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ChangeEvent{Operation: models.ChangeDeleted, ItemType: models.FileItem, ItemID: "report.pdf"}, change)
+
+	_, err = c.OpenChangeEvent(&pb.SubscribeToChangesResponse{
+		Id:       "2",
+		ItemType: pb.ItemType_ITEM_TYPE_NOTE,
+		Item:     &pb.SubscribeToChangesResponse_Note{Note: &pb.GetNotesResponse_Note{Id: "2", Body: "gk1:broken"}},
+	})
+	require.Error(t, err, "item which can not be decrypted should be rejected")
+}
+
+func TestClientService_EncryptedFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: client, cipher: cipher}
+
+	content := bytes.Repeat([]byte("top secret report "), 10000)
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, content, 0600))
+
+	var stored bytes.Buffer
+	var first *pb.UploadFileRequest
+	upload := mock.NewMockGophKeeperService_UploadFileClient(ctrl)
+	client.EXPECT().UploadFile(gomock.Any()).Return(upload, nil)
+	upload.EXPECT().Send(gomock.Any()).DoAndReturn(func(request *pb.UploadFileRequest) error {
+		if first == nil {
+			first = request
+		}
+		stored.Write(request.Data)
+		return nil
+	}).AnyTimes()
+	upload.EXPECT().CloseSend().Return(nil)
+	gomock.InOrder(
+		upload.EXPECT().Recv().Return(&pb.UploadFileResponse{UploadId: "upload"}, nil),
+		upload.EXPECT().Recv().DoAndReturn(func() (*pb.UploadFileResponse, error) {
+			sum := sha256.Sum256(stored.Bytes())
+			return &pb.UploadFileResponse{Success: true, Digest: hex.EncodeToString(sum[:])}, nil
+		}),
+	)
+	c.UploadFile(context.Background(), path, nil)
+
+	require.NotNil(t, first)
+	require.NotEqual(t, "report.txt", first.Filename)
+	require.True(t, vault.IsSealed(first.FileKey))
+	require.True(t, vault.IsSealed(first.EncryptedName))
+	require.NotContains(t, stored.String(), "top secret")
+
+	file, err := c.openFile(&pb.GetFilesResponse_File{Name: first.Filename, EncryptedName: first.EncryptedName})
+	require.NoError(t, err)
+	require.Equal(t, models.File{ID: first.Filename, Name: "report.txt"}, file)
+
+	sum := sha256.Sum256(stored.Bytes())
+	header := &pb.DownloadFileResponse{
+		Size:          int64(stored.Len()),
+		Digest:        hex.EncodeToString(sum[:]),
+		FileKey:       first.FileKey,
+		EncryptedName: first.EncryptedName,
+	}
+	download := func() {
+		stream := mock.NewMockGophKeeperService_DownloadFileClient(ctrl)
+		client.EXPECT().DownloadFile(gomock.Any(), &pb.DownloadFileRequest{Name: first.Filename}).Return(stream, nil)
+		gomock.InOrder(
+			stream.EXPECT().Recv().Return(header, nil),
+			stream.EXPECT().Recv().Return(&pb.DownloadFileResponse{Data: stored.Bytes()}, nil),
+			stream.EXPECT().Recv().Return(nil, io.EOF),
+		)
+	}
+
+	dir := t.TempDir()
+	download()
+	downloaded, err := c.downloadFile(context.Background(), first.Filename, dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "report.txt"), downloaded)
+	result, err := os.ReadFile(downloaded)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(content, result))
+	entries, _ := os.ReadDir(dir)
+	require.Len(t, entries, 1, "only decrypted file should be left")
+
+	locked := ClientService{client: client}
+	download()
+	_, err = locked.downloadFile(context.Background(), first.Filename, t.TempDir())
+	require.ErrorIs(t, err, ErrVaultLocked)
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConflictError - error when item was changed or deleted by another client since it was read.
+// Server keeps current copy of item (models.Credentials, models.Card or models.Note), it is nil
+// when item was deleted.
+type ConflictError struct {
+	Server any
+}
+
+func (e *ConflictError) Error() string {
+	if e.Server == nil {
+		return "item was deleted by another client"
+	}
+	return "item was changed by another client"
+}
+
+// conflict converts rejected change of outdated item version into ConflictError with decrypted server copy.
+func (c *ClientService) conflict(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Aborted {
+		return err
+	}
+	conflict := &ConflictError{}
+	for _, detail := range st.Details() {
+		var openErr error
+		switch current := detail.(type) {
+		case *pb.GetCredentialsResponse_Credential:
+			conflict.Server, openErr = c.openCredentials(current)
+		case *pb.GetCardsResponse_Card:
+			conflict.Server, openErr = c.openCard(current)
+		case *pb.GetNotesResponse_Note:
+			conflict.Server, openErr = c.openNote(current)
+		}
+		if openErr != nil {
+			return openErr
+		}
+	}
+	return conflict
+}
+
+// deletionConflict - same as conflict, but deletion of item which is already deleted is not an error.
+func (c *ClientService) deletionConflict(err error) error {
+	err = c.conflict(err)
+	var conflict *ConflictError
+	if errors.As(err, &conflict) && conflict.Server == nil {
+		return nil
+	}
+	return err
+}
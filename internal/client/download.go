@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"go.uber.org/zap"
+)
+
+// downloadAttempts - number of streams download is tried with before giving up
+const downloadAttempts = 5
+
+// partialSuffix - suffix of files with partially downloaded data
+const partialSuffix = ".part"
+
+var (
+	// ErrDownloadDigestMismatch - error when received file differs from one stored on server
+	ErrDownloadDigestMismatch = errors.New("downloaded file digest mismatch")
+	// ErrVaultLocked - error when encrypted file is downloaded while vault is locked
+	ErrVaultLocked = errors.New("vault is locked, encrypted file can not be opened")
+)
+
+// downloadFile downloads file with ID into dir. Data is received into partial file named after file ID and digest,
+// which is decrypted and moved to original name of file only after its digest is verified.
+func (c *ClientService) downloadFile(ctx context.Context, id, dir string) (path string, err error) {
+	for attempt := 1; ; attempt++ {
+		path, err = c.receiveFile(ctx, id, dir)
+		if err == nil {
+			return path, nil
+		}
+		if !resumable(err) || attempt == downloadAttempts {
+			return "", fmt.Errorf("downloadFile: %w", err)
+		}
+		logger.Log().Info("download interrupted, resuming", zap.String("file", id), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("downloadFile: %w", ctx.Err())
+		case <-time.After(time.Duration(attempt) * transferRetryDelay):
+		}
+	}
+}
+
+// partialPath - file partially downloaded data of file with digest is kept in
+func partialPath(path, digest string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+"."+digest+partialSuffix)
+}
+
+// findPartial - partially downloaded data of file and digest of file it belongs to
+func findPartial(path string) (partial, digest string) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return "", ""
+	}
+	prefix := "." + filepath.Base(path) + "."
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, prefix) && strings.HasSuffix(name, partialSuffix) {
+			return filepath.Join(filepath.Dir(path), name), strings.TrimSuffix(strings.TrimPrefix(name, prefix), partialSuffix)
+		}
+	}
+	return "", ""
+}
+
+// receiveFile - continues download from data received before and moves verified file to its original name
+func (c *ClientService) receiveFile(ctx context.Context, id, dir string) (string, error) {
+	stored := filepath.Join(dir, filepath.Base(id))
+	partial, digest := findPartial(stored)
+	var offset int64
+	if info, err := os.Stat(partial); partial != "" && err == nil {
+		offset = info.Size()
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.DownloadFile(c.getCtx(streamCtx), &pb.DownloadFileRequest{Name: id, Offset: offset})
+	if err != nil {
+		return "", err
+	}
+	header, err := stream.Recv()
+	if err != nil {
+		return "", err
+	}
+	if _, err = hex.DecodeString(header.Digest); err != nil || header.Digest == "" {
+		return "", fmt.Errorf("invalid file digest %q", header.Digest)
+	}
+	if partial != "" && (header.Digest != digest || header.Size < offset) {
+		// file was changed on server since partial download, download starts over by new stream
+		cancel()
+		if err = os.Remove(partial); err != nil {
+			return "", err
+		}
+		return c.receiveFile(ctx, id, dir)
+	}
+	if partial == "" {
+		partial = partialPath(stored, header.Digest)
+	}
+
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", err
+	}
+	for {
+		var chunk *pb.DownloadFileResponse
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return "", err
+		}
+		if _, err = file.Write(chunk.Data); err != nil {
+			_ = file.Close()
+			return "", err
+		}
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+
+	if err = verifyFile(partial, header.Size, header.Digest); err != nil {
+		_ = os.Remove(partial)
+		return "", err
+	}
+	return c.finishDownload(partial, dir, id, header)
+}
+
+// finishDownload - moves verified file to its original name, encrypted file is decrypted
+func (c *ClientService) finishDownload(partial, dir, id string, header *pb.DownloadFileResponse) (string, error) {
+	name := id
+	if header.EncryptedName != "" || header.FileKey != "" {
+		if c.cipher == nil {
+			return "", ErrVaultLocked
+		}
+		name = header.EncryptedName
+		if err := c.open(models.FileItem, id, map[string]*string{"name": &name}); err != nil {
+			return "", err
+		}
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+	if header.FileKey == "" {
+		return path, os.Rename(partial, path)
+	}
+
+	key, err := c.cipher.UnwrapKey(header.FileKey, vault.Binding(models.FileItem, id, fileKeyField))
+	if err != nil {
+		return "", err
+	}
+	src, err := os.Open(partial)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp(dir, "."+filepath.Base(id)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	err = vault.DecryptStream(dst, src, key)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(dst.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	_ = os.Remove(partial)
+	return path, nil
+}
+
+// verifyFile - check that file has expected size and SHA-256 digest
+func verifyFile(path string, size int64, digest string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	if n != size || hex.EncodeToString(hash.Sum(nil)) != digest {
+		return ErrDownloadDigestMismatch
+	}
+	return nil
+}
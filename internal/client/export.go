@@ -0,0 +1,495 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/archive"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/importer"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+)
+
+// exportedFile - file of vault received by export, its encrypted content is kept in temporary file until it ends
+type exportedFile struct {
+	file    archive.File
+	name    string
+	fileKey string
+	content *os.File
+}
+
+// ExportVault writes decrypted vault into archive at path protected by password, archive is written into temporary
+// file next to path and replaces it only when export is complete.
+func (c *ClientService) ExportVault(ctx context.Context, path, password string) (manifest archive.Manifest, err error) {
+	defer func() {
+		if c.fallBackToCache(err) {
+			err = ErrOffline
+		}
+		if err != nil {
+			err = fmt.Errorf("ExportVault: %w", err)
+		}
+	}()
+	if c.cipher == nil {
+		return manifest, ErrVaultLocked
+	}
+	dir, err := os.MkdirTemp("", "gophkeeper-export-")
+	if err != nil {
+		return manifest, err
+	}
+	defer os.RemoveAll(dir)
+
+	contents := &archive.Vault{Manifest: archive.Manifest{CreatedAt: time.Now().UTC(), Account: c.email}}
+	if err = c.receiveExport(ctx, dir, contents); err != nil {
+		return manifest, err
+	}
+
+	target, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return manifest, err
+	}
+	err = archive.Write(target, password, contents)
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(target.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(target.Name())
+		return manifest, err
+	}
+	return contents.Manifest, nil
+}
+
+// receiveExport - decrypt items of export stream into contents and files into dir
+func (c *ClientService) receiveExport(ctx context.Context, dir string, contents *archive.Vault) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.ExportVault(c.getCtx(ctx), &pb.ExportVaultRequest{})
+	if err != nil {
+		return err
+	}
+
+	var file *exportedFile
+	defer func() {
+		if file != nil {
+			_ = file.content.Close()
+		}
+	}()
+	finishFile := func() error {
+		if file == nil {
+			return nil
+		}
+		exported := file
+		file = nil
+		if err := c.decryptExportedFile(exported); err != nil {
+			return fmt.Errorf("file %s can not be decrypted: %w", exported.file.Name, err)
+		}
+		contents.Manifest.Files = append(contents.Manifest.Files, exported.file)
+		return nil
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, isChunk := resp.Entry.(*pb.ExportVaultResponse_Chunk); !isChunk {
+			if err = finishFile(); err != nil {
+				return err
+			}
+		}
+
+		switch entry := resp.Entry.(type) {
+		case *pb.ExportVaultResponse_Credentials:
+			credentials, err := c.openCredentials(entry.Credentials)
+			if err != nil {
+				return err
+			}
+			contents.Credentials = append(contents.Credentials, credentials)
+		case *pb.ExportVaultResponse_Card:
+			card, err := c.openCard(entry.Card)
+			if err != nil {
+				return err
+			}
+			contents.Cards = append(contents.Cards, card)
+		case *pb.ExportVaultResponse_Note:
+			note, err := c.openNote(entry.Note)
+			if err != nil {
+				return err
+			}
+			contents.Notes = append(contents.Notes, note)
+		case *pb.ExportVaultResponse_File:
+			opened, err := c.openFile(entry.File)
+			if err != nil {
+				return err
+			}
+			content, err := os.CreateTemp(dir, "file-*")
+			if err != nil {
+				return err
+			}
+			file = &exportedFile{
+				file: archive.File{
+					Name:       opened.Name,
+					UploadedAt: opened.UploadedAt,
+					Metadata:   opened.Metadata,
+					Source:     content.Name() + ".plain",
+				},
+				name:    entry.File.Name,
+				fileKey: entry.File.FileKey,
+				content: content,
+			}
+		case *pb.ExportVaultResponse_Chunk:
+			if file == nil {
+				return fmt.Errorf("chunk does not follow file entry")
+			}
+			if _, err = file.content.Write(entry.Chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return finishFile()
+}
+
+// decryptExportedFile - decrypt received content into Source of file, files uploaded before vault was initialized
+// are kept as is
+func (c *ClientService) decryptExportedFile(exported *exportedFile) error {
+	encrypted := exported.content
+	defer os.Remove(encrypted.Name())
+	defer encrypted.Close()
+	if exported.fileKey == "" {
+		if err := encrypted.Close(); err != nil {
+			return err
+		}
+		return os.Rename(encrypted.Name(), exported.file.Source)
+	}
+
+	key, err := c.cipher.UnwrapKey(exported.fileKey, vault.Binding(models.FileItem, exported.name, fileKeyField))
+	if err != nil {
+		return err
+	}
+	if _, err = encrypted.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(exported.file.Source, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	err = vault.DecryptStream(dst, encrypted, key)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ImportVault restores archive at path protected by password into vault. Items and files equal to ones of vault
+// or met earlier in archive are skipped as duplicates, dry run only reports what would be imported.
+func (c *ClientService) ImportVault(ctx context.Context, path, password string, dryRun bool) (report models.ImportReport, err error) {
+	defer func() {
+		if c.fallBackToCache(err) {
+			err = ErrOffline
+		}
+		if err != nil {
+			err = fmt.Errorf("ImportVault: %w", err)
+		}
+	}()
+	report.DryRun = dryRun
+	if c.cipher == nil {
+		return report, ErrVaultLocked
+	}
+	source, err := os.Open(path)
+	if err != nil {
+		return report, err
+	}
+	defer source.Close()
+	contents, err := archive.Read(source, password)
+	if err != nil {
+		return report, err
+	}
+	defer contents.Close()
+	return c.importContents(ctx, contents, dryRun)
+}
+
+// ImportItems creates items mapped from export of other password manager in vault, items already present in vault
+// are skipped as duplicates and dry run only reports what would be imported.
+func (c *ClientService) ImportItems(ctx context.Context, items importer.Items, dryRun bool) (report models.ImportReport, err error) {
+	defer func() {
+		if c.fallBackToCache(err) {
+			err = ErrOffline
+		}
+		if err != nil {
+			err = fmt.Errorf("ImportItems: %w", err)
+		}
+	}()
+	report.DryRun = dryRun
+	if c.cipher == nil {
+		return report, ErrVaultLocked
+	}
+	return c.importContents(ctx, &archive.Vault{Credentials: items.Credentials, Cards: items.Cards, Notes: items.Notes}, dryRun)
+}
+
+// importContents - send items and files of contents which are not present in vault to server in one stream
+func (c *ClientService) importContents(ctx context.Context, contents *archive.Vault, dryRun bool) (report models.ImportReport, err error) {
+	report.DryRun = dryRun
+	var requests []*pb.ImportVaultRequest
+	var files []archive.File
+	// names - names of sent items of every type, server refers to rejected items by their position
+	names := make(map[pb.ItemType][]string)
+	credentials, err := c.getCredentials(ctx)
+	if err != nil {
+		return report, err
+	}
+	err = importItems(credentials, contents.Credentials, &report.Credentials, func(item models.Credentials) error {
+		request, err := c.credentialsRequest(item)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Credentials{Credentials: request}})
+		names[pb.ItemType_ITEM_TYPE_CREDENTIALS] = append(names[pb.ItemType_ITEM_TYPE_CREDENTIALS], item.ServiceName)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	cards, err := c.getCards(ctx)
+	if err != nil {
+		return report, err
+	}
+	err = importItems(cards, contents.Cards, &report.Cards, func(item models.Card) error {
+		request, err := c.cardRequest(item)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Card{Card: request}})
+		names[pb.ItemType_ITEM_TYPE_CARD] = append(names[pb.ItemType_ITEM_TYPE_CARD], cardName(item.Number))
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	notes, err := c.getNotes(ctx)
+	if err != nil {
+		return report, err
+	}
+	err = importItems(notes, contents.Notes, &report.Notes, func(item models.Note) error {
+		request, err := c.noteRequest(item)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Note{Note: request}})
+		names[pb.ItemType_ITEM_TYPE_NOTE] = append(names[pb.ItemType_ITEM_TYPE_NOTE], item.Title)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if files, err = c.importFiles(ctx, contents.Manifest.Files, &report.Files); err != nil {
+		return report, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	resp, err := c.sendImport(ctx, requests, files)
+	if err != nil {
+		return report, err
+	}
+	report.Credentials.Imported = int(resp.Credentials)
+	report.Cards.Imported = int(resp.Cards)
+	report.Notes.Imported = int(resp.Notes)
+	report.Files.Imported = int(resp.Files)
+	for _, file := range files {
+		names[pb.ItemType_ITEM_TYPE_FILE] = append(names[pb.ItemType_ITEM_TYPE_FILE], file.Name)
+	}
+	for _, rejection := range resp.Rejected {
+		rejectImported(&report, rejection, names[rejection.ItemType])
+	}
+	return report, nil
+}
+
+// rejectImported - count item refused by server and describe it by name of sent item
+func rejectImported(report *models.ImportReport, rejection *pb.ImportVaultRejection, names []string) {
+	kinds := map[pb.ItemType]struct {
+		name  string
+		count *models.ImportCount
+	}{
+		pb.ItemType_ITEM_TYPE_CREDENTIALS: {models.CredentialsItem, &report.Credentials},
+		pb.ItemType_ITEM_TYPE_CARD:        {models.CardItem, &report.Cards},
+		pb.ItemType_ITEM_TYPE_NOTE:        {models.NoteItem, &report.Notes},
+		pb.ItemType_ITEM_TYPE_FILE:        {models.FileItem, &report.Files},
+	}
+	kind, ok := kinds[rejection.ItemType]
+	if !ok {
+		return
+	}
+	kind.count.Rejected++
+	name := fmt.Sprintf("#%d", rejection.Index+1)
+	if rejection.Index >= 0 && int(rejection.Index) < len(names) {
+		name = names[rejection.Index]
+	}
+	report.Rejections = append(report.Rejections, fmt.Sprintf("%s %q: %s", kind.name, name, rejection.Reason))
+}
+
+// cardName - card number without all digits but last ones
+func cardName(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return "*" + number[len(number)-4:]
+}
+
+// importFiles - files of archive which are not present in vault, files with the same name are duplicates
+func (c *ClientService) importFiles(ctx context.Context, imported []archive.File, count *models.ImportCount) ([]archive.File, error) {
+	if len(imported) == 0 {
+		return nil, nil
+	}
+	existing, err := c.getFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, file := range existing {
+		names[file.Name] = true
+	}
+	var files []archive.File
+	for _, file := range imported {
+		if names[file.Name] {
+			count.Duplicates++
+			continue
+		}
+		names[file.Name] = true
+		count.Imported++
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// importItems - pass items of archive which are not present in vault to add, vault items differing only by ID,
+// version and time of upload are duplicates
+func importItems[T any](existing, imported []T, count *models.ImportCount, add func(item T) error) error {
+	known := make(map[string]bool, len(existing)+len(imported))
+	for _, item := range existing {
+		known[itemKey(item)] = true
+	}
+	for _, item := range imported {
+		key := itemKey(item)
+		if known[key] {
+			count.Duplicates++
+			continue
+		}
+		known[key] = true
+		count.Imported++
+		if err := add(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemKey - content of item without its ID, version and time of upload
+func itemKey(item any) string {
+	var fields map[string]any
+	data, _ := json.Marshal(item)
+	_ = json.Unmarshal(data, &fields)
+	delete(fields, "id")
+	delete(fields, "version")
+	delete(fields, "uploaded_at")
+	key, _ := json.Marshal(fields)
+	return string(key)
+}
+
+// sendImport - stream sealed items and encrypted files to server
+func (c *ClientService) sendImport(ctx context.Context, requests []*pb.ImportVaultRequest, files []archive.File) (*pb.ImportVaultResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.ImportVault(c.getCtx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	// broken stream reports io.EOF on Send, actual status is received by CloseAndRecv
+	send := func(request *pb.ImportVaultRequest) error {
+		if err := stream.Send(request); err != io.EOF {
+			return err
+		}
+		_, err := stream.CloseAndRecv()
+		return err
+	}
+
+	for _, request := range requests {
+		if err = send(request); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range files {
+		if err = c.sendImportFile(file, send); err != nil {
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// sendImportFile - encrypt file with new file key and send it by chunks after its header
+func (c *ClientService) sendImportFile(file archive.File, send func(request *pb.ImportVaultRequest) error) error {
+	u := &upload{filename: file.Name}
+	encryptedPath, err := c.encryptFile(file.Source, u)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(encryptedPath)
+	metadata, err := c.sealMetadata(models.FileItem, u.filename, file.Metadata)
+	if err != nil {
+		return err
+	}
+	encrypted, err := os.Open(encryptedPath)
+	if err != nil {
+		return err
+	}
+	defer encrypted.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, encrypted); err != nil {
+		return err
+	}
+	if _, err = encrypted.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	err = send(&pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_File{File: &pb.ImportVaultFile{
+		Name:          u.filename,
+		Metadata:      metadata,
+		FileKey:       u.fileKey,
+		EncryptedName: u.encryptedName,
+		Digest:        hex.EncodeToString(hash.Sum(nil)),
+	}}})
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, uploadChunkSize)
+	for {
+		n, err := io.ReadFull(encrypted, buffer)
+		if n > 0 {
+			chunk := &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Chunk{Chunk: buffer[:n]}}
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/archive"
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/importer"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestClientService_ExportImportVault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: client, cipher: cipher, email: "user@example.com"}
+	ctx := context.Background()
+
+	credentialsRequest, err := c.credentialsRequest(models.Credentials{ServiceName: "mail", Identity: "user", Password: "secret"})
+	require.NoError(t, err)
+	credentials := &pb.GetCredentialsResponse_Credential{Id: credentialsRequest.Id, Version: 1, ServiceName: credentialsRequest.ServiceName,
+		Identity: credentialsRequest.Identity, Password: credentialsRequest.Password, UploadedAt: "2024-05-01T10:00:00Z"}
+	cardRequest, err := c.cardRequest(models.Card{Number: "4111111111111111", ExpirationDate: "12/30", HolderName: "USER", CVV: "123"})
+	require.NoError(t, err)
+	card := &pb.GetCardsResponse_Card{Id: cardRequest.Id, Number: cardRequest.Number, ExpirationDate: cardRequest.ExpirationDate,
+		HolderName: cardRequest.HolderName, Cvv: cardRequest.Cvv}
+	body := "buy milk"
+	require.NoError(t, c.seal(models.NoteItem, "3", map[string]*string{"body": &body}))
+	note := &pb.GetNotesResponse_Note{Id: "3", Title: "todo", Body: body, Metadata: map[string]string{}}
+
+	content := bytes.Repeat([]byte("report "), 30000)
+	fileKey, _ := vault.NewFileKey()
+	wrappedKey, _ := cipher.WrapKey(fileKey, vault.Binding(models.FileItem, "random", fileKeyField))
+	encryptedName, _ := cipher.Seal("report.txt", vault.Binding(models.FileItem, "random", "name"))
+	var encrypted bytes.Buffer
+	require.NoError(t, vault.EncryptStream(&encrypted, bytes.NewReader(content), fileKey))
+	file := &pb.GetFilesResponse_File{Name: "random", FileKey: wrappedKey, EncryptedName: encryptedName}
+
+	exportStream := mock.NewMockGophKeeperService_ExportVaultClient(ctrl)
+	client.EXPECT().ExportVault(gomock.Any(), gomock.Any()).Return(exportStream, nil)
+	half := encrypted.Len() / 2
+	gomock.InOrder(
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Credentials{Credentials: credentials}}, nil),
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Card{Card: card}}, nil),
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Note{Note: note}}, nil),
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_File{File: file}}, nil),
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Chunk{Chunk: encrypted.Bytes()[:half]}}, nil),
+		exportStream.EXPECT().Recv().Return(&pb.ExportVaultResponse{Entry: &pb.ExportVaultResponse_Chunk{Chunk: encrypted.Bytes()[half:]}}, nil),
+		exportStream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+
+	path := filepath.Join(t.TempDir(), "vault.gka")
+	manifest, err := c.ExportVault(ctx, path, "archive password")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", manifest.Account)
+	assert.Equal(t, 1, manifest.Credentials)
+	assert.Equal(t, 1, manifest.Cards)
+	assert.Equal(t, 1, manifest.Notes)
+	require.Len(t, manifest.Files, 1)
+	assert.Equal(t, "report.txt", manifest.Files[0].Name)
+
+	source, err := os.Open(path)
+	require.NoError(t, err)
+	contents, err := archive.Read(source, "archive password")
+	_ = source.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", contents.Credentials[0].Password)
+	assert.Equal(t, "123", contents.Cards[0].CVV)
+	assert.Equal(t, "buy milk", contents.Notes[0].Body)
+	exported, err := os.ReadFile(contents.Manifest.Files[0].Source)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(content, exported))
+	require.NoError(t, contents.Close())
+
+	// archive is restored into vault which already has the credentials
+	expectVault := func() {
+		client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(
+			&pb.GetCredentialsResponse{Credentials: []*pb.GetCredentialsResponse_Credential{{Id: credentials.Id, Version: 4,
+				ServiceName: "mail", Identity: credentials.Identity, Password: credentials.Password}}}, nil)
+		client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{}, nil)
+		client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil)
+		client.EXPECT().GetFiles(gomock.Any(), gomock.Any()).Return(&pb.GetFilesResponse{}, nil)
+	}
+	expectVault()
+	report, err := c.ImportVault(ctx, path, "archive password", true)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportReport{
+		DryRun:      true,
+		Credentials: models.ImportCount{Duplicates: 1},
+		Cards:       models.ImportCount{Imported: 1},
+		Notes:       models.ImportCount{Imported: 1},
+		Files:       models.ImportCount{Imported: 1},
+	}, report)
+
+	expectVault()
+	importStream := mock.NewMockGophKeeperService_ImportVaultClient(ctrl)
+	client.EXPECT().ImportVault(gomock.Any()).Return(importStream, nil)
+	var sent []*pb.ImportVaultRequest
+	importStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(request *pb.ImportVaultRequest) error {
+		if chunk, ok := request.Entry.(*pb.ImportVaultRequest_Chunk); ok {
+			request = &pb.ImportVaultRequest{Entry: &pb.ImportVaultRequest_Chunk{Chunk: bytes.Clone(chunk.Chunk)}}
+		}
+		sent = append(sent, request)
+		return nil
+	}).AnyTimes()
+	importStream.EXPECT().CloseAndRecv().Return(&pb.ImportVaultResponse{Cards: 1, Notes: 1, Files: 1}, nil)
+
+	report, err = c.ImportVault(ctx, path, "archive password", false)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, models.ImportCount{Imported: 1}, report.Files)
+	require.Len(t, sent, 4)
+	importedCard := sent[0].GetCard()
+	require.NotNil(t, importedCard)
+	assert.True(t, vault.IsSealed(importedCard.Number))
+	importedNote := sent[1].GetNote()
+	require.NotNil(t, importedNote)
+	assert.NotEqual(t, body, importedNote.Body, "note should be sealed again")
+	assert.NotEqual(t, "3", importedNote.Id, "note should get new ID")
+	opened, err := cipher.Open(importedNote.Body, vault.Binding(models.NoteItem, importedNote.Id, "body"))
+	require.NoError(t, err)
+	assert.Equal(t, "buy milk", opened)
+
+	importedFile := sent[2].GetFile()
+	require.NotNil(t, importedFile)
+	require.NotEmpty(t, importedFile.Name)
+	name, err := cipher.Open(importedFile.EncryptedName, vault.Binding(models.FileItem, importedFile.Name, "name"))
+	require.NoError(t, err)
+	assert.Equal(t, "report.txt", name)
+	uploaded := sent[3].GetChunk()
+	digest := sha256.Sum256(uploaded)
+	assert.Equal(t, hex.EncodeToString(digest[:]), importedFile.Digest)
+	key, err := cipher.UnwrapKey(importedFile.FileKey, vault.Binding(models.FileItem, importedFile.Name, fileKeyField))
+	require.NoError(t, err)
+	var decrypted bytes.Buffer
+	require.NoError(t, vault.DecryptStream(&decrypted, bytes.NewReader(uploaded), key))
+	assert.True(t, bytes.Equal(content, decrypted.Bytes()))
+}
+
+func TestClientService_ImportVault_Invalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: mock.NewMockGophKeeperServiceClient(ctrl), cipher: cipher}
+	path := filepath.Join(t.TempDir(), "vault.gka")
+	target, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, archive.Write(target, "archive password", &archive.Vault{}))
+	require.NoError(t, target.Close())
+
+	_, err = c.ImportVault(context.Background(), path, "wrong password", true)
+	require.ErrorIs(t, err, archive.ErrDecrypt)
+
+	locked := ClientService{client: mock.NewMockGophKeeperServiceClient(ctrl)}
+	_, err = locked.ImportVault(context.Background(), path, "archive password", true)
+	require.ErrorIs(t, err, ErrVaultLocked)
+	_, err = locked.ExportVault(context.Background(), path, "archive password")
+	require.ErrorIs(t, err, ErrVaultLocked)
+}
+
+func TestClientService_ImportItems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	_, _, cipher := newTestVault(t, "master")
+	c := ClientService{client: client, cipher: cipher, email: "user@example.com"}
+	ctx := context.Background()
+
+	existing, err := c.credentialsRequest(models.NewCredentials("mail", "user", "secret"))
+	require.NoError(t, err)
+	client.EXPECT().GetCredentials(gomock.Any(), gomock.Any()).Return(
+		&pb.GetCredentialsResponse{Credentials: []*pb.GetCredentialsResponse_Credential{{Id: existing.Id, Version: 1,
+			ServiceName: existing.ServiceName, Identity: existing.Identity, Password: existing.Password}}}, nil)
+	client.EXPECT().GetCards(gomock.Any(), gomock.Any()).Return(&pb.GetCardsResponse{}, nil)
+	client.EXPECT().GetNotes(gomock.Any(), gomock.Any()).Return(&pb.GetNotesResponse{}, nil)
+
+	importStream := mock.NewMockGophKeeperService_ImportVaultClient(ctrl)
+	client.EXPECT().ImportVault(gomock.Any()).Return(importStream, nil)
+	var sent []*pb.ImportVaultRequest
+	importStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(request *pb.ImportVaultRequest) error {
+		sent = append(sent, request)
+		return nil
+	}).AnyTimes()
+	importStream.EXPECT().CloseAndRecv().Return(&pb.ImportVaultResponse{Notes: 1, Rejected: []*pb.ImportVaultRejection{
+		{ItemType: pb.ItemType_ITEM_TYPE_CREDENTIALS, Index: 0, Reason: "credentials already exist"},
+	}}, nil)
+
+	report, err := c.ImportItems(ctx, importer.Items{
+		Credentials: []models.Credentials{
+			models.NewCredentials("mail", "user", "secret"),
+			models.NewCredentials("forum", "user", "other"),
+		},
+		Notes: []models.Note{models.NewNote("wifi", "password")},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, models.ImportReport{
+		Credentials: models.ImportCount{Duplicates: 1, Rejected: 1},
+		Notes:       models.ImportCount{Imported: 1},
+		Rejections:  []string{`credentials "forum": credentials already exist`},
+	}, report)
+	require.Len(t, sent, 2)
+	imported := sent[0].GetCredentials()
+	require.NotNil(t, imported)
+	assert.Equal(t, "forum", imported.ServiceName)
+	assert.True(t, vault.IsSealed(imported.Password))
+
+	locked := ClientService{client: client}
+	_, err = locked.ImportItems(ctx, importer.Items{}, true)
+	require.ErrorIs(t, err, ErrVaultLocked)
+}
@@ -0,0 +1,503 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayConflict - offline change rejected because of concurrent change, it is kept in queue under the key
+// and replay waits for it until user is told about the conflict
+type replayConflict struct {
+	models.ReplayConflict
+	key []byte
+}
+
+// isUnavailable - check if request failed because server is unreachable
+func isUnavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// openCache opens local copy of user's vault, client keeps working online if cache can not be opened
+// or cache directory is not configured.
+func (c *ClientService) openCache(email string) {
+	c.email = email
+	c.synced = nil
+	c.replayConflict, c.dropped = nil, 0
+	if c.cache != nil {
+		_ = c.cache.Close()
+		c.cache = nil
+	}
+	if c.cacheDir == "" {
+		return
+	}
+	cache, err := OpenCache(cachePath(c.cacheDir, c.serverAddress, email))
+	if err != nil {
+		logger.Log().Error("could not open local cache", zap.Error(err))
+		return
+	}
+	c.cache = cache
+}
+
+// unlockCache gives cache access to vault key and keeps key parameters to unlock vault offline.
+func (c *ClientService) unlockCache(params models.VaultParams) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.unlock(c.cipher)
+	if !c.isAvailable {
+		return
+	}
+	if err := c.cache.SaveVaultParams(params); err != nil {
+		logger.Log().Error("could not cache vault parameters", zap.Error(err))
+	}
+	c.keepRefreshToken(c.refreshToken)
+}
+
+// fallBackToCache tracks server availability by result of request and reports if the request
+// should be served by local cache.
+func (c *ClientService) fallBackToCache(err error) bool {
+	if !isUnavailable(err) {
+		if err == nil {
+			c.isAvailable = true
+		}
+		return false
+	}
+	c.isAvailable = false
+	return c.cache != nil
+}
+
+// SyncStatus reports if server is reachable, how many offline changes are waiting for replay and how many
+// of them were dropped as server rejected them.
+func (c *ClientService) SyncStatus() (online bool, pending, dropped int) {
+	if c.cache != nil {
+		pending = c.cache.PendingCount()
+	}
+	return c.isAvailable, pending, c.dropped
+}
+
+// TakeReplayConflict returns offline change which conflicts with change of another client and removes it
+// from queue, so caller has to show it to user. Nil is returned when there is no conflict. Conflicting
+// deletion is dropped as there is nothing to merge.
+func (c *ClientService) TakeReplayConflict() (*models.ReplayConflict, error) {
+	conflict := c.replayConflict
+	if conflict == nil {
+		return nil, nil
+	}
+	if err := c.cache.done(conflict.key); err != nil {
+		return nil, fmt.Errorf("TakeReplayConflict: %w", err)
+	}
+	c.replayConflict = nil
+	if conflict.Mine == nil {
+		c.dropped++
+	}
+	return &conflict.ReplayConflict, nil
+}
+
+// cacheItems keeps fresh copy of items received from server.
+func cacheItems[T any](c *ClientService, kind string, items []T, id func(T) string) {
+	if c.cache == nil || c.cache.cipher == nil {
+		return
+	}
+	byID := make(map[string]any, len(items))
+	for _, item := range items {
+		byID[id(item)] = item
+	}
+	if err := c.cache.replace(kind, byID); err != nil {
+		logger.Log().Error("could not update local cache", zap.String("kind", kind), zap.Error(err))
+	}
+}
+
+// queueOffline applies change to local cache and keeps it until server is reachable.
+func (c *ClientService) queueOffline(method, kind, action, id string, item any) error {
+	if err := c.cache.queue(kind, action, id, item); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+// replayPending sends changes made offline to server before any other request. Failed replay is retried
+// by next request, only ErrSessionExpired is returned as user has to sign in again to replay changes.
+func (c *ClientService) replayPending(ctx context.Context) error {
+	if c.cache == nil || c.cache.cipher == nil || c.replayConflict != nil || c.cache.PendingCount() == 0 {
+		return nil
+	}
+	err := c.replay(ctx)
+	if errors.Is(err, ErrSessionExpired) {
+		return err
+	}
+	if err != nil {
+		logger.Log().Info("offline changes are not replayed yet", zap.Error(err))
+	}
+	return nil
+}
+
+// replay sends queued changes in order they were made. Replay stops while server is unreachable or
+// change conflicts with change of another client, until the conflict is taken by TakeReplayConflict.
+// Changes rejected by server for other reasons are dropped as they would block the queue forever.
+func (c *ClientService) replay(ctx context.Context) error {
+	keys, ops, err := c.cache.pendingOperations()
+	if err != nil {
+		return err
+	}
+	if c.token == "" {
+		if err = c.reauthorize(); err != nil {
+			return err
+		}
+	}
+	versions := make(map[string]int64)
+	for i, op := range ops {
+		err = c.apply(ctx, op, versions)
+		if isUnavailable(err) {
+			c.isAvailable = false
+			return ErrOffline
+		}
+		c.isAvailable = true
+		var conflict *ConflictError
+		if errors.As(err, &conflict) {
+			if c.replayConflict, err = newReplayConflict(keys[i], op, err); err != nil {
+				return err
+			}
+			return c.replayConflict.Err
+		}
+		if err != nil {
+			logger.Log().Error("offline change was rejected by server",
+				zap.String("kind", op.Kind), zap.String("action", op.Action), zap.Error(err))
+			c.dropped++
+		}
+		if err = c.cache.done(keys[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reauthorize restores session of user who was signed in offline by refresh token of previous session.
+// ErrSessionExpired is returned when the session was expired or revoked meanwhile.
+func (c *ClientService) reauthorize() error {
+	refreshToken, err := c.cache.refreshToken()
+	if err != nil {
+		return fmt.Errorf("reauthorize: %w", err)
+	}
+	if refreshToken == "" {
+		return fmt.Errorf("reauthorize: %w", ErrSessionExpired)
+	}
+	resp, err := c.client.RefreshToken(c.deviceCtx(context.Background()), &pb.RefreshTokenRequest{RefreshToken: refreshToken})
+	if isUnavailable(err) {
+		c.isAvailable = false
+		return ErrOffline
+	}
+	if status.Code(err) == codes.Unauthenticated {
+		c.keepRefreshToken("")
+		return fmt.Errorf("reauthorize: %w", ErrSessionExpired)
+	}
+	if err != nil {
+		return fmt.Errorf("reauthorize: %w", err)
+	}
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	c.keepRefreshToken(resp.GetRefreshToken())
+	return nil
+}
+
+// apply sends single queued change to server. Versions keep new versions of items updated during replay,
+// so next offline change of the same item is sent with version server expects.
+func (c *ClientService) apply(ctx context.Context, op pendingOperation, versions map[string]int64) (err error) {
+	key := op.Kind + "/" + op.ID
+	version, replayed := versions[key]
+	switch op.Kind {
+	case credentialsKind:
+		var credentials models.Credentials
+		if err = decodeItem(op, &credentials); err != nil {
+			return
+		}
+		if replayed {
+			credentials.Version = version
+		}
+		switch op.Action {
+		case createAction:
+			return c.createCredentials(ctx, credentials)
+		case updateAction:
+			if credentials, err = c.updateCredentials(ctx, credentials); err == nil {
+				versions[key] = credentials.Version
+			}
+			return
+		case deleteAction:
+			return c.deleteCredentials(ctx, op.ID, credentials.Version)
+		}
+	case cardsKind:
+		var card models.Card
+		if err = decodeItem(op, &card); err != nil {
+			return
+		}
+		if replayed {
+			card.Version = version
+		}
+		switch op.Action {
+		case createAction:
+			return c.createCard(ctx, card)
+		case updateAction:
+			if card, err = c.updateCards(ctx, card); err == nil {
+				versions[key] = card.Version
+			}
+			return
+		case deleteAction:
+			return c.deleteCard(ctx, op.ID, card.Version)
+		}
+	case notesKind:
+		var note models.Note
+		if err = decodeItem(op, &note); err != nil {
+			return
+		}
+		if replayed {
+			note.Version = version
+		}
+		switch op.Action {
+		case createAction:
+			return c.createNote(ctx, note)
+		case updateAction:
+			if note, err = c.updateNote(ctx, note); err == nil {
+				versions[key] = note.Version
+			}
+			return
+		case deleteAction:
+			return c.deleteNote(ctx, op.ID, note.Version)
+		}
+	}
+	return fmt.Errorf("unknown offline change %s of %s", op.Action, op.Kind)
+}
+
+// newReplayConflict describes queued change which server rejected because of concurrent change.
+func newReplayConflict(key []byte, op pendingOperation, err error) (conflict *replayConflict, decodeErr error) {
+	conflict = &replayConflict{ReplayConflict: models.ReplayConflict{Err: err}, key: key}
+	switch op.Kind {
+	case credentialsKind:
+		conflict.ItemType = models.CredentialsItem
+		conflict.Base, conflict.Mine, decodeErr = decodeConflict[models.Credentials](op)
+	case cardsKind:
+		conflict.ItemType = models.CardItem
+		conflict.Base, conflict.Mine, decodeErr = decodeConflict[models.Card](op)
+	case notesKind:
+		conflict.ItemType = models.NoteItem
+		conflict.Base, conflict.Mine, decodeErr = decodeConflict[models.Note](op)
+	}
+	return
+}
+
+// decodeConflict - item before and after queued change, item after deletion is nil
+func decodeConflict[T any](op pendingOperation) (base, mine any, err error) {
+	var baseItem, mineItem T
+	if len(op.Base) > 0 {
+		if err = json.Unmarshal(op.Base, &baseItem); err != nil {
+			return
+		}
+	}
+	if op.Action == deleteAction {
+		return baseItem, nil, nil
+	}
+	if err = decodeItem(op, &mineItem); err != nil {
+		return
+	}
+	return baseItem, mineItem, nil
+}
+
+func decodeItem(op pendingOperation, item any) error {
+	if len(op.Item) == 0 {
+		return nil
+	}
+	return json.Unmarshal(op.Item, item)
+}
+
+func (c *ClientService) CreateCredentials(ctx context.Context, serviceName, identity, password, totp string, metadata map[string]string) error {
+	credentials := models.Credentials{ServiceName: serviceName, Identity: identity, Password: password, TOTP: totp, Metadata: metadata}
+	if err := c.replayPending(ctx); err != nil {
+		return fmt.Errorf("CreateCredentials: %w", err)
+	}
+	err := c.createCredentials(ctx, credentials)
+	if c.fallBackToCache(err) {
+		credentials.ID, credentials.UploadedAt = newLocalID(), time.Now()
+		return c.queueOffline("CreateCredentials", credentialsKind, createAction, credentials.ID, credentials)
+	}
+	return err
+}
+
+func (c *ClientService) GetCredentials(ctx context.Context) (credentials []models.Credentials, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return credentials, fmt.Errorf("GetCredentials: %w", err)
+	}
+	credentials, err = c.getCredentials(ctx)
+	if c.fallBackToCache(err) {
+		credentials, err = loadCached[models.Credentials](c.cache, credentialsKind)
+		if err != nil {
+			err = fmt.Errorf("GetCredentials: %w", err)
+		}
+		return
+	}
+	if err == nil {
+		cacheItems(c, credentialsKind, credentials, func(item models.Credentials) string { return item.ID })
+	}
+	return
+}
+
+func (c *ClientService) UpdateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return updatedCredentials, fmt.Errorf("UpdateCredentials: %w", err)
+	}
+	updatedCredentials, err = c.updateCredentials(ctx, credentials)
+	if c.fallBackToCache(err) {
+		updatedCredentials = credentials
+		updatedCredentials.UploadedAt = time.Now()
+		err = c.queueOffline("UpdateCredentials", credentialsKind, updateAction, credentials.ID, updatedCredentials)
+	}
+	return
+}
+
+func (c *ClientService) DeleteCredentials(ctx context.Context, credentialsID string, version int64) (err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return fmt.Errorf("DeleteCredentials: %w", err)
+	}
+	err = c.deleteCredentials(ctx, credentialsID, version)
+	if c.fallBackToCache(err) {
+		err = c.queueOffline("DeleteCredentials", credentialsKind, deleteAction, credentialsID,
+			models.Credentials{ID: credentialsID, Version: version})
+	}
+	return
+}
+
+func (c *ClientService) CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string, metadata map[string]string) error {
+	card := models.Card{Number: number, ExpirationDate: expirationDate, HolderName: holderName, CVV: cvv, Metadata: metadata}
+	if err := c.replayPending(ctx); err != nil {
+		return fmt.Errorf("CreateCard: %w", err)
+	}
+	err := c.createCard(ctx, card)
+	if c.fallBackToCache(err) {
+		card.ID, card.UploadedAt = newLocalID(), time.Now()
+		return c.queueOffline("CreateCard", cardsKind, createAction, card.ID, card)
+	}
+	return err
+}
+
+func (c *ClientService) GetCards(ctx context.Context) (cards []models.Card, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return cards, fmt.Errorf("GetCards: %w", err)
+	}
+	cards, err = c.getCards(ctx)
+	if c.fallBackToCache(err) {
+		cards, err = loadCached[models.Card](c.cache, cardsKind)
+		if err != nil {
+			err = fmt.Errorf("GetCards: %w", err)
+		}
+		return
+	}
+	if err == nil {
+		cacheItems(c, cardsKind, cards, func(item models.Card) string { return item.ID })
+	}
+	return
+}
+
+func (c *ClientService) UpdateCards(ctx context.Context, card models.Card) (updatedCard models.Card, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return updatedCard, fmt.Errorf("UpdateCards: %w", err)
+	}
+	updatedCard, err = c.updateCards(ctx, card)
+	if c.fallBackToCache(err) {
+		updatedCard = card
+		updatedCard.UploadedAt = time.Now()
+		err = c.queueOffline("UpdateCards", cardsKind, updateAction, card.ID, updatedCard)
+	}
+	return
+}
+
+func (c *ClientService) DeleteCard(ctx context.Context, cardID string, version int64) (err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return fmt.Errorf("DeleteCard: %w", err)
+	}
+	err = c.deleteCard(ctx, cardID, version)
+	if c.fallBackToCache(err) {
+		err = c.queueOffline("DeleteCard", cardsKind, deleteAction, cardID, models.Card{ID: cardID, Version: version})
+	}
+	return
+}
+
+func (c *ClientService) CreateNote(ctx context.Context, title, body string, metadata map[string]string) error {
+	note := models.Note{Title: title, Body: body, Metadata: metadata}
+	if err := c.replayPending(ctx); err != nil {
+		return fmt.Errorf("CreateNote: %w", err)
+	}
+	err := c.createNote(ctx, note)
+	if c.fallBackToCache(err) {
+		note.ID, note.UploadedAt = newLocalID(), time.Now()
+		return c.queueOffline("CreateNote", notesKind, createAction, note.ID, note)
+	}
+	return err
+}
+
+func (c *ClientService) GetNotes(ctx context.Context) (notes []models.Note, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return notes, fmt.Errorf("GetNotes: %w", err)
+	}
+	notes, err = c.getNotes(ctx)
+	if c.fallBackToCache(err) {
+		notes, err = loadCached[models.Note](c.cache, notesKind)
+		if err != nil {
+			err = fmt.Errorf("GetNotes: %w", err)
+		}
+		return
+	}
+	if err == nil {
+		cacheItems(c, notesKind, notes, func(item models.Note) string { return item.ID })
+	}
+	return
+}
+
+func (c *ClientService) UpdateNote(ctx context.Context, note models.Note) (updatedNote models.Note, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return updatedNote, fmt.Errorf("UpdateNote: %w", err)
+	}
+	updatedNote, err = c.updateNote(ctx, note)
+	if c.fallBackToCache(err) {
+		updatedNote = note
+		updatedNote.UploadedAt = time.Now()
+		err = c.queueOffline("UpdateNote", notesKind, updateAction, note.ID, updatedNote)
+	}
+	return
+}
+
+func (c *ClientService) DeleteNote(ctx context.Context, noteID string, version int64) (err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return fmt.Errorf("DeleteNote: %w", err)
+	}
+	err = c.deleteNote(ctx, noteID, version)
+	if c.fallBackToCache(err) {
+		err = c.queueOffline("DeleteNote", notesKind, deleteAction, noteID, models.Note{ID: noteID, Version: version})
+	}
+	return
+}
+
+// GetFiles lists uploaded files, while server is unreachable last known list is returned
+// though files themselves are available only online.
+func (c *ClientService) GetFiles(ctx context.Context) (files []models.File, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return files, fmt.Errorf("GetFiles: %w", err)
+	}
+	files, err = c.getFiles(ctx)
+	if c.fallBackToCache(err) {
+		files, err = loadCached[models.File](c.cache, filesKind)
+		if err != nil {
+			err = fmt.Errorf("GetFiles: %w", err)
+		}
+		return
+	}
+	if err == nil {
+		cacheItems(c, filesKind, files, func(item models.File) string { return item.ID })
+	}
+	return
+}
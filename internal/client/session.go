@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/version"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenRefreshMargin - access token is refreshed this long before it expires, so request does not race expiration
+const tokenRefreshMargin = 30 * time.Second
+
+// setTokens keeps access token of session with its expiration time and refresh token which prolongs the session.
+func (c *ClientService) setTokens(token, refreshToken, expiresAt string) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	c.token = token
+	c.refreshToken = refreshToken
+	c.tokenExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+}
+
+// accessToken returns access token of session refreshing it when it is about to expire. Refresh token is
+// replaced on every refresh, so concurrent requests wait for single refresh instead of reusing old token,
+// which server treats as stolen one.
+func (c *ClientService) accessToken(ctx context.Context) string {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.refreshToken == "" || c.client == nil || time.Until(c.tokenExpiresAt) > tokenRefreshMargin {
+		return c.token
+	}
+	resp, err := c.client.RefreshToken(c.deviceCtx(ctx), &pb.RefreshTokenRequest{RefreshToken: c.refreshToken})
+	if err != nil {
+		logger.Log().Warn("access token can not be refreshed", zap.Error(err))
+		if status.Code(err) == codes.Unauthenticated {
+			// session is expired or revoked, so only new sign in helps
+			c.refreshToken = ""
+			c.keepRefreshToken("")
+		}
+		return c.token
+	}
+	c.token = resp.GetToken()
+	c.refreshToken = resp.GetRefreshToken()
+	c.tokenExpiresAt, _ = time.Parse(time.RFC3339, resp.GetExpiresAt())
+	c.keepRefreshToken(c.refreshToken)
+	return c.token
+}
+
+// keepRefreshToken saves refresh token to local cache, so session is restored after user signed in offline.
+// Refresh token is replaced on every refresh, so saved one has to follow it.
+func (c *ClientService) keepRefreshToken(refreshToken string) {
+	if c.cache == nil || c.cache.cipher == nil {
+		return
+	}
+	if err := c.cache.saveRefreshToken(refreshToken); err != nil {
+		logger.Log().Error("could not cache refresh token", zap.Error(err))
+	}
+}
+
+// deviceCtx - outgoing context with name of device and version of client, server labels sessions and change
+// events by them
+func (c *ClientService) deviceCtx(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "device", c.device, "client-version", clientVersion())
+}
+
+// clientVersion - version of client build, it is shown in list of sessions
+func clientVersion() string {
+	if version.BuildVersion == "" {
+		return "N/A"
+	}
+	return version.BuildVersion
+}
+
+// ListSessions - return signed in devices of user, the current one is marked
+func (c *ClientService) ListSessions(ctx context.Context) (sessions []models.Session, err error) {
+	response, err := c.client.ListSessions(c.getCtx(ctx), &pb.ListSessionsRequest{})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("ListSessions: %w", err)
+		return
+	}
+
+	sessions = make([]models.Session, 0, len(response.Sessions))
+	for _, session := range response.Sessions {
+		createdAt, _ := time.Parse(time.RFC3339, session.CreatedAt)
+		lastSeenAt, _ := time.Parse(time.RFC3339, session.LastSeenAt)
+		expiresAt, _ := time.Parse(time.RFC3339, session.ExpiresAt)
+		sessions = append(sessions, models.Session{
+			ID:            session.Id,
+			Device:        session.Device,
+			ClientVersion: session.ClientVersion,
+			IP:            session.Ip,
+			CreatedAt:     createdAt,
+			LastSeenAt:    lastSeenAt,
+			ExpiresAt:     expiresAt,
+			Current:       session.Current,
+		})
+	}
+	return
+}
+
+// RevokeSession - sign out device of user, it has to sign in again
+func (c *ClientService) RevokeSession(ctx context.Context, id string) (err error) {
+	_, err = c.client.RevokeSession(c.getCtx(ctx), &pb.RevokeSessionRequest{Id: id})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("RevokeSession: %w", err)
+	}
+	return
+}
+
+// ChangePassword - replace password of account, other devices are signed out and have to sign in with new password.
+// Vault key does not derive from password of account, master password is changed by ChangeMasterPassword.
+func (c *ClientService) ChangePassword(ctx context.Context, oldPassword, newPassword string) (revoked int, err error) {
+	response, err := c.client.ChangePassword(c.getCtx(ctx), &pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("ChangePassword: %w", err)
+		return
+	}
+	return int(response.RevokedSessions), nil
+}
+
+// DeleteAccount - erase account with all its data on server, session is finished and local copy of the vault
+// is removed from device as well
+func (c *ClientService) DeleteAccount(ctx context.Context, password string) (deletedAt time.Time, err error) {
+	response, err := c.client.DeleteAccount(c.getCtx(ctx), &pb.DeleteAccountRequest{Password: password})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("DeleteAccount: %w", err)
+		return
+	}
+	deletedAt, _ = time.Parse(time.RFC3339, response.DeletedAt)
+
+	c.setTokens("", "", "")
+	c.cipher, c.synced = nil, nil
+	if c.cache != nil {
+		_ = c.cache.Close()
+		c.cache = nil
+	}
+	if c.cacheDir != "" {
+		if removeErr := os.Remove(cachePath(c.cacheDir, c.serverAddress, c.email)); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+			logger.Log().Error("could not remove local cache of deleted account", zap.Error(removeErr))
+		}
+	}
+	c.email = ""
+	return deletedAt, nil
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientService_TokenRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	authorization := func(ctx context.Context) string {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return md.Get("authorization")[0]
+	}
+
+	testTable := []struct {
+		name                 string
+		expiresAt            time.Time
+		mock                 func()
+		expectedToken        string
+		expectedRefreshToken string
+	}{
+		{
+			name:      "Valid Token",
+			expiresAt: time.Now().Add(time.Hour),
+			mock: func() {
+				client.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, _ *pb.DeleteFileRequest, _ ...grpc.CallOption) (*pb.DeleteFileResponse, error) {
+						require.Equal(t, "oldToken", authorization(ctx))
+						return &pb.DeleteFileResponse{}, nil
+					})
+			},
+			expectedToken:        "oldToken",
+			expectedRefreshToken: "oldRefreshToken",
+		},
+		{
+			name:      "Expired Token",
+			expiresAt: time.Now().Add(-time.Minute),
+			mock: func() {
+				client.EXPECT().RefreshToken(gomock.Any(), &pb.RefreshTokenRequest{RefreshToken: "oldRefreshToken"}).Return(
+					&pb.RefreshTokenResponse{
+						Token:        "newToken",
+						RefreshToken: "newRefreshToken",
+						ExpiresAt:    time.Now().Add(time.Hour).Format(time.RFC3339),
+					}, nil)
+				client.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, _ *pb.DeleteFileRequest, _ ...grpc.CallOption) (*pb.DeleteFileResponse, error) {
+						require.Equal(t, "newToken", authorization(ctx))
+						return &pb.DeleteFileResponse{}, nil
+					})
+			},
+			expectedToken:        "newToken",
+			expectedRefreshToken: "newRefreshToken",
+		},
+		{
+			name:      "Revoked Session",
+			expiresAt: time.Now().Add(-time.Minute),
+			mock: func() {
+				client.EXPECT().RefreshToken(gomock.Any(), gomock.Any()).Return(
+					nil, status.Error(codes.Unauthenticated, "session is expired or revoked"))
+				client.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).Return(&pb.DeleteFileResponse{}, nil)
+			},
+			expectedToken:        "oldToken",
+			expectedRefreshToken: "",
+		},
+	}
+
+	for _, tt := range testTable {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			c := ClientService{client: client}
+			c.setTokens("oldToken", "oldRefreshToken", tt.expiresAt.Format(time.RFC3339))
+
+			err := c.DeleteFile(context.Background(), "file.txt")
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedToken, c.token)
+			require.Equal(t, tt.expectedRefreshToken, c.refreshToken)
+		})
+	}
+}
+
+func TestClientService_ListSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client}
+
+	client.EXPECT().ListSessions(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ *pb.ListSessionsRequest, _ ...grpc.CallOption) (*pb.ListSessionsResponse, error) {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			require.Equal(t, []string{"N/A"}, md.Get("client-version"))
+			return &pb.ListSessionsResponse{Sessions: []*pb.ListSessionsResponse_Session{
+				{Id: "1", Device: "laptop", ClientVersion: "v1.2.0", Ip: "10.0.0.1", LastSeenAt: "2026-01-02T03:04:05Z", Current: true},
+				{Id: "2", Device: "phone"},
+			}}, nil
+		})
+	sessions, err := c.ListSessions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Equal(t, "laptop", sessions[0].Device)
+	require.Equal(t, "10.0.0.1", sessions[0].IP)
+	require.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), sessions[0].LastSeenAt)
+	require.True(t, sessions[0].Current)
+	require.False(t, sessions[1].Current)
+
+	client.EXPECT().RevokeSession(gomock.Any(), &pb.RevokeSessionRequest{Id: "2"}).Return(&pb.RevokeSessionResponse{}, nil)
+	require.NoError(t, c.RevokeSession(context.Background(), "2"))
+
+	client.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.NotFound, "session not found"))
+	err = c.RevokeSession(context.Background(), "2")
+	require.Error(t, err)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestClientService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client, email: "test@example.com"}
+
+	client.EXPECT().ChangePassword(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.PermissionDenied, "old password is not valid"))
+	_, err := c.ChangePassword(context.Background(), "wrong", "changed")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	client.EXPECT().ChangePassword(gomock.Any(), &pb.ChangePasswordRequest{OldPassword: "password", NewPassword: "changed"}).
+		Return(&pb.ChangePasswordResponse{RevokedSessions: 2}, nil)
+	revoked, err := c.ChangePassword(context.Background(), "password", "changed")
+	require.NoError(t, err)
+	require.Equal(t, 2, revoked)
+}
+
+func TestClientService_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client, cacheDir: t.TempDir(), serverAddress: ":3200"}
+	client.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(&pb.SignInResponse{Token: "token"}, nil)
+	require.NoError(t, c.SignIn("test@example.com", "password"))
+	path := cachePath(c.cacheDir, ":3200", "test@example.com")
+	require.True(t, cacheExists(path))
+
+	client.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.PermissionDenied, "password is not valid"))
+	_, err := c.DeleteAccount(context.Background(), "wrong")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.True(t, cacheExists(path), "cache is kept while account exists")
+
+	client.EXPECT().DeleteAccount(gomock.Any(), &pb.DeleteAccountRequest{Password: "password"}).
+		Return(&pb.DeleteAccountResponse{Email: "test@example.com", DeletedAt: "2024-05-01T10:00:00Z"}, nil)
+	deletedAt, err := c.DeleteAccount(context.Background(), "password")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), deletedAt.UTC())
+	require.False(t, cacheExists(path), "local copy of deleted vault is removed")
+	require.Empty(t, c.token)
+	require.Empty(t, c.email)
+}
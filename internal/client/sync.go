@@ -0,0 +1,203 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"go.uber.org/zap"
+)
+
+// syncState - copy of user's vault received by previous syncs, changes received from server are merged into it
+type syncState struct {
+	cursor      int64
+	credentials map[string]models.Credentials
+	cards       map[string]models.Card
+	notes       map[string]models.Note
+	files       map[string]models.File
+}
+
+func newSyncState() *syncState {
+	return &syncState{
+		credentials: make(map[string]models.Credentials),
+		cards:       make(map[string]models.Card),
+		notes:       make(map[string]models.Note),
+		files:       make(map[string]models.File),
+	}
+}
+
+// vault - full change set with all items of synced vault, items are ordered by upload time, files by name
+func (state *syncState) vault() models.ChangeSet {
+	vault := models.ChangeSet{
+		Cursor:      state.cursor,
+		Full:        true,
+		Credentials: slices.Collect(maps.Values(state.credentials)),
+		Cards:       slices.Collect(maps.Values(state.cards)),
+		Notes:       slices.Collect(maps.Values(state.notes)),
+		Files:       slices.Collect(maps.Values(state.files)),
+	}
+	slices.SortFunc(vault.Credentials, func(a, b models.Credentials) int {
+		return cmp.Or(a.UploadedAt.Compare(b.UploadedAt), cmp.Compare(a.ID, b.ID))
+	})
+	slices.SortFunc(vault.Cards, func(a, b models.Card) int {
+		return cmp.Or(a.UploadedAt.Compare(b.UploadedAt), cmp.Compare(a.ID, b.ID))
+	})
+	slices.SortFunc(vault.Notes, func(a, b models.Note) int {
+		return cmp.Or(a.UploadedAt.Compare(b.UploadedAt), cmp.Compare(a.ID, b.ID))
+	})
+	slices.SortFunc(vault.Files, func(a, b models.File) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return vault
+}
+
+// sync receives items changed since previous sync and merges them into synced copy of vault.
+func (c *ClientService) sync(ctx context.Context) (vault models.ChangeSet, err error) {
+	state := c.synced
+	if state == nil {
+		state = newSyncState()
+	}
+	resp, err := c.client.Sync(c.getCtx(ctx), &pb.SyncRequest{Cursor: state.cursor})
+	if err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+
+	changes := models.ChangeSet{Cursor: resp.Cursor, Full: resp.Full}
+	for _, credentials := range resp.Credentials {
+		var opened models.Credentials
+		if opened, err = c.openCredentials(credentials); err != nil {
+			return vault, fmt.Errorf("Sync: %w", err)
+		}
+		changes.Credentials = append(changes.Credentials, opened)
+	}
+	for _, card := range resp.Cards {
+		var opened models.Card
+		if opened, err = c.openCard(card); err != nil {
+			return vault, fmt.Errorf("Sync: %w", err)
+		}
+		changes.Cards = append(changes.Cards, opened)
+	}
+	for _, note := range resp.Notes {
+		var opened models.Note
+		if opened, err = c.openNote(note); err != nil {
+			return vault, fmt.Errorf("Sync: %w", err)
+		}
+		changes.Notes = append(changes.Notes, opened)
+	}
+	for _, file := range resp.Files {
+		var opened models.File
+		if opened, err = c.openFile(file); err != nil {
+			return vault, fmt.Errorf("Sync: %w", err)
+		}
+		changes.Files = append(changes.Files, opened)
+	}
+
+	if changes.Full {
+		state = newSyncState()
+	}
+	state.merge(changes, resp.Deleted)
+	c.synced = state
+	c.cacheChanges(changes, resp.Deleted)
+	return state.vault(), nil
+}
+
+// merge applies changes received from server to synced copy of vault.
+func (state *syncState) merge(changes models.ChangeSet, deleted []*pb.SyncResponse_DeletedItem) {
+	state.cursor = changes.Cursor
+	for _, credentials := range changes.Credentials {
+		state.credentials[credentials.ID] = credentials
+	}
+	for _, card := range changes.Cards {
+		state.cards[card.ID] = card
+	}
+	for _, note := range changes.Notes {
+		state.notes[note.ID] = note
+	}
+	for _, file := range changes.Files {
+		state.files[file.ID] = file
+	}
+	for _, item := range deleted {
+		switch item.ItemType {
+		case pb.ItemType_ITEM_TYPE_CREDENTIALS:
+			delete(state.credentials, item.Id)
+		case pb.ItemType_ITEM_TYPE_CARD:
+			delete(state.cards, item.Id)
+		case pb.ItemType_ITEM_TYPE_NOTE:
+			delete(state.notes, item.Id)
+		case pb.ItemType_ITEM_TYPE_FILE:
+			delete(state.files, item.Id)
+		}
+	}
+}
+
+// Sync brings copy of vault up to date with server, only items changed since previous sync are transferred.
+// Result is full change set with all items of vault, while server is unreachable it is read from local cache.
+func (c *ClientService) Sync(ctx context.Context) (vault models.ChangeSet, err error) {
+	if err = c.replayPending(ctx); err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+	vault, err = c.sync(ctx)
+	if c.fallBackToCache(err) {
+		return c.cachedVault()
+	}
+	return
+}
+
+// cacheChanges keeps items received by sync in local cache. Full copy of vault replaces cached items, while
+// delta is applied to them, so refresh of cache costs as much as items changed since previous sync.
+func (c *ClientService) cacheChanges(changes models.ChangeSet, deleted []*pb.SyncResponse_DeletedItem) {
+	deletedIDs := make(map[pb.ItemType][]string)
+	for _, item := range deleted {
+		deletedIDs[item.ItemType] = append(deletedIDs[item.ItemType], item.Id)
+	}
+	cacheDelta(c, changes.Full, credentialsKind, changes.Credentials, deletedIDs[pb.ItemType_ITEM_TYPE_CREDENTIALS],
+		func(item models.Credentials) string { return item.ID })
+	cacheDelta(c, changes.Full, cardsKind, changes.Cards, deletedIDs[pb.ItemType_ITEM_TYPE_CARD],
+		func(item models.Card) string { return item.ID })
+	cacheDelta(c, changes.Full, notesKind, changes.Notes, deletedIDs[pb.ItemType_ITEM_TYPE_NOTE],
+		func(item models.Note) string { return item.ID })
+	cacheDelta(c, changes.Full, filesKind, changes.Files, deletedIDs[pb.ItemType_ITEM_TYPE_FILE],
+		func(item models.File) string { return item.ID })
+}
+
+// cacheDelta applies items of given kind changed since previous sync to local cache, full change set
+// replaces cached items.
+func cacheDelta[T any](c *ClientService, full bool, kind string, items []T, deleted []string, id func(T) string) {
+	if full {
+		cacheItems(c, kind, items, id)
+		return
+	}
+	if c.cache == nil || c.cache.cipher == nil || len(items)+len(deleted) == 0 {
+		return
+	}
+	byID := make(map[string]any, len(items))
+	for _, item := range items {
+		byID[id(item)] = item
+	}
+	if err := c.cache.apply(kind, byID, deleted); err != nil {
+		logger.Log().Error("could not update local cache", zap.String("kind", kind), zap.Error(err))
+	}
+}
+
+// cachedVault reads all items of vault from local cache.
+func (c *ClientService) cachedVault() (vault models.ChangeSet, err error) {
+	vault.Full = true
+	if vault.Credentials, err = loadCached[models.Credentials](c.cache, credentialsKind); err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+	if vault.Cards, err = loadCached[models.Card](c.cache, cardsKind); err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+	if vault.Notes, err = loadCached[models.Note](c.cache, notesKind); err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+	if vault.Files, err = loadCached[models.File](c.cache, filesKind); err != nil {
+		return vault, fmt.Errorf("Sync: %w", err)
+	}
+	return
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/models"
+)
+
+// VerifyTOTP finishes sign in which returned ErrTOTPRequired, code is either code of authenticator app
+// or one of recovery codes.
+func (c *ClientService) VerifyTOTP(code string) error {
+	resp, err := c.client.VerifyTOTP(c.deviceCtx(context.Background()), &pb.VerifyTOTPRequest{
+		PartialToken: c.partialToken,
+		Code:         code,
+	})
+	if err != nil {
+		return fmt.Errorf("VerifyTOTP: %w", err)
+	}
+	c.partialToken = ""
+	c.setTokens(resp.GetToken(), resp.GetRefreshToken(), resp.GetExpiresAt())
+	c.isAvailable = true
+	c.openCache(c.email)
+
+	return nil
+}
+
+// EnrollTOTP - generate secret of authenticator app, it is required on sign in after ConfirmTOTP
+func (c *ClientService) EnrollTOTP(ctx context.Context) (enrollment models.TOTPEnrollment, err error) {
+	response, err := c.client.EnrollTOTP(c.getCtx(ctx), &pb.EnrollTOTPRequest{})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("EnrollTOTP: %w", err)
+		return
+	}
+
+	enrollment = models.TOTPEnrollment{
+		Secret:          response.Secret,
+		ProvisioningURI: response.ProvisioningUri,
+		RecoveryCodes:   response.RecoveryCodes,
+	}
+	return
+}
+
+// ConfirmTOTP - enable two-factor authentication by code of just provisioned authenticator app
+func (c *ClientService) ConfirmTOTP(ctx context.Context, code string) (err error) {
+	_, err = c.client.ConfirmTOTP(c.getCtx(ctx), &pb.ConfirmTOTPRequest{Code: code})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("ConfirmTOTP: %w", err)
+	}
+	return
+}
+
+// DisableTOTP - turn two-factor authentication off, code is either code of authenticator app or recovery code
+func (c *ClientService) DisableTOTP(ctx context.Context, code string) (err error) {
+	_, err = c.client.DisableTOTP(c.getCtx(ctx), &pb.DisableTOTPRequest{Code: code})
+	if c.fallBackToCache(err) {
+		err = ErrOffline
+	}
+	if err != nil {
+		err = fmt.Errorf("DisableTOTP: %w", err)
+	}
+	return
+}
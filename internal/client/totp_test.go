@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientService_SignInWithTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client}
+
+	client.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(
+		&pb.SignInResponse{TotpRequired: true, PartialToken: "partialToken"}, nil)
+	err := c.SignIn("test@example.com", "password")
+	require.True(t, errors.Is(err, ErrTOTPRequired))
+	require.Empty(t, c.token)
+
+	client.EXPECT().VerifyTOTP(gomock.Any(), &pb.VerifyTOTPRequest{PartialToken: "partialToken", Code: "000000"}).Return(
+		nil, status.Error(codes.Unauthenticated, "two-factor authentication code is not valid"))
+	err = c.VerifyTOTP("000000")
+	require.Equal(t, codes.Unauthenticated, status.Code(errors.Unwrap(err)))
+
+	client.EXPECT().VerifyTOTP(gomock.Any(), &pb.VerifyTOTPRequest{PartialToken: "partialToken", Code: "123456"}).Return(
+		&pb.VerifyTOTPResponse{
+			Token:        "token",
+			RefreshToken: "refreshToken",
+			ExpiresAt:    time.Now().Add(time.Hour).Format(time.RFC3339),
+		}, nil)
+	require.NoError(t, c.VerifyTOTP("123456"))
+	require.Equal(t, "token", c.token)
+	require.Equal(t, "refreshToken", c.refreshToken)
+	require.Empty(t, c.partialToken)
+	require.Equal(t, "test@example.com", c.email)
+	require.True(t, c.isAvailable)
+}
+
+func TestClientService_EnrollTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := mock.NewMockGophKeeperServiceClient(ctrl)
+	c := ClientService{client: client, isAvailable: true}
+
+	client.EXPECT().EnrollTOTP(gomock.Any(), gomock.Any()).Return(&pb.EnrollTOTPResponse{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningUri: "otpauth://totp/GophKeeper:test@example.com?secret=JBSWY3DPEHPK3PXP",
+		RecoveryCodes:   []string{"aaaaa-bbbbb"},
+	}, nil)
+	enrollment, err := c.EnrollTOTP(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", enrollment.Secret)
+	require.Contains(t, enrollment.ProvisioningURI, "otpauth://")
+	require.Equal(t, []string{"aaaaa-bbbbb"}, enrollment.RecoveryCodes)
+
+	client.EXPECT().ConfirmTOTP(gomock.Any(), &pb.ConfirmTOTPRequest{Code: "123456"}).Return(&pb.ConfirmTOTPResponse{}, nil)
+	require.NoError(t, c.ConfirmTOTP(context.Background(), "123456"))
+
+	client.EXPECT().DisableTOTP(gomock.Any(), &pb.DisableTOTPRequest{Code: "aaaaa-bbbbb"}).Return(
+		nil, status.Error(codes.InvalidArgument, "two-factor authentication code is not valid"))
+	err = c.DisableTOTP(context.Background(), "aaaaa-bbbbb")
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(errors.Unwrap(err)))
+
+	client.EXPECT().EnrollTOTP(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "server is down"))
+	_, err = c.EnrollTOTP(context.Background())
+	require.Error(t, err)
+	require.False(t, c.isAvailable)
+}
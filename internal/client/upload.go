@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/vault"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// uploadChunkSize - size of chunks file is streamed by
+	uploadChunkSize = 1 << 20
+	// uploadAttempts - number of streams upload is tried with before giving up
+	uploadAttempts = 5
+)
+
+// transferRetryDelay - pause before interrupted upload or download is resumed, it grows with every attempt
+var transferRetryDelay = time.Second
+
+// ErrUploadDigestMismatch - error when server stored file different from local one
+var ErrUploadDigestMismatch = errors.New("uploaded file digest mismatch")
+
+// upload - state of file upload shared by streams
+type upload struct {
+	file          *os.File
+	filename      string
+	metadata      map[string]string
+	fileKey       string
+	encryptedName string
+	digest        string
+	id            string
+	offset        int64
+}
+
+// fileKeyField - name of field wrapped file key is bound to together with name file is stored under
+const fileKeyField = "file_key"
+
+// encryptFile encrypts file with new file key into temporary file. Encrypted file is uploaded under random name,
+// original name is sealed and kept next to wrapped file key, both are bound to random name.
+func (c *ClientService) encryptFile(filePath string, u *upload) (encryptedPath string, err error) {
+	key, err := vault.NewFileKey()
+	if err != nil {
+		return "", err
+	}
+	name := uuid.NewString()
+	if u.fileKey, err = c.cipher.WrapKey(key, vault.Binding(models.FileItem, name, fileKeyField)); err != nil {
+		return "", err
+	}
+	u.encryptedName = u.filename
+	if err = c.seal(models.FileItem, name, map[string]*string{"name": &u.encryptedName}); err != nil {
+		return "", err
+	}
+	u.filename = name
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	dst, err := os.CreateTemp("", "gophkeeper-upload-*")
+	if err != nil {
+		return "", err
+	}
+	err = vault.EncryptStream(dst, src, key)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// uploadFile streams file to server, interrupted stream is continued from offset committed by server.
+func (c *ClientService) uploadFile(ctx context.Context, filePath string, u *upload) (*pb.UploadFileResponse, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("uploadFile: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("uploadFile: %w", err)
+	}
+	u.file, u.digest = file, hex.EncodeToString(hash.Sum(nil))
+
+	var resp *pb.UploadFileResponse
+	for attempt := 1; ; attempt++ {
+		if err = c.resumeUpload(ctx, u); err == nil {
+			resp, err = c.sendUpload(ctx, u)
+		}
+		if err == nil {
+			if resp.Digest != u.digest {
+				return resp, fmt.Errorf("uploadFile: %w", ErrUploadDigestMismatch)
+			}
+			return resp, nil
+		}
+		if !resumable(err) || attempt == uploadAttempts {
+			return nil, fmt.Errorf("uploadFile: %w", err)
+		}
+		logger.Log().Info("upload interrupted, resuming", zap.String("file", u.filename), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("uploadFile: %w", ctx.Err())
+		case <-time.After(time.Duration(attempt) * transferRetryDelay):
+		}
+	}
+}
+
+// resumable - check if upload failed because of connection and may be continued
+func resumable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// resumeUpload - moves upload to offset committed by server, upload starts over if server lost session
+func (c *ClientService) resumeUpload(ctx context.Context, u *upload) error {
+	if u.id == "" {
+		u.offset = 0
+		return nil
+	}
+	resp, err := c.client.ResumeUpload(c.getCtx(ctx), &pb.ResumeUploadRequest{UploadId: u.id})
+	if status.Code(err) == codes.NotFound {
+		u.id, u.offset = "", 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	u.offset = resp.Offset
+	return nil
+}
+
+// sendUpload - streams file from upload offset and waits for server to complete it
+func (c *ClientService) sendUpload(ctx context.Context, u *upload) (*pb.UploadFileResponse, error) {
+	if _, err := u.file.Seek(u.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	stream, err := c.client.UploadFile(c.getCtx(ctx))
+	if err != nil {
+		return nil, err
+	}
+	// broken stream reports io.EOF on Send, actual status is received by Recv
+	streamErr := func(err error) error {
+		if err == io.EOF {
+			_, err = stream.Recv()
+		}
+		return err
+	}
+
+	buffer := make([]byte, uploadChunkSize)
+	for started := false; ; started = true {
+		n, err := io.ReadFull(u.file, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		if n == 0 && started {
+			break
+		}
+
+		checksum := sha256.Sum256(buffer[:n])
+		request := &pb.UploadFileRequest{
+			Data:     buffer[:n],
+			Filename: u.filename,
+			UploadId: u.id,
+			Offset:   u.offset,
+			Checksum: hex.EncodeToString(checksum[:]),
+		}
+		if u.id == "" {
+			request.Metadata, request.Digest = u.metadata, u.digest
+			request.FileKey, request.EncryptedName = u.fileKey, u.encryptedName
+		}
+		if err = stream.Send(request); err != nil {
+			return nil, streamErr(err)
+		}
+		if !started {
+			session, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			u.id = session.UploadId
+		}
+		u.offset += int64(n)
+	}
+
+	if err = stream.Send(&pb.UploadFileRequest{UploadId: u.id, Digest: u.digest}); err != nil {
+		return nil, streamErr(err)
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
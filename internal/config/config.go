@@ -7,6 +7,8 @@ const (
 	TOKENPREFIX         headerKey = "Bearer "
 	USERIDCONTEXTKEY    headerKey = "userID"
 	SESSIONIDCONTEXTKEY headerKey = "sessionID"
+	DEVICEHEADER        headerKey = "device"
+	CLIENTVERSIONHEADER headerKey = "client-version"
 )
 
 // ServerConfig - shortener server configurations
@@ -14,8 +16,23 @@ type ServerConfig struct {
 	Secret        string `json:"-"`               // Secret for encryption algorithms
 	LogsLevel     string `json:"-"`               // LogsLevel - level of logger
 	GRPCAddress   string `json:"grpc_address"`    // GRPCAddress - address which system use to run gRPC server
-	DatabaseDSN   string `json:"database_dsn"`    // DatabaseDSN - DSN path for DB connection
+	DatabaseDSN   string `json:"database_dsn"`    // DatabaseDSN - DSN path for DB connection, sqlite:// scheme selects embedded SQLite
 	MinIOAddress  string `json:"min_io_address"`  // MinIOAddress - address on which system use to connect to MinIO
 	MinIOLogin    string `json:"min_io_login"`    // MinIOLogin - login which system use to connect to MinIO
 	MinIOPassword string `json:"min_io_password"` // MinIOPassword - password which system use to connect to MinIO
+	BlobStore     string `json:"blob_store"`      // BlobStore - storage of users' files: minio or fs
+	BlobStorePath string `json:"blob_store_path"` // BlobStorePath - directory which keeps users' files in fs storage
+
+	MasterKeyFile          string   `json:"master_key_file"`           // MasterKeyFile - path to file with base64 encoded master key
+	PreviousMasterKeyFiles []string `json:"previous_master_key_files"` // PreviousMasterKeyFiles - paths to master keys which are being rotated out
+	MasterKey              string   `json:"-"`                         // MasterKey - base64 encoded key which wraps users data keys
+	PreviousMasterKeys     []string `json:"-"`                         // PreviousMasterKeys - keys which are still accepted to unwrap data keys
+	RotateKeys             bool     `json:"-"`                         // RotateKeys - re-wrap all data keys by MasterKey and exit
+
+	TrashRetention string `json:"trash_retention"` // TrashRetention - how long deleted items are kept in trash before purge, e.g. 720h
+
+	SignInAttempts int    `json:"sign_in_attempts"` // SignInAttempts - failed sign in attempts in a row which lock account
+	SignInLockout  string `json:"sign_in_lockout"`  // SignInLockout - how long account stays locked and failures are remembered, e.g. 15m
+
+	Demo bool `json:"-"` // Demo - keep data in memory and files in BlobStorePath, so server runs without any infrastructure
 }
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+)
+
+// Types of Bitwarden items
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+	bitwardenCard       = 3
+	bitwardenIdentity   = 4
+)
+
+// bitwardenExport - unencrypted JSON export of Bitwarden vault
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Fields   []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+	Login struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+}
+
+func parseBitwarden(src io.Reader) (items Items, err error) {
+	var export bitwardenExport
+	if err = json.NewDecoder(src).Decode(&export); err != nil {
+		return items, fmt.Errorf("Bitwarden JSON can not be decoded: %w", err)
+	}
+	if export.Encrypted {
+		return items, ErrEncryptedExport
+	}
+	folders := make(map[string]string, len(export.Folders))
+	for _, folder := range export.Folders {
+		folders[folder.ID] = folder.Name
+	}
+
+	for _, item := range export.Items {
+		metadata := map[string]string{"folder": folders[item.FolderID]}
+		for _, field := range item.Fields {
+			if _, exists := metadata[field.Name]; !exists {
+				metadata[field.Name] = field.Value
+			}
+		}
+
+		switch item.Type {
+		case bitwardenLogin:
+			if len(item.Login.URIs) > 0 {
+				metadata["url"] = item.Login.URIs[0].URI
+			}
+			items.addLogin(item.Name, item.Login.Username, item.Login.Password, item.Login.TOTP, item.Notes, metadata)
+		case bitwardenSecureNote:
+			items.addNote(item.Name, item.Notes, metadata)
+		case bitwardenCard:
+			metadata["title"], metadata["brand"], metadata["notes"] = item.Name, item.Card.Brand, item.Notes
+			card := models.NewCard(strings.ReplaceAll(item.Card.Number, " ", ""),
+				expirationDate(item.Card.ExpMonth, item.Card.ExpYear), item.Card.CardholderName, item.Card.Code)
+			card.Metadata = compact(metadata)
+			items.Cards = append(items.Cards, card)
+		case bitwardenIdentity:
+			items.Skipped = append(items.Skipped, fmt.Sprintf("%q: identities are not supported", item.Name))
+		default:
+			items.Skipped = append(items.Skipped, fmt.Sprintf("%q: unknown item type %d", item.Name, item.Type))
+		}
+	}
+	return items, nil
+}
+
+// expirationDate - expiration of card in MM/YY form used by card screen
+func expirationDate(month, year string) string {
+	if month == "" && year == "" {
+		return ""
+	}
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if len(year) == 4 {
+		year = year[2:]
+	}
+	return month + "/" + year
+}
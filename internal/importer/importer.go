@@ -0,0 +1,129 @@
+// Package importer maps exports of other password managers to items of vault. Supported exports are KeePass XML
+// (File > Export > KeePass XML), unencrypted Bitwarden JSON and 1Password CSV. Fields which have no place in
+// GophKeeper items, like URLs, folders and custom fields, are kept in metadata of items.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/totp"
+)
+
+// Formats of exports
+const (
+	KeePass     = "keepass"
+	Bitwarden   = "bitwarden"
+	OnePassword = "1password"
+)
+
+// kdbxSignature - first bytes of KeePass database, it is encrypted and has to be exported to XML first
+var kdbxSignature = []byte{0x03, 0xD9, 0xA2, 0x9A}
+
+var (
+	// ErrUnknownFormat - error when format of export is not supported
+	ErrUnknownFormat = errors.New("unknown export format, expected keepass, bitwarden or 1password")
+	// ErrEncryptedExport - error when export is encrypted by password manager
+	ErrEncryptedExport = errors.New("export is encrypted, export vault without encryption")
+)
+
+// Items - vault items mapped from export
+type Items struct {
+	Credentials []models.Credentials
+	Cards       []models.Card
+	Notes       []models.Note
+	// Skipped - entries of export which are not imported, with reason
+	Skipped []string
+}
+
+// Parse - map export of format read from src to vault items
+func Parse(format string, src io.Reader) (Items, error) {
+	switch strings.ToLower(format) {
+	case KeePass:
+		reader := bufio.NewReader(src)
+		if signature, _ := reader.Peek(len(kdbxSignature)); bytes.Equal(signature, kdbxSignature) {
+			return Items{}, errors.New("KDBX database is encrypted, export it to KeePass XML (File > Export) first")
+		}
+		return parseKeePass(reader)
+	case Bitwarden:
+		return parseBitwarden(src)
+	case OnePassword:
+		return parseOnePassword(src)
+	}
+	return Items{}, ErrUnknownFormat
+}
+
+// DetectFormat - format of export by extension of its file, empty when it can not be guessed
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml", ".kdbx":
+		return KeePass
+	case ".json":
+		return Bitwarden
+	case ".csv":
+		return OnePassword
+	}
+	return ""
+}
+
+// Count - number of mapped items
+func (items *Items) Count() int {
+	return len(items.Credentials) + len(items.Cards) + len(items.Notes)
+}
+
+// addLogin - map login entry to credentials, entry without username and password becomes note when it has text
+func (items *Items) addLogin(title, username, password, otp, notes string, metadata map[string]string) {
+	if username == "" && password == "" {
+		items.addNote(title, notes, metadata)
+		return
+	}
+	if title == "" {
+		title = metadata["url"]
+	}
+	if notes != "" {
+		metadata["notes"] = notes
+	}
+	credentials := models.NewCredentials(title, username, password)
+	if otp != "" {
+		seed, err := totp.NormalizeSeed(otp)
+		if err != nil {
+			items.Skipped = append(items.Skipped, fmt.Sprintf("TOTP of %q: %v", title, err))
+		}
+		credentials.TOTP = seed
+	}
+	credentials.Metadata = compact(metadata)
+	items.Credentials = append(items.Credentials, credentials)
+}
+
+// addNote - map entry with text only to note, entry without text is skipped
+func (items *Items) addNote(title, body string, metadata map[string]string) {
+	if body == "" {
+		items.Skipped = append(items.Skipped, fmt.Sprintf("%q: entry has no login, password or text", title))
+		return
+	}
+	if title == "" {
+		title = "Imported note"
+	}
+	note := models.NewNote(title, body)
+	note.Metadata = compact(metadata)
+	items.Notes = append(items.Notes, note)
+}
+
+// compact - metadata without empty values, nil when nothing is left
+func compact(metadata map[string]string) map[string]string {
+	for key, value := range metadata {
+		if strings.TrimSpace(value) == "" {
+			delete(metadata, key)
+		}
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
@@ -0,0 +1,156 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keePassXML = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta><RecycleBinUUID>bin</RecycleBinUUID></Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>GitHub</Value></String>
+				<String><Key>UserName</Key><Value>octocat</Value></String>
+				<String><Key>Password</Key><Value>secret</Value></String>
+				<String><Key>URL</Key><Value>https://github.com</Value></String>
+				<String><Key>otp</Key><Value>otpauth://totp/GitHub:octocat?secret=JBSWY3DPEHPK3PXP&amp;issuer=GitHub</Value></String>
+				<String><Key>Recovery</Key><Value>codes</Value></String>
+				<History><Entry><String><Key>Password</Key><Value>old</Value></String></Entry></History>
+			</Entry>
+			<Group>
+				<UUID>work</UUID>
+				<Name>Work</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Door</Value></String>
+					<String><Key>Notes</Key><Value>code 1234</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>bin</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+					<String><Key>UserName</Key><Value>user</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+const bitwardenJSON = `{
+	"encrypted": false,
+	"folders": [{"id": "f1", "name": "Banking"}],
+	"items": [
+		{"type": 1, "name": "Mail", "folderId": null, "notes": "main box",
+			"login": {"username": "user@example.com", "password": "secret", "totp": "bad seed!", "uris": [{"uri": "https://mail.example.com"}]},
+			"fields": [{"name": "pin", "value": "0000"}]},
+		{"type": 2, "name": "Wi-Fi", "notes": "password"},
+		{"type": 3, "name": "Visa", "folderId": "f1",
+			"card": {"cardholderName": "John Doe", "brand": "Visa", "number": "4111 1111 1111 1111", "expMonth": "3", "expYear": "2030", "code": "123"}},
+		{"type": 4, "name": "Passport"}
+	]
+}`
+
+const onePasswordCSV = "\ufeffTitle,Website,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes,Security question\n" +
+	"Shop,https://shop.example.com,buyer,secret,JBSWY3DPEHPK3PXP,false,false,shopping,,pet\n" +
+	"Old,,old,old,,false,true,,,\n" +
+	",https://forum.example.com,reader,pass,,false,false,,\"line one\nline two\",\n"
+
+func TestParse_KeePass(t *testing.T) {
+	items, err := Parse(KeePass, strings.NewReader(keePassXML))
+	require.NoError(t, err)
+
+	require.Len(t, items.Credentials, 1)
+	credentials := items.Credentials[0]
+	assert.Equal(t, "GitHub", credentials.ServiceName)
+	assert.Equal(t, "octocat", credentials.Identity)
+	assert.Equal(t, "secret", credentials.Password)
+	assert.NotEmpty(t, credentials.TOTP)
+	assert.Equal(t, map[string]string{"url": "https://github.com", "Recovery": "codes"}, credentials.Metadata)
+
+	require.Len(t, items.Notes, 1)
+	assert.Equal(t, "Door", items.Notes[0].Title)
+	assert.Equal(t, "code 1234", items.Notes[0].Body)
+	assert.Equal(t, map[string]string{"folder": "Work"}, items.Notes[0].Metadata)
+	assert.Empty(t, items.Skipped)
+	assert.Equal(t, 2, items.Count())
+}
+
+func TestParse_Bitwarden(t *testing.T) {
+	items, err := Parse(Bitwarden, strings.NewReader(bitwardenJSON))
+	require.NoError(t, err)
+
+	require.Len(t, items.Credentials, 1)
+	credentials := items.Credentials[0]
+	assert.Equal(t, "Mail", credentials.ServiceName)
+	assert.Equal(t, "secret", credentials.Password)
+	assert.Empty(t, credentials.TOTP)
+	assert.Equal(t, map[string]string{"url": "https://mail.example.com", "pin": "0000", "notes": "main box"}, credentials.Metadata)
+
+	require.Len(t, items.Notes, 1)
+	assert.Equal(t, "Wi-Fi", items.Notes[0].Title)
+
+	require.Len(t, items.Cards, 1)
+	card := items.Cards[0]
+	assert.Equal(t, "4111111111111111", card.Number)
+	assert.Equal(t, "03/30", card.ExpirationDate)
+	assert.Equal(t, "John Doe", card.HolderName)
+	assert.Equal(t, "123", card.CVV)
+	assert.Equal(t, map[string]string{"title": "Visa", "brand": "Visa", "folder": "Banking"}, card.Metadata)
+
+	require.Len(t, items.Skipped, 2)
+	assert.Contains(t, items.Skipped[0], "TOTP of \"Mail\"")
+	assert.Contains(t, items.Skipped[1], "identities are not supported")
+
+	_, err = Parse(Bitwarden, strings.NewReader(`{"encrypted": true, "items": []}`))
+	require.ErrorIs(t, err, ErrEncryptedExport)
+}
+
+func TestParse_OnePassword(t *testing.T) {
+	items, err := Parse(OnePassword, strings.NewReader(onePasswordCSV))
+	require.NoError(t, err)
+
+	require.Len(t, items.Credentials, 2)
+	shop := items.Credentials[0]
+	assert.Equal(t, "Shop", shop.ServiceName)
+	assert.Equal(t, "buyer", shop.Identity)
+	assert.NotEmpty(t, shop.TOTP)
+	assert.Equal(t, map[string]string{"url": "https://shop.example.com", "tags": "shopping", "security question": "pet"}, shop.Metadata)
+
+	forum := items.Credentials[1]
+	assert.Equal(t, "https://forum.example.com", forum.ServiceName, "title should fall back to url")
+	assert.Equal(t, "line one\nline two", forum.Metadata["notes"])
+
+	require.Len(t, items.Skipped, 1)
+	assert.Contains(t, items.Skipped[0], "archived")
+
+	_, err = Parse(OnePassword, strings.NewReader("a,b\n1,2\n"))
+	require.Error(t, err)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse("lastpass", strings.NewReader(""))
+	require.ErrorIs(t, err, ErrUnknownFormat)
+
+	kdbx := append([]byte{0x03, 0xD9, 0xA2, 0x9A, 0x67, 0xFB, 0x4B, 0xB5}, bytes.Repeat([]byte{0}, 32)...)
+	_, err = Parse(KeePass, bytes.NewReader(kdbx))
+	require.ErrorContains(t, err, "KeePass XML")
+
+	_, err = Parse(Bitwarden, strings.NewReader("not json"))
+	require.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, KeePass, DetectFormat("/tmp/Database.XML"))
+	assert.Equal(t, Bitwarden, DetectFormat("bitwarden_export.json"))
+	assert.Equal(t, OnePassword, DetectFormat("1password.csv"))
+	assert.Equal(t, "", DetectFormat("export.txt"))
+}
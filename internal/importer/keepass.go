@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// keePassFile - XML export of KeePass database, history of entries is not imported
+type keePassFile struct {
+	XMLName    xml.Name       `xml:"KeePassFile"`
+	RecycleBin string         `xml:"Meta>RecycleBinUUID"`
+	Groups     []keePassGroup `xml:"Root>Group"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+}
+
+// keePassOTPFields - fields KeePass and KeePassXC keep TOTP seed in
+var keePassOTPFields = []string{"otp", "TimeOtp-Secret-Base32"}
+
+func parseKeePass(src io.Reader) (items Items, err error) {
+	var file keePassFile
+	if err = xml.NewDecoder(src).Decode(&file); err != nil {
+		return items, fmt.Errorf("KeePass XML can not be decoded: %w", err)
+	}
+	// root group is named after database, folders are counted from its subgroups
+	for _, root := range file.Groups {
+		items.addKeePassGroup(root, "", file.RecycleBin)
+	}
+	return items, nil
+}
+
+func (items *Items) addKeePassGroup(group keePassGroup, folder, recycleBin string) {
+	if group.UUID != "" && group.UUID == recycleBin {
+		return
+	}
+	for _, entry := range group.Entries {
+		fields := make(map[string]string, len(entry.Strings))
+		for _, field := range entry.Strings {
+			fields[field.Key] = field.Value
+		}
+		title, username, password, notes := fields["Title"], fields["UserName"], fields["Password"], fields["Notes"]
+		var otp string
+		for _, key := range keePassOTPFields {
+			if otp == "" {
+				otp = fields[key]
+			}
+		}
+
+		metadata := map[string]string{"url": fields["URL"], "folder": folder}
+		for key, value := range fields {
+			switch key {
+			case "Title", "UserName", "Password", "Notes", "URL", "otp":
+				continue
+			}
+			// parameters of one-time passwords are part of seed
+			if strings.HasPrefix(key, "TimeOtp-") || strings.HasPrefix(key, "HmacOtp-") {
+				continue
+			}
+			if _, exists := metadata[key]; !exists {
+				metadata[key] = value
+			}
+		}
+		items.addLogin(title, username, password, otp, notes, metadata)
+	}
+
+	for _, subgroup := range group.Groups {
+		path := subgroup.Name
+		if folder != "" {
+			path = folder + "/" + subgroup.Name
+		}
+		items.addKeePassGroup(subgroup, path, recycleBin)
+	}
+}
@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// onePasswordColumns - fields of logins by lower case headers of 1Password 7 and 8 CSV exports
+var onePasswordColumns = map[string]string{
+	"title":             "title",
+	"name":              "title",
+	"url":               "url",
+	"website":           "url",
+	"login url":         "url",
+	"username":          "username",
+	"login username":    "username",
+	"password":          "password",
+	"login password":    "password",
+	"otpauth":           "otp",
+	"one-time password": "otp",
+	"notes":             "notes",
+	"notesplain":        "notes",
+	"tags":              "tags",
+	"archived":          "archived",
+	"favorite":          "favorite",
+}
+
+func parseOnePassword(src io.Reader) (items Items, err error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return items, fmt.Errorf("1Password CSV can not be read: %w", err)
+	}
+	columns := make([]string, len(header))
+	known := false
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := onePasswordColumns[name]; ok {
+			columns[i], known = field, true
+		} else {
+			columns[i] = name
+		}
+	}
+	if !known {
+		return items, errors.New("1Password CSV has no header with Title, Username and Password columns")
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return items, fmt.Errorf("1Password CSV can not be read: %w", err)
+		}
+
+		fields := make(map[string]string, len(record))
+		metadata := make(map[string]string)
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			switch columns[i] {
+			case "title", "username", "password", "otp", "notes", "archived", "favorite":
+				fields[columns[i]] = value
+			default:
+				metadata[columns[i]] = value
+			}
+		}
+		if strings.EqualFold(fields["archived"], "true") {
+			items.Skipped = append(items.Skipped, fmt.Sprintf("%q: archived item on line %d", fields["title"], line))
+			continue
+		}
+		items.addLogin(fields["title"], fields["username"], fields["password"], fields["otp"], fields["notes"], metadata)
+	}
+}
@@ -2,24 +2,37 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/PaBah/GophKeeper/internal/auth"
 	"github.com/PaBah/GophKeeper/internal/config"
 	"github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/logger"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
+// SessionStore - storage of sessions, which records every request made in session
+type SessionStore interface {
+	TouchSession(ctx context.Context, session models.Session) error
+}
+
 type GRPCServerMiddleware struct {
 	secret string
+	// sessions - access token of session which is revoked or expired is rejected, nil disables the check
+	sessions SessionStore
 }
 
 var (
@@ -29,11 +42,12 @@ var (
 	instance *GRPCServerMiddleware
 )
 
-// NewGRPCServerMiddleware initializes a MyMiddleware instance with provided logger and secret.
-func NewGRPCServerMiddleware(secret string) *GRPCServerMiddleware {
+// NewGRPCServerMiddleware initializes a MyMiddleware instance with provided secret and storage of sessions.
+func NewGRPCServerMiddleware(secret string, sessions SessionStore) *GRPCServerMiddleware {
 	once.Do(func() {
 		instance = &GRPCServerMiddleware{
-			secret: secret,
+			secret:   secret,
+			sessions: sessions,
 		}
 	})
 
@@ -47,7 +61,9 @@ func (m GRPCServerMiddleware) AuthInterceptor(ctx context.Context,
 	handler grpc.UnaryHandler) (interface{}, error) {
 	switch info.FullMethod {
 	case proto.GophKeeperService_SignUp_FullMethodName,
-		proto.GophKeeperService_SignIn_FullMethodName:
+		proto.GophKeeperService_SignIn_FullMethodName,
+		proto.GophKeeperService_RefreshToken_FullMethodName,
+		proto.GophKeeperService_VerifyTOTP_FullMethodName:
 		logger.Log().Debug("No protected method", zap.String("method", info.FullMethod))
 
 		return handler(ctx, req)
@@ -96,6 +112,10 @@ func (m GRPCServerMiddleware) AuthInterceptor(ctx context.Context,
 		return nil, status.Errorf(codes.Unauthenticated, "token empty or not valid")
 	}
 
+	if err := m.touchSession(ctx, userID, sessionID); err != nil {
+		return nil, err
+	}
+
 	userCtx := context.WithValue(ctx, config.USERIDCONTEXTKEY, userID)
 	sessionCtx := context.WithValue(userCtx, config.SESSIONIDCONTEXTKEY, sessionID)
 
@@ -148,6 +168,10 @@ func (m GRPCServerMiddleware) StreamAuthInterceptor(srv interface{},
 		return status.Errorf(codes.Unauthenticated, "token empty or not valid")
 	}
 
+	if err := m.touchSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
 	//nolint:staticcheck
 	userCtx := context.WithValue(ctx, config.USERIDCONTEXTKEY, userID)
 	sessionCtx := context.WithValue(userCtx, config.SESSIONIDCONTEXTKEY, sessionID)
@@ -156,3 +180,46 @@ func (m GRPCServerMiddleware) StreamAuthInterceptor(srv interface{},
 
 	return handler(srv, wrappedStream)
 }
+
+// touchSession - record request in session, request of revoked or expired session is rejected
+func (m GRPCServerMiddleware) touchSession(ctx context.Context, userID, sessionID string) error {
+	if m.sessions == nil {
+		return nil
+	}
+
+	session := RequestSession(ctx)
+	session.ID, session.UserID, session.LastSeenAt = sessionID, userID, time.Now()
+	err := m.sessions.TouchSession(ctx, session)
+	if errors.Is(err, storage.ErrNotFound) {
+		logger.Log().Debug("session is revoked or expired", zap.String("session", sessionID))
+
+		return status.Errorf(codes.Unauthenticated, "session is revoked or expired")
+	}
+	if err != nil {
+		logger.Log().Error("session can not be checked", zap.Error(err))
+
+		return status.Errorf(codes.Internal, "session can not be checked")
+	}
+
+	return nil
+}
+
+// RequestSession - device, client version and address of client which sent request
+func RequestSession(ctx context.Context) (session models.Session) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if device := md.Get(string(config.DEVICEHEADER)); len(device) > 0 {
+			session.Device = device[0]
+		}
+		if version := md.Get(string(config.CLIENTVERSIONHEADER)); len(version) > 0 {
+			session.ClientVersion = version[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		session.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(session.IP); err == nil {
+			session.IP = host
+		}
+	}
+
+	return session
+}
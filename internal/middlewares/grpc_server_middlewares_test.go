@@ -3,6 +3,7 @@ package middlewares
 import (
 	"context"
 	"errors"
+	"net"
 	"strings"
 	"testing"
 	"time"
@@ -11,11 +12,14 @@ import (
 	"github.com/PaBah/GophKeeper/internal/config"
 	proto "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
 	"github.com/PaBah/GophKeeper/internal/mock"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/PaBah/GophKeeper/internal/storage"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -40,7 +44,7 @@ func TestNewGRPCServerMiddleware(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_ = NewGRPCServerMiddleware(tt.secret)
+			_ = NewGRPCServerMiddleware(tt.secret, nil)
 		})
 	}
 }
@@ -107,7 +111,7 @@ func TestAuthInterceptor(t *testing.T) {
 	var ErrContext = errors.New("context error")
 	secret := "secret"
 
-	middleware := NewGRPCServerMiddleware(secret)
+	middleware := NewGRPCServerMiddleware(secret, nil)
 
 	userID := "1"
 	userName := "testuser"
@@ -226,7 +230,7 @@ func TestAuthInterceptor(t *testing.T) {
 
 func TestStreamAuthInterceptor(t *testing.T) {
 	secret := "valid_secret"
-	middleware := NewGRPCServerMiddleware("valid_secret")
+	middleware := NewGRPCServerMiddleware("valid_secret", nil)
 	userID := "1"
 	userName := "testuser"
 	validToken, _ := auth.BuildJWTString(userID, userName, secret)
@@ -334,3 +338,52 @@ func TestStreamAuthInterceptor(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthInterceptor_RevokedSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock.NewMockRepository(ctrl)
+	m := GRPCServerMiddleware{secret: "valid_secret", sessions: repo}
+	token, _ := auth.BuildJWTString("user", "session", "valid_secret")
+	md := metadata.Pairs(string(config.AUTHORIZATIONHEADER), token,
+		string(config.DEVICEHEADER), "laptop", string(config.CLIENTVERSIONHEADER), "v1.2.0")
+	ctx := peer.NewContext(metadata.NewIncomingContext(context.Background(), md),
+		&peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	info := &grpc.UnaryServerInfo{FullMethod: proto.GophKeeperService_CreateCard_FullMethodName}
+	handler := func(ctx context.Context, req any) (any, error) { return "testResponse", nil }
+
+	tests := []struct {
+		name     string
+		touchErr error
+		wantCode codes.Code
+	}{
+		{name: "Active Session", wantCode: codes.OK},
+		{name: "Revoked Session", touchErr: storage.ErrNotFound, wantCode: codes.Unauthenticated},
+		{name: "Storage Error", touchErr: errors.New("db error"), wantCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.EXPECT().TouchSession(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, session models.Session) error {
+					require.Equal(t, "session", session.ID)
+					require.Equal(t, "user", session.UserID)
+					require.Equal(t, "v1.2.0", session.ClientVersion)
+					require.Equal(t, "10.0.0.1", session.IP)
+					require.WithinDuration(t, time.Now(), session.LastSeenAt, time.Minute)
+					return tt.touchErr
+				})
+
+			_, err := m.AuthInterceptor(ctx, nil, info, handler)
+			require.Equal(t, tt.wantCode, status.Code(err))
+
+			repo.EXPECT().TouchSession(gomock.Any(), gomock.Any()).Return(tt.touchErr)
+			mockStream := mock.NewMockMockServerStream(ctrl)
+			mockStream.EXPECT().Context().Return(ctx).AnyTimes()
+			err = m.StreamAuthInterceptor(nil, mockStream, &grpc.StreamServerInfo{},
+				func(srv interface{}, stream grpc.ServerStream) error { return nil })
+			require.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
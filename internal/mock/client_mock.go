@@ -7,8 +7,11 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	archive "github.com/PaBah/GophKeeper/internal/archive"
 	pb "github.com/PaBah/GophKeeper/internal/gen/proto/gophkeeper/v1"
+	importer "github.com/PaBah/GophKeeper/internal/importer"
 	models "github.com/PaBah/GophKeeper/internal/models"
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
@@ -88,60 +91,132 @@ func (m *MockGRPCClientProvider) EXPECT() *MockGRPCClientProviderMockRecorder {
 	return m.recorder
 }
 
+// ChangeMasterPassword mocks base method.
+func (m *MockGRPCClientProvider) ChangeMasterPassword(ctx context.Context, oldMasterPassword, newMasterPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMasterPassword", ctx, oldMasterPassword, newMasterPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeMasterPassword indicates an expected call of ChangeMasterPassword.
+func (mr *MockGRPCClientProviderMockRecorder) ChangeMasterPassword(ctx, oldMasterPassword, newMasterPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMasterPassword", reflect.TypeOf((*MockGRPCClientProvider)(nil).ChangeMasterPassword), ctx, oldMasterPassword, newMasterPassword)
+}
+
+// ChangePassword mocks base method.
+func (m *MockGRPCClientProvider) ChangePassword(ctx context.Context, oldPassword, newPassword string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, oldPassword, newPassword)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockGRPCClientProviderMockRecorder) ChangePassword(ctx, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockGRPCClientProvider)(nil).ChangePassword), ctx, oldPassword, newPassword)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockGRPCClientProvider) ConfirmTOTP(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockGRPCClientProviderMockRecorder) ConfirmTOTP(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockGRPCClientProvider)(nil).ConfirmTOTP), ctx, code)
+}
+
 // CreateCard mocks base method.
-func (m *MockGRPCClientProvider) CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string) error {
+func (m *MockGRPCClientProvider) CreateCard(ctx context.Context, number, expirationDate, holderName, cvv string, metadata map[string]string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCard", ctx, number, expirationDate, holderName, cvv)
+	ret := m.ctrl.Call(m, "CreateCard", ctx, number, expirationDate, holderName, cvv, metadata)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateCard indicates an expected call of CreateCard.
-func (mr *MockGRPCClientProviderMockRecorder) CreateCard(ctx, number, expirationDate, holderName, cvv interface{}) *gomock.Call {
+func (mr *MockGRPCClientProviderMockRecorder) CreateCard(ctx, number, expirationDate, holderName, cvv, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCard", reflect.TypeOf((*MockGRPCClientProvider)(nil).CreateCard), ctx, number, expirationDate, holderName, cvv)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCard", reflect.TypeOf((*MockGRPCClientProvider)(nil).CreateCard), ctx, number, expirationDate, holderName, cvv, metadata)
 }
 
 // CreateCredentials mocks base method.
-func (m *MockGRPCClientProvider) CreateCredentials(ctx context.Context, serviceName, identity, password string) error {
+func (m *MockGRPCClientProvider) CreateCredentials(ctx context.Context, serviceName, identity, password, totp string, metadata map[string]string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCredentials", ctx, serviceName, identity, password)
+	ret := m.ctrl.Call(m, "CreateCredentials", ctx, serviceName, identity, password, totp, metadata)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateCredentials indicates an expected call of CreateCredentials.
-func (mr *MockGRPCClientProviderMockRecorder) CreateCredentials(ctx, serviceName, identity, password interface{}) *gomock.Call {
+func (mr *MockGRPCClientProviderMockRecorder) CreateCredentials(ctx, serviceName, identity, password, totp, metadata interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCredentials", reflect.TypeOf((*MockGRPCClientProvider)(nil).CreateCredentials), ctx, serviceName, identity, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCredentials", reflect.TypeOf((*MockGRPCClientProvider)(nil).CreateCredentials), ctx, serviceName, identity, password, totp, metadata)
+}
+
+// CreateNote mocks base method.
+func (m *MockGRPCClientProvider) CreateNote(ctx context.Context, title, body string, metadata map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNote", ctx, title, body, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNote indicates an expected call of CreateNote.
+func (mr *MockGRPCClientProviderMockRecorder) CreateNote(ctx, title, body, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockGRPCClientProvider)(nil).CreateNote), ctx, title, body, metadata)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockGRPCClientProvider) DeleteAccount(ctx context.Context, password string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, password)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockGRPCClientProviderMockRecorder) DeleteAccount(ctx, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteAccount), ctx, password)
 }
 
 // DeleteCard mocks base method.
-func (m *MockGRPCClientProvider) DeleteCard(ctx context.Context, cardID string) error {
+func (m *MockGRPCClientProvider) DeleteCard(arg0 context.Context, arg1 string, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCard", ctx, cardID)
+	ret := m.ctrl.Call(m, "DeleteCard", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCard indicates an expected call of DeleteCard.
-func (mr *MockGRPCClientProviderMockRecorder) DeleteCard(ctx, cardID interface{}) *gomock.Call {
+func (mr *MockGRPCClientProviderMockRecorder) DeleteCard(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteCard), ctx, cardID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteCard), arg0, arg1, arg2)
 }
 
 // DeleteCredentials mocks base method.
-func (m *MockGRPCClientProvider) DeleteCredentials(ctx context.Context, credentialsID string) error {
+func (m *MockGRPCClientProvider) DeleteCredentials(arg0 context.Context, arg1 string, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCredentials", ctx, credentialsID)
+	ret := m.ctrl.Call(m, "DeleteCredentials", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCredentials indicates an expected call of DeleteCredentials.
-func (mr *MockGRPCClientProviderMockRecorder) DeleteCredentials(ctx, credentialsID interface{}) *gomock.Call {
+func (mr *MockGRPCClientProviderMockRecorder) DeleteCredentials(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredentials", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteCredentials), ctx, credentialsID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredentials", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteCredentials), arg0, arg1, arg2)
 }
 
 // DeleteFile mocks base method.
@@ -158,6 +233,34 @@ func (mr *MockGRPCClientProviderMockRecorder) DeleteFile(ctx, name interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteFile), ctx, name)
 }
 
+// DeleteNote mocks base method.
+func (m *MockGRPCClientProvider) DeleteNote(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockGRPCClientProviderMockRecorder) DeleteNote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockGRPCClientProvider)(nil).DeleteNote), arg0, arg1, arg2)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockGRPCClientProvider) DisableTOTP(ctx context.Context, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockGRPCClientProviderMockRecorder) DisableTOTP(ctx, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockGRPCClientProvider)(nil).DisableTOTP), ctx, code)
+}
+
 // DownloadsFile mocks base method.
 func (m *MockGRPCClientProvider) DownloadsFile(ctx context.Context, name string) {
 	m.ctrl.T.Helper()
@@ -170,6 +273,50 @@ func (mr *MockGRPCClientProviderMockRecorder) DownloadsFile(ctx, name interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadsFile", reflect.TypeOf((*MockGRPCClientProvider)(nil).DownloadsFile), ctx, name)
 }
 
+// EmptyTrash mocks base method.
+func (m *MockGRPCClientProvider) EmptyTrash(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockGRPCClientProviderMockRecorder) EmptyTrash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockGRPCClientProvider)(nil).EmptyTrash), arg0)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockGRPCClientProvider) EnrollTOTP(ctx context.Context) (models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", ctx)
+	ret0, _ := ret[0].(models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockGRPCClientProviderMockRecorder) EnrollTOTP(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockGRPCClientProvider)(nil).EnrollTOTP), ctx)
+}
+
+// ExportVault mocks base method.
+func (m *MockGRPCClientProvider) ExportVault(ctx context.Context, path, password string) (archive.Manifest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportVault", ctx, path, password)
+	ret0, _ := ret[0].(archive.Manifest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportVault indicates an expected call of ExportVault.
+func (mr *MockGRPCClientProviderMockRecorder) ExportVault(ctx, path, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportVault", reflect.TypeOf((*MockGRPCClientProvider)(nil).ExportVault), ctx, path, password)
+}
+
 // GetCards mocks base method.
 func (m *MockGRPCClientProvider) GetCards(ctx context.Context) ([]models.Card, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +362,167 @@ func (mr *MockGRPCClientProviderMockRecorder) GetFiles(ctx interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFiles", reflect.TypeOf((*MockGRPCClientProvider)(nil).GetFiles), ctx)
 }
 
+// GetNotes mocks base method.
+func (m *MockGRPCClientProvider) GetNotes(ctx context.Context) ([]models.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotes", ctx)
+	ret0, _ := ret[0].([]models.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotes indicates an expected call of GetNotes.
+func (mr *MockGRPCClientProviderMockRecorder) GetNotes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockGRPCClientProvider)(nil).GetNotes), ctx)
+}
+
+// ImportItems mocks base method.
+func (m *MockGRPCClientProvider) ImportItems(ctx context.Context, items importer.Items, dryRun bool) (models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportItems", ctx, items, dryRun)
+	ret0, _ := ret[0].(models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportItems indicates an expected call of ImportItems.
+func (mr *MockGRPCClientProviderMockRecorder) ImportItems(ctx, items, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportItems", reflect.TypeOf((*MockGRPCClientProvider)(nil).ImportItems), ctx, items, dryRun)
+}
+
+// ImportVault mocks base method.
+func (m *MockGRPCClientProvider) ImportVault(ctx context.Context, path, password string, dryRun bool) (models.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportVault", ctx, path, password, dryRun)
+	ret0, _ := ret[0].(models.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportVault indicates an expected call of ImportVault.
+func (mr *MockGRPCClientProviderMockRecorder) ImportVault(ctx, path, password, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportVault", reflect.TypeOf((*MockGRPCClientProvider)(nil).ImportVault), ctx, path, password, dryRun)
+}
+
+// InitVault mocks base method.
+func (m *MockGRPCClientProvider) InitVault(ctx context.Context, masterPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitVault", ctx, masterPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitVault indicates an expected call of InitVault.
+func (mr *MockGRPCClientProviderMockRecorder) InitVault(ctx, masterPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitVault", reflect.TypeOf((*MockGRPCClientProvider)(nil).InitVault), ctx, masterPassword)
+}
+
+// ListItemHistory mocks base method.
+func (m *MockGRPCClientProvider) ListItemHistory(arg0 context.Context, arg1, arg2 string) ([]models.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItemHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItemHistory indicates an expected call of ListItemHistory.
+func (mr *MockGRPCClientProviderMockRecorder) ListItemHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItemHistory", reflect.TypeOf((*MockGRPCClientProvider)(nil).ListItemHistory), arg0, arg1, arg2)
+}
+
+// ListSessions mocks base method.
+func (m *MockGRPCClientProvider) ListSessions(ctx context.Context) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGRPCClientProviderMockRecorder) ListSessions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGRPCClientProvider)(nil).ListSessions), ctx)
+}
+
+// ListTrash mocks base method.
+func (m *MockGRPCClientProvider) ListTrash(arg0 context.Context) ([]models.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0)
+	ret0, _ := ret[0].([]models.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGRPCClientProviderMockRecorder) ListTrash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGRPCClientProvider)(nil).ListTrash), arg0)
+}
+
+// OpenChangeEvent mocks base method.
+func (m *MockGRPCClientProvider) OpenChangeEvent(arg0 *pb.SubscribeToChangesResponse) (models.ChangeEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenChangeEvent", arg0)
+	ret0, _ := ret[0].(models.ChangeEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenChangeEvent indicates an expected call of OpenChangeEvent.
+func (mr *MockGRPCClientProviderMockRecorder) OpenChangeEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenChangeEvent", reflect.TypeOf((*MockGRPCClientProvider)(nil).OpenChangeEvent), arg0)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockGRPCClientProvider) RestoreFromTrash(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFromTrash", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockGRPCClientProviderMockRecorder) RestoreFromTrash(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockGRPCClientProvider)(nil).RestoreFromTrash), arg0, arg1, arg2)
+}
+
+// RestoreItemRevision mocks base method.
+func (m *MockGRPCClientProvider) RestoreItemRevision(arg0 context.Context, arg1, arg2, arg3 string, arg4 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItemRevision", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreItemRevision indicates an expected call of RestoreItemRevision.
+func (mr *MockGRPCClientProviderMockRecorder) RestoreItemRevision(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItemRevision", reflect.TypeOf((*MockGRPCClientProvider)(nil).RestoreItemRevision), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGRPCClientProvider) RevokeSession(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGRPCClientProviderMockRecorder) RevokeSession(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGRPCClientProvider)(nil).RevokeSession), ctx, id)
+}
+
 // SetSessionID mocks base method.
 func (m *MockGRPCClientProvider) SetSessionID(sessionID string) {
 	m.ctrl.T.Helper()
@@ -255,6 +563,52 @@ func (mr *MockGRPCClientProviderMockRecorder) SignUp(email, password interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockGRPCClientProvider)(nil).SignUp), email, password)
 }
 
+// Sync mocks base method.
+func (m *MockGRPCClientProvider) Sync(arg0 context.Context) (models.ChangeSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0)
+	ret0, _ := ret[0].(models.ChangeSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockGRPCClientProviderMockRecorder) Sync(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGRPCClientProvider)(nil).Sync), arg0)
+}
+
+// SyncStatus mocks base method.
+func (m *MockGRPCClientProvider) SyncStatus() (bool, int, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncStatus")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(int)
+	return ret0, ret1, ret2
+}
+
+// SyncStatus indicates an expected call of SyncStatus.
+func (mr *MockGRPCClientProviderMockRecorder) SyncStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockGRPCClientProvider)(nil).SyncStatus))
+}
+
+// TakeReplayConflict mocks base method.
+func (m *MockGRPCClientProvider) TakeReplayConflict() (*models.ReplayConflict, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeReplayConflict")
+	ret0, _ := ret[0].(*models.ReplayConflict)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeReplayConflict indicates an expected call of TakeReplayConflict.
+func (mr *MockGRPCClientProviderMockRecorder) TakeReplayConflict() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeReplayConflict", reflect.TypeOf((*MockGRPCClientProvider)(nil).TakeReplayConflict))
+}
+
 // TryToConnect mocks base method.
 func (m *MockGRPCClientProvider) TryToConnect() bool {
 	m.ctrl.T.Helper()
@@ -269,6 +623,20 @@ func (mr *MockGRPCClientProviderMockRecorder) TryToConnect() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryToConnect", reflect.TypeOf((*MockGRPCClientProvider)(nil).TryToConnect))
 }
 
+// UnlockVault mocks base method.
+func (m *MockGRPCClientProvider) UnlockVault(ctx context.Context, masterPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockVault", ctx, masterPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockVault indicates an expected call of UnlockVault.
+func (mr *MockGRPCClientProviderMockRecorder) UnlockVault(ctx, masterPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockVault", reflect.TypeOf((*MockGRPCClientProvider)(nil).UnlockVault), ctx, masterPassword)
+}
+
 // UpdateCards mocks base method.
 func (m *MockGRPCClientProvider) UpdateCards(ctx context.Context, card models.Card) (models.Card, error) {
 	m.ctrl.T.Helper()
@@ -300,17 +668,33 @@ func (mr *MockGRPCClientProviderMockRecorder) UpdateCredentials(ctx, credentials
 }
 
 
-// UploadFile mocks base method.
-func (m *MockGRPCClientProvider) UploadFile(ctx context.Context, filePath string) {
+// UpdateFileMetadata mocks base method.
+func (m *MockGRPCClientProvider) UpdateFileMetadata(ctx context.Context, name string, metadata map[string]string) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "UploadFile", ctx, filePath)
+	ret := m.ctrl.Call(m, "UpdateFileMetadata", ctx, name, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
+// UpdateFileMetadata indicates an expected call of UpdateFileMetadata.
+func (mr *MockGRPCClientProviderMockRecorder) UpdateFileMetadata(ctx, name, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadata", reflect.TypeOf((*MockGRPCClientProvider)(nil).UpdateFileMetadata), ctx, name, metadata)
+}
 
-// UploadFile indicates an expected call of UploadFile.
-func (mr *MockGRPCClientProviderMockRecorder) UploadFile(ctx, filePath interface{}) *gomock.Call {
+// UpdateNote mocks base method.
+func (m *MockGRPCClientProvider) UpdateNote(ctx context.Context, note models.Note) (models.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNote", ctx, note)
+	ret0, _ := ret[0].(models.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockGRPCClientProviderMockRecorder) UpdateNote(ctx, note interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockGRPCClientProvider)(nil).UploadFile), ctx, filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockGRPCClientProvider)(nil).UpdateNote), ctx, note)
 }
 
 // SubscribeToChanges mocks base method.
@@ -326,4 +710,29 @@ func (m *MockGRPCClientProvider) SubscribeToChanges(ctx context.Context)  (grpc.
 func (mr *MockGRPCClientProviderMockRecorder) SubscribeToChanges(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToChanges", reflect.TypeOf((*MockGRPCClientProvider)(nil).SubscribeToChanges), ctx)
-}
\ No newline at end of file
+}
+// UploadFile mocks base method.
+func (m *MockGRPCClientProvider) UploadFile(ctx context.Context, filePath string, metadata map[string]string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UploadFile", ctx, filePath, metadata)
+}
+
+// UploadFile indicates an expected call of UploadFile.
+func (mr *MockGRPCClientProviderMockRecorder) UploadFile(ctx, filePath, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockGRPCClientProvider)(nil).UploadFile), ctx, filePath, metadata)
+}
+
+// VerifyTOTP mocks base method.
+func (m *MockGRPCClientProvider) VerifyTOTP(code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTOTP", code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyTOTP indicates an expected call of VerifyTOTP.
+func (mr *MockGRPCClientProviderMockRecorder) VerifyTOTP(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTOTP", reflect.TypeOf((*MockGRPCClientProvider)(nil).VerifyTOTP), code)
+}
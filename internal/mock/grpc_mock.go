@@ -36,6 +36,66 @@ func (m *MockGophKeeperServiceClient) EXPECT() *MockGophKeeperServiceClientMockR
 	return m.recorder
 }
 
+// ChangeMasterPassword mocks base method.
+func (m *MockGophKeeperServiceClient) ChangeMasterPassword(ctx context.Context, in *v1.ChangeMasterPasswordRequest, opts ...grpc.CallOption) (*v1.ChangeMasterPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChangeMasterPassword", varargs...)
+	ret0, _ := ret[0].(*v1.ChangeMasterPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeMasterPassword indicates an expected call of ChangeMasterPassword.
+func (mr *MockGophKeeperServiceClientMockRecorder) ChangeMasterPassword(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMasterPassword", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ChangeMasterPassword), varargs...)
+}
+
+// ChangePassword mocks base method.
+func (m *MockGophKeeperServiceClient) ChangePassword(ctx context.Context, in *v1.ChangePasswordRequest, opts ...grpc.CallOption) (*v1.ChangePasswordResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ChangePassword", varargs...)
+	ret0, _ := ret[0].(*v1.ChangePasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockGophKeeperServiceClientMockRecorder) ChangePassword(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ChangePassword), varargs...)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockGophKeeperServiceClient) ConfirmTOTP(ctx context.Context, in *v1.ConfirmTOTPRequest, opts ...grpc.CallOption) (*v1.ConfirmTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ConfirmTOTP", varargs...)
+	ret0, _ := ret[0].(*v1.ConfirmTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockGophKeeperServiceClientMockRecorder) ConfirmTOTP(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ConfirmTOTP), varargs...)
+}
+
 // CreateCard mocks base method.
 func (m *MockGophKeeperServiceClient) CreateCard(ctx context.Context, in *v1.CreateCardRequest, opts ...grpc.CallOption) (*v1.CreateCardResponse, error) {
 	m.ctrl.T.Helper()
@@ -76,6 +136,46 @@ func (mr *MockGophKeeperServiceClientMockRecorder) CreateCredentials(ctx, in int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCredentials", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).CreateCredentials), varargs...)
 }
 
+// CreateNote mocks base method.
+func (m *MockGophKeeperServiceClient) CreateNote(ctx context.Context, in *v1.CreateNoteRequest, opts ...grpc.CallOption) (*v1.CreateNoteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateNote", varargs...)
+	ret0, _ := ret[0].(*v1.CreateNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNote indicates an expected call of CreateNote.
+func (mr *MockGophKeeperServiceClientMockRecorder) CreateNote(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).CreateNote), varargs...)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockGophKeeperServiceClient) DeleteAccount(ctx context.Context, in *v1.DeleteAccountRequest, opts ...grpc.CallOption) (*v1.DeleteAccountResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteAccount", varargs...)
+	ret0, _ := ret[0].(*v1.DeleteAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockGophKeeperServiceClientMockRecorder) DeleteAccount(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).DeleteAccount), varargs...)
+}
+
 // DeleteCard mocks base method.
 func (m *MockGophKeeperServiceClient) DeleteCard(ctx context.Context, in *v1.DeleteCardRequest, opts ...grpc.CallOption) (*v1.DeleteCardResponse, error) {
 	m.ctrl.T.Helper()
@@ -136,6 +236,46 @@ func (mr *MockGophKeeperServiceClientMockRecorder) DeleteFile(ctx, in interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).DeleteFile), varargs...)
 }
 
+// DeleteNote mocks base method.
+func (m *MockGophKeeperServiceClient) DeleteNote(ctx context.Context, in *v1.DeleteNoteRequest, opts ...grpc.CallOption) (*v1.DeleteNoteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteNote", varargs...)
+	ret0, _ := ret[0].(*v1.DeleteNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockGophKeeperServiceClientMockRecorder) DeleteNote(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).DeleteNote), varargs...)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockGophKeeperServiceClient) DisableTOTP(ctx context.Context, in *v1.DisableTOTPRequest, opts ...grpc.CallOption) (*v1.DisableTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DisableTOTP", varargs...)
+	ret0, _ := ret[0].(*v1.DisableTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockGophKeeperServiceClientMockRecorder) DisableTOTP(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).DisableTOTP), varargs...)
+}
+
 // DownloadFile mocks base method.
 func (m *MockGophKeeperServiceClient) DownloadFile(ctx context.Context, in *v1.DownloadFileRequest, opts ...grpc.CallOption) (v1.GophKeeperService_DownloadFileClient, error) {
 	m.ctrl.T.Helper()
@@ -156,6 +296,66 @@ func (mr *MockGophKeeperServiceClientMockRecorder) DownloadFile(ctx, in interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).DownloadFile), varargs...)
 }
 
+// EmptyTrash mocks base method.
+func (m *MockGophKeeperServiceClient) EmptyTrash(ctx context.Context, in *v1.EmptyTrashRequest, opts ...grpc.CallOption) (*v1.EmptyTrashResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EmptyTrash", varargs...)
+	ret0, _ := ret[0].(*v1.EmptyTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockGophKeeperServiceClientMockRecorder) EmptyTrash(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).EmptyTrash), varargs...)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockGophKeeperServiceClient) EnrollTOTP(ctx context.Context, in *v1.EnrollTOTPRequest, opts ...grpc.CallOption) (*v1.EnrollTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnrollTOTP", varargs...)
+	ret0, _ := ret[0].(*v1.EnrollTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockGophKeeperServiceClientMockRecorder) EnrollTOTP(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).EnrollTOTP), varargs...)
+}
+
+// ExportVault mocks base method.
+func (m *MockGophKeeperServiceClient) ExportVault(ctx context.Context, in *v1.ExportVaultRequest, opts ...grpc.CallOption) (v1.GophKeeperService_ExportVaultClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportVault", varargs...)
+	ret0, _ := ret[0].(v1.GophKeeperService_ExportVaultClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportVault indicates an expected call of ExportVault.
+func (mr *MockGophKeeperServiceClientMockRecorder) ExportVault(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportVault", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ExportVault), varargs...)
+}
+
 // GetCards mocks base method.
 func (m *MockGophKeeperServiceClient) GetCards(ctx context.Context, in *v1.GetCardsRequest, opts ...grpc.CallOption) (*v1.GetCardsResponse, error) {
 	m.ctrl.T.Helper()
@@ -216,6 +416,246 @@ func (mr *MockGophKeeperServiceClientMockRecorder) GetFiles(ctx, in interface{},
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFiles", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).GetFiles), varargs...)
 }
 
+// GetNotes mocks base method.
+func (m *MockGophKeeperServiceClient) GetNotes(ctx context.Context, in *v1.GetNotesRequest, opts ...grpc.CallOption) (*v1.GetNotesResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetNotes", varargs...)
+	ret0, _ := ret[0].(*v1.GetNotesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotes indicates an expected call of GetNotes.
+func (mr *MockGophKeeperServiceClientMockRecorder) GetNotes(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).GetNotes), varargs...)
+}
+
+// GetVaultParams mocks base method.
+func (m *MockGophKeeperServiceClient) GetVaultParams(ctx context.Context, in *v1.GetVaultParamsRequest, opts ...grpc.CallOption) (*v1.GetVaultParamsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetVaultParams", varargs...)
+	ret0, _ := ret[0].(*v1.GetVaultParamsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultParams indicates an expected call of GetVaultParams.
+func (mr *MockGophKeeperServiceClientMockRecorder) GetVaultParams(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultParams", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).GetVaultParams), varargs...)
+}
+
+// ImportVault mocks base method.
+func (m *MockGophKeeperServiceClient) ImportVault(ctx context.Context, opts ...grpc.CallOption) (v1.GophKeeperService_ImportVaultClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ImportVault", varargs...)
+	ret0, _ := ret[0].(v1.GophKeeperService_ImportVaultClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportVault indicates an expected call of ImportVault.
+func (mr *MockGophKeeperServiceClientMockRecorder) ImportVault(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportVault", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ImportVault), varargs...)
+}
+
+// InitVault mocks base method.
+func (m *MockGophKeeperServiceClient) InitVault(ctx context.Context, in *v1.InitVaultRequest, opts ...grpc.CallOption) (*v1.InitVaultResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "InitVault", varargs...)
+	ret0, _ := ret[0].(*v1.InitVaultResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitVault indicates an expected call of InitVault.
+func (mr *MockGophKeeperServiceClientMockRecorder) InitVault(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitVault", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).InitVault), varargs...)
+}
+
+// ListItemHistory mocks base method.
+func (m *MockGophKeeperServiceClient) ListItemHistory(ctx context.Context, in *v1.ListItemHistoryRequest, opts ...grpc.CallOption) (*v1.ListItemHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListItemHistory", varargs...)
+	ret0, _ := ret[0].(*v1.ListItemHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItemHistory indicates an expected call of ListItemHistory.
+func (mr *MockGophKeeperServiceClientMockRecorder) ListItemHistory(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItemHistory", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ListItemHistory), varargs...)
+}
+
+// ListSessions mocks base method.
+func (m *MockGophKeeperServiceClient) ListSessions(ctx context.Context, in *v1.ListSessionsRequest, opts ...grpc.CallOption) (*v1.ListSessionsResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListSessions", varargs...)
+	ret0, _ := ret[0].(*v1.ListSessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGophKeeperServiceClientMockRecorder) ListSessions(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ListSessions), varargs...)
+}
+
+// ListTrash mocks base method.
+func (m *MockGophKeeperServiceClient) ListTrash(ctx context.Context, in *v1.ListTrashRequest, opts ...grpc.CallOption) (*v1.ListTrashResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListTrash", varargs...)
+	ret0, _ := ret[0].(*v1.ListTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGophKeeperServiceClientMockRecorder) ListTrash(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ListTrash), varargs...)
+}
+
+// RefreshToken mocks base method.
+func (m *MockGophKeeperServiceClient) RefreshToken(ctx context.Context, in *v1.RefreshTokenRequest, opts ...grpc.CallOption) (*v1.RefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RefreshToken", varargs...)
+	ret0, _ := ret[0].(*v1.RefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockGophKeeperServiceClientMockRecorder) RefreshToken(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).RefreshToken), varargs...)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockGophKeeperServiceClient) RestoreFromTrash(ctx context.Context, in *v1.RestoreFromTrashRequest, opts ...grpc.CallOption) (*v1.RestoreFromTrashResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreFromTrash", varargs...)
+	ret0, _ := ret[0].(*v1.RestoreFromTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockGophKeeperServiceClientMockRecorder) RestoreFromTrash(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).RestoreFromTrash), varargs...)
+}
+
+// RestoreItemRevision mocks base method.
+func (m *MockGophKeeperServiceClient) RestoreItemRevision(ctx context.Context, in *v1.RestoreItemRevisionRequest, opts ...grpc.CallOption) (*v1.RestoreItemRevisionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RestoreItemRevision", varargs...)
+	ret0, _ := ret[0].(*v1.RestoreItemRevisionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreItemRevision indicates an expected call of RestoreItemRevision.
+func (mr *MockGophKeeperServiceClientMockRecorder) RestoreItemRevision(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItemRevision", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).RestoreItemRevision), varargs...)
+}
+
+// ResumeUpload mocks base method.
+func (m *MockGophKeeperServiceClient) ResumeUpload(ctx context.Context, in *v1.ResumeUploadRequest, opts ...grpc.CallOption) (*v1.ResumeUploadResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResumeUpload", varargs...)
+	ret0, _ := ret[0].(*v1.ResumeUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeUpload indicates an expected call of ResumeUpload.
+func (mr *MockGophKeeperServiceClientMockRecorder) ResumeUpload(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeUpload", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).ResumeUpload), varargs...)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGophKeeperServiceClient) RevokeSession(ctx context.Context, in *v1.RevokeSessionRequest, opts ...grpc.CallOption) (*v1.RevokeSessionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevokeSession", varargs...)
+	ret0, _ := ret[0].(*v1.RevokeSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGophKeeperServiceClientMockRecorder) RevokeSession(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).RevokeSession), varargs...)
+}
+
 // SignIn mocks base method.
 func (m *MockGophKeeperServiceClient) SignIn(ctx context.Context, in *v1.SignInRequest, opts ...grpc.CallOption) (*v1.SignInResponse, error) {
 	m.ctrl.T.Helper()
@@ -253,27 +693,47 @@ func (m *MockGophKeeperServiceClient) SignUp(ctx context.Context, in *v1.SignUpR
 func (mr *MockGophKeeperServiceClientMockRecorder) SignUp(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{ctx, in}, opts...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).SignUp), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).SignUp), varargs...)
+}
+
+// SubscribeToChanges mocks base method.
+func (m *MockGophKeeperServiceClient) SubscribeToChanges(ctx context.Context, in *v1.SubscribeToChangesRequest, opts ...grpc.CallOption) (v1.GophKeeperService_SubscribeToChangesClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SubscribeToChanges", varargs...)
+	ret0, _ := ret[0].(v1.GophKeeperService_SubscribeToChangesClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeToChanges indicates an expected call of SubscribeToChanges.
+func (mr *MockGophKeeperServiceClientMockRecorder) SubscribeToChanges(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToChanges", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).SubscribeToChanges), varargs...)
 }
 
-// SubscribeToChanges mocks base method.
-func (m *MockGophKeeperServiceClient) SubscribeToChanges(ctx context.Context, in *v1.SubscribeToChangesRequest, opts ...grpc.CallOption) (v1.GophKeeperService_SubscribeToChangesClient, error) {
+// Sync mocks base method.
+func (m *MockGophKeeperServiceClient) Sync(ctx context.Context, in *v1.SyncRequest, opts ...grpc.CallOption) (*v1.SyncResponse, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx, in}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "SubscribeToChanges", varargs...)
-	ret0, _ := ret[0].(v1.GophKeeperService_SubscribeToChangesClient)
+	ret := m.ctrl.Call(m, "Sync", varargs...)
+	ret0, _ := ret[0].(*v1.SyncResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SubscribeToChanges indicates an expected call of SubscribeToChanges.
-func (mr *MockGophKeeperServiceClientMockRecorder) SubscribeToChanges(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+// Sync indicates an expected call of Sync.
+func (mr *MockGophKeeperServiceClientMockRecorder) Sync(ctx, in interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{ctx, in}, opts...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToChanges", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).SubscribeToChanges), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).Sync), varargs...)
 }
 
 // UpdateCard mocks base method.
@@ -316,6 +776,46 @@ func (mr *MockGophKeeperServiceClientMockRecorder) UpdateCredentials(ctx, in int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCredentials", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).UpdateCredentials), varargs...)
 }
 
+// UpdateFileMetadata mocks base method.
+func (m *MockGophKeeperServiceClient) UpdateFileMetadata(ctx context.Context, in *v1.UpdateFileMetadataRequest, opts ...grpc.CallOption) (*v1.UpdateFileMetadataResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateFileMetadata", varargs...)
+	ret0, _ := ret[0].(*v1.UpdateFileMetadataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFileMetadata indicates an expected call of UpdateFileMetadata.
+func (mr *MockGophKeeperServiceClientMockRecorder) UpdateFileMetadata(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadata", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).UpdateFileMetadata), varargs...)
+}
+
+// UpdateNote mocks base method.
+func (m *MockGophKeeperServiceClient) UpdateNote(ctx context.Context, in *v1.UpdateNoteRequest, opts ...grpc.CallOption) (*v1.UpdateNoteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateNote", varargs...)
+	ret0, _ := ret[0].(*v1.UpdateNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockGophKeeperServiceClientMockRecorder) UpdateNote(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).UpdateNote), varargs...)
+}
+
 // UploadFile mocks base method.
 func (m *MockGophKeeperServiceClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (v1.GophKeeperService_UploadFileClient, error) {
 	m.ctrl.T.Helper()
@@ -336,6 +836,26 @@ func (mr *MockGophKeeperServiceClientMockRecorder) UploadFile(ctx interface{}, o
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).UploadFile), varargs...)
 }
 
+// VerifyTOTP mocks base method.
+func (m *MockGophKeeperServiceClient) VerifyTOTP(ctx context.Context, in *v1.VerifyTOTPRequest, opts ...grpc.CallOption) (*v1.VerifyTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, in}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifyTOTP", varargs...)
+	ret0, _ := ret[0].(*v1.VerifyTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTOTP indicates an expected call of VerifyTOTP.
+func (mr *MockGophKeeperServiceClientMockRecorder) VerifyTOTP(ctx, in interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, in}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTOTP", reflect.TypeOf((*MockGophKeeperServiceClient)(nil).VerifyTOTP), varargs...)
+}
+
 // MockGophKeeperServiceServer is a mock of GophKeeperServiceServer interface.
 type MockGophKeeperServiceServer struct {
 	ctrl     *gomock.Controller
@@ -359,6 +879,51 @@ func (m *MockGophKeeperServiceServer) EXPECT() *MockGophKeeperServiceServerMockR
 	return m.recorder
 }
 
+// ChangeMasterPassword mocks base method.
+func (m *MockGophKeeperServiceServer) ChangeMasterPassword(arg0 context.Context, arg1 *v1.ChangeMasterPasswordRequest) (*v1.ChangeMasterPasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMasterPassword", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ChangeMasterPasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeMasterPassword indicates an expected call of ChangeMasterPassword.
+func (mr *MockGophKeeperServiceServerMockRecorder) ChangeMasterPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMasterPassword", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ChangeMasterPassword), arg0, arg1)
+}
+
+// ChangePassword mocks base method.
+func (m *MockGophKeeperServiceServer) ChangePassword(arg0 context.Context, arg1 *v1.ChangePasswordRequest) (*v1.ChangePasswordResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ChangePasswordResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockGophKeeperServiceServerMockRecorder) ChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ChangePassword), arg0, arg1)
+}
+
+// ConfirmTOTP mocks base method.
+func (m *MockGophKeeperServiceServer) ConfirmTOTP(arg0 context.Context, arg1 *v1.ConfirmTOTPRequest) (*v1.ConfirmTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTP", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ConfirmTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTOTP indicates an expected call of ConfirmTOTP.
+func (mr *MockGophKeeperServiceServerMockRecorder) ConfirmTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTP", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ConfirmTOTP), arg0, arg1)
+}
+
 // CreateCard mocks base method.
 func (m *MockGophKeeperServiceServer) CreateCard(arg0 context.Context, arg1 *v1.CreateCardRequest) (*v1.CreateCardResponse, error) {
 	m.ctrl.T.Helper()
@@ -389,6 +954,36 @@ func (mr *MockGophKeeperServiceServerMockRecorder) CreateCredentials(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCredentials", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).CreateCredentials), arg0, arg1)
 }
 
+// CreateNote mocks base method.
+func (m *MockGophKeeperServiceServer) CreateNote(arg0 context.Context, arg1 *v1.CreateNoteRequest) (*v1.CreateNoteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNote", arg0, arg1)
+	ret0, _ := ret[0].(*v1.CreateNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNote indicates an expected call of CreateNote.
+func (mr *MockGophKeeperServiceServerMockRecorder) CreateNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).CreateNote), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockGophKeeperServiceServer) DeleteAccount(arg0 context.Context, arg1 *v1.DeleteAccountRequest) (*v1.DeleteAccountResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(*v1.DeleteAccountResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockGophKeeperServiceServerMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).DeleteAccount), arg0, arg1)
+}
+
 // DeleteCard mocks base method.
 func (m *MockGophKeeperServiceServer) DeleteCard(arg0 context.Context, arg1 *v1.DeleteCardRequest) (*v1.DeleteCardResponse, error) {
 	m.ctrl.T.Helper()
@@ -434,6 +1029,36 @@ func (mr *MockGophKeeperServiceServerMockRecorder) DeleteFile(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).DeleteFile), arg0, arg1)
 }
 
+// DeleteNote mocks base method.
+func (m *MockGophKeeperServiceServer) DeleteNote(arg0 context.Context, arg1 *v1.DeleteNoteRequest) (*v1.DeleteNoteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNote", arg0, arg1)
+	ret0, _ := ret[0].(*v1.DeleteNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockGophKeeperServiceServerMockRecorder) DeleteNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).DeleteNote), arg0, arg1)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockGophKeeperServiceServer) DisableTOTP(arg0 context.Context, arg1 *v1.DisableTOTPRequest) (*v1.DisableTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", arg0, arg1)
+	ret0, _ := ret[0].(*v1.DisableTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockGophKeeperServiceServerMockRecorder) DisableTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).DisableTOTP), arg0, arg1)
+}
+
 // DownloadFile mocks base method.
 func (m *MockGophKeeperServiceServer) DownloadFile(arg0 *v1.DownloadFileRequest, arg1 v1.GophKeeperService_DownloadFileServer) error {
 	m.ctrl.T.Helper()
@@ -448,6 +1073,50 @@ func (mr *MockGophKeeperServiceServerMockRecorder) DownloadFile(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadFile", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).DownloadFile), arg0, arg1)
 }
 
+// EmptyTrash mocks base method.
+func (m *MockGophKeeperServiceServer) EmptyTrash(arg0 context.Context, arg1 *v1.EmptyTrashRequest) (*v1.EmptyTrashResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", arg0, arg1)
+	ret0, _ := ret[0].(*v1.EmptyTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockGophKeeperServiceServerMockRecorder) EmptyTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).EmptyTrash), arg0, arg1)
+}
+
+// EnrollTOTP mocks base method.
+func (m *MockGophKeeperServiceServer) EnrollTOTP(arg0 context.Context, arg1 *v1.EnrollTOTPRequest) (*v1.EnrollTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollTOTP", arg0, arg1)
+	ret0, _ := ret[0].(*v1.EnrollTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollTOTP indicates an expected call of EnrollTOTP.
+func (mr *MockGophKeeperServiceServerMockRecorder) EnrollTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollTOTP", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).EnrollTOTP), arg0, arg1)
+}
+
+// ExportVault mocks base method.
+func (m *MockGophKeeperServiceServer) ExportVault(arg0 *v1.ExportVaultRequest, arg1 v1.GophKeeperService_ExportVaultServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportVault", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportVault indicates an expected call of ExportVault.
+func (mr *MockGophKeeperServiceServerMockRecorder) ExportVault(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportVault", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ExportVault), arg0, arg1)
+}
+
 // GetCards mocks base method.
 func (m *MockGophKeeperServiceServer) GetCards(arg0 context.Context, arg1 *v1.GetCardsRequest) (*v1.GetCardsResponse, error) {
 	m.ctrl.T.Helper()
@@ -493,6 +1162,185 @@ func (mr *MockGophKeeperServiceServerMockRecorder) GetFiles(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFiles", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).GetFiles), arg0, arg1)
 }
 
+// GetNotes mocks base method.
+func (m *MockGophKeeperServiceServer) GetNotes(arg0 context.Context, arg1 *v1.GetNotesRequest) (*v1.GetNotesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotes", arg0, arg1)
+	ret0, _ := ret[0].(*v1.GetNotesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotes indicates an expected call of GetNotes.
+func (mr *MockGophKeeperServiceServerMockRecorder) GetNotes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).GetNotes), arg0, arg1)
+}
+
+// GetVaultParams mocks base method.
+func (m *MockGophKeeperServiceServer) GetVaultParams(arg0 context.Context, arg1 *v1.GetVaultParamsRequest) (*v1.GetVaultParamsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultParams", arg0, arg1)
+	ret0, _ := ret[0].(*v1.GetVaultParamsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultParams indicates an expected call of GetVaultParams.
+func (mr *MockGophKeeperServiceServerMockRecorder) GetVaultParams(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultParams", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).GetVaultParams), arg0, arg1)
+}
+
+// ImportVault mocks base method.
+func (m *MockGophKeeperServiceServer) ImportVault(arg0 v1.GophKeeperService_ImportVaultServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportVault", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportVault indicates an expected call of ImportVault.
+func (mr *MockGophKeeperServiceServerMockRecorder) ImportVault(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportVault", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ImportVault), arg0)
+}
+
+// InitVault mocks base method.
+func (m *MockGophKeeperServiceServer) InitVault(arg0 context.Context, arg1 *v1.InitVaultRequest) (*v1.InitVaultResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitVault", arg0, arg1)
+	ret0, _ := ret[0].(*v1.InitVaultResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InitVault indicates an expected call of InitVault.
+func (mr *MockGophKeeperServiceServerMockRecorder) InitVault(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitVault", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).InitVault), arg0, arg1)
+}
+
+// ListItemHistory mocks base method.
+func (m *MockGophKeeperServiceServer) ListItemHistory(arg0 context.Context, arg1 *v1.ListItemHistoryRequest) (*v1.ListItemHistoryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItemHistory", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ListItemHistoryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItemHistory indicates an expected call of ListItemHistory.
+func (mr *MockGophKeeperServiceServerMockRecorder) ListItemHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItemHistory", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ListItemHistory), arg0, arg1)
+}
+
+// ListSessions mocks base method.
+func (m *MockGophKeeperServiceServer) ListSessions(arg0 context.Context, arg1 *v1.ListSessionsRequest) (*v1.ListSessionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ListSessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockGophKeeperServiceServerMockRecorder) ListSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ListSessions), arg0, arg1)
+}
+
+// ListTrash mocks base method.
+func (m *MockGophKeeperServiceServer) ListTrash(arg0 context.Context, arg1 *v1.ListTrashRequest) (*v1.ListTrashResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ListTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockGophKeeperServiceServerMockRecorder) ListTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ListTrash), arg0, arg1)
+}
+
+// RefreshToken mocks base method.
+func (m *MockGophKeeperServiceServer) RefreshToken(arg0 context.Context, arg1 *v1.RefreshTokenRequest) (*v1.RefreshTokenResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(*v1.RefreshTokenResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockGophKeeperServiceServerMockRecorder) RefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).RefreshToken), arg0, arg1)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockGophKeeperServiceServer) RestoreFromTrash(arg0 context.Context, arg1 *v1.RestoreFromTrashRequest) (*v1.RestoreFromTrashResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFromTrash", arg0, arg1)
+	ret0, _ := ret[0].(*v1.RestoreFromTrashResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockGophKeeperServiceServerMockRecorder) RestoreFromTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).RestoreFromTrash), arg0, arg1)
+}
+
+// RestoreItemRevision mocks base method.
+func (m *MockGophKeeperServiceServer) RestoreItemRevision(arg0 context.Context, arg1 *v1.RestoreItemRevisionRequest) (*v1.RestoreItemRevisionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItemRevision", arg0, arg1)
+	ret0, _ := ret[0].(*v1.RestoreItemRevisionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreItemRevision indicates an expected call of RestoreItemRevision.
+func (mr *MockGophKeeperServiceServerMockRecorder) RestoreItemRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItemRevision", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).RestoreItemRevision), arg0, arg1)
+}
+
+// ResumeUpload mocks base method.
+func (m *MockGophKeeperServiceServer) ResumeUpload(arg0 context.Context, arg1 *v1.ResumeUploadRequest) (*v1.ResumeUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeUpload", arg0, arg1)
+	ret0, _ := ret[0].(*v1.ResumeUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResumeUpload indicates an expected call of ResumeUpload.
+func (mr *MockGophKeeperServiceServerMockRecorder) ResumeUpload(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeUpload", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).ResumeUpload), arg0, arg1)
+}
+
+// RevokeSession mocks base method.
+func (m *MockGophKeeperServiceServer) RevokeSession(arg0 context.Context, arg1 *v1.RevokeSessionRequest) (*v1.RevokeSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(*v1.RevokeSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockGophKeeperServiceServerMockRecorder) RevokeSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).RevokeSession), arg0, arg1)
+}
+
 // SignIn mocks base method.
 func (m *MockGophKeeperServiceServer) SignIn(arg0 context.Context, arg1 *v1.SignInRequest) (*v1.SignInResponse, error) {
 	m.ctrl.T.Helper()
@@ -537,6 +1385,21 @@ func (mr *MockGophKeeperServiceServerMockRecorder) SubscribeToChanges(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToChanges", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).SubscribeToChanges), arg0, arg1)
 }
 
+// Sync mocks base method.
+func (m *MockGophKeeperServiceServer) Sync(arg0 context.Context, arg1 *v1.SyncRequest) (*v1.SyncResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1)
+	ret0, _ := ret[0].(*v1.SyncResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockGophKeeperServiceServerMockRecorder) Sync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).Sync), arg0, arg1)
+}
+
 // UpdateCard mocks base method.
 func (m *MockGophKeeperServiceServer) UpdateCard(arg0 context.Context, arg1 *v1.UpdateCardRequest) (*v1.UpdateCardResponse, error) {
 	m.ctrl.T.Helper()
@@ -567,6 +1430,36 @@ func (mr *MockGophKeeperServiceServerMockRecorder) UpdateCredentials(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCredentials", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).UpdateCredentials), arg0, arg1)
 }
 
+// UpdateFileMetadata mocks base method.
+func (m *MockGophKeeperServiceServer) UpdateFileMetadata(arg0 context.Context, arg1 *v1.UpdateFileMetadataRequest) (*v1.UpdateFileMetadataResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileMetadata", arg0, arg1)
+	ret0, _ := ret[0].(*v1.UpdateFileMetadataResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFileMetadata indicates an expected call of UpdateFileMetadata.
+func (mr *MockGophKeeperServiceServerMockRecorder) UpdateFileMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileMetadata", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).UpdateFileMetadata), arg0, arg1)
+}
+
+// UpdateNote mocks base method.
+func (m *MockGophKeeperServiceServer) UpdateNote(arg0 context.Context, arg1 *v1.UpdateNoteRequest) (*v1.UpdateNoteResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNote", arg0, arg1)
+	ret0, _ := ret[0].(*v1.UpdateNoteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockGophKeeperServiceServerMockRecorder) UpdateNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).UpdateNote), arg0, arg1)
+}
+
 // UploadFile mocks base method.
 func (m *MockGophKeeperServiceServer) UploadFile(arg0 v1.GophKeeperService_UploadFileServer) error {
 	m.ctrl.T.Helper()
@@ -581,6 +1474,21 @@ func (mr *MockGophKeeperServiceServerMockRecorder) UploadFile(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadFile", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).UploadFile), arg0)
 }
 
+// VerifyTOTP mocks base method.
+func (m *MockGophKeeperServiceServer) VerifyTOTP(arg0 context.Context, arg1 *v1.VerifyTOTPRequest) (*v1.VerifyTOTPResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyTOTP", arg0, arg1)
+	ret0, _ := ret[0].(*v1.VerifyTOTPResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyTOTP indicates an expected call of VerifyTOTP.
+func (mr *MockGophKeeperServiceServerMockRecorder) VerifyTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyTOTP", reflect.TypeOf((*MockGophKeeperServiceServer)(nil).VerifyTOTP), arg0, arg1)
+}
+
 // mustEmbedUnimplementedGophKeeperServiceServer mocks base method.
 func (m *MockGophKeeperServiceServer) mustEmbedUnimplementedGophKeeperServiceServer() {
 	m.ctrl.T.Helper()
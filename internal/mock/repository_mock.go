@@ -7,6 +7,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/PaBah/GophKeeper/internal/models"
 	"go.uber.org/mock/gomock"
@@ -50,6 +51,35 @@ func (mr *MockRepositoryMockRecorder) AuthorizeUser(ctx, email interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeUser", reflect.TypeOf((*MockRepository)(nil).AuthorizeUser), ctx, email)
 }
 
+// ChangeVaultParams mocks base method.
+func (m *MockRepository) ChangeVaultParams(ctx context.Context, oldKeyCheck string, params models.VaultParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeVaultParams", ctx, oldKeyCheck, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeVaultParams indicates an expected call of ChangeVaultParams.
+func (mr *MockRepositoryMockRecorder) ChangeVaultParams(ctx, oldKeyCheck, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeVaultParams", reflect.TypeOf((*MockRepository)(nil).ChangeVaultParams), ctx, oldKeyCheck, params)
+}
+
+// ChangePassword mocks base method.
+func (m *MockRepository) ChangePassword(ctx context.Context, passwordHash, keepSessionID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, passwordHash, keepSessionID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockRepositoryMockRecorder) ChangePassword(ctx, passwordHash, keepSessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockRepository)(nil).ChangePassword), ctx, passwordHash, keepSessionID)
+}
+
 // CreateCard mocks base method.
 func (m *MockRepository) CreateCard(ctx context.Context, card models.Card) (models.Card, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +110,35 @@ func (mr *MockRepositoryMockRecorder) CreateCredentials(ctx, credentials interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCredentials", reflect.TypeOf((*MockRepository)(nil).CreateCredentials), ctx, credentials)
 }
 
+// CreateNote mocks base method.
+func (m *MockRepository) CreateNote(ctx context.Context, note models.Note) (models.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNote", ctx, note)
+	ret0, _ := ret[0].(models.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNote indicates an expected call of CreateNote.
+func (mr *MockRepositoryMockRecorder) CreateNote(ctx, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockRepository)(nil).CreateNote), ctx, note)
+}
+
+// CreateSession mocks base method.
+func (m *MockRepository) CreateSession(ctx context.Context, session models.Session, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockRepositoryMockRecorder) CreateSession(ctx, session, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockRepository)(nil).CreateSession), ctx, session, tokenHash)
+}
+
 // CreateUser mocks base method.
 func (m *MockRepository) CreateUser(ctx context.Context, user models.User) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -95,32 +154,103 @@ func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, user)
 }
 
+// DeleteAccount mocks base method.
+func (m *MockRepository) DeleteAccount(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockRepositoryMockRecorder) DeleteAccount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockRepository)(nil).DeleteAccount), ctx)
+}
+
 // DeleteCard mocks base method.
-func (m *MockRepository) DeleteCard(ctx context.Context, cardID string) error {
+func (m *MockRepository) DeleteCard(arg0 context.Context, arg1 string, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCard", ctx, cardID)
+	ret := m.ctrl.Call(m, "DeleteCard", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCard indicates an expected call of DeleteCard.
-func (mr *MockRepositoryMockRecorder) DeleteCard(ctx, cardID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) DeleteCard(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockRepository)(nil).DeleteCard), ctx, cardID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCard", reflect.TypeOf((*MockRepository)(nil).DeleteCard), arg0, arg1, arg2)
 }
 
 // DeleteCredentials mocks base method.
-func (m *MockRepository) DeleteCredentials(ctx context.Context, credentialsID string) error {
+func (m *MockRepository) DeleteCredentials(arg0 context.Context, arg1 string, arg2 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCredentials", ctx, credentialsID)
+	ret := m.ctrl.Call(m, "DeleteCredentials", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCredentials indicates an expected call of DeleteCredentials.
-func (mr *MockRepositoryMockRecorder) DeleteCredentials(ctx, credentialsID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) DeleteCredentials(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredentials", reflect.TypeOf((*MockRepository)(nil).DeleteCredentials), ctx, credentialsID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredentials", reflect.TypeOf((*MockRepository)(nil).DeleteCredentials), arg0, arg1, arg2)
+}
+
+// DeleteNote mocks base method.
+func (m *MockRepository) DeleteNote(arg0 context.Context, arg1 string, arg2 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNote", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockRepositoryMockRecorder) DeleteNote(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockRepository)(nil).DeleteNote), arg0, arg1, arg2)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockRepository) DisableTOTP(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockRepositoryMockRecorder) DisableTOTP(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockRepository)(nil).DisableTOTP), ctx)
+}
+
+// EmptyTrash mocks base method.
+func (m *MockRepository) EmptyTrash(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockRepositoryMockRecorder) EmptyTrash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockRepository)(nil).EmptyTrash), arg0)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockRepository) EnableTOTP(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockRepositoryMockRecorder) EnableTOTP(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockRepository)(nil).EnableTOTP), ctx)
 }
 
 // GetCards mocks base method.
@@ -153,6 +283,328 @@ func (mr *MockRepositoryMockRecorder) GetCredentials(ctx interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredentials", reflect.TypeOf((*MockRepository)(nil).GetCredentials), ctx)
 }
 
+// GetNotes mocks base method.
+func (m *MockRepository) GetNotes(ctx context.Context) ([]models.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotes", ctx)
+	ret0, _ := ret[0].([]models.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotes indicates an expected call of GetNotes.
+func (mr *MockRepositoryMockRecorder) GetNotes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockRepository)(nil).GetNotes), ctx)
+}
+
+// GetSignInAttempts mocks base method.
+func (m *MockRepository) GetSignInAttempts(ctx context.Context, key string) (models.SignInAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSignInAttempts", ctx, key)
+	ret0, _ := ret[0].(models.SignInAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSignInAttempts indicates an expected call of GetSignInAttempts.
+func (mr *MockRepositoryMockRecorder) GetSignInAttempts(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSignInAttempts", reflect.TypeOf((*MockRepository)(nil).GetSignInAttempts), ctx, key)
+}
+
+// GetTOTP mocks base method.
+func (m *MockRepository) GetTOTP(ctx context.Context) (models.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx)
+	ret0, _ := ret[0].(models.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockRepositoryMockRecorder) GetTOTP(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockRepository)(nil).GetTOTP), ctx)
+}
+
+// GetUser mocks base method.
+func (m *MockRepository) GetUser(ctx context.Context) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockRepositoryMockRecorder) GetUser(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx)
+}
+
+// GetVaultParams mocks base method.
+func (m *MockRepository) GetVaultParams(ctx context.Context) (models.VaultParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVaultParams", ctx)
+	ret0, _ := ret[0].(models.VaultParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVaultParams indicates an expected call of GetVaultParams.
+func (mr *MockRepositoryMockRecorder) GetVaultParams(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVaultParams", reflect.TypeOf((*MockRepository)(nil).GetVaultParams), ctx)
+}
+
+// ListItemHistory mocks base method.
+func (m *MockRepository) ListItemHistory(arg0 context.Context, arg1, arg2 string) ([]models.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItemHistory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItemHistory indicates an expected call of ListItemHistory.
+func (mr *MockRepositoryMockRecorder) ListItemHistory(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItemHistory", reflect.TypeOf((*MockRepository)(nil).ListItemHistory), arg0, arg1, arg2)
+}
+
+// ListSessions mocks base method.
+func (m *MockRepository) ListSessions(ctx context.Context) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockRepositoryMockRecorder) ListSessions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockRepository)(nil).ListSessions), ctx)
+}
+
+// ListTrash mocks base method.
+func (m *MockRepository) ListTrash(arg0 context.Context) ([]models.TrashItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", arg0)
+	ret0, _ := ret[0].([]models.TrashItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockRepositoryMockRecorder) ListTrash(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockRepository)(nil).ListTrash), arg0)
+}
+
+// LockSignIn mocks base method.
+func (m *MockRepository) LockSignIn(ctx context.Context, key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockSignIn", ctx, key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockSignIn indicates an expected call of LockSignIn.
+func (mr *MockRepositoryMockRecorder) LockSignIn(ctx, key, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockSignIn", reflect.TypeOf((*MockRepository)(nil).LockSignIn), ctx, key, until)
+}
+
+// PurgeSignInAttempts mocks base method.
+func (m *MockRepository) PurgeSignInAttempts(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSignInAttempts", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeSignInAttempts indicates an expected call of PurgeSignInAttempts.
+func (mr *MockRepositoryMockRecorder) PurgeSignInAttempts(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSignInAttempts", reflect.TypeOf((*MockRepository)(nil).PurgeSignInAttempts), ctx, before)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockRepository) PurgeTrash(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockRepositoryMockRecorder) PurgeTrash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockRepository)(nil).PurgeTrash), arg0, arg1)
+}
+
+// RecordChange mocks base method.
+func (m *MockRepository) RecordChange(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChange indicates an expected call of RecordChange.
+func (mr *MockRepositoryMockRecorder) RecordChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChange", reflect.TypeOf((*MockRepository)(nil).RecordChange), arg0, arg1, arg2)
+}
+
+// RecordSignInFailure mocks base method.
+func (m *MockRepository) RecordSignInFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (models.SignInAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSignInFailure", ctx, key, failedAt, resetBefore)
+	ret0, _ := ret[0].(models.SignInAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordSignInFailure indicates an expected call of RecordSignInFailure.
+func (mr *MockRepositoryMockRecorder) RecordSignInFailure(ctx, key, failedAt, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSignInFailure", reflect.TypeOf((*MockRepository)(nil).RecordSignInFailure), ctx, key, failedAt, resetBefore)
+}
+
+// RefreshSession mocks base method.
+func (m *MockRepository) RefreshSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", ctx, tokenHash, newTokenHash, expiresAt)
+	ret0, _ := ret[0].(models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockRepositoryMockRecorder) RefreshSession(ctx, tokenHash, newTokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockRepository)(nil).RefreshSession), ctx, tokenHash, newTokenHash, expiresAt)
+}
+
+// ResetSignInAttempts mocks base method.
+func (m *MockRepository) ResetSignInAttempts(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSignInAttempts", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSignInAttempts indicates an expected call of ResetSignInAttempts.
+func (mr *MockRepositoryMockRecorder) ResetSignInAttempts(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSignInAttempts", reflect.TypeOf((*MockRepository)(nil).ResetSignInAttempts), ctx, key)
+}
+
+// RestoreFromTrash mocks base method.
+func (m *MockRepository) RestoreFromTrash(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFromTrash", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreFromTrash indicates an expected call of RestoreFromTrash.
+func (mr *MockRepositoryMockRecorder) RestoreFromTrash(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFromTrash", reflect.TypeOf((*MockRepository)(nil).RestoreFromTrash), arg0, arg1, arg2)
+}
+
+// RestoreItemRevision mocks base method.
+func (m *MockRepository) RestoreItemRevision(arg0 context.Context, arg1, arg2, arg3 string, arg4 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreItemRevision", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreItemRevision indicates an expected call of RestoreItemRevision.
+func (mr *MockRepositoryMockRecorder) RestoreItemRevision(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreItemRevision", reflect.TypeOf((*MockRepository)(nil).RestoreItemRevision), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RevokeSession mocks base method.
+func (m *MockRepository) RevokeSession(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockRepositoryMockRecorder) RevokeSession(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockRepository)(nil).RevokeSession), ctx, id)
+}
+
+// SetTOTP mocks base method.
+func (m *MockRepository) SetTOTP(ctx context.Context, secret string, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTP", ctx, secret, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTP indicates an expected call of SetTOTP.
+func (mr *MockRepositoryMockRecorder) SetTOTP(ctx, secret, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTP", reflect.TypeOf((*MockRepository)(nil).SetTOTP), ctx, secret, recoveryCodeHashes)
+}
+
+// SetVaultParams mocks base method.
+func (m *MockRepository) SetVaultParams(ctx context.Context, params models.VaultParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVaultParams", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVaultParams indicates an expected call of SetVaultParams.
+func (mr *MockRepositoryMockRecorder) SetVaultParams(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVaultParams", reflect.TypeOf((*MockRepository)(nil).SetVaultParams), ctx, params)
+}
+
+// Sync mocks base method.
+func (m *MockRepository) Sync(arg0 context.Context, arg1 int64) (models.ChangeSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", arg0, arg1)
+	ret0, _ := ret[0].(models.ChangeSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockRepositoryMockRecorder) Sync(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockRepository)(nil).Sync), arg0, arg1)
+}
+
+// TouchSession mocks base method.
+func (m *MockRepository) TouchSession(ctx context.Context, session models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockRepositoryMockRecorder) TouchSession(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockRepository)(nil).TouchSession), ctx, session)
+}
+
 // UpdateCard mocks base method.
 func (m *MockRepository) UpdateCard(ctx context.Context, card models.Card) (models.Card, error) {
 	m.ctrl.T.Helper()
@@ -182,3 +634,32 @@ func (mr *MockRepositoryMockRecorder) UpdateCredentials(ctx, credentials interfa
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCredentials", reflect.TypeOf((*MockRepository)(nil).UpdateCredentials), ctx, credentials)
 }
+
+// UpdateNote mocks base method.
+func (m *MockRepository) UpdateNote(ctx context.Context, note models.Note) (models.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNote", ctx, note)
+	ret0, _ := ret[0].(models.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockRepositoryMockRecorder) UpdateNote(ctx, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockRepository)(nil).UpdateNote), ctx, note)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockRepository) UseRecoveryCode(ctx context.Context, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockRepositoryMockRecorder) UseRecoveryCode(ctx, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockRepository)(nil).UseRecoveryCode), ctx, codeHash)
+}
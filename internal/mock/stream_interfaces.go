@@ -40,3 +40,25 @@ type GophKeeperService_DownloadFileServer interface {
 	Send(*pb.DownloadFileResponse) error
 	grpc.ServerStream
 }
+
+type GophKeeperService_ExportVaultClient interface {
+	Recv() (*pb.ExportVaultResponse, error)
+	grpc.ClientStream
+}
+
+type GophKeeperService_ImportVaultClient interface {
+	Send(*pb.ImportVaultRequest) error
+	CloseAndRecv() (*pb.ImportVaultResponse, error)
+	grpc.ClientStream
+}
+
+type GophKeeperService_ExportVaultServer interface {
+	Send(*pb.ExportVaultResponse) error
+	grpc.ServerStream
+}
+
+type GophKeeperService_ImportVaultServer interface {
+	Recv() (*pb.ImportVaultRequest, error)
+	SendAndClose(*pb.ImportVaultResponse) error
+	grpc.ServerStream
+}
@@ -501,6 +501,266 @@ func (mr *MockGophKeeperService_DownloadFileClientMockRecorder) Trailer() *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockGophKeeperService_DownloadFileClient)(nil).Trailer))
 }
 
+// MockGophKeeperService_ExportVaultClient is a mock of GophKeeperService_ExportVaultClient interface.
+type MockGophKeeperService_ExportVaultClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockGophKeeperService_ExportVaultClientMockRecorder
+}
+
+// MockGophKeeperService_ExportVaultClientMockRecorder is the mock recorder for MockGophKeeperService_ExportVaultClient.
+type MockGophKeeperService_ExportVaultClientMockRecorder struct {
+	mock *MockGophKeeperService_ExportVaultClient
+}
+
+// NewMockGophKeeperService_ExportVaultClient creates a new mock instance.
+func NewMockGophKeeperService_ExportVaultClient(ctrl *gomock.Controller) *MockGophKeeperService_ExportVaultClient {
+	mock := &MockGophKeeperService_ExportVaultClient{ctrl: ctrl}
+	mock.recorder = &MockGophKeeperService_ExportVaultClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGophKeeperService_ExportVaultClient) EXPECT() *MockGophKeeperService_ExportVaultClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockGophKeeperService_ExportVaultClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockGophKeeperService_ExportVaultClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockGophKeeperService_ExportVaultClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockGophKeeperService_ExportVaultClient) Recv() (*v1.ExportVaultResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*v1.ExportVaultResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockGophKeeperService_ExportVaultClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).RecvMsg), m)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockGophKeeperService_ExportVaultClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockGophKeeperService_ExportVaultClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockGophKeeperService_ExportVaultClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockGophKeeperService_ExportVaultClient)(nil).Trailer))
+}
+
+// MockGophKeeperService_ImportVaultClient is a mock of GophKeeperService_ImportVaultClient interface.
+type MockGophKeeperService_ImportVaultClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockGophKeeperService_ImportVaultClientMockRecorder
+}
+
+// MockGophKeeperService_ImportVaultClientMockRecorder is the mock recorder for MockGophKeeperService_ImportVaultClient.
+type MockGophKeeperService_ImportVaultClientMockRecorder struct {
+	mock *MockGophKeeperService_ImportVaultClient
+}
+
+// NewMockGophKeeperService_ImportVaultClient creates a new mock instance.
+func NewMockGophKeeperService_ImportVaultClient(ctrl *gomock.Controller) *MockGophKeeperService_ImportVaultClient {
+	mock := &MockGophKeeperService_ImportVaultClient{ctrl: ctrl}
+	mock.recorder = &MockGophKeeperService_ImportVaultClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGophKeeperService_ImportVaultClient) EXPECT() *MockGophKeeperService_ImportVaultClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).Header))
+}
+
+// CloseAndRecv mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) CloseAndRecv() (*v1.ImportVaultResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAndRecv")
+	ret0, _ := ret[0].(*v1.ImportVaultResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAndRecv indicates an expected call of CloseAndRecv.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) CloseAndRecv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAndRecv", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).CloseAndRecv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockGophKeeperService_ImportVaultClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) Send(request *v1.ImportVaultRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) Send(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).Send), request)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockGophKeeperService_ImportVaultClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockGophKeeperService_ImportVaultClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockGophKeeperService_ImportVaultClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockGophKeeperService_ImportVaultClient)(nil).Trailer))
+}
+
 // MockGophKeeperService_SubscribeToChangesServer is a mock of GophKeeperService_SubscribeToChangesServer interface.
 type MockGophKeeperService_SubscribeToChangesServer struct {
 	ctrl     *gomock.Controller
@@ -872,3 +1132,256 @@ func (mr *MockGophKeeperService_DownloadFileServerMockRecorder) SetTrailer(arg0
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockGophKeeperService_DownloadFileServer)(nil).SetTrailer), arg0)
 }
+
+// MockGophKeeperService_ExportVaultServer is a mock of GophKeeperService_ExportVaultServer interface.
+type MockGophKeeperService_ExportVaultServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockGophKeeperService_ExportVaultServerMockRecorder
+}
+
+// MockGophKeeperService_ExportVaultServerMockRecorder is the mock recorder for MockGophKeeperService_ExportVaultServer.
+type MockGophKeeperService_ExportVaultServerMockRecorder struct {
+	mock *MockGophKeeperService_ExportVaultServer
+}
+
+// NewMockGophKeeperService_ExportVaultServer creates a new mock instance.
+func NewMockGophKeeperService_ExportVaultServer(ctrl *gomock.Controller) *MockGophKeeperService_ExportVaultServer {
+	mock := &MockGophKeeperService_ExportVaultServer{ctrl: ctrl}
+	mock.recorder = &MockGophKeeperService_ExportVaultServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGophKeeperService_ExportVaultServer) EXPECT() *MockGophKeeperService_ExportVaultServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockGophKeeperService_ExportVaultServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).Context))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockGophKeeperService_ExportVaultServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockGophKeeperService_ExportVaultServer) Send(arg0 *v1.ExportVaultResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockGophKeeperService_ExportVaultServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockGophKeeperService_ExportVaultServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockGophKeeperService_ExportVaultServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockGophKeeperService_ExportVaultServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockGophKeeperService_ExportVaultServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockGophKeeperService_ExportVaultServer)(nil).SetTrailer), arg0)
+}
+
+// MockGophKeeperService_ImportVaultServer is a mock of GophKeeperService_ImportVaultServer interface.
+type MockGophKeeperService_ImportVaultServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockGophKeeperService_ImportVaultServerMockRecorder
+}
+
+// MockGophKeeperService_ImportVaultServerMockRecorder is the mock recorder for MockGophKeeperService_ImportVaultServer.
+type MockGophKeeperService_ImportVaultServerMockRecorder struct {
+	mock *MockGophKeeperService_ImportVaultServer
+}
+
+// NewMockGophKeeperService_ImportVaultServer creates a new mock instance.
+func NewMockGophKeeperService_ImportVaultServer(ctrl *gomock.Controller) *MockGophKeeperService_ImportVaultServer {
+	mock := &MockGophKeeperService_ImportVaultServer{ctrl: ctrl}
+	mock.recorder = &MockGophKeeperService_ImportVaultServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGophKeeperService_ImportVaultServer) EXPECT() *MockGophKeeperService_ImportVaultServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) Recv() (*v1.ImportVaultRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*v1.ImportVaultRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockGophKeeperService_ImportVaultServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).RecvMsg), m)
+}
+
+// SendAndClose mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) SendAndClose(arg0 *v1.ImportVaultResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendAndClose", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendAndClose indicates an expected call of SendAndClose.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) SendAndClose(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendAndClose", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).SendAndClose), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockGophKeeperService_ImportVaultServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockGophKeeperService_ImportVaultServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockGophKeeperService_ImportVaultServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockGophKeeperService_ImportVaultServer)(nil).SetTrailer), arg0)
+}
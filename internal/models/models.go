@@ -13,28 +13,188 @@ type User struct {
 }
 
 type Credentials struct {
-	ID          string    `json:"id"`
-	ServiceName string    `json:"service_name"`
-	Identity    string    `json:"identity"`
-	Password    string    `json:"password"`
-	UserID      string    `json:"-"`
-	UploadedAt  time.Time `json:"uploaded_at"`
+	ID          string            `json:"id"`
+	ServiceName string            `json:"service_name"`
+	Identity    string            `json:"identity"`
+	Password    string            `json:"password"`
+	TOTP        string            `json:"totp,omitempty"`
+	UserID      string            `json:"-"`
+	UploadedAt  time.Time         `json:"uploaded_at"`
+	Version     int64             `json:"version"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
 type Card struct {
-	ID             string    `json:"id"`
-	Number         string    `json:"number"`
-	ExpirationDate string    `json:"expiration_date"`
-	HolderName     string    `json:"holder_name"`
-	CVV            string    `json:"cvv"`
-	UserID         string    `json:"-"`
-	UploadedAt     time.Time `json:"uploaded_at"`
+	ID             string            `json:"id"`
+	Number         string            `json:"number"`
+	ExpirationDate string            `json:"expiration_date"`
+	HolderName     string            `json:"holder_name"`
+	CVV            string            `json:"cvv"`
+	UserID         string            `json:"-"`
+	UploadedAt     time.Time         `json:"uploaded_at"`
+	Version        int64             `json:"version"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+type Note struct {
+	ID         string            `json:"id"`
+	Title      string            `json:"title"`
+	Body       string            `json:"body"`
+	UserID     string            `json:"-"`
+	UploadedAt time.Time         `json:"uploaded_at"`
+	Version    int64             `json:"version"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Types of vault items
+const (
+	CredentialsItem = "credentials"
+	CardItem        = "card"
+	NoteItem        = "note"
+	FileItem        = "file"
+)
+
+// Revision - state of vault item before it was changed or deleted
+type Revision struct {
+	ID        string    `json:"id"`
+	ItemID    string    `json:"item_id"`
+	ItemType  string    `json:"item_type"`
+	Version   int64     `json:"version"`
+	RevisedAt time.Time `json:"revised_at"`
+	Deleted   bool      `json:"deleted"`
+	// Item - Credentials, Card or Note as it was in this revision
+	Item any `json:"item"`
+}
+
+// TrashItem - deleted item which can be restored until it is purged
+type TrashItem struct {
+	ItemType  string    `json:"item_type"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// Item - Credentials, Card, Note or File as it was deleted
+	Item any `json:"item"`
+}
+
+// Change - vault item which was created, updated or deleted since client's cursor
+type Change struct {
+	ItemType string `json:"item_type"`
+	// ItemID - ID of Credentials, Card or Note, name of File
+	ItemID string `json:"item_id"`
+}
+
+// ChangeSet - state of vault items changed since client's cursor. Full change set has all items of vault,
+// so client drops its copy instead of merging changes into it.
+type ChangeSet struct {
+	Cursor      int64         `json:"cursor"`
+	Full        bool          `json:"full"`
+	Credentials []Credentials `json:"credentials,omitempty"`
+	Cards       []Card        `json:"cards,omitempty"`
+	Notes       []Note        `json:"notes,omitempty"`
+	Files       []File        `json:"files,omitempty"`
+	Deleted     []Change      `json:"deleted,omitempty"`
+}
+
+// Operations of change events
+const (
+	ChangeCreated = "create"
+	ChangeUpdated = "update"
+	ChangeDeleted = "delete"
+)
+
+// ChangeEvent - change of vault item made by another session of user
+type ChangeEvent struct {
+	Operation string `json:"operation"`
+	ItemType  string `json:"item_type"`
+	// ItemID - ID of Credentials, Card or Note, name of File
+	ItemID  string `json:"item_id"`
+	Version int64  `json:"version"`
+	// Item - Credentials, Card, Note or File after change, nil for deletion and when item has to be synced
+	Item any `json:"item,omitempty"`
+	// Device - name of device which made change
+	Device string `json:"device"`
+}
+
+// ReplayConflict - change made offline to item which was changed by another client meanwhile
+type ReplayConflict struct {
+	ItemType string
+	// Base - Credentials, Card or Note before offline change, Mine - after it, Mine is nil for deletion
+	Base, Mine any
+	// Err - rejection of change with current server copy of item
+	Err error
+}
+
+// VaultParams - parameters of vault key derivation from master password. WrappedKey is empty until master
+// password is changed first time, key derived from master password is the vault key till then.
+type VaultParams struct {
+	Salt       string `json:"salt"`
+	KeyCheck   string `json:"key_check"`
+	WrappedKey string `json:"wrapped_key"`
+}
+
+// TOTP - second factor of user's sign in, secret is kept unconfirmed until user enters code of authenticator app
+type TOTP struct {
+	// Account - email of user, it labels secret in authenticator app
+	Account string `json:"account"`
+	Secret  string `json:"-"`
+	Enabled bool   `json:"enabled"`
+}
+
+// TOTPEnrollment - secret of authenticator app shown to user once, with recovery codes replacing lost app
+type TOTPEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioning_uri"`
+	RecoveryCodes   []string `json:"recovery_codes"`
 }
 
 type File struct {
-	Name       string    `json:"name"`
-	Size       string    `json:"size"`
-	UploadedAt time.Time `json:"uploaded_at"`
+	// ID - name file is stored under, random for encrypted files
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Size       string            `json:"size"`
+	UploadedAt time.Time         `json:"uploaded_at"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// Session - signed in device of user, its refresh token is kept by server only as hash
+type Session struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"-"`
+	Device        string    `json:"device"`
+	ClientVersion string    `json:"client_version"`
+	IP            string    `json:"ip"`
+	CreatedAt     time.Time `json:"created_at"`
+	RefreshedAt   time.Time `json:"refreshed_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// Current - session which requested the list of sessions
+	Current bool `json:"current,omitempty"`
+}
+
+// SignInAttempts - failed sign in attempts made for account or from address, sign in is refused until LockedUntil
+type SignInAttempts struct {
+	// Key - email of account or IP address attempts are counted for, prefixed by its kind
+	Key           string    `json:"key"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// ImportCount - items of one type which are imported, which are skipped as already present in vault and which are
+// refused by server
+type ImportCount struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	Rejected   int `json:"rejected"`
+}
+
+// ImportReport - result of vault import, dry run reports what would be imported without changing vault
+type ImportReport struct {
+	DryRun      bool        `json:"dry_run"`
+	Credentials ImportCount `json:"credentials"`
+	Cards       ImportCount `json:"cards"`
+	Notes       ImportCount `json:"notes"`
+	Files       ImportCount `json:"files"`
+	// Rejections - items refused by server with reason
+	Rejections []string `json:"rejections,omitempty"`
 }
 
 func NewUser(email string, originalPassword string) User {
@@ -57,3 +217,10 @@ func NewCard(number, expirationDate, holderName, cvv string) Card {
 		CVV:            cvv,
 	}
 }
+
+func NewNote(title, body string) Note {
+	return Note{
+		Title: title,
+		Body:  body,
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteScheme - scheme of DSN which selects embedded SQLite Data Base, path to Data Base file follows it
+const sqliteScheme = "sqlite://"
+
+// sqlitePragmas - connection settings of SQLite Data Base: references are checked as in Postgres
+// and concurrent writers wait for lock instead of failing
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+// dialect - differences of Data Bases supported by DBStorage, queries are shared otherwise
+type dialect struct {
+	name string
+	// driver - name of database/sql driver
+	driver string
+	// migrations - directory of dialect migrations in db.MigrationsFS
+	migrations string
+	// jsonObjectAgg - aggregate function which builds JSON object from key, value pairs
+	jsonObjectAgg string
+	// migrationDriver - golang-migrate driver over opened Data Base
+	migrationDriver func(db *sql.DB) (database.Driver, error)
+	// isUniqueViolation - report if error is caused by violated unique constraint
+	isUniqueViolation func(err error) bool
+	// timestamp - value of time parameter which is stored or compared with stored timestamps
+	timestamp func(t time.Time) any
+	// maxOpenConns - limit of opened connections, zero means no limit
+	maxOpenConns int
+}
+
+// postgresDialect - Postgres over pgx, used for DSN without known scheme
+var postgresDialect = dialect{
+	name:          "psql_db",
+	driver:        "pgx",
+	migrations:    "migrations",
+	jsonObjectAgg: "json_object_agg",
+	migrationDriver: func(db *sql.DB) (database.Driver, error) {
+		return postgres.WithInstance(db, &postgres.Config{})
+	},
+	isUniqueViolation: func(err error) bool {
+		var pgErr *pgconn.PgError
+		return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation
+	},
+	timestamp: func(t time.Time) any { return t },
+}
+
+// sqliteDialect - embedded SQLite, timestamps are kept as UTC text of CURRENT_TIMESTAMP format
+// so they are compared as strings
+var sqliteDialect = dialect{
+	name:          "sqlite_db",
+	driver:        "sqlite",
+	migrations:    "migrations/sqlite",
+	jsonObjectAgg: "json_group_object",
+	migrationDriver: func(db *sql.DB) (database.Driver, error) {
+		return sqlite.WithInstance(db, &sqlite.Config{})
+	},
+	isUniqueViolation: func(err error) bool {
+		var sqliteErr *sqlitedriver.Error
+		return errors.As(err, &sqliteErr) &&
+			(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+	},
+	timestamp: func(t time.Time) any { return t.UTC().Format(time.DateTime) },
+	// SQLite allows single writer, so requests are serialized instead of failing on busy Data Base,
+	// in-memory Data Base exists only within its connection as well
+	maxOpenConns: 1,
+}
+
+// dialectOf - dialect selected by DSN scheme and DSN which is passed to its driver
+func dialectOf(databaseDSN string) (*dialect, string) {
+	path, ok := strings.CutPrefix(databaseDSN, sqliteScheme)
+	if !ok {
+		return &postgresDialect, databaseDSN
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return &sqliteDialect, path + separator + sqlitePragmas
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Parameters of envelope encryption of sensitive columns
+const (
+	masterKeyLength    = 32
+	dataKeyLength      = 32
+	masterKeyIDLength  = 4
+	sealedColumnPrefix = "gks2:"
+	// legacyColumnPrefix - values sealed before they were bound to their rows, they are opened without associated data
+	legacyColumnPrefix = "gks1:"
+)
+
+var (
+	// ErrInvalidMasterKey - error when master key is not base64 encoded 32 bytes key
+	ErrInvalidMasterKey = errors.New("master key must be base64 encoded 32 bytes")
+	// ErrNoMasterKey - error when encryption of columns is requested but master key is not configured
+	ErrNoMasterKey = errors.New("master key is not configured")
+	// ErrUnknownMasterKey - error when data key was wrapped by master key which is not configured
+	ErrUnknownMasterKey = errors.New("data key is wrapped by unknown master key")
+	// ErrMalformedColumn - error when sealed column value can not be decoded
+	ErrMalformedColumn = errors.New("malformed sealed column value")
+)
+
+// KeyManager - envelope encryption of sensitive columns: every user has own data key,
+// which is stored in users table wrapped by server master key
+type KeyManager struct {
+	currentID string
+	masters   map[string]cipher.AEAD
+	dataKeys  sync.Map
+}
+
+// NewKeyManager - create KeyManager which wraps data keys with masterKey, previousKeys are only used
+// to unwrap data keys which were not rotated yet
+func NewKeyManager(masterKey string, previousKeys ...string) (*KeyManager, error) {
+	km := &KeyManager{masters: make(map[string]cipher.AEAD)}
+	for i, encoded := range append([]string{masterKey}, previousKeys...) {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != masterKeyLength {
+			return nil, ErrInvalidMasterKey
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		id := masterKeyID(key)
+		if i == 0 {
+			km.currentID = id
+		}
+		km.masters[id] = aead
+	}
+	return km, nil
+}
+
+// masterKeyID - short fingerprint of master key which is stored next to wrapped data key
+func masterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:masterKeyIDLength])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newDataKey - generate new random data key and return it wrapped by current master key
+func (km *KeyManager) newDataKey() (string, error) {
+	key := make([]byte, dataKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return km.wrap(key)
+}
+
+func (km *KeyManager) wrap(dataKey []byte) (string, error) {
+	sealed, err := seal(km.masters[km.currentID], dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	return km.currentID + ":" + sealed, nil
+}
+
+func (km *KeyManager) unwrap(wrappedKey string) ([]byte, error) {
+	id, sealed, found := strings.Cut(wrappedKey, ":")
+	aead, known := km.masters[id]
+	if !found || !known {
+		return nil, ErrUnknownMasterKey
+	}
+	return open(aead, sealed, nil)
+}
+
+// isCurrent - check if data key is wrapped by current master key
+func (km *KeyManager) isCurrent(wrappedKey string) bool {
+	return strings.HasPrefix(wrappedKey, km.currentID+":")
+}
+
+// rewrap - wrap data key by current master key
+func (km *KeyManager) rewrap(wrappedKey string) (string, error) {
+	key, err := km.unwrap(wrappedKey)
+	if err != nil {
+		return "", err
+	}
+	return km.wrap(key)
+}
+
+// dataCipher - return cipher over user's data key, unwrapped keys are cached as they do not change on rotation
+func (km *KeyManager) dataCipher(userID, wrappedKey string) (cipher.AEAD, error) {
+	if aead, ok := km.dataKeys.Load(userID); ok {
+		return aead.(cipher.AEAD), nil
+	}
+	key, err := km.unwrap(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	km.dataKeys.Store(userID, aead)
+	return aead, nil
+}
+
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+func open(aead cipher.AEAD, value string, additionalData []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformedColumn
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// boundRow - row sealed column values belong to, values are bound to table, column, row and user by associated
+// data, so they can not be moved to another row or account in data base
+type boundRow struct {
+	table, id, userID string
+}
+
+// binding - associated data of value of given column of the row
+func (row boundRow) binding(column string) []byte {
+	return []byte(strings.Join([]string{row.table, column, row.id, row.userID}, "\x00"))
+}
+
+// metadataRow - row metadata value of item is bound to, metadata rows are replaced on every change of item,
+// so value is bound to item and key instead of own ID
+func metadataRow(itemID, key, userID string) boundRow {
+	return boundRow{table: "item_metadata", id: itemID + "/" + key, userID: userID}
+}
+
+// isSealedColumn - check if column value is encrypted by data key
+func isSealedColumn(value string) bool {
+	return isBoundColumn(value) || strings.HasPrefix(value, legacyColumnPrefix)
+}
+
+// isBoundColumn - check if column value is encrypted by data key and bound to its row
+func isBoundColumn(value string) bool {
+	return strings.HasPrefix(value, sealedColumnPrefix)
+}
+
+// sealColumn - encrypt value of column of the row in place, nothing is done when encryption is not configured
+func sealColumn(aead cipher.AEAD, row boundRow, column string, value *string) error {
+	if aead == nil {
+		return nil
+	}
+	sealed, err := seal(aead, []byte(*value), row.binding(column))
+	if err != nil {
+		return err
+	}
+	*value = sealedColumnPrefix + sealed
+	return nil
+}
+
+// openColumn - decrypt value of column of the row in place, values stored before encryption was enabled are kept as is
+func openColumn(aead cipher.AEAD, row boundRow, column string, value *string) error {
+	if !isSealedColumn(*value) {
+		return nil
+	}
+	if aead == nil {
+		return ErrNoMasterKey
+	}
+	var plaintext []byte
+	var err error
+	if isBoundColumn(*value) {
+		plaintext, err = open(aead, strings.TrimPrefix(*value, sealedColumnPrefix), row.binding(column))
+	} else {
+		plaintext, err = open(aead, strings.TrimPrefix(*value, legacyColumnPrefix), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("column can not be decrypted: %w", err)
+	}
+	*value = string(plaintext)
+	return nil
+}
+
+// sealColumns - encrypt values of encrypted columns of the row in place, values are given in order of encryptedColumns
+func sealColumns(aead cipher.AEAD, row boundRow, values ...*string) error {
+	columns := encryptedColumnsOf(row.table)
+	if len(columns) != len(values) {
+		return fmt.Errorf("table %s has %d encrypted columns, %d values are given", row.table, len(columns), len(values))
+	}
+	for i, value := range values {
+		if err := sealColumn(aead, row, columns[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openColumns - decrypt values of encrypted columns of the row in place, values are given in order of encryptedColumns
+func openColumns(aead cipher.AEAD, row boundRow, values ...*string) error {
+	columns := encryptedColumnsOf(row.table)
+	if len(columns) != len(values) {
+		return fmt.Errorf("table %s has %d encrypted columns, %d values are given", row.table, len(columns), len(values))
+	}
+	for i, value := range values {
+		if err := openColumn(aead, row, columns[i], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
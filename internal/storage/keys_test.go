@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMasterKey(t *testing.T) string {
+	key := make([]byte, masterKeyLength)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestNewKeyManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Valid key", key: newTestMasterKey(t)},
+		{name: "Not base64", key: "not base64 !", wantErr: true},
+		{name: "Short key", key: base64.StdEncoding.EncodeToString([]byte("short")), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyManager(tt.key)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidMasterKey)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKeyManager_Rewrap(t *testing.T) {
+	oldKey, newKey := newTestMasterKey(t), newTestMasterKey(t)
+	oldManager, err := NewKeyManager(oldKey)
+	require.NoError(t, err)
+	wrapped, err := oldManager.newDataKey()
+	require.NoError(t, err)
+	dataKey, err := oldManager.unwrap(wrapped)
+	require.NoError(t, err)
+
+	newManager, err := NewKeyManager(newKey, oldKey)
+	require.NoError(t, err)
+	assert.False(t, newManager.isCurrent(wrapped))
+
+	rewrapped, err := newManager.rewrap(wrapped)
+	require.NoError(t, err)
+	assert.True(t, newManager.isCurrent(rewrapped))
+	unwrapped, err := newManager.unwrap(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped, "data key should not change on rotation")
+
+	_, err = oldManager.unwrap(rewrapped)
+	assert.ErrorIs(t, err, ErrUnknownMasterKey)
+}
+
+func TestSealOpenColumns(t *testing.T) {
+	km, err := NewKeyManager(newTestMasterKey(t))
+	require.NoError(t, err)
+	wrapped, err := km.newDataKey()
+	require.NoError(t, err)
+	aead, err := km.dataCipher("user", wrapped)
+	require.NoError(t, err)
+	row := boundRow{table: "credentials", id: "1", userID: "user"}
+
+	identity, password, totp := "identity", "password", "plain"
+	require.NoError(t, sealColumn(aead, row, "identity", &identity))
+	require.NoError(t, sealColumn(aead, row, "password", &password))
+	assert.True(t, isBoundColumn(identity))
+	assert.NotContains(t, password, "password")
+
+	require.NoError(t, openColumns(aead, row, &identity, &password, &totp))
+	assert.Equal(t, "identity", identity)
+	assert.Equal(t, "password", password)
+	assert.Equal(t, "plain", totp, "values stored before encryption are kept as is")
+	assert.Error(t, openColumns(aead, row, &identity), "every encrypted column of table is expected")
+
+	sealed := "value"
+	require.NoError(t, sealColumn(aead, row, "password", &sealed))
+	assert.ErrorIs(t, openColumn(nil, row, "password", &sealed), ErrNoMasterKey)
+
+	unsealed := "value"
+	require.NoError(t, sealColumn(nil, row, "password", &unsealed))
+	assert.Equal(t, "value", unsealed, "values are not encrypted without master key")
+}
+
+func TestOpenColumn_Binding(t *testing.T) {
+	km, err := NewKeyManager(newTestMasterKey(t))
+	require.NoError(t, err)
+	wrapped, err := km.newDataKey()
+	require.NoError(t, err)
+	aead, err := km.dataCipher("user", wrapped)
+	require.NoError(t, err)
+	row := boundRow{table: "credentials", id: "1", userID: "user"}
+
+	sealed := "secret"
+	require.NoError(t, sealColumn(aead, row, "password", &sealed))
+	for name, other := range map[string]struct {
+		row    boundRow
+		column string
+	}{
+		"column": {row: row, column: "identity"},
+		"row":    {row: boundRow{table: "credentials", id: "2", userID: "user"}, column: "password"},
+		"table":  {row: boundRow{table: "cards", id: "1", userID: "user"}, column: "password"},
+		"user":   {row: boundRow{table: "credentials", id: "1", userID: "other"}, column: "password"},
+	} {
+		value := sealed
+		assert.Error(t, openColumn(aead, other.row, other.column, &value), "value moved to other %s must not open", name)
+	}
+
+	legacySealed, err := seal(aead, []byte("secret"), nil)
+	require.NoError(t, err)
+	legacy := legacyColumnPrefix + legacySealed
+	assert.True(t, isSealedColumn(legacy))
+	assert.False(t, isBoundColumn(legacy))
+	require.NoError(t, openColumn(aead, row, "password", &legacy))
+	assert.Equal(t, "secret", legacy, "values sealed before binding are opened without it")
+}
@@ -0,0 +1,853 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/config"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/google/uuid"
+)
+
+// memoryItem - credentials, card or note kept by MemoryStorage, values are data columns of item table
+type memoryItem struct {
+	itemType   string
+	id         string
+	userID     string
+	created    int64
+	version    int64
+	uploadedAt time.Time
+	deletedAt  time.Time
+	values     []string
+	metadata   map[string]string
+}
+
+// build - model of item with copy of its metadata
+func (item *memoryItem) build() any {
+	return itemTables[item.itemType].item(item.id, item.version, item.uploadedAt, maps.Clone(item.metadata), slices.Clone(item.values))
+}
+
+// memorySession - session kept by MemoryStorage with hashes of its current and replaced refresh tokens
+type memorySession struct {
+	session           models.Session
+	tokenHash         string
+	previousTokenHash string
+}
+
+// memoryTOTP - second factor of user kept by MemoryStorage with hashes of not used recovery codes
+type memoryTOTP struct {
+	secret        string
+	enabled       bool
+	recoveryCodes map[string]bool
+}
+
+// memoryRevision - previous revision of item kept by MemoryStorage
+type memoryRevision struct {
+	revision models.Revision
+	userID   string
+}
+
+// MemoryStorage - model of Repository storage kept in memory, it is safe for concurrent use
+// and follows semantics of DBStorage, so it is used by tests and demo mode
+type MemoryStorage struct {
+	mutex    sync.RWMutex
+	users    map[string]models.User
+	emails   map[string]string
+	vaults   map[string]models.VaultParams
+	totp     map[string]*memoryTOTP
+	sessions map[string]*memorySession
+	attempts map[string]models.SignInAttempts
+	items    map[string]*memoryItem
+	history  map[string][]memoryRevision
+	changes  []models.Change
+	// changeUsers - owners of changes, change with sequence number i+1 is kept at index i
+	changeUsers []string
+	created     int64
+}
+
+// NewMemoryStorage - create empty instance of MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		users:    make(map[string]models.User),
+		emails:   make(map[string]string),
+		vaults:   make(map[string]models.VaultParams),
+		totp:     make(map[string]*memoryTOTP),
+		sessions: make(map[string]*memorySession),
+		attempts: make(map[string]models.SignInAttempts),
+		items:    make(map[string]*memoryItem),
+		history:  make(map[string][]memoryRevision),
+	}
+}
+
+// CreateUser - create new user, email must be unique
+func (ms *MemoryStorage) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.emails[user.Email]; ok {
+		return models.User{}, ErrAlreadyExists
+	}
+	created := models.User{ID: uuid.NewString(), Email: user.Email, Password: user.Password}
+	ms.users[created.ID] = created
+	ms.emails[created.Email] = created.ID
+	return created, nil
+}
+
+// AuthorizeUser - return user with given email
+func (ms *MemoryStorage) AuthorizeUser(ctx context.Context, email string) (models.User, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID, ok := ms.emails[email]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return ms.users[userID], nil
+}
+
+// GetUser - return user from context
+func (ms *MemoryStorage) GetUser(ctx context.Context) (models.User, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	user, ok := ms.users[ctx.Value(config.USERIDCONTEXTKEY).(string)]
+	if !ok {
+		return models.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+// ChangePassword - replace password hash of user and revoke all sessions of user except kept one,
+// IDs of revoked sessions are returned
+func (ms *MemoryStorage) ChangePassword(ctx context.Context, passwordHash, keepSessionID string) ([]string, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	user, ok := ms.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	user.Password = passwordHash
+	ms.users[userID] = user
+
+	revoked := make([]string, 0)
+	for id, stored := range ms.sessions {
+		if stored.session.UserID == userID && id != keepSessionID {
+			delete(ms.sessions, id)
+			revoked = append(revoked, id)
+		}
+	}
+	return revoked, nil
+}
+
+// DeleteAccount - erase user with all items, history and sessions of user, IDs of revoked sessions are returned
+func (ms *MemoryStorage) DeleteAccount(ctx context.Context) ([]string, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	user, ok := ms.users[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(ms.users, userID)
+	delete(ms.emails, user.Email)
+	delete(ms.vaults, userID)
+	delete(ms.totp, userID)
+
+	revoked := make([]string, 0)
+	for id, stored := range ms.sessions {
+		if stored.session.UserID == userID {
+			delete(ms.sessions, id)
+			revoked = append(revoked, id)
+		}
+	}
+	for id, item := range ms.items {
+		if item.userID == userID {
+			delete(ms.items, id)
+		}
+	}
+	for id, revisions := range ms.history {
+		if len(revisions) > 0 && revisions[0].userID == userID {
+			delete(ms.history, id)
+		}
+	}
+	// changes are blanked instead of removal, since sequence number of change is its position
+	for i, owner := range ms.changeUsers {
+		if owner == userID {
+			ms.changes[i] = models.Change{}
+			ms.changeUsers[i] = ""
+		}
+	}
+	return revoked, nil
+}
+
+// CreateSession - save new session of user with hash of its refresh token
+func (ms *MemoryStorage) CreateSession(ctx context.Context, session models.Session, tokenHash string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if _, ok := ms.users[session.UserID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := ms.sessions[session.ID]; ok {
+		return ErrAlreadyExists
+	}
+	session.CreatedAt = now()
+	session.RefreshedAt = session.CreatedAt
+	session.LastSeenAt = session.LastSeenAt.UTC().Truncate(time.Microsecond)
+	ms.sessions[session.ID] = &memorySession{session: session, tokenHash: tokenHash}
+	return nil
+}
+
+// RefreshSession - replace refresh token of not expired session by new one. Refresh token which was already
+// replaced means it was stolen or leaked, so the session is revoked and ErrNotFound is returned as for unknown token
+func (ms *MemoryStorage) RefreshSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (models.Session, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for id, session := range ms.sessions {
+		switch {
+		case session.tokenHash == tokenHash && session.session.ExpiresAt.After(time.Now()):
+			session.previousTokenHash, session.tokenHash = session.tokenHash, newTokenHash
+			session.session.RefreshedAt = now()
+			session.session.ExpiresAt = expiresAt
+			return session.session, nil
+		case session.previousTokenHash == tokenHash:
+			delete(ms.sessions, id)
+			return models.Session{}, ErrNotFound
+		}
+	}
+	return models.Session{}, ErrNotFound
+}
+
+// TouchSession - record time, address and client version of request made in session, ErrNotFound is returned
+// when session is revoked or expired
+func (ms *MemoryStorage) TouchSession(ctx context.Context, session models.Session) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	stored, ok := ms.sessions[session.ID]
+	if !ok || stored.session.UserID != session.UserID || !stored.session.ExpiresAt.After(time.Now()) {
+		return ErrNotFound
+	}
+	stored.session.LastSeenAt = session.LastSeenAt.UTC().Truncate(time.Microsecond)
+	stored.session.IP = session.IP
+	stored.session.ClientVersion = session.ClientVersion
+	return nil
+}
+
+// ListSessions - return not expired sessions of user from the most recently seen one
+func (ms *MemoryStorage) ListSessions(ctx context.Context) ([]models.Session, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	sessions := make([]models.Session, 0)
+	for _, stored := range ms.sessions {
+		if stored.session.UserID == userID && stored.session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, stored.session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b models.Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession - delete session of user, so neither its access token nor refresh token is accepted anymore
+func (ms *MemoryStorage) RevokeSession(ctx context.Context, id string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	stored, ok := ms.sessions[id]
+	if !ok || stored.session.UserID != ctx.Value(config.USERIDCONTEXTKEY).(string) {
+		return ErrNotFound
+	}
+	delete(ms.sessions, id)
+	return nil
+}
+
+// GetVaultParams - return user's vault key derivation parameters, empty if vault is not initialized yet
+func (ms *MemoryStorage) GetVaultParams(ctx context.Context) (models.VaultParams, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	if _, ok := ms.users[userID]; !ok {
+		return models.VaultParams{}, ErrNotFound
+	}
+	return ms.vaults[userID], nil
+}
+
+// SetVaultParams - save user's vault key derivation parameters, vault can be initialized only once
+func (ms *MemoryStorage) SetVaultParams(ctx context.Context, params models.VaultParams) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	if _, ok := ms.users[userID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := ms.vaults[userID]; ok {
+		return ErrAlreadyExists
+	}
+	ms.vaults[userID] = params
+	return nil
+}
+
+// ChangeVaultParams - replace user's vault key derivation parameters if key check was not changed meanwhile
+func (ms *MemoryStorage) ChangeVaultParams(ctx context.Context, oldKeyCheck string, params models.VaultParams) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	if _, ok := ms.users[userID]; !ok {
+		return ErrNotFound
+	}
+	if current, ok := ms.vaults[userID]; !ok || current.KeyCheck != oldKeyCheck {
+		return ErrVaultChanged
+	}
+	ms.vaults[userID] = params
+	return nil
+}
+
+// GetTOTP - return second factor of user, its secret is empty until user enrolls
+func (ms *MemoryStorage) GetTOTP(ctx context.Context) (models.TOTP, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	user, ok := ms.users[userID]
+	if !ok {
+		return models.TOTP{}, ErrNotFound
+	}
+	totp := models.TOTP{Account: user.Email}
+	if stored, ok := ms.totp[userID]; ok {
+		totp.Secret, totp.Enabled = stored.secret, stored.enabled
+	}
+	return totp, nil
+}
+
+// SetTOTP - save not confirmed secret of user's second factor and hashes of recovery codes replacing previous ones,
+// secret can not be replaced while second factor is enabled
+func (ms *MemoryStorage) SetTOTP(ctx context.Context, secret string, recoveryCodeHashes []string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	if _, ok := ms.users[userID]; !ok {
+		return ErrNotFound
+	}
+	if stored, ok := ms.totp[userID]; ok && stored.enabled {
+		return ErrAlreadyExists
+	}
+	totp := &memoryTOTP{secret: secret, recoveryCodes: make(map[string]bool)}
+	for _, codeHash := range recoveryCodeHashes {
+		totp.recoveryCodes[codeHash] = true
+	}
+	ms.totp[userID] = totp
+	return nil
+}
+
+// EnableTOTP - require second factor on sign in of user, whose secret is confirmed
+func (ms *MemoryStorage) EnableTOTP(ctx context.Context) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	stored, ok := ms.totp[ctx.Value(config.USERIDCONTEXTKEY).(string)]
+	if !ok {
+		return ErrNotFound
+	}
+	stored.enabled = true
+	return nil
+}
+
+// UseRecoveryCode - spend recovery code of user, ErrNotFound is returned for unknown or already used code
+func (ms *MemoryStorage) UseRecoveryCode(ctx context.Context, codeHash string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	stored, ok := ms.totp[ctx.Value(config.USERIDCONTEXTKEY).(string)]
+	if !ok || !stored.recoveryCodes[codeHash] {
+		return ErrNotFound
+	}
+	delete(stored.recoveryCodes, codeHash)
+	return nil
+}
+
+// DisableTOTP - forget second factor of user with its recovery codes
+func (ms *MemoryStorage) DisableTOTP(ctx context.Context) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.totp, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	return nil
+}
+
+// GetSignInAttempts - return failed sign in attempts of key, key without failures has zero attempts
+func (ms *MemoryStorage) GetSignInAttempts(ctx context.Context, key string) (models.SignInAttempts, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	attempts, ok := ms.attempts[key]
+	if !ok {
+		return models.SignInAttempts{Key: key}, nil
+	}
+	return attempts, nil
+}
+
+// RecordSignInFailure - count failed sign in attempt of key, failures made before resetBefore are forgotten
+func (ms *MemoryStorage) RecordSignInFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (models.SignInAttempts, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	attempts, ok := ms.attempts[key]
+	if !ok || attempts.LastFailureAt.Before(resetBefore) {
+		attempts = models.SignInAttempts{Key: key}
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = failedAt
+	ms.attempts[key] = attempts
+	return attempts, nil
+}
+
+// LockSignIn - refuse sign in of key until given time
+func (ms *MemoryStorage) LockSignIn(ctx context.Context, key string, until time.Time) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if attempts, ok := ms.attempts[key]; ok {
+		attempts.LockedUntil = until
+		ms.attempts[key] = attempts
+	}
+	return nil
+}
+
+// ResetSignInAttempts - forget failed sign in attempts of key, e.g. after successful sign in
+func (ms *MemoryStorage) ResetSignInAttempts(ctx context.Context, key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.attempts, key)
+	return nil
+}
+
+// PurgeSignInAttempts - delete attempts of keys which failed last time and were unlocked before given time
+func (ms *MemoryStorage) PurgeSignInAttempts(ctx context.Context, before time.Time) (purged int, err error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for key, attempts := range ms.attempts {
+		if attempts.LastFailureAt.Before(before) && attempts.LockedUntil.Before(before) {
+			delete(ms.attempts, key)
+			purged++
+		}
+	}
+	return
+}
+
+// CreateCredentials - create new credentials record
+func (ms *MemoryStorage) CreateCredentials(ctx context.Context, credentials models.Credentials) (models.Credentials, error) {
+	item, err := ms.createItem(ctx, credentialsItem, credentials.ID, credentials.Metadata,
+		credentials.ServiceName, credentials.Identity, credentials.Password, credentials.TOTP)
+	if err != nil {
+		return models.Credentials{}, err
+	}
+	credentials.ID, credentials.UploadedAt, credentials.Version = item.id, item.uploadedAt, item.version
+	return credentials, nil
+}
+
+// GetCredentials - return list of users Credentials
+func (ms *MemoryStorage) GetCredentials(ctx context.Context) ([]models.Credentials, error) {
+	return listItems[models.Credentials](ms, ctx, credentialsItem), nil
+}
+
+// UpdateCredentials - update Credentials model
+func (ms *MemoryStorage) UpdateCredentials(ctx context.Context, credentials models.Credentials) (models.Credentials, error) {
+	item, err := ms.updateItem(ctx, credentialsItem, credentials.ID, credentials.Version, credentials.Metadata,
+		credentials.ServiceName, credentials.Identity, credentials.Password, credentials.TOTP)
+	if err != nil {
+		return models.Credentials{}, err
+	}
+	credentials.UploadedAt, credentials.Version = item.uploadedAt, item.version
+	return credentials, nil
+}
+
+// DeleteCredentials - delete Credentials of given version
+func (ms *MemoryStorage) DeleteCredentials(ctx context.Context, credentialsID string, version int64) error {
+	return ms.deleteItem(ctx, credentialsItem, credentialsID, version)
+}
+
+// CreateCard - create new Card record
+func (ms *MemoryStorage) CreateCard(ctx context.Context, card models.Card) (models.Card, error) {
+	item, err := ms.createItem(ctx, cardItem, card.ID, card.Metadata, card.Number, card.ExpirationDate, card.HolderName, card.CVV)
+	if err != nil {
+		return models.Card{}, err
+	}
+	card.ID, card.UploadedAt, card.Version = item.id, item.uploadedAt, item.version
+	return card, nil
+}
+
+// GetCards - return list of users Cards
+func (ms *MemoryStorage) GetCards(ctx context.Context) ([]models.Card, error) {
+	return listItems[models.Card](ms, ctx, cardItem), nil
+}
+
+// UpdateCard - update Card model
+func (ms *MemoryStorage) UpdateCard(ctx context.Context, card models.Card) (models.Card, error) {
+	item, err := ms.updateItem(ctx, cardItem, card.ID, card.Version, card.Metadata,
+		card.Number, card.ExpirationDate, card.HolderName, card.CVV)
+	if err != nil {
+		return models.Card{}, err
+	}
+	card.UploadedAt, card.Version = item.uploadedAt, item.version
+	return card, nil
+}
+
+// DeleteCard - delete Card of given version
+func (ms *MemoryStorage) DeleteCard(ctx context.Context, cardID string, version int64) error {
+	return ms.deleteItem(ctx, cardItem, cardID, version)
+}
+
+// CreateNote - create new Note record
+func (ms *MemoryStorage) CreateNote(ctx context.Context, note models.Note) (models.Note, error) {
+	item, err := ms.createItem(ctx, noteItem, note.ID, note.Metadata, note.Title, note.Body)
+	if err != nil {
+		return models.Note{}, err
+	}
+	note.ID, note.UploadedAt, note.Version = item.id, item.uploadedAt, item.version
+	return note, nil
+}
+
+// GetNotes - return list of users Notes
+func (ms *MemoryStorage) GetNotes(ctx context.Context) ([]models.Note, error) {
+	return listItems[models.Note](ms, ctx, noteItem), nil
+}
+
+// UpdateNote - update Note model, note is uploaded again on every update
+func (ms *MemoryStorage) UpdateNote(ctx context.Context, note models.Note) (models.Note, error) {
+	item, err := ms.updateItem(ctx, noteItem, note.ID, note.Version, note.Metadata, note.Title, note.Body)
+	if err != nil {
+		return models.Note{}, err
+	}
+	note.UploadedAt, note.Version = item.uploadedAt, item.version
+	return note, nil
+}
+
+// DeleteNote - delete user's Note of given version
+func (ms *MemoryStorage) DeleteNote(ctx context.Context, noteID string, version int64) error {
+	return ms.deleteItem(ctx, noteItem, noteID, version)
+}
+
+// createItem - save new item of user from context under given ID or new one when it is empty and log its creation
+func (ms *MemoryStorage) createItem(ctx context.Context, itemType, itemID string, metadata map[string]string, values ...string) (memoryItem, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if itemID == "" {
+		itemID = uuid.NewString()
+	}
+	if _, ok := ms.items[itemID]; ok {
+		return memoryItem{}, ErrAlreadyExists
+	}
+	ms.created++
+	item := &memoryItem{
+		itemType:   itemType,
+		id:         itemID,
+		userID:     ctx.Value(config.USERIDCONTEXTKEY).(string),
+		created:    ms.created,
+		version:    1,
+		uploadedAt: now(),
+		values:     values,
+		metadata:   maps.Clone(metadata),
+	}
+	ms.items[item.id] = item
+	ms.recordChange(item.userID, itemType, item.id)
+	return *item, nil
+}
+
+// activeItem - user's item of given type and version which is not in trash,
+// ErrVersionConflict is returned when item was changed or deleted since client read it
+func (ms *MemoryStorage) activeItem(ctx context.Context, itemType, itemID string, version int64) (*memoryItem, error) {
+	item, ok := ms.items[itemID]
+	if !ok || item.itemType != itemType || item.userID != ctx.Value(config.USERIDCONTEXTKEY).(string) ||
+		item.version != version || !item.deletedAt.IsZero() {
+		return nil, ErrVersionConflict
+	}
+	return item, nil
+}
+
+// updateItem - replace values and metadata of item of given version keeping its previous revision in history
+func (ms *MemoryStorage) updateItem(ctx context.Context, itemType, itemID string, version int64, metadata map[string]string, values ...string) (memoryItem, error) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	item, err := ms.activeItem(ctx, itemType, itemID, version)
+	if err != nil {
+		return memoryItem{}, err
+	}
+	ms.addRevision(item, false)
+	item.values = values
+	item.metadata = maps.Clone(metadata)
+	item.version++
+	if itemType == noteItem {
+		item.uploadedAt = now()
+	}
+	ms.recordChange(item.userID, itemType, item.id)
+	return *item, nil
+}
+
+// deleteItem - move item of given version to trash keeping its last revision in history
+func (ms *MemoryStorage) deleteItem(ctx context.Context, itemType, itemID string, version int64) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	item, err := ms.activeItem(ctx, itemType, itemID, version)
+	if err != nil {
+		return err
+	}
+	ms.addRevision(item, true)
+	item.deletedAt = now()
+	item.version++
+	ms.recordChange(item.userID, itemType, item.id)
+	return nil
+}
+
+// addRevision - copy current state of item to its history
+func (ms *MemoryStorage) addRevision(item *memoryItem, deleted bool) {
+	ms.history[item.id] = append(ms.history[item.id], memoryRevision{
+		userID: item.userID,
+		revision: models.Revision{
+			ID:        uuid.NewString(),
+			ItemID:    item.id,
+			ItemType:  item.itemType,
+			Version:   item.version,
+			RevisedAt: now(),
+			Deleted:   deleted,
+			Item:      item.build(),
+		},
+	})
+}
+
+// listItems - user's items of given type which are not in trash in order of their creation
+func listItems[T any](ms *MemoryStorage, ctx context.Context, itemType string) []T {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	items := make([]T, 0)
+	for _, item := range ms.sortedItems(func(item *memoryItem) bool {
+		return item.itemType == itemType && item.userID == userID && item.deletedAt.IsZero()
+	}) {
+		items = append(items, item.build().(T))
+	}
+	return items
+}
+
+// sortedItems - items matching filter in order of their creation
+func (ms *MemoryStorage) sortedItems(filter func(item *memoryItem) bool) []*memoryItem {
+	var items []*memoryItem
+	for _, item := range ms.items {
+		if filter(item) {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b *memoryItem) int { return cmp.Compare(a.created, b.created) })
+	return items
+}
+
+// ListItemHistory - return previous revisions of user's item from the newest to the oldest one
+func (ms *MemoryStorage) ListItemHistory(ctx context.Context, itemType, itemID string) ([]models.Revision, error) {
+	if _, ok := itemTables[itemType]; !ok {
+		return nil, ErrUnknownItemType
+	}
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	revisions := make([]models.Revision, 0)
+	for _, revision := range ms.history[itemID] {
+		if revision.userID == userID && revision.revision.ItemType == itemType {
+			revisions = append(revisions, revision.revision)
+		}
+	}
+	slices.SortStableFunc(revisions, func(a, b models.Revision) int {
+		return cmp.Or(cmp.Compare(b.Version, a.Version), b.RevisedAt.Compare(a.RevisedAt))
+	})
+	return revisions, nil
+}
+
+// RestoreItemRevision - replace user's item of given version by its previous revision, restore is saved as
+// a new version of item, so current state stays in history as well
+func (ms *MemoryStorage) RestoreItemRevision(ctx context.Context, itemType, itemID, revisionID string, version int64) (restoredVersion int64, err error) {
+	revisions, err := ms.ListItemHistory(ctx, itemType, itemID)
+	if err != nil {
+		return
+	}
+	idx := slices.IndexFunc(revisions, func(revision models.Revision) bool { return revision.ID == revisionID })
+	if idx < 0 {
+		return 0, ErrNotFound
+	}
+
+	switch item := revisions[idx].Item.(type) {
+	case models.Credentials:
+		item.Version = version
+		item, err = ms.UpdateCredentials(ctx, item)
+		restoredVersion = item.Version
+	case models.Card:
+		item.Version = version
+		item, err = ms.UpdateCard(ctx, item)
+		restoredVersion = item.Version
+	case models.Note:
+		item.Version = version
+		item, err = ms.UpdateNote(ctx, item)
+		restoredVersion = item.Version
+	}
+	return
+}
+
+// ListTrash - return user's deleted credentials, cards and notes from the most recently deleted ones
+func (ms *MemoryStorage) ListTrash(ctx context.Context) ([]models.TrashItem, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	items := make([]models.TrashItem, 0)
+	for _, itemType := range trashItemTypes {
+		trashed := ms.sortedItems(func(item *memoryItem) bool {
+			return item.itemType == itemType && item.userID == userID && !item.deletedAt.IsZero()
+		})
+		slices.SortStableFunc(trashed, func(a, b *memoryItem) int { return b.deletedAt.Compare(a.deletedAt) })
+		for _, item := range trashed {
+			items = append(items, models.TrashItem{ItemType: itemType, DeletedAt: item.deletedAt, Item: item.build()})
+		}
+	}
+	return items, nil
+}
+
+// RestoreFromTrash - return user's deleted item back, restore is saved as a new version of item
+func (ms *MemoryStorage) RestoreFromTrash(ctx context.Context, itemType, itemID string) error {
+	if _, ok := itemTables[itemType]; !ok {
+		return ErrUnknownItemType
+	}
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	item, ok := ms.items[itemID]
+	if !ok || item.itemType != itemType || item.userID != ctx.Value(config.USERIDCONTEXTKEY).(string) || item.deletedAt.IsZero() {
+		return ErrNotFound
+	}
+	item.deletedAt = time.Time{}
+	item.version++
+	ms.recordChange(item.userID, itemType, item.id)
+	return nil
+}
+
+// EmptyTrash - permanently delete all user's items from trash
+func (ms *MemoryStorage) EmptyTrash(ctx context.Context) error {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	ms.purgeTrash(func(item *memoryItem) bool { return item.userID == userID })
+	return nil
+}
+
+// PurgeTrash - permanently delete items of all users which were deleted before given time
+func (ms *MemoryStorage) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	return ms.purgeTrash(func(item *memoryItem) bool { return item.deletedAt.Before(before) }), nil
+}
+
+// purgeTrash - permanently delete trashed items matching condition together with their history
+func (ms *MemoryStorage) purgeTrash(condition func(item *memoryItem) bool) (purged int) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for id, item := range ms.items {
+		if !item.deletedAt.IsZero() && condition(item) {
+			delete(ms.items, id)
+			delete(ms.history, id)
+			purged++
+		}
+	}
+	return
+}
+
+// RecordChange - log change of item which is not kept in storage
+func (ms *MemoryStorage) RecordChange(ctx context.Context, itemType, itemID string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.recordChange(ctx.Value(config.USERIDCONTEXTKEY).(string), itemType, itemID)
+	return nil
+}
+
+// recordChange - append change of user's item to changes log, sequence number of change is its position in log
+func (ms *MemoryStorage) recordChange(userID, itemType, itemID string) {
+	ms.changes = append(ms.changes, models.Change{ItemType: itemType, ItemID: itemID})
+	ms.changeUsers = append(ms.changeUsers, userID)
+}
+
+// Sync - current state of user's items changed since cursor, items which do not exist anymore or are in trash
+// are reported as deleted. Cursor which is not known to server requests full copy of vault.
+func (ms *MemoryStorage) Sync(ctx context.Context, cursor int64) (changes models.ChangeSet, err error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	for i := len(ms.changeUsers) - 1; i >= 0; i-- {
+		if ms.changeUsers[i] == userID {
+			changes.Cursor = int64(i + 1)
+			break
+		}
+	}
+	changes.Full = cursor <= 0 || cursor > changes.Cursor
+
+	// changed items are ordered by their last change as in DBStorage
+	var changed []models.Change
+	if !changes.Full {
+		for i := len(ms.changes) - 1; i >= int(cursor); i-- {
+			if ms.changeUsers[i] == userID && !slices.Contains(changed, ms.changes[i]) {
+				changed = append(changed, ms.changes[i])
+			}
+		}
+		slices.Reverse(changed)
+	}
+
+	for _, change := range changed {
+		if change.ItemType == models.FileItem {
+			changes.Files = append(changes.Files, models.File{Name: change.ItemID})
+		}
+	}
+	for _, itemType := range trashItemTypes {
+		present := make(map[string]bool)
+		for _, item := range ms.sortedItems(func(item *memoryItem) bool {
+			return item.itemType == itemType && item.userID == userID && item.deletedAt.IsZero() &&
+				(changes.Full || slices.Contains(changed, models.Change{ItemType: itemType, ItemID: item.id}))
+		}) {
+			present[item.id] = true
+			switch item := item.build().(type) {
+			case models.Credentials:
+				changes.Credentials = append(changes.Credentials, item)
+			case models.Card:
+				changes.Cards = append(changes.Cards, item)
+			case models.Note:
+				changes.Notes = append(changes.Notes, item)
+			}
+		}
+		for _, change := range changed {
+			if change.ItemType == itemType && !present[change.ItemID] {
+				changes.Deleted = append(changes.Deleted, change)
+			}
+		}
+	}
+	return
+}
+
+// now - current time truncated as timestamps kept by Data Base
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
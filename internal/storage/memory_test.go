@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PaBah/GophKeeper/internal/config"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_Concurrent(t *testing.T) {
+	ms := NewMemoryStorage()
+	user, err := ms.CreateUser(context.Background(), models.User{Email: "owner@example.com", Password: "hash"})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, user.ID)
+
+	const workers = 16
+	var created, duplicates atomic.Int32
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ms.CreateUser(context.Background(), models.User{Email: "same@example.com", Password: "hash"})
+			switch {
+			case err == nil:
+				created.Add(1)
+			case errors.Is(err, ErrAlreadyExists):
+				duplicates.Add(1)
+			}
+			note, err := ms.CreateNote(ctx, models.Note{Title: "todo", Body: "body"})
+			if assert.NoError(t, err) {
+				note.Body = "changed"
+				_, err = ms.UpdateNote(ctx, note)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load())
+	assert.Equal(t, int32(workers-1), duplicates.Load())
+
+	notes, err := ms.GetNotes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, notes, workers)
+	changes, err := ms.Sync(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*workers), changes.Cursor)
+}
+
+func TestMemoryStorage_ReturnsCopies(t *testing.T) {
+	ms := NewMemoryStorage()
+	user, err := ms.CreateUser(context.Background(), models.User{Email: "owner@example.com", Password: "hash"})
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, user.ID)
+
+	metadata := map[string]string{"tag": "home"}
+	_, err = ms.CreateNote(ctx, models.Note{Title: "todo", Body: "body", Metadata: metadata})
+	require.NoError(t, err)
+	metadata["tag"] = "work"
+
+	notes, err := ms.GetNotes(ctx)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "home", notes[0].Metadata["tag"])
+	notes[0].Metadata["tag"] = "work"
+
+	notes, err = ms.GetNotes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "home", notes[0].Metadata["tag"])
+}
+
+func TestMemoryStorage_ClientChosenID(t *testing.T) {
+	ms := NewMemoryStorage()
+	owner, err := ms.CreateUser(context.Background(), models.User{Email: "owner@example.com", Password: "hash"})
+	require.NoError(t, err)
+	other, err := ms.CreateUser(context.Background(), models.User{Email: "other@example.com", Password: "hash"})
+	require.NoError(t, err)
+	ownerCtx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, owner.ID)
+	otherCtx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, other.ID)
+
+	note, err := ms.CreateNote(ownerCtx, models.Note{ID: "0b9f2a4e-3c1d-4f5e-9a6b-7c8d9e0f1a2b", Title: "todo", Body: "body"})
+	require.NoError(t, err)
+	assert.Equal(t, "0b9f2a4e-3c1d-4f5e-9a6b-7c8d9e0f1a2b", note.ID)
+
+	_, err = ms.CreateCard(otherCtx, models.Card{ID: note.ID, Number: "4111111111111111"})
+	require.ErrorIs(t, err, ErrAlreadyExists, "item of other user should not be replaced")
+	notes, err := ms.GetNotes(ownerCtx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Note{note}, notes)
+}
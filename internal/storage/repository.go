@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/PaBah/GophKeeper/internal/models"
 )
@@ -10,17 +11,66 @@ import (
 // ErrAlreadyExists - error when user tries to save already existing data
 var ErrAlreadyExists = errors.New("already exists")
 
+// ErrVersionConflict - error when item was changed or deleted since client read its version
+var ErrVersionConflict = errors.New("item version conflict")
+
+// ErrNotFound - error when requested data does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrVaultChanged - error when vault parameters were changed since client read its key check
+var ErrVaultChanged = errors.New("vault parameters were changed")
+
+// ErrUnknownItemType - error when item type has no history
+var ErrUnknownItemType = errors.New("unknown item type")
+
 // Repository - interface over Repository pattern for system storage
 type Repository interface {
 	CreateUser(ctx context.Context, user models.User) (models.User, error)
 	AuthorizeUser(ctx context.Context, email string) (models.User, error)
+	GetUser(ctx context.Context) (models.User, error)
+	ChangePassword(ctx context.Context, passwordHash, keepSessionID string) ([]string, error)
+	DeleteAccount(ctx context.Context) ([]string, error)
+	CreateSession(ctx context.Context, session models.Session, tokenHash string) error
+	RefreshSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (models.Session, error)
+	TouchSession(ctx context.Context, session models.Session) error
+	ListSessions(ctx context.Context) ([]models.Session, error)
+	RevokeSession(ctx context.Context, id string) error
+	GetVaultParams(ctx context.Context) (models.VaultParams, error)
+	SetVaultParams(ctx context.Context, params models.VaultParams) error
+	ChangeVaultParams(ctx context.Context, oldKeyCheck string, params models.VaultParams) error
+	GetTOTP(ctx context.Context) (models.TOTP, error)
+	SetTOTP(ctx context.Context, secret string, recoveryCodeHashes []string) error
+	EnableTOTP(ctx context.Context) error
+	UseRecoveryCode(ctx context.Context, codeHash string) error
+	DisableTOTP(ctx context.Context) error
+	GetSignInAttempts(ctx context.Context, key string) (models.SignInAttempts, error)
+	RecordSignInFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (models.SignInAttempts, error)
+	LockSignIn(ctx context.Context, key string, until time.Time) error
+	ResetSignInAttempts(ctx context.Context, key string) error
+	PurgeSignInAttempts(ctx context.Context, before time.Time) (int, error)
 	CreateCredentials(ctx context.Context, credentials models.Credentials) (models.Credentials, error)
 	GetCredentials(ctx context.Context) ([]models.Credentials, error)
 	UpdateCredentials(ctx context.Context, credentials models.Credentials) (models.Credentials, error)
-	DeleteCredentials(ctx context.Context, credentialsID string) error
+	DeleteCredentials(ctx context.Context, credentialsID string, version int64) error
 
 	CreateCard(ctx context.Context, card models.Card) (models.Card, error)
 	GetCards(ctx context.Context) ([]models.Card, error)
 	UpdateCard(ctx context.Context, card models.Card) (models.Card, error)
-	DeleteCard(ctx context.Context, cardID string) error
+	DeleteCard(ctx context.Context, cardID string, version int64) error
+
+	CreateNote(ctx context.Context, note models.Note) (models.Note, error)
+	GetNotes(ctx context.Context) ([]models.Note, error)
+	UpdateNote(ctx context.Context, note models.Note) (models.Note, error)
+	DeleteNote(ctx context.Context, noteID string, version int64) error
+
+	ListItemHistory(ctx context.Context, itemType, itemID string) ([]models.Revision, error)
+	RestoreItemRevision(ctx context.Context, itemType, itemID, revisionID string, version int64) (int64, error)
+
+	ListTrash(ctx context.Context) ([]models.TrashItem, error)
+	RestoreFromTrash(ctx context.Context, itemType, itemID string) error
+	EmptyTrash(ctx context.Context) error
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+
+	RecordChange(ctx context.Context, itemType, itemID string) error
+	Sync(ctx context.Context, cursor int64) (models.ChangeSet, error)
 }
@@ -0,0 +1,684 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PaBah/GophKeeper/internal/config"
+	"github.com/PaBah/GophKeeper/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepository - conformance suite which every Repository implementation must pass,
+// newRepository returns empty or shared storage, so every check works within own user
+func testRepository(t *testing.T, newRepository func(t *testing.T) Repository) {
+	repository := newRepository(t)
+
+	newUser := func(t *testing.T) context.Context {
+		user, err := repository.CreateUser(context.Background(), models.NewUser(uuid.NewString()+"@example.com", "password"))
+		require.NoError(t, err)
+		require.NotEmpty(t, user.ID)
+		return context.WithValue(context.Background(), config.USERIDCONTEXTKEY, user.ID)
+	}
+
+	t.Run("Users", func(t *testing.T) {
+		ctx := context.Background()
+		email := uuid.NewString() + "@example.com"
+		created, err := repository.CreateUser(ctx, models.User{Email: email, Password: "hash"})
+		require.NoError(t, err)
+		assert.Equal(t, email, created.Email)
+
+		_, err = repository.CreateUser(ctx, models.User{Email: email, Password: "other"})
+		assert.ErrorIs(t, err, ErrAlreadyExists)
+
+		authorized, err := repository.AuthorizeUser(ctx, email)
+		require.NoError(t, err)
+		assert.Equal(t, models.User{ID: created.ID, Email: email, Password: "hash"}, authorized)
+
+		_, err = repository.AuthorizeUser(ctx, uuid.NewString()+"@example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("ChangePassword", func(t *testing.T) {
+		ctx := newUser(t)
+		userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+		user, err := repository.GetUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, userID, user.ID)
+		assert.NotEmpty(t, user.Email)
+
+		other := newUser(t)
+		otherID := other.Value(config.USERIDCONTEXTKEY).(string)
+		current, stale := uuid.NewString(), uuid.NewString()
+		for _, session := range []models.Session{
+			{ID: current, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)},
+			{ID: stale, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)},
+			{ID: uuid.NewString(), UserID: otherID, ExpiresAt: time.Now().Add(time.Hour)},
+		} {
+			require.NoError(t, repository.CreateSession(ctx, session, uuid.NewString()))
+		}
+
+		revoked, err := repository.ChangePassword(ctx, "new hash", current)
+		require.NoError(t, err)
+		assert.Equal(t, []string{stale}, revoked)
+		user, err = repository.GetUser(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "new hash", user.Password)
+
+		sessions, err := repository.ListSessions(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, current, sessions[0].ID)
+		sessions, err = repository.ListSessions(other)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+
+		missing := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, uuid.NewString())
+		_, err = repository.GetUser(missing)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repository.ChangePassword(missing, "hash", "")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("DeleteAccount", func(t *testing.T) {
+		ctx := newUser(t)
+		userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+		user, err := repository.GetUser(ctx)
+		require.NoError(t, err)
+		other := newUser(t)
+		otherID := other.Value(config.USERIDCONTEXTKEY).(string)
+		session := uuid.NewString()
+		require.NoError(t, repository.CreateSession(ctx, models.Session{ID: session, UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, uuid.NewString()))
+		require.NoError(t, repository.CreateSession(other, models.Session{ID: uuid.NewString(), UserID: otherID, ExpiresAt: time.Now().Add(time.Hour)}, uuid.NewString()))
+
+		credentials := models.NewCredentials("mail", "user", "secret")
+		credentials.Metadata = map[string]string{"url": "https://mail.example.com"}
+		created, err := repository.CreateCredentials(ctx, credentials)
+		require.NoError(t, err)
+		_, err = repository.UpdateCredentials(ctx, created)
+		require.NoError(t, err)
+		_, err = repository.CreateCard(ctx, models.NewCard("4111 1111 1111 1111", "12/30", "Test User", "123"))
+		require.NoError(t, err)
+		note, err := repository.CreateNote(ctx, models.Note{Title: "todo", Body: "buy milk"})
+		require.NoError(t, err)
+		require.NoError(t, repository.DeleteNote(ctx, note.ID, note.Version))
+		require.NoError(t, repository.SetVaultParams(ctx, models.VaultParams{Salt: "salt", KeyCheck: "check"}))
+		require.NoError(t, repository.SetTOTP(ctx, "secret", []string{"code"}))
+		_, err = repository.CreateNote(other, models.Note{Title: "other", Body: "kept"})
+		require.NoError(t, err)
+
+		revoked, err := repository.DeleteAccount(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{session}, revoked)
+		_, err = repository.GetUser(ctx)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repository.AuthorizeUser(ctx, user.Email)
+		assert.Error(t, err)
+		revisions, _ := repository.ListItemHistory(ctx, models.CredentialsItem, created.ID)
+		assert.Empty(t, revisions)
+		_, err = repository.DeleteAccount(ctx)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// email is released for new sign up, which starts with empty vault
+		recreated, err := repository.CreateUser(context.Background(), models.User{Email: user.Email, Password: "hash"})
+		require.NoError(t, err)
+		recreatedCtx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, recreated.ID)
+		params, err := repository.GetVaultParams(recreatedCtx)
+		require.NoError(t, err)
+		assert.Empty(t, params)
+
+		notes, err := repository.GetNotes(other)
+		require.NoError(t, err)
+		assert.Len(t, notes, 1)
+		sessions, err := repository.ListSessions(other)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+
+	t.Run("Sessions", func(t *testing.T) {
+		ctx := newUser(t)
+		userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+		session := models.Session{ID: uuid.NewString(), UserID: userID, Device: "laptop", ExpiresAt: time.Now().Add(time.Hour)}
+		first, second, third := uuid.NewString(), uuid.NewString(), uuid.NewString()
+		require.NoError(t, repository.CreateSession(ctx, session, first))
+
+		refreshed, err := repository.RefreshSession(ctx, first, second, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, session.ID, refreshed.ID)
+		assert.Equal(t, userID, refreshed.UserID)
+		assert.Equal(t, "laptop", refreshed.Device)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), refreshed.ExpiresAt, time.Minute)
+
+		_, err = repository.RefreshSession(ctx, uuid.NewString(), third, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// reuse of replaced refresh token revokes the session
+		_, err = repository.RefreshSession(ctx, first, third, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repository.RefreshSession(ctx, second, third, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		expired := models.Session{ID: uuid.NewString(), UserID: userID, ExpiresAt: time.Now().Add(-time.Hour)}
+		require.NoError(t, repository.CreateSession(ctx, expired, first))
+		_, err = repository.RefreshSession(ctx, first, third, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ListAndRevokeSessions", func(t *testing.T) {
+		ctx := newUser(t)
+		userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+		laptop := models.Session{ID: uuid.NewString(), UserID: userID, Device: "laptop", ClientVersion: "v1.0.0",
+			IP: "10.0.0.1", LastSeenAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+		phone := models.Session{ID: uuid.NewString(), UserID: userID, Device: "phone",
+			LastSeenAt: time.Now().Add(-2 * time.Hour), ExpiresAt: time.Now().Add(time.Hour)}
+		expired := models.Session{ID: uuid.NewString(), UserID: userID, Device: "tablet",
+			LastSeenAt: time.Now(), ExpiresAt: time.Now().Add(-time.Hour)}
+		require.NoError(t, repository.CreateSession(ctx, laptop, uuid.NewString()))
+		require.NoError(t, repository.CreateSession(ctx, phone, uuid.NewString()))
+		require.NoError(t, repository.CreateSession(ctx, expired, uuid.NewString()))
+
+		sessions, err := repository.ListSessions(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, laptop.ID, sessions[0].ID)
+		assert.Equal(t, "v1.0.0", sessions[0].ClientVersion)
+		assert.Equal(t, "10.0.0.1", sessions[0].IP)
+		assert.Equal(t, phone.ID, sessions[1].ID)
+
+		// request in phone session makes it the most recently seen one
+		phone.IP, phone.ClientVersion, phone.LastSeenAt = "10.0.0.2", "v1.1.0", time.Now()
+		require.NoError(t, repository.TouchSession(ctx, phone))
+		sessions, err = repository.ListSessions(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		assert.Equal(t, phone.ID, sessions[0].ID)
+		assert.Equal(t, "10.0.0.2", sessions[0].IP)
+		assert.Equal(t, "v1.1.0", sessions[0].ClientVersion)
+		assert.WithinDuration(t, time.Now(), sessions[0].LastSeenAt, time.Minute)
+
+		assert.ErrorIs(t, repository.TouchSession(ctx, expired), ErrNotFound)
+		assert.ErrorIs(t, repository.RevokeSession(newUser(t), laptop.ID), ErrNotFound)
+
+		require.NoError(t, repository.RevokeSession(ctx, laptop.ID))
+		assert.ErrorIs(t, repository.RevokeSession(ctx, laptop.ID), ErrNotFound)
+		assert.ErrorIs(t, repository.TouchSession(ctx, laptop), ErrNotFound)
+		sessions, err = repository.ListSessions(ctx)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, phone.ID, sessions[0].ID)
+	})
+
+	t.Run("VaultParams", func(t *testing.T) {
+		ctx := newUser(t)
+		params, err := repository.GetVaultParams(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, params)
+
+		vault := models.VaultParams{Salt: "salt", KeyCheck: "check"}
+		require.NoError(t, repository.SetVaultParams(ctx, vault))
+		assert.ErrorIs(t, repository.SetVaultParams(ctx, models.VaultParams{Salt: "other", KeyCheck: "other"}), ErrAlreadyExists)
+
+		params, err = repository.GetVaultParams(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, vault, params)
+
+		changed := models.VaultParams{Salt: "new salt", KeyCheck: "new check", WrappedKey: "key"}
+		assert.ErrorIs(t, repository.ChangeVaultParams(ctx, "other", changed), ErrVaultChanged)
+		require.NoError(t, repository.ChangeVaultParams(ctx, vault.KeyCheck, changed))
+		assert.ErrorIs(t, repository.ChangeVaultParams(ctx, vault.KeyCheck, changed), ErrVaultChanged)
+
+		params, err = repository.GetVaultParams(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, changed, params)
+	})
+
+	t.Run("TOTP", func(t *testing.T) {
+		ctx := newUser(t)
+		totp, err := repository.GetTOTP(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, totp.Account)
+		assert.Empty(t, totp.Secret)
+		assert.False(t, totp.Enabled)
+		assert.ErrorIs(t, repository.EnableTOTP(ctx), ErrNotFound)
+
+		require.NoError(t, repository.SetTOTP(ctx, "first", []string{"code1"}))
+		// enrollment is restarted until secret is confirmed, previous recovery codes are replaced
+		require.NoError(t, repository.SetTOTP(ctx, "secret", []string{"code2", "code3"}))
+		totp, err = repository.GetTOTP(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", totp.Secret)
+		assert.False(t, totp.Enabled)
+
+		require.NoError(t, repository.EnableTOTP(ctx))
+		totp, err = repository.GetTOTP(ctx)
+		require.NoError(t, err)
+		assert.True(t, totp.Enabled)
+		assert.ErrorIs(t, repository.SetTOTP(ctx, "other", nil), ErrAlreadyExists)
+
+		assert.ErrorIs(t, repository.UseRecoveryCode(ctx, "code1"), ErrNotFound)
+		assert.ErrorIs(t, repository.UseRecoveryCode(newUser(t), "code2"), ErrNotFound)
+		require.NoError(t, repository.UseRecoveryCode(ctx, "code2"))
+		assert.ErrorIs(t, repository.UseRecoveryCode(ctx, "code2"), ErrNotFound)
+
+		require.NoError(t, repository.DisableTOTP(ctx))
+		totp, err = repository.GetTOTP(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, totp.Secret)
+		assert.False(t, totp.Enabled)
+		assert.ErrorIs(t, repository.UseRecoveryCode(ctx, "code3"), ErrNotFound)
+	})
+
+	t.Run("SignInAttempts", func(t *testing.T) {
+		ctx := context.Background()
+		key := "account:" + uuid.NewString() + "@example.com"
+		attempts, err := repository.GetSignInAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, models.SignInAttempts{Key: key}, attempts)
+
+		now := time.Now().Truncate(time.Second)
+		attempts, err = repository.RecordSignInFailure(ctx, key, now.Add(-time.Minute), now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures)
+		attempts, err = repository.RecordSignInFailure(ctx, key, now, now.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts.Failures)
+		assert.WithinDuration(t, now, attempts.LastFailureAt, time.Second)
+		assert.True(t, attempts.LockedUntil.IsZero())
+
+		require.NoError(t, repository.LockSignIn(ctx, key, now.Add(time.Minute)))
+		attempts, err = repository.GetSignInAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts.Failures)
+		assert.WithinDuration(t, now.Add(time.Minute), attempts.LockedUntil, time.Second)
+
+		// failures made before window are forgotten together with lock
+		attempts, err = repository.RecordSignInFailure(ctx, key, now.Add(time.Hour), now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures)
+		assert.True(t, attempts.LockedUntil.IsZero())
+
+		other := "ip:" + uuid.NewString()
+		_, err = repository.RecordSignInFailure(ctx, other, now, now.Add(-time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, repository.ResetSignInAttempts(ctx, key))
+		attempts, err = repository.GetSignInAttempts(ctx, key)
+		require.NoError(t, err)
+		assert.Zero(t, attempts.Failures)
+		attempts, err = repository.GetSignInAttempts(ctx, other)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures)
+
+		// attempts are purged once their failures are forgotten and lock is over
+		locked := "reauth:" + uuid.NewString()
+		_, err = repository.RecordSignInFailure(ctx, locked, now.Add(-2*time.Hour), now.Add(-3*time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, repository.LockSignIn(ctx, locked, now.Add(time.Minute)))
+		_, err = repository.PurgeSignInAttempts(ctx, now.Add(-time.Hour))
+		require.NoError(t, err)
+		attempts, err = repository.GetSignInAttempts(ctx, other)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures, "recent failures are kept")
+		attempts, err = repository.GetSignInAttempts(ctx, locked)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts.Failures, "locked key is kept")
+
+		purged, err := repository.PurgeSignInAttempts(ctx, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 2)
+		attempts, err = repository.GetSignInAttempts(ctx, locked)
+		require.NoError(t, err)
+		assert.Zero(t, attempts.Failures)
+	})
+
+	t.Run("Credentials", func(t *testing.T) {
+		ctx := newUser(t)
+		credentials := models.NewCredentials("mail", "user", "secret")
+		credentials.Metadata = map[string]string{"url": "https://mail.example.com"}
+		credentials.TOTP = "JBSWY3DPEHPK3PXP"
+		created, err := repository.CreateCredentials(ctx, credentials)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.ID)
+		assert.Equal(t, int64(1), created.Version)
+
+		list, err := repository.GetCredentials(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, created.ID, list[0].ID)
+		assert.Equal(t, "user", list[0].Identity)
+		assert.Equal(t, "secret", list[0].Password)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", list[0].TOTP)
+		assert.Equal(t, credentials.Metadata, list[0].Metadata)
+
+		created.Password = "changed"
+		created.TOTP = ""
+		created.Metadata = nil
+		updated, err := repository.UpdateCredentials(ctx, created)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+
+		_, err = repository.UpdateCredentials(ctx, created)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+
+		list, err = repository.GetCredentials(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "changed", list[0].Password)
+		assert.Empty(t, list[0].TOTP)
+		assert.Empty(t, list[0].Metadata)
+
+		revisions, err := repository.ListItemHistory(ctx, models.CredentialsItem, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", revisions[0].Item.(models.Credentials).TOTP)
+
+		assert.ErrorIs(t, repository.DeleteCredentials(ctx, created.ID, 1), ErrVersionConflict)
+		require.NoError(t, repository.DeleteCredentials(ctx, created.ID, updated.Version))
+		list, err = repository.GetCredentials(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, list)
+
+		other, err := repository.GetCredentials(newUser(t))
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	})
+
+	t.Run("Cards", func(t *testing.T) {
+		ctx := newUser(t)
+		created, err := repository.CreateCard(ctx, models.NewCard("4111 1111 1111 1111", "12/30", "Test User", "123"))
+		require.NoError(t, err)
+		require.NotEmpty(t, created.ID)
+
+		created.HolderName = "Other User"
+		updated, err := repository.UpdateCard(ctx, created)
+		require.NoError(t, err)
+		assert.Equal(t, created.Version+1, updated.Version)
+
+		cards, err := repository.GetCards(ctx)
+		require.NoError(t, err)
+		require.Len(t, cards, 1)
+		assert.Equal(t, "4111 1111 1111 1111", cards[0].Number)
+		assert.Equal(t, "Other User", cards[0].HolderName)
+		assert.Equal(t, "123", cards[0].CVV)
+
+		require.NoError(t, repository.DeleteCard(ctx, created.ID, updated.Version))
+		cards, err = repository.GetCards(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, cards)
+	})
+
+	t.Run("Notes", func(t *testing.T) {
+		ctx := newUser(t)
+		created, err := repository.CreateNote(ctx, models.Note{Title: "todo", Body: "buy milk", Metadata: map[string]string{"tag": "home"}})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.ID)
+		assert.False(t, created.UploadedAt.IsZero())
+
+		created.Body = "buy bread"
+		updated, err := repository.UpdateNote(ctx, created)
+		require.NoError(t, err)
+		assert.Equal(t, created.Version+1, updated.Version)
+
+		notes, err := repository.GetNotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "buy bread", notes[0].Body)
+		assert.Equal(t, map[string]string{"tag": "home"}, notes[0].Metadata)
+
+		_, err = repository.UpdateNote(ctx, created)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		require.NoError(t, repository.DeleteNote(ctx, created.ID, updated.Version))
+	})
+
+	t.Run("History", func(t *testing.T) {
+		ctx := newUser(t)
+		created, err := repository.CreateNote(ctx, models.Note{Title: "todo", Body: "first", Metadata: map[string]string{"tag": "home"}})
+		require.NoError(t, err)
+		created.Body = "second"
+		created.Metadata = nil
+		updated, err := repository.UpdateNote(ctx, created)
+		require.NoError(t, err)
+
+		revisions, err := repository.ListItemHistory(ctx, models.NoteItem, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, int64(1), revisions[0].Version)
+		assert.False(t, revisions[0].Deleted)
+		revised, ok := revisions[0].Item.(models.Note)
+		require.True(t, ok)
+		assert.Equal(t, "first", revised.Body)
+		assert.Equal(t, map[string]string{"tag": "home"}, revised.Metadata)
+
+		_, err = repository.RestoreItemRevision(ctx, models.NoteItem, created.ID, "unknown", updated.Version)
+		assert.ErrorIs(t, err, ErrNotFound)
+		restored, err := repository.RestoreItemRevision(ctx, models.NoteItem, created.ID, revisions[0].ID, updated.Version)
+		require.NoError(t, err)
+		assert.Equal(t, updated.Version+1, restored)
+
+		notes, err := repository.GetNotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "first", notes[0].Body)
+		assert.Equal(t, map[string]string{"tag": "home"}, notes[0].Metadata)
+
+		require.NoError(t, repository.DeleteNote(ctx, created.ID, restored))
+		revisions, err = repository.ListItemHistory(ctx, models.NoteItem, created.ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 3)
+		assert.True(t, revisions[0].Deleted)
+
+		_, err = repository.ListItemHistory(ctx, "unknown", created.ID)
+		assert.ErrorIs(t, err, ErrUnknownItemType)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		ctx := newUser(t)
+		credentials, err := repository.CreateCredentials(ctx, models.NewCredentials("mail", "user", "secret"))
+		require.NoError(t, err)
+		card, err := repository.CreateCard(ctx, models.NewCard("4111 1111 1111 1111", "12/30", "Test User", "123"))
+		require.NoError(t, err)
+		note, err := repository.CreateNote(ctx, models.Note{Title: "todo", Body: "buy milk"})
+		require.NoError(t, err)
+		require.NoError(t, repository.DeleteCredentials(ctx, credentials.ID, credentials.Version))
+		require.NoError(t, repository.DeleteCard(ctx, card.ID, card.Version))
+		require.NoError(t, repository.DeleteNote(ctx, note.ID, note.Version))
+
+		trash, err := repository.ListTrash(ctx)
+		require.NoError(t, err)
+		require.Len(t, trash, 3)
+		assert.Equal(t, models.CredentialsItem, trash[0].ItemType)
+		assert.Equal(t, "secret", trash[0].Item.(models.Credentials).Password)
+		assert.False(t, trash[0].DeletedAt.IsZero())
+
+		require.NoError(t, repository.RestoreFromTrash(ctx, models.NoteItem, note.ID))
+		assert.ErrorIs(t, repository.RestoreFromTrash(ctx, models.NoteItem, note.ID), ErrNotFound)
+		assert.ErrorIs(t, repository.RestoreFromTrash(ctx, "unknown", note.ID), ErrUnknownItemType)
+		notes, err := repository.GetNotes(ctx)
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, note.Version+2, notes[0].Version)
+
+		purged, err := repository.PurgeTrash(context.Background(), time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+		trash, err = repository.ListTrash(ctx)
+		require.NoError(t, err)
+		assert.Len(t, trash, 2)
+
+		purged, err = repository.PurgeTrash(context.Background(), time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 2)
+		trash, err = repository.ListTrash(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+		revisions, err := repository.ListItemHistory(ctx, models.CardItem, card.ID)
+		require.NoError(t, err)
+		assert.Empty(t, revisions)
+
+		require.NoError(t, repository.DeleteNote(ctx, note.ID, notes[0].Version))
+		require.NoError(t, repository.EmptyTrash(ctx))
+		trash, err = repository.ListTrash(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		ctx := newUser(t)
+		changes, err := repository.Sync(ctx, 0)
+		require.NoError(t, err)
+		assert.True(t, changes.Full)
+		assert.Zero(t, changes.Cursor)
+
+		credentials, err := repository.CreateCredentials(ctx, models.NewCredentials("mail", "user", "secret"))
+		require.NoError(t, err)
+		note, err := repository.CreateNote(ctx, models.Note{Title: "todo", Body: "buy milk"})
+		require.NoError(t, err)
+
+		full, err := repository.Sync(ctx, 0)
+		require.NoError(t, err)
+		assert.True(t, full.Full)
+		assert.Positive(t, full.Cursor)
+		require.Len(t, full.Credentials, 1)
+		require.Len(t, full.Notes, 1)
+		assert.Equal(t, "secret", full.Credentials[0].Password)
+
+		unchanged, err := repository.Sync(ctx, full.Cursor)
+		require.NoError(t, err)
+		assert.False(t, unchanged.Full)
+		assert.Equal(t, full.Cursor, unchanged.Cursor)
+		assert.Empty(t, unchanged.Credentials)
+		assert.Empty(t, unchanged.Notes)
+		assert.Empty(t, unchanged.Deleted)
+
+		note.Body = "buy bread"
+		_, err = repository.UpdateNote(ctx, note)
+		require.NoError(t, err)
+		require.NoError(t, repository.DeleteCredentials(ctx, credentials.ID, credentials.Version))
+		require.NoError(t, repository.RecordChange(ctx, models.FileItem, "report.pdf"))
+
+		delta, err := repository.Sync(ctx, full.Cursor)
+		require.NoError(t, err)
+		assert.False(t, delta.Full)
+		assert.Greater(t, delta.Cursor, full.Cursor)
+		require.Len(t, delta.Notes, 1)
+		assert.Equal(t, "buy bread", delta.Notes[0].Body)
+		assert.Empty(t, delta.Credentials)
+		assert.Equal(t, []models.Change{{ItemType: models.CredentialsItem, ItemID: credentials.ID}}, delta.Deleted)
+		require.Len(t, delta.Files, 1)
+		assert.Equal(t, "report.pdf", delta.Files[0].Name)
+
+		unknown, err := repository.Sync(ctx, delta.Cursor+1)
+		require.NoError(t, err)
+		assert.True(t, unknown.Full)
+	})
+}
+
+func TestSQLiteStorage_Conformance(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		keys, err := NewKeyManager(newTestMasterKey(t))
+		require.NoError(t, err)
+		store, err := NewDBStorage(context.Background(), sqliteScheme+filepath.Join(t.TempDir(), "gophkeeper.db"), keys)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = store.Close() })
+		return &store
+	})
+}
+
+func TestSQLiteStorage_InMemory(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		store, err := NewDBStorage(context.Background(), sqliteScheme+":memory:", nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = store.Close() })
+		return &store
+	})
+}
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewMemoryStorage()
+	})
+}
+
+// TestPostgresStorage_Conformance - runs only against Postgres given by TEST_DATABASE_DSN, Data Base is shared
+// with its previous runs, so every check creates own user
+func TestPostgresStorage_Conformance(t *testing.T) {
+	databaseDSN := os.Getenv("TEST_DATABASE_DSN")
+	if databaseDSN == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	testRepository(t, func(t *testing.T) Repository {
+		store, err := NewDBStorage(context.Background(), databaseDSN, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = store.Close() })
+		return &store
+	})
+}
+
+// TestPostgresStorage_SyncOutOfOrderCommits - change of transaction which started first but commits last must not
+// be passed by cursor given to client before its commit
+func TestPostgresStorage_SyncOutOfOrderCommits(t *testing.T) {
+	databaseDSN := os.Getenv("TEST_DATABASE_DSN")
+	if databaseDSN == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	store, err := NewDBStorage(context.Background(), databaseDSN, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	user, err := store.CreateUser(context.Background(), models.NewUser(uuid.NewString()+"@example.com", "password"))
+	require.NoError(t, err)
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, user.ID)
+	require.NoError(t, store.RecordChange(ctx, models.FileItem, "baseline.pdf"))
+	start, err := store.Sync(ctx, 0)
+	require.NoError(t, err)
+	require.Positive(t, start.Cursor)
+
+	insertChange := `INSERT INTO changes (item_type, item_id, user_id) VALUES ($1, $2, $3)`
+	first, err := store.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { _ = first.Rollback() }()
+	_, err = first.ExecContext(ctx, insertChange, models.FileItem, "first.pdf", user.ID)
+	require.NoError(t, err)
+
+	committed := make(chan error, 1)
+	go func() {
+		second, err := store.db.BeginTx(ctx, nil)
+		if err != nil {
+			committed <- err
+			return
+		}
+		if _, err = second.ExecContext(ctx, insertChange, models.FileItem, "second.pdf", user.ID); err != nil {
+			_ = second.Rollback()
+			committed <- err
+			return
+		}
+		committed <- second.Commit()
+	}()
+
+	select {
+	case err = <-committed:
+		require.NoError(t, err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	before, err := store.Sync(ctx, start.Cursor)
+	require.NoError(t, err)
+	assert.False(t, before.Full)
+	assert.Empty(t, before.Files, "changes committed after first uncommitted one are not reported yet")
+
+	require.NoError(t, first.Commit())
+	select {
+	case err = <-committed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("second transaction is not committed")
+	}
+
+	after, err := store.Sync(ctx, before.Cursor)
+	require.NoError(t, err)
+	assert.False(t, after.Full)
+	assert.ElementsMatch(t, []models.File{{Name: "first.pdf"}, {Name: "second.pdf"}}, after.Files)
+}
@@ -2,43 +2,66 @@ package storage
 
 import (
 	"context"
+	"crypto/cipher"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/PaBah/GophKeeper/db"
 	"github.com/PaBah/GophKeeper/internal/config"
 	"github.com/PaBah/GophKeeper/internal/models"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
-	"github.com/jackc/pgerrcode"
-	"github.com/jackc/pgx/v5/pgconn"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/google/uuid"
 )
 
-// DBStorage - model of Repository storage on top of Data Base
+// Item types which metadata is stored in item_metadata table
+const (
+	credentialsItem = models.CredentialsItem
+	cardItem        = models.CardItem
+	noteItem        = models.NoteItem
+)
+
+// DBStorage - model of Repository storage on top of Data Base, Postgres or embedded SQLite
 type DBStorage struct {
-	db *sql.DB
+	db      *sql.DB
+	keys    *KeyManager
+	dialect *dialect
+}
+
+// sqlDialect - dialect of storage Data Base, Postgres unless other one was selected by DSN
+func (ds *DBStorage) sqlDialect() *dialect {
+	if ds.dialect == nil {
+		return &postgresDialect
+	}
+	return ds.dialect
 }
 
 func (ds *DBStorage) initialize(ctx context.Context, databaseDSN string) (err error) {
+	ds.dialect, databaseDSN = dialectOf(databaseDSN)
 
-	ds.db, err = sql.Open("pgx", databaseDSN)
+	ds.db, err = sql.Open(ds.dialect.driver, databaseDSN)
 	if err != nil {
 		return
 	}
+	ds.db.SetMaxOpenConns(ds.dialect.maxOpenConns)
 
-	driver, err := iofs.New(db.MigrationsFS, "migrations")
+	driver, err := iofs.New(db.MigrationsFS, ds.dialect.migrations)
 	if err != nil {
 		return err
 	}
 
-	d, err := postgres.WithInstance(ds.db, &postgres.Config{})
+	d, err := ds.dialect.migrationDriver(ds.db)
 	if err != nil {
 		return err
 	}
 
-	m, err := migrate.NewWithInstance("iofs", driver, "psql_db", d)
+	m, err := migrate.NewWithInstance("iofs", driver, ds.dialect.name, d)
 	if err != nil {
 		return err
 	}
@@ -52,8 +75,7 @@ func (ds *DBStorage) CreateUser(ctx context.Context, user models.User) (createdU
 	_, DBerr := ds.db.ExecContext(ctx,
 		`INSERT INTO users(email, password) VALUES ($1, $2)`, user.Email, user.Password)
 
-	var pgErr *pgconn.PgError
-	if errors.As(DBerr, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+	if ds.sqlDialect().isUniqueViolation(DBerr) {
 		err = ErrAlreadyExists
 		return
 	}
@@ -74,102 +96,490 @@ func (ds *DBStorage) AuthorizeUser(ctx context.Context, email string) (user mode
 	return
 }
 
-// CreateCredentials - create new credentials record
-func (ds *DBStorage) CreateCredentials(ctx context.Context, credentials models.Credentials) (createdCredentials models.Credentials, err error) {
-	createdCredentials = credentials
-	_, DBerr := ds.db.ExecContext(ctx,
-		`INSERT INTO credentials(service_name, identity, password, user_id) VALUES ($1, $2, $3, $4)`,
-		credentials.ServiceName, credentials.Identity, credentials.Password, ctx.Value(config.USERIDCONTEXTKEY).(string))
+// GetUser - return user from context
+func (ds *DBStorage) GetUser(ctx context.Context) (user models.User, err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	row := ds.db.QueryRowContext(ctx, `SELECT email, password FROM users WHERE id=$1`, userID)
+	err = row.Scan(&user.Email, &user.Password)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrNotFound
+	}
+	user.ID = userID
+	return
+}
 
-	var pgErr *pgconn.PgError
-	if errors.As(DBerr, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-		err = ErrAlreadyExists
+// ChangePassword - replace password hash of user and revoke all sessions of user except kept one,
+// IDs of revoked sessions are returned
+func (ds *DBStorage) ChangePassword(ctx context.Context, passwordHash, keepSessionID string) (revoked []string, err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, `UPDATE users SET password=$1 WHERE id=$2`, passwordHash, userID)
+	if err != nil {
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrNotFound
+	}
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM sessions WHERE user_id=$1 and CAST(id AS TEXT)<>$2 RETURNING id`, userID, keepSessionID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	revoked = make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		revoked = append(revoked, id)
+	}
+	return revoked, rows.Err()
+}
+
+// accountTables - tables with rows of user, they are erased in this order along with account. Changes are
+// erased after items, since deletion of items is logged to them.
+var accountTables = []string{
+	"item_metadata", "credentials", "cards", "notes", "credentials_history", "cards_history", "notes_history",
+	"recovery_codes", "changes",
+}
+
+// DeleteAccount - erase user with all items, history and sessions of user, IDs of revoked sessions are returned
+func (ds *DBStorage) DeleteAccount(ctx context.Context) (revoked []string, err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM sessions WHERE user_id=$1 RETURNING id`, userID)
+	if err != nil {
 		return
 	}
+	revoked = make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		revoked = append(revoked, id)
+	}
+	if err = rows.Close(); err != nil {
+		return nil, err
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 
-	row := ds.db.QueryRowContext(ctx, `SELECT id, uploaded_at FROM credentials WHERE service_name=$1 and user_id=$2`,
-		credentials.ServiceName, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	for _, table := range accountTables {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id=$1`, userID); err != nil {
+			return nil, err
+		}
+	}
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id=$1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrNotFound
+	}
+	return revoked, nil
+}
 
-	_ = row.Scan(&createdCredentials.ID, &createdCredentials.UploadedAt)
+// sessionColumns - columns of sessions table scanned by scanSession
+const sessionColumns = `id, user_id, device, client_version, ip, created_at, refreshed_at, last_seen_at, expires_at`
 
+// scanSession - scan session selected by sessionColumns
+func scanSession(row interface{ Scan(dest ...any) error }) (session models.Session, err error) {
+	err = row.Scan(&session.ID, &session.UserID, &session.Device, &session.ClientVersion, &session.IP,
+		&session.CreatedAt, &session.RefreshedAt, &session.LastSeenAt, &session.ExpiresAt)
 	return
 }
 
-// GetCredentials - return list of users Credentials
-func (ds *DBStorage) GetCredentials(ctx context.Context) (credentials []models.Credentials, err error) {
-	var rows *sql.Rows
-	rows, err = ds.db.QueryContext(ctx,
-		`SELECT id, service_name, identity, password, uploaded_at FROM credentials WHERE user_id=$1`,
+// CreateSession - save new session of user with hash of its refresh token
+func (ds *DBStorage) CreateSession(ctx context.Context, session models.Session, tokenHash string) (err error) {
+	_, err = ds.db.ExecContext(ctx,
+		`INSERT INTO sessions(id, user_id, token_hash, device, client_version, ip, last_seen_at, expires_at) `+
+			`VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		session.ID, session.UserID, tokenHash, session.Device, session.ClientVersion, session.IP,
+		ds.sqlDialect().timestamp(session.LastSeenAt), ds.sqlDialect().timestamp(session.ExpiresAt))
+	return
+}
+
+// RefreshSession - replace refresh token of not expired session by new one. Refresh token which was already
+// replaced means it was stolen or leaked, so the session is revoked and ErrNotFound is returned as for unknown token
+func (ds *DBStorage) RefreshSession(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (session models.Session, err error) {
+	row := ds.db.QueryRowContext(ctx,
+		`UPDATE sessions SET previous_token_hash=token_hash, token_hash=$1, refreshed_at=CURRENT_TIMESTAMP, expires_at=$2 `+
+			`WHERE token_hash=$3 and expires_at>CURRENT_TIMESTAMP RETURNING `+sessionColumns,
+		newTokenHash, ds.sqlDialect().timestamp(expiresAt), tokenHash)
+	session, err = scanSession(row)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+
+	if _, err = ds.db.ExecContext(ctx, `DELETE FROM sessions WHERE previous_token_hash=$1`, tokenHash); err != nil {
+		return
+	}
+	return session, ErrNotFound
+}
+
+// TouchSession - record time, address and client version of request made in session, ErrNotFound is returned
+// when session is revoked or expired
+func (ds *DBStorage) TouchSession(ctx context.Context, session models.Session) (err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`UPDATE sessions SET last_seen_at=$1, ip=$2, client_version=$3 `+
+			`WHERE id=$4 and user_id=$5 and expires_at>CURRENT_TIMESTAMP`,
+		ds.sqlDialect().timestamp(session.LastSeenAt), session.IP, session.ClientVersion, session.ID, session.UserID)
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrNotFound
+	}
+	return
+}
+
+// ListSessions - return not expired sessions of user from the most recently seen one
+func (ds *DBStorage) ListSessions(ctx context.Context) (sessions []models.Session, err error) {
+	rows, err := ds.db.QueryContext(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE user_id=$1 and expires_at>CURRENT_TIMESTAMP ORDER BY last_seen_at DESC`,
 		ctx.Value(config.USERIDCONTEXTKEY).(string))
 	if err != nil {
 		return
 	}
-	err = rows.Err()
 	defer rows.Close()
 
-	credentials = make([]models.Credentials, 0)
+	sessions = make([]models.Session, 0)
 	for rows.Next() {
-		var credentialSet models.Credentials
-		err = rows.Scan(&credentialSet.ID, &credentialSet.ServiceName, &credentialSet.Identity, &credentialSet.Password, &credentialSet.UploadedAt)
-		if err != nil {
+		var session models.Session
+		if session, err = scanSession(rows); err != nil {
 			return nil, err
 		}
-		credentials = append(credentials, credentialSet)
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession - delete session of user, so neither its access token nor refresh token is accepted anymore
+func (ds *DBStorage) RevokeSession(ctx context.Context, id string) (err error) {
+	result, err := ds.db.ExecContext(ctx, `DELETE FROM sessions WHERE id=$1 and user_id=$2`,
+		id, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrNotFound
 	}
 	return
 }
 
-// UpdateCredentials - update Credentials model
-func (ds *DBStorage) UpdateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error) {
-	_, err = ds.db.ExecContext(ctx,
-		`UPDATE credentials SET service_name=$1, identity=$2, password=$3 WHERE user_id=$4 and id=$5`,
-		credentials.ServiceName, credentials.Identity, credentials.Password, ctx.Value(config.USERIDCONTEXTKEY).(string), credentials.ID)
-	if err == nil {
-		updatedCredentials = credentials
+// GetVaultParams - return user's vault key derivation parameters, empty if vault is not initialized yet
+func (ds *DBStorage) GetVaultParams(ctx context.Context) (params models.VaultParams, err error) {
+	row := ds.db.QueryRowContext(ctx, `SELECT vault_salt, vault_key_check, vault_key FROM users WHERE id=$1`,
+		ctx.Value(config.USERIDCONTEXTKEY).(string))
+	var salt, keyCheck, wrappedKey sql.NullString
+	err = row.Scan(&salt, &keyCheck, &wrappedKey)
+	if err != nil {
+		return
 	}
+	params = models.VaultParams{Salt: salt.String, KeyCheck: keyCheck.String, WrappedKey: wrappedKey.String}
+	return
+}
 
-	row := ds.db.QueryRowContext(ctx, `SELECT uploaded_at FROM credentials WHERE id=$1 and user_id=$2`,
-		credentials.ID, ctx.Value(config.USERIDCONTEXTKEY).(string))
+// SetVaultParams - save user's vault key derivation parameters, vault can be initialized only once
+func (ds *DBStorage) SetVaultParams(ctx context.Context, params models.VaultParams) (err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`UPDATE users SET vault_salt=$1, vault_key_check=$2 WHERE id=$3 and vault_salt IS NULL`,
+		params.Salt, params.KeyCheck, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrAlreadyExists
+	}
+	return
+}
 
-	_ = row.Scan(&updatedCredentials.UploadedAt)
+// ChangeVaultParams - replace user's vault key derivation parameters if key check was not changed meanwhile
+func (ds *DBStorage) ChangeVaultParams(ctx context.Context, oldKeyCheck string, params models.VaultParams) (err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`UPDATE users SET vault_salt=$1, vault_key_check=$2, vault_key=$3 WHERE id=$4 and vault_key_check=$5`,
+		params.Salt, params.KeyCheck, params.WrappedKey, ctx.Value(config.USERIDCONTEXTKEY).(string), oldKeyCheck)
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrVaultChanged
+	}
+	return
+}
 
+// GetTOTP - return second factor of user, its secret is empty until user enrolls
+func (ds *DBStorage) GetTOTP(ctx context.Context) (totp models.TOTP, err error) {
+	var secret sql.NullString
+	err = ds.db.QueryRowContext(ctx, `SELECT email, totp_secret, totp_enabled FROM users WHERE id=$1`,
+		ctx.Value(config.USERIDCONTEXTKEY).(string)).Scan(&totp.Account, &secret, &totp.Enabled)
+	if err != nil || !secret.Valid {
+		return
+	}
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	totp.Secret = secret.String
+	err = openColumn(aead, userRow(ctx), "totp_secret", &totp.Secret)
 	return
 }
 
-// DeleteCredentials - update Credentials model
-func (ds *DBStorage) DeleteCredentials(ctx context.Context, credentialsID string) (err error) {
-	_, err = ds.db.ExecContext(ctx,
-		`DELETE FROM credentials WHERE user_id=$1 and id=$2`, ctx.Value(config.USERIDCONTEXTKEY).(string), credentialsID)
+// SetTOTP - save not confirmed secret of user's second factor and hashes of recovery codes replacing previous ones,
+// secret can not be replaced while second factor is enabled
+func (ds *DBStorage) SetTOTP(ctx context.Context, secret string, recoveryCodeHashes []string) (err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	if err = sealColumn(aead, userRow(ctx), "totp_secret", &secret); err != nil {
+		return
+	}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, `UPDATE users SET totp_secret=$1 WHERE id=$2 and totp_enabled=FALSE`, secret, userID)
+	if err != nil {
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrAlreadyExists
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return
+	}
+	for _, codeHash := range recoveryCodeHashes {
+		if _, err = tx.ExecContext(ctx, `INSERT INTO recovery_codes(user_id, code_hash) VALUES ($1, $2)`, userID, codeHash); err != nil {
+			return
+		}
+	}
 	return
 }
 
-// CreateCard - create new Card record
-func (ds *DBStorage) CreateCard(ctx context.Context, card models.Card) (createdCard models.Card, err error) {
-	createdCard = card
-	_, DBerr := ds.db.ExecContext(ctx,
-		`INSERT INTO cards(number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5)`,
-		card.Number, card.ExpirationDate, card.HolderName, card.CVV,
+// EnableTOTP - require second factor on sign in of user, whose secret is confirmed
+func (ds *DBStorage) EnableTOTP(ctx context.Context) (err error) {
+	result, err := ds.db.ExecContext(ctx, `UPDATE users SET totp_enabled=TRUE WHERE id=$1 and totp_secret IS NOT NULL`,
 		ctx.Value(config.USERIDCONTEXTKEY).(string))
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrNotFound
+	}
+	return
+}
 
-	var pgErr *pgconn.PgError
-	if errors.As(DBerr, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-		err = ErrAlreadyExists
+// UseRecoveryCode - spend recovery code of user, ErrNotFound is returned for unknown or already used code
+func (ds *DBStorage) UseRecoveryCode(ctx context.Context, codeHash string) (err error) {
+	result, err := ds.db.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id=$1 and code_hash=$2`,
+		ctx.Value(config.USERIDCONTEXTKEY).(string), codeHash)
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err == nil && affected == 0 {
+		err = ErrNotFound
+	}
+	return
+}
+
+// DisableTOTP - forget second factor of user with its recovery codes
+func (ds *DBStorage) DisableTOTP(ctx context.Context) (err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET totp_secret=NULL, totp_enabled=FALSE WHERE id=$1`, userID); err != nil {
 		return
 	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id=$1`, userID)
+	return
+}
 
-	row := ds.db.QueryRowContext(ctx, `SELECT id, uploaded_at FROM cards WHERE number=$1 and user_id=$2`,
-		card.Number, ctx.Value(config.USERIDCONTEXTKEY).(string))
+// signInAttemptsColumns - columns of sign_in_attempts table scanned by scanSignInAttempts
+const signInAttemptsColumns = `key, failures, last_failure_at, locked_until`
 
-	_ = row.Scan(&createdCard.ID, &createdCard.UploadedAt)
+// scanSignInAttempts - scan attempts selected by signInAttemptsColumns, not locked key has no locked_until
+func scanSignInAttempts(row interface{ Scan(dest ...any) error }) (attempts models.SignInAttempts, err error) {
+	var lockedUntil sql.NullTime
+	err = row.Scan(&attempts.Key, &attempts.Failures, &attempts.LastFailureAt, &lockedUntil)
+	attempts.LockedUntil = lockedUntil.Time
+	return
+}
 
+// GetSignInAttempts - return failed sign in attempts of key, key without failures has zero attempts
+func (ds *DBStorage) GetSignInAttempts(ctx context.Context, key string) (attempts models.SignInAttempts, err error) {
+	row := ds.db.QueryRowContext(ctx, `SELECT `+signInAttemptsColumns+` FROM sign_in_attempts WHERE key=$1`, key)
+	attempts, err = scanSignInAttempts(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SignInAttempts{Key: key}, nil
+	}
 	return
 }
 
-// GetCards - return list of users Cards
-func (ds *DBStorage) GetCards(ctx context.Context) (cards []models.Card, err error) {
+// RecordSignInFailure - count failed sign in attempt of key, failures made before resetBefore are forgotten
+func (ds *DBStorage) RecordSignInFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (models.SignInAttempts, error) {
+	row := ds.db.QueryRowContext(ctx,
+		`INSERT INTO sign_in_attempts(key, failures, last_failure_at) VALUES ($1, 1, $2) `+
+			`ON CONFLICT (key) DO UPDATE SET `+
+			`failures=CASE WHEN sign_in_attempts.last_failure_at<$3 THEN 1 ELSE sign_in_attempts.failures+1 END, `+
+			`locked_until=CASE WHEN sign_in_attempts.last_failure_at<$3 THEN NULL ELSE sign_in_attempts.locked_until END, `+
+			`last_failure_at=excluded.last_failure_at RETURNING `+signInAttemptsColumns,
+		key, ds.sqlDialect().timestamp(failedAt), ds.sqlDialect().timestamp(resetBefore))
+	return scanSignInAttempts(row)
+}
+
+// LockSignIn - refuse sign in of key until given time
+func (ds *DBStorage) LockSignIn(ctx context.Context, key string, until time.Time) (err error) {
+	_, err = ds.db.ExecContext(ctx, `UPDATE sign_in_attempts SET locked_until=$1 WHERE key=$2`,
+		ds.sqlDialect().timestamp(until), key)
+	return
+}
+
+// ResetSignInAttempts - forget failed sign in attempts of key, e.g. after successful sign in
+func (ds *DBStorage) ResetSignInAttempts(ctx context.Context, key string) (err error) {
+	_, err = ds.db.ExecContext(ctx, `DELETE FROM sign_in_attempts WHERE key=$1`, key)
+	return
+}
+
+// PurgeSignInAttempts - delete attempts of keys which failed last time and were unlocked before given time
+func (ds *DBStorage) PurgeSignInAttempts(ctx context.Context, before time.Time) (purged int, err error) {
+	result, err := ds.db.ExecContext(ctx,
+		`DELETE FROM sign_in_attempts WHERE last_failure_at<$1 and (locked_until IS NULL or locked_until<$1)`,
+		ds.sqlDialect().timestamp(before))
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// userCipher - return cipher over data key of user from context, data key is generated on first use
+func (ds *DBStorage) userCipher(ctx context.Context) (cipher.AEAD, error) {
+	if ds.keys == nil {
+		return nil, nil
+	}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	var wrappedKey sql.NullString
+	err := ds.db.QueryRowContext(ctx, `SELECT data_key FROM users WHERE id=$1`, userID).Scan(&wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wrappedKey.Valid {
+		newKey, err := ds.keys.newDataKey()
+		if err != nil {
+			return nil, err
+		}
+		_, err = ds.db.ExecContext(ctx, `UPDATE users SET data_key=$1 WHERE id=$2 and data_key IS NULL`, newKey, userID)
+		if err != nil {
+			return nil, err
+		}
+		// data key could be generated by concurrent request, so the stored one is used
+		err = ds.db.QueryRowContext(ctx, `SELECT data_key FROM users WHERE id=$1`, userID).Scan(&wrappedKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ds.keys.dataCipher(userID, wrappedKey.String)
+}
+
+// CreateCredentials - create new credentials record
+func (ds *DBStorage) CreateCredentials(ctx context.Context, credentials models.Credentials) (createdCredentials models.Credentials, err error) {
+	createdCredentials = credentials
+	id := credentials.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	identity, password, totp := credentials.Identity, credentials.Password, credentials.TOTP
+	if err = sealColumns(aead, boundItemRow(ctx, "credentials", id), &identity, &password, &totp); err != nil {
+		return
+	}
+	err = ds.withTx(ctx, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`INSERT INTO credentials(id, service_name, identity, password, totp, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`,
+			id, credentials.ServiceName, identity, password, totp, ctx.Value(config.USERIDCONTEXTKEY).(string))
+
+		DBerr := row.Scan(&createdCredentials.UploadedAt, &createdCredentials.Version)
+		if ds.sqlDialect().isUniqueViolation(DBerr) {
+			return ErrAlreadyExists
+		}
+		if DBerr != nil {
+			return DBerr
+		}
+		createdCredentials.ID = id
+		if len(credentials.Metadata) == 0 {
+			return nil
+		}
+		return ds.saveMetadata(ctx, tx, aead, credentialsItem, createdCredentials.ID, credentials.Metadata)
+	})
+	return
+}
+
+// GetCredentials - return list of users Credentials
+func (ds *DBStorage) GetCredentials(ctx context.Context) (credentials []models.Credentials, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
 	var rows *sql.Rows
 	rows, err = ds.db.QueryContext(ctx,
-		`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at FROM cards WHERE user_id=$1`,
+		`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`,
 		ctx.Value(config.USERIDCONTEXTKEY).(string))
 	if err != nil {
 		return
@@ -177,40 +587,981 @@ func (ds *DBStorage) GetCards(ctx context.Context) (cards []models.Card, err err
 	err = rows.Err()
 	defer rows.Close()
 
-	cards = make([]models.Card, 0)
+	credentials = make([]models.Credentials, 0)
 	for rows.Next() {
-		var card models.Card
-		err = rows.Scan(&card.ID, &card.Number, &card.ExpirationDate, &card.HolderName, &card.CVV, &card.UploadedAt)
+		var credentialSet models.Credentials
+		err = rows.Scan(&credentialSet.ID, &credentialSet.ServiceName, &credentialSet.Identity, &credentialSet.Password, &credentialSet.TOTP,
+			&credentialSet.UploadedAt, &credentialSet.Version)
+		if err == nil {
+			err = openColumns(aead, boundItemRow(ctx, "credentials", credentialSet.ID),
+				&credentialSet.Identity, &credentialSet.Password, &credentialSet.TOTP)
+		}
 		if err != nil {
 			return nil, err
 		}
-		cards = append(cards, card)
+		credentials = append(credentials, credentialSet)
+	}
+
+	metadata, err := ds.loadMetadata(ctx, aead, credentialsItem)
+	if err != nil {
+		return nil, err
+	}
+	for i := range credentials {
+		credentials[i].Metadata = metadata[credentials[i].ID]
 	}
 	return
 }
 
-// UpdateCard - update Card model
-func (ds *DBStorage) UpdateCard(ctx context.Context, card models.Card) (updatedCard models.Card, err error) {
-
-	_, err = ds.db.ExecContext(ctx,
-		`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4 WHERE user_id=$5 and id=$6`,
-		card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), card.ID)
-	if err == nil {
-		updatedCard = card
+// UpdateCredentials - update Credentials model
+func (ds *DBStorage) UpdateCredentials(ctx context.Context, credentials models.Credentials) (updatedCredentials models.Credentials, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
 	}
+	identity, password, totp := credentials.Identity, credentials.Password, credentials.TOTP
+	if err = sealColumns(aead, boundItemRow(ctx, "credentials", credentials.ID), &identity, &password, &totp); err != nil {
+		return
+	}
+	updatedCredentials = credentials
+	err = ds.withRevision(ctx, credentialsItem, credentials.ID, credentials.Version, false, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`UPDATE credentials SET service_name=$1, identity=$2, password=$3, totp=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`,
+			credentials.ServiceName, identity, password, totp, ctx.Value(config.USERIDCONTEXTKEY).(string), credentials.ID, credentials.Version)
 
-	row := ds.db.QueryRowContext(ctx, `SELECT uploaded_at FROM cards WHERE id=$1 and user_id=$2`,
-		card.ID, ctx.Value(config.USERIDCONTEXTKEY).(string))
-
-	_ = row.Scan(&updatedCard.UploadedAt)
+		err := row.Scan(&updatedCredentials.UploadedAt, &updatedCredentials.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		return ds.saveMetadata(ctx, tx, aead, credentialsItem, credentials.ID, credentials.Metadata)
+	})
+	if err != nil {
+		return models.Credentials{}, err
+	}
+	return
+}
 
+// DeleteCredentials - delete Credentials of given version
+func (ds *DBStorage) DeleteCredentials(ctx context.Context, credentialsID string, version int64) (err error) {
+	err = ds.withRevision(ctx, credentialsItem, credentialsID, version, true, func(tx *sql.Tx) error {
+		return checkVersion(tx.ExecContext(ctx,
+			`UPDATE credentials SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`,
+			ctx.Value(config.USERIDCONTEXTKEY).(string), credentialsID, version))
+	})
 	return
 }
 
-// DeleteCard - update Card model
-func (ds *DBStorage) DeleteCard(ctx context.Context, cardID string) (err error) {
-	_, err = ds.db.ExecContext(ctx,
-		`DELETE FROM cards WHERE user_id=$1 and id=$2`, ctx.Value(config.USERIDCONTEXTKEY).(string), cardID)
+// CreateCard - create new Card record
+func (ds *DBStorage) CreateCard(ctx context.Context, card models.Card) (createdCard models.Card, err error) {
+	createdCard = card
+	id := card.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	sealed := card
+	if err = sealColumns(aead, boundItemRow(ctx, "cards", id), &sealed.Number, &sealed.ExpirationDate, &sealed.HolderName, &sealed.CVV); err != nil {
+		return
+	}
+	err = ds.withTx(ctx, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`INSERT INTO cards(id, number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`,
+			id, sealed.Number, sealed.ExpirationDate, sealed.HolderName, sealed.CVV,
+			ctx.Value(config.USERIDCONTEXTKEY).(string))
+
+		DBerr := row.Scan(&createdCard.UploadedAt, &createdCard.Version)
+		if ds.sqlDialect().isUniqueViolation(DBerr) {
+			return ErrAlreadyExists
+		}
+		if DBerr != nil {
+			return DBerr
+		}
+		createdCard.ID = id
+		if len(card.Metadata) == 0 {
+			return nil
+		}
+		return ds.saveMetadata(ctx, tx, aead, cardItem, createdCard.ID, card.Metadata)
+	})
+	return
+}
+
+// GetCards - return list of users Cards
+func (ds *DBStorage) GetCards(ctx context.Context) (cards []models.Card, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	var rows *sql.Rows
+	rows, err = ds.db.QueryContext(ctx,
+		`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at, version FROM cards WHERE user_id=$1 and deleted_at IS NULL`,
+		ctx.Value(config.USERIDCONTEXTKEY).(string))
+	if err != nil {
+		return
+	}
+	err = rows.Err()
+	defer rows.Close()
+
+	cards = make([]models.Card, 0)
+	for rows.Next() {
+		var card models.Card
+		err = rows.Scan(&card.ID, &card.Number, &card.ExpirationDate, &card.HolderName, &card.CVV, &card.UploadedAt, &card.Version)
+		if err == nil {
+			err = openColumns(aead, boundItemRow(ctx, "cards", card.ID), &card.Number, &card.ExpirationDate, &card.HolderName, &card.CVV)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	metadata, err := ds.loadMetadata(ctx, aead, cardItem)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cards {
+		cards[i].Metadata = metadata[cards[i].ID]
+	}
+	return
+}
+
+// UpdateCard - update Card model
+func (ds *DBStorage) UpdateCard(ctx context.Context, card models.Card) (updatedCard models.Card, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	sealed := card
+	if err = sealColumns(aead, boundItemRow(ctx, "cards", card.ID), &sealed.Number, &sealed.ExpirationDate, &sealed.HolderName, &sealed.CVV); err != nil {
+		return
+	}
+	updatedCard = card
+	err = ds.withRevision(ctx, cardItem, card.ID, card.Version, false, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`,
+			sealed.Number, sealed.ExpirationDate, sealed.HolderName, sealed.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), card.ID, card.Version)
+
+		err := row.Scan(&updatedCard.UploadedAt, &updatedCard.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		return ds.saveMetadata(ctx, tx, aead, cardItem, card.ID, card.Metadata)
+	})
+	if err != nil {
+		return models.Card{}, err
+	}
+	return
+}
+
+// DeleteCard - delete Card of given version
+func (ds *DBStorage) DeleteCard(ctx context.Context, cardID string, version int64) (err error) {
+	err = ds.withRevision(ctx, cardItem, cardID, version, true, func(tx *sql.Tx) error {
+		return checkVersion(tx.ExecContext(ctx,
+			`UPDATE cards SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`,
+			ctx.Value(config.USERIDCONTEXTKEY).(string), cardID, version))
+	})
+	return
+}
+
+// CreateNote - create new Note record
+func (ds *DBStorage) CreateNote(ctx context.Context, note models.Note) (createdNote models.Note, err error) {
+	createdNote = note
+	id := note.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	body := note.Body
+	if err = sealColumns(aead, boundItemRow(ctx, "notes", id), &body); err != nil {
+		return
+	}
+	err = ds.withTx(ctx, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`INSERT INTO notes(id, title, body, user_id) VALUES ($1, $2, $3, $4) RETURNING uploaded_at, version`,
+			id, note.Title, body, ctx.Value(config.USERIDCONTEXTKEY).(string))
+
+		err := row.Scan(&createdNote.UploadedAt, &createdNote.Version)
+		if ds.sqlDialect().isUniqueViolation(err) {
+			return ErrAlreadyExists
+		}
+		if err != nil {
+			return err
+		}
+		createdNote.ID = id
+		if len(note.Metadata) == 0 {
+			return nil
+		}
+		return ds.saveMetadata(ctx, tx, aead, noteItem, createdNote.ID, note.Metadata)
+	})
+	return
+}
+
+// GetNotes - return list of users Notes
+func (ds *DBStorage) GetNotes(ctx context.Context) (notes []models.Note, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	var rows *sql.Rows
+	rows, err = ds.db.QueryContext(ctx,
+		`SELECT id, title, body, uploaded_at, version FROM notes WHERE user_id=$1 and deleted_at IS NULL`,
+		ctx.Value(config.USERIDCONTEXTKEY).(string))
+	if err != nil {
+		return
+	}
+	err = rows.Err()
+	defer rows.Close()
+
+	notes = make([]models.Note, 0)
+	for rows.Next() {
+		var note models.Note
+		err = rows.Scan(&note.ID, &note.Title, &note.Body, &note.UploadedAt, &note.Version)
+		if err == nil {
+			err = openColumns(aead, boundItemRow(ctx, "notes", note.ID), &note.Body)
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	metadata, err := ds.loadMetadata(ctx, aead, noteItem)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notes {
+		notes[i].Metadata = metadata[notes[i].ID]
+	}
+	return
+}
+
+// UpdateNote - update Note model
+func (ds *DBStorage) UpdateNote(ctx context.Context, note models.Note) (updatedNote models.Note, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	body := note.Body
+	if err = sealColumns(aead, boundItemRow(ctx, "notes", note.ID), &body); err != nil {
+		return
+	}
+	updatedNote = note
+	err = ds.withRevision(ctx, noteItem, note.ID, note.Version, false, func(tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx,
+			`UPDATE notes SET title=$1, body=$2, uploaded_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$3 and id=$4 and version=$5 RETURNING uploaded_at, version`,
+			note.Title, body, ctx.Value(config.USERIDCONTEXTKEY).(string), note.ID, note.Version)
+
+		err := row.Scan(&updatedNote.UploadedAt, &updatedNote.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVersionConflict
+		}
+		if err != nil {
+			return err
+		}
+		return ds.saveMetadata(ctx, tx, aead, noteItem, note.ID, note.Metadata)
+	})
+	return
+}
+
+// DeleteNote - delete user's Note of given version
+func (ds *DBStorage) DeleteNote(ctx context.Context, noteID string, version int64) (err error) {
+	err = ds.withRevision(ctx, noteItem, noteID, version, true, func(tx *sql.Tx) error {
+		return checkVersion(tx.ExecContext(ctx,
+			`UPDATE notes SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`,
+			ctx.Value(config.USERIDCONTEXTKEY).(string), noteID, version))
+	})
+	return
+}
+
+// checkVersion - report conflict when statement guarded by item version changed nothing,
+// so item was changed or deleted since client read it
+func checkVersion(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// itemTable - table of item type, table keeping its previous revisions and data columns copied to history
+type itemTable struct {
+	table   string
+	history string
+	columns []string
+	// item - build item from opened values of data columns
+	item func(id string, version int64, uploadedAt time.Time, metadata map[string]string, values []string) any
+}
+
+// trashItemTypes - item types which are moved to trash on deletion, in order they are listed in trash
+var trashItemTypes = []string{credentialsItem, cardItem, noteItem}
+
+// itemTables - tables of item types which keep revisions and are moved to trash on deletion
+var itemTables = map[string]itemTable{
+	credentialsItem: {table: "credentials", history: "credentials_history",
+		columns: []string{"service_name", "identity", "password", "totp"},
+		item: func(id string, version int64, uploadedAt time.Time, metadata map[string]string, values []string) any {
+			return models.Credentials{ID: id, ServiceName: values[0], Identity: values[1], Password: values[2], TOTP: values[3],
+				UploadedAt: uploadedAt, Version: version, Metadata: metadata}
+		}},
+	cardItem: {table: "cards", history: "cards_history",
+		columns: []string{"number", "expiration_date", "holder_name", "cvv"},
+		item: func(id string, version int64, uploadedAt time.Time, metadata map[string]string, values []string) any {
+			return models.Card{ID: id, Number: values[0], ExpirationDate: values[1], HolderName: values[2], CVV: values[3],
+				UploadedAt: uploadedAt, Version: version, Metadata: metadata}
+		}},
+	noteItem: {table: "notes", history: "notes_history",
+		columns: []string{"title", "body"},
+		item: func(id string, version int64, uploadedAt time.Time, metadata map[string]string, values []string) any {
+			return models.Note{ID: id, Title: values[0], Body: values[1],
+				UploadedAt: uploadedAt, Version: version, Metadata: metadata}
+		}},
+}
+
+// withRevision - copy item of given version with its metadata to history and apply change to item in the same transaction,
+// ErrVersionConflict is returned when item was changed or deleted since client read it
+func (ds *DBStorage) withRevision(ctx context.Context, itemType, itemID string, version int64, deleted bool, change func(tx *sql.Tx) error) (err error) {
+	history, ok := itemTables[itemType]
+	if !ok {
+		return ErrUnknownItemType
+	}
+	columns := strings.Join(history.columns, ", ")
+	return ds.withTx(ctx, func(tx *sql.Tx) error {
+		err := checkVersion(tx.ExecContext(ctx, fmt.Sprintf(
+			`INSERT INTO %s(item_id, %s, metadata, version, uploaded_at, deleted, user_id) `+
+				`SELECT id, %s, (SELECT %s(key, value) FROM item_metadata WHERE item_id=%s.id), version, uploaded_at, $1, user_id `+
+				`FROM %s WHERE user_id=$2 and id=$3 and version=$4 and deleted_at IS NULL`,
+			history.history, columns, columns, ds.sqlDialect().jsonObjectAgg, history.table, history.table),
+			deleted, ctx.Value(config.USERIDCONTEXTKEY).(string), itemID, version))
+		if err != nil {
+			return err
+		}
+		return change(tx)
+	})
+}
+
+// withTx - run given function in transaction, which is committed only when function succeeds
+func (ds *DBStorage) withTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(tx)
+}
+
+// ListItemHistory - return previous revisions of user's item from the newest to the oldest one
+func (ds *DBStorage) ListItemHistory(ctx context.Context, itemType, itemID string) (revisions []models.Revision, err error) {
+	history, ok := itemTables[itemType]
+	if !ok {
+		return nil, ErrUnknownItemType
+	}
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	rows, err := ds.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, %s, metadata, version, uploaded_at, revised_at, deleted FROM %s WHERE user_id=$1 and item_id=$2 ORDER BY version DESC, revised_at DESC`,
+		strings.Join(history.columns, ", "), history.history), userID, itemID)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	revisions = make([]models.Revision, 0)
+	for rows.Next() {
+		revision := models.Revision{ItemID: itemID, ItemType: itemType}
+		values := make([]string, len(history.columns))
+		var sealedMetadata sql.NullString
+		var uploadedAt time.Time
+		dest := []any{&revision.ID}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &sealedMetadata, &revision.Version, &uploadedAt, &revision.RevisedAt, &revision.Deleted)
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if err = history.open(aead, boundItemRow(ctx, history.table, itemID), values); err != nil {
+			return nil, err
+		}
+		var metadata map[string]string
+		if sealedMetadata.Valid {
+			if err = json.Unmarshal([]byte(sealedMetadata.String), &metadata); err != nil {
+				return nil, err
+			}
+			for key, value := range metadata {
+				if err = openColumn(aead, metadataRow(itemID, key, userID), "value", &value); err != nil {
+					return nil, err
+				}
+				metadata[key] = value
+			}
+		}
+		revision.Item = history.item(itemID, revision.Version, uploadedAt, metadata, values)
+		revisions = append(revisions, revision)
+	}
+	err = rows.Err()
+	return
+}
+
+// RestoreItemRevision - replace user's item of given version by its previous revision, restore is saved as
+// a new version of item, so current state stays in history as well
+func (ds *DBStorage) RestoreItemRevision(ctx context.Context, itemType, itemID, revisionID string, version int64) (restoredVersion int64, err error) {
+	revisions, err := ds.ListItemHistory(ctx, itemType, itemID)
+	if err != nil {
+		return
+	}
+	idx := slices.IndexFunc(revisions, func(revision models.Revision) bool { return revision.ID == revisionID })
+	if idx < 0 {
+		return 0, ErrNotFound
+	}
+
+	switch item := revisions[idx].Item.(type) {
+	case models.Credentials:
+		item.Version = version
+		item, err = ds.UpdateCredentials(ctx, item)
+		restoredVersion = item.Version
+	case models.Card:
+		item.Version = version
+		item, err = ds.UpdateCard(ctx, item)
+		restoredVersion = item.Version
+	case models.Note:
+		item.Version = version
+		item, err = ds.UpdateNote(ctx, item)
+		restoredVersion = item.Version
+	}
+	return
+}
+
+// ListTrash - return user's deleted credentials, cards and notes from the most recently deleted ones
+func (ds *DBStorage) ListTrash(ctx context.Context) (items []models.TrashItem, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	items = make([]models.TrashItem, 0)
+	for _, itemType := range trashItemTypes {
+		table := itemTables[itemType]
+		var rows *sql.Rows
+		rows, err = ds.db.QueryContext(ctx, fmt.Sprintf(
+			`SELECT id, %s, uploaded_at, version, deleted_at FROM %s WHERE user_id=$1 and deleted_at IS NOT NULL ORDER BY deleted_at DESC`,
+			strings.Join(table.columns, ", "), table.table),
+			ctx.Value(config.USERIDCONTEXTKEY).(string))
+		if err != nil {
+			return nil, err
+		}
+
+		type trashedRow struct {
+			id                    string
+			version               int64
+			uploadedAt, deletedAt time.Time
+			values                []string
+		}
+		var trashed []trashedRow
+		for rows.Next() {
+			row := trashedRow{values: make([]string, len(table.columns))}
+			dest := []any{&row.id}
+			for i := range row.values {
+				dest = append(dest, &row.values[i])
+			}
+			dest = append(dest, &row.uploadedAt, &row.version, &row.deletedAt)
+			if err = rows.Scan(dest...); err == nil {
+				err = table.open(aead, boundItemRow(ctx, table.table, row.id), row.values)
+			}
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			trashed = append(trashed, row)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var metadata map[string]map[string]string
+		metadata, err = ds.loadMetadata(ctx, aead, itemType)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range trashed {
+			items = append(items, models.TrashItem{
+				ItemType:  itemType,
+				DeletedAt: row.deletedAt,
+				Item:      table.item(row.id, row.version, row.uploadedAt, metadata[row.id], row.values),
+			})
+		}
+	}
+	return
+}
+
+// RestoreFromTrash - return user's deleted item back, restore is saved as a new version of item
+func (ds *DBStorage) RestoreFromTrash(ctx context.Context, itemType, itemID string) (err error) {
+	table, ok := itemTables[itemType]
+	if !ok {
+		return ErrUnknownItemType
+	}
+	err = checkVersion(ds.db.ExecContext(ctx, fmt.Sprintf(
+		`UPDATE %s SET deleted_at=NULL, version=version+1 WHERE user_id=$1 and id=$2 and deleted_at IS NOT NULL`, table.table),
+		ctx.Value(config.USERIDCONTEXTKEY).(string), itemID))
+	if errors.Is(err, ErrVersionConflict) {
+		err = ErrNotFound
+	}
+	return
+}
+
+// EmptyTrash - permanently delete all user's items from trash
+func (ds *DBStorage) EmptyTrash(ctx context.Context) (err error) {
+	_, err = ds.purgeTrash(ctx, `user_id=$1`, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	return
+}
+
+// PurgeTrash - permanently delete items of all users which were deleted before given time
+func (ds *DBStorage) PurgeTrash(ctx context.Context, before time.Time) (purged int, err error) {
+	return ds.purgeTrash(ctx, `deleted_at<$1`, ds.sqlDialect().timestamp(before))
+}
+
+// purgeTrash - permanently delete trashed items matching condition together with their metadata and history
+func (ds *DBStorage) purgeTrash(ctx context.Context, condition string, args ...any) (purged int, err error) {
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	for _, itemType := range trashItemTypes {
+		table := itemTables[itemType]
+		trashed := fmt.Sprintf(`SELECT id FROM %s WHERE deleted_at IS NOT NULL and %s`, table.table, condition)
+		if _, err = tx.ExecContext(ctx, `DELETE FROM item_metadata WHERE item_id IN (`+trashed+`)`, args...); err != nil {
+			return
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE item_id IN (%s)`, table.history, trashed), args...); err != nil {
+			return
+		}
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE deleted_at IS NOT NULL and %s`, table.table, condition), args...)
+		if err != nil {
+			return
+		}
+		affected, _ := result.RowsAffected()
+		purged += int(affected)
+	}
+	return
+}
+
+// RecordChange - log change of item which is not kept in data base, changes of credentials, cards, notes
+// and their metadata are logged by triggers
+func (ds *DBStorage) RecordChange(ctx context.Context, itemType, itemID string) (err error) {
+	_, err = ds.db.ExecContext(ctx, `INSERT INTO changes (item_type, item_id, user_id) VALUES ($1, $2, $3)`,
+		itemType, itemID, ctx.Value(config.USERIDCONTEXTKEY).(string))
+	return
+}
+
+// Sync - current state of user's items changed since cursor, items which do not exist anymore or are in trash
+// are reported as deleted. Cursor which is not known to server requests full copy of vault. Changed files are
+// reported by name only as they are kept out of data base. Cursor is never above seq of uncommitted change: on
+// Postgres writers of user's changes are serialised until commit (see 000017_serialize_changes), SQLite has single
+// writer.
+func (ds *DBStorage) Sync(ctx context.Context, cursor int64) (changes models.ChangeSet, err error) {
+	aead, err := ds.userCipher(ctx)
+	if err != nil {
+		return
+	}
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	err = ds.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM changes WHERE user_id=$1`, userID).Scan(&changes.Cursor)
+	if err != nil {
+		return
+	}
+	changes.Full = cursor <= 0 || cursor > changes.Cursor
+
+	changed := make(map[string][]string)
+	if !changes.Full {
+		var rows *sql.Rows
+		rows, err = ds.db.QueryContext(ctx,
+			`SELECT item_type, item_id FROM changes WHERE user_id=$1 and seq>$2 GROUP BY item_type, item_id ORDER BY MAX(seq)`,
+			userID, cursor)
+		if err != nil {
+			return
+		}
+		for rows.Next() {
+			var change models.Change
+			if err = rows.Scan(&change.ItemType, &change.ItemID); err != nil {
+				rows.Close()
+				return
+			}
+			if change.ItemType == models.FileItem {
+				changes.Files = append(changes.Files, models.File{Name: change.ItemID})
+				continue
+			}
+			changed[change.ItemType] = append(changed[change.ItemType], change.ItemID)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	for _, itemType := range trashItemTypes {
+		if !changes.Full && len(changed[itemType]) == 0 {
+			continue
+		}
+		condition, args := `user_id=$1 and deleted_at IS NULL`, []any{userID}
+		if !changes.Full {
+			condition += ` and CAST(id AS TEXT) IN (SELECT item_id FROM changes WHERE user_id=$1 and item_type=$2 and seq>$3)`
+			args = append(args, itemType, cursor)
+		}
+		var items []any
+		items, err = ds.queryItems(ctx, aead, itemType, condition, args...)
+		if err != nil {
+			return
+		}
+		present := make(map[string]bool, len(items))
+		for _, item := range items {
+			switch item := item.(type) {
+			case models.Credentials:
+				present[item.ID] = true
+				changes.Credentials = append(changes.Credentials, item)
+			case models.Card:
+				present[item.ID] = true
+				changes.Cards = append(changes.Cards, item)
+			case models.Note:
+				present[item.ID] = true
+				changes.Notes = append(changes.Notes, item)
+			}
+		}
+		for _, id := range changed[itemType] {
+			if !present[id] {
+				changes.Deleted = append(changes.Deleted, models.Change{ItemType: itemType, ItemID: id})
+			}
+		}
+	}
+	return
+}
+
+// queryItems - user's items of given type matching condition with their metadata
+func (ds *DBStorage) queryItems(ctx context.Context, aead cipher.AEAD, itemType, condition string, args ...any) (items []any, err error) {
+	table := itemTables[itemType]
+	rows, err := ds.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s, uploaded_at, version FROM %s WHERE %s`,
+		strings.Join(table.columns, ", "), table.table, condition), args...)
+	if err != nil {
+		return
+	}
+
+	type itemRow struct {
+		id         string
+		version    int64
+		uploadedAt time.Time
+		values     []string
+	}
+	var found []itemRow
+	for rows.Next() {
+		row := itemRow{values: make([]string, len(table.columns))}
+		dest := []any{&row.id}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+		dest = append(dest, &row.uploadedAt, &row.version)
+		if err = rows.Scan(dest...); err == nil {
+			err = table.open(aead, boundItemRow(ctx, table.table, row.id), row.values)
+		}
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found = append(found, row)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil || len(found) == 0 {
+		return
+	}
+
+	metadata, err := ds.loadMetadata(ctx, aead, itemType)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range found {
+		items = append(items, table.item(row.id, row.version, row.uploadedAt, metadata[row.id], row.values))
+	}
+	return
+}
+
+// open - decrypt values of data columns of item row which are encrypted by user's data key
+func (table itemTable) open(aead cipher.AEAD, row boundRow, values []string) error {
+	for i, column := range table.columns {
+		if isEncryptedColumn(table.table, column) {
+			if err := openColumn(aead, row, column, &values[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isEncryptedColumn - report if column of table is encrypted by user's data key
+func isEncryptedColumn(table, column string) bool {
+	return slices.Contains(encryptedColumnsOf(table), column)
+}
+
+// saveMetadata - replace metadata of item by given one within transaction of item change,
+// values are encrypted as other sensitive columns
+func (ds *DBStorage) saveMetadata(ctx context.Context, tx *sql.Tx, aead cipher.AEAD, itemType, itemID string, metadata map[string]string) (err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	_, err = tx.ExecContext(ctx, `DELETE FROM item_metadata WHERE user_id=$1 and item_id=$2`, userID, itemID)
+	if err != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := metadata[key]
+		if err = sealColumn(aead, metadataRow(itemID, key, userID), "value", &value); err != nil {
+			return
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO item_metadata(item_id, item_type, key, value, user_id) VALUES ($1, $2, $3, $4, $5)`,
+			itemID, itemType, key, value, userID)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// loadMetadata - return metadata of all user's items of given type grouped by item ID
+func (ds *DBStorage) loadMetadata(ctx context.Context, aead cipher.AEAD, itemType string) (metadata map[string]map[string]string, err error) {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	rows, err := ds.db.QueryContext(ctx,
+		`SELECT item_id, key, value FROM item_metadata WHERE user_id=$1 and item_type=$2`, userID, itemType)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	metadata = make(map[string]map[string]string)
+	for rows.Next() {
+		var itemID, key, value string
+		err = rows.Scan(&itemID, &key, &value)
+		if err == nil {
+			err = openColumn(aead, metadataRow(itemID, key, userID), "value", &value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if metadata[itemID] == nil {
+			metadata[itemID] = make(map[string]string)
+		}
+		metadata[itemID][key] = value
+	}
+	err = rows.Err()
+	return
+}
+
+// RotateMasterKey - re-wrap data keys of all users by current master key, encrypted columns are not touched,
+// so servers configured with both keys keep working while rotation is in progress
+func (ds *DBStorage) RotateMasterKey(ctx context.Context) (rotated int, err error) {
+	if ds.keys == nil {
+		return 0, ErrNoMasterKey
+	}
+	rows, err := ds.db.QueryContext(ctx, `SELECT id, data_key FROM users WHERE data_key IS NOT NULL`)
+	if err != nil {
+		return
+	}
+	wrappedKeys := make(map[string]string)
+	for rows.Next() {
+		var userID, wrappedKey string
+		if err = rows.Scan(&userID, &wrappedKey); err != nil {
+			rows.Close()
+			return
+		}
+		wrappedKeys[userID] = wrappedKey
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return
+	}
+
+	for userID, wrappedKey := range wrappedKeys {
+		if ds.keys.isCurrent(wrappedKey) {
+			continue
+		}
+		var rewrapped string
+		rewrapped, err = ds.keys.rewrap(wrappedKey)
+		if err != nil {
+			return
+		}
+		var result sql.Result
+		result, err = ds.db.ExecContext(ctx, `UPDATE users SET data_key=$1 WHERE id=$2 and data_key=$3`,
+			rewrapped, userID, wrappedKey)
+		if err != nil {
+			return
+		}
+		affected, _ := result.RowsAffected()
+		rotated += int(affected)
+	}
+	return
+}
+
+// boundItemRow - row of item of user from context, sealed values of item are bound to it
+func boundItemRow(ctx context.Context, table, id string) boundRow {
+	return boundRow{table: table, id: id, userID: ctx.Value(config.USERIDCONTEXTKEY).(string)}
+}
+
+// userRow - row of user from context, sealed values of account are bound to it
+func userRow(ctx context.Context) boundRow {
+	userID := ctx.Value(config.USERIDCONTEXTKEY).(string)
+	return boundRow{table: "users", id: userID, userID: userID}
+}
+
+// encryptedTable - table with sensitive columns, their values are bound to row of boundTable with ID selected by rowID
+type encryptedTable struct {
+	table      string
+	columns    []string
+	boundTable string
+	rowID      string
+}
+
+// encryptedColumns - sensitive columns of tables which are encrypted by user's data key. Values are bound to table
+// and row given by rowID expression, revisions are copied from items as they are, so they stay bound to their items.
+var encryptedColumns = []encryptedTable{
+	{table: "credentials", columns: []string{"identity", "password", "totp"}, boundTable: "credentials", rowID: "id"},
+	{table: "cards", columns: []string{"number", "expiration_date", "holder_name", "cvv"}, boundTable: "cards", rowID: "id"},
+	{table: "notes", columns: []string{"body"}, boundTable: "notes", rowID: "id"},
+	{table: "item_metadata", columns: []string{"value"}, boundTable: "item_metadata",
+		rowID: "CAST(item_id AS TEXT) || '/' || key"},
+	{table: "credentials_history", columns: []string{"identity", "password", "totp"}, boundTable: "credentials", rowID: "item_id"},
+	{table: "cards_history", columns: []string{"number", "expiration_date", "holder_name", "cvv"}, boundTable: "cards", rowID: "item_id"},
+	{table: "notes_history", columns: []string{"body"}, boundTable: "notes", rowID: "item_id"},
+}
+
+// encryptedColumnsOf - encrypted columns of table in order their values are passed to sealColumns and openColumns
+func encryptedColumnsOf(table string) []string {
+	for _, encrypted := range encryptedColumns {
+		if encrypted.table == table {
+			return encrypted.columns
+		}
+	}
+	return nil
+}
+
+// EncryptPlaintextRows - encrypt sensitive columns of rows which were stored before encryption was enabled,
+// values sealed before they were bound to their rows are sealed again bound
+func (ds *DBStorage) EncryptPlaintextRows(ctx context.Context) (encrypted int, err error) {
+	if ds.keys == nil {
+		return 0, ErrNoMasterKey
+	}
+	for _, table := range encryptedColumns {
+		var count int
+		count, err = ds.encryptPlaintextRows(ctx, table)
+		encrypted += count
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (ds *DBStorage) encryptPlaintextRows(ctx context.Context, table encryptedTable) (encrypted int, err error) {
+	rows, err := ds.db.QueryContext(ctx,
+		fmt.Sprintf(`SELECT id, user_id, %s, %s FROM %s`, table.rowID, strings.Join(table.columns, ", "), table.table))
+	if err != nil {
+		return
+	}
+	type plaintextRow struct {
+		id     string
+		bound  boundRow
+		values []string
+	}
+	var plaintextRows []plaintextRow
+	for rows.Next() {
+		row := plaintextRow{bound: boundRow{table: table.boundTable}, values: make([]string, len(table.columns))}
+		dest := []any{&row.id, &row.bound.userID, &row.bound.id}
+		for i := range row.values {
+			dest = append(dest, &row.values[i])
+		}
+		if err = rows.Scan(dest...); err != nil {
+			rows.Close()
+			return
+		}
+		for _, value := range row.values {
+			if !isBoundColumn(value) {
+				plaintextRows = append(plaintextRows, row)
+				break
+			}
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return
+	}
+
+	assignments := make([]string, len(table.columns))
+	for i, column := range table.columns {
+		assignments[i] = fmt.Sprintf("%s=$%d", column, i+1)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id=$%d and user_id=$%d`,
+		table.table, strings.Join(assignments, ", "), len(table.columns)+1, len(table.columns)+2)
+
+	for _, row := range plaintextRows {
+		var aead cipher.AEAD
+		aead, err = ds.userCipher(context.WithValue(ctx, config.USERIDCONTEXTKEY, row.bound.userID))
+		if err != nil {
+			return
+		}
+		args := make([]any, 0, len(table.columns)+2)
+		for i, column := range table.columns {
+			if !isBoundColumn(row.values[i]) {
+				if err = openColumn(aead, row.bound, column, &row.values[i]); err != nil {
+					return
+				}
+				if err = sealColumn(aead, row.bound, column, &row.values[i]); err != nil {
+					return
+				}
+			}
+			args = append(args, row.values[i])
+		}
+		args = append(args, row.id, row.bound.userID)
+		if _, err = ds.db.ExecContext(ctx, query, args...); err != nil {
+			return
+		}
+		encrypted++
+	}
 	return
 }
 
@@ -219,9 +1570,10 @@ func (ds *DBStorage) Close() error {
 	return ds.db.Close()
 }
 
-// NewDBStorage - create instance of DBStorage
-func NewDBStorage(ctx context.Context, databaseDSN string) (DBStorage, error) {
-	store := DBStorage{}
+// NewDBStorage - create instance of DBStorage, sensitive columns are encrypted when keys are passed.
+// DSN of "sqlite://path/to/file.db" form selects embedded SQLite Data Base, any other DSN is passed to Postgres
+func NewDBStorage(ctx context.Context, databaseDSN string, keys *KeyManager) (DBStorage, error) {
+	store := DBStorage{keys: keys}
 	err := store.initialize(ctx, databaseDSN)
 	return store, err
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
@@ -36,7 +37,7 @@ func TestNewDBStorage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			st, err := NewDBStorage(ctx, tt.databaseDSN)
+			st, err := NewDBStorage(ctx, tt.databaseDSN, nil)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewDBStorage() error = %v, wantErr %v", err, tt.wantErr)
@@ -50,16 +51,44 @@ func TestNewDBStorage(t *testing.T) {
 	}
 }
 
+func expectLoadMetadata(mock sqlmock.Sqlmock, itemType string) *sqlmock.ExpectedQuery {
+	return mock.ExpectQuery(regexp.QuoteMeta(`SELECT item_id, key, value FROM item_metadata WHERE user_id=$1 and item_type=$2`)).
+		WithArgs("test", itemType)
+}
+
+// expectSaveMetadata - expect replacement of item metadata within transaction of item change,
+// metadata is passed as sorted key, value pairs
+func expectSaveMetadata(mock sqlmock.Sqlmock, itemID string, metadata ...string) {
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM item_metadata WHERE user_id=$1 and item_id=$2`)).
+		WithArgs("test", itemID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	for i := 0; i+1 < len(metadata); i += 2 {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO item_metadata(item_id, item_type, key, value, user_id) VALUES ($1, $2, $3, $4, $5)`)).
+			WithArgs(itemID, sqlmock.AnyArg(), metadata[i], metadata[i+1], "test").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+}
+
+// expectRevision - expect item of given version to be copied to history table at start of transaction
+func expectRevision(mock sqlmock.Sqlmock, history, itemID string, version int, deleted bool) *sqlmock.ExpectedExec {
+	mock.ExpectBegin()
+	return mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO `+history+`(item_id, `)).
+		WithArgs(deleted, "test", itemID, version).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
 func TestDBStorage_DeleteShortURLs(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	ds := &DBStorage{
 		db: db,
 	}
-	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM cards WHERE user_id=$1 and id=$2`)).
-		WithArgs("test", "test").
+	expectRevision(mock, "cards_history", "test", 1, true)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`)).
+		WithArgs("test", "test", 1).
 		WillReturnResult(sqlmock.NewResult(1, 1)).
 		WillReturnError(nil)
-	err := ds.DeleteCard(context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test"), "test")
+	mock.ExpectCommit()
+	err := ds.DeleteCard(context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test"), "test", 1)
 	assert.NoError(t, err, "successfully deleted card")
 }
 
@@ -71,16 +100,38 @@ func TestDBStorage_UpdateCard(t *testing.T) {
 	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
 	card := models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123")
 	card.ID = "1"
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4 WHERE user_id=$5 and id=$6`)).
-		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), "1").
-		WillReturnResult(sqlmock.NewResult(1, 1)).
-		WillReturnError(nil)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT uploaded_at FROM cards WHERE id=$1 and user_id=$2`)).
-		WithArgs(1, "test").
-		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at"}).
-			AddRow(time.Now()))
+	expectRevision(mock, "cards_history", "1", 0, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), "1", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).
+			AddRow(time.Now(), 1))
+	expectSaveMetadata(mock, "1")
+	mock.ExpectCommit()
 	_, err := ds.UpdateCard(ctx, card)
 	assert.NoError(t, err, "successfully updated card")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_UpdateCard_MetadataError(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{
+		db: db,
+	}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	card := models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123")
+	card.ID = "1"
+	card.Metadata = map[string]string{"bank": "Tinkoff"}
+	expectRevision(mock, "cards_history", "1", 0, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).
+			AddRow(time.Now(), 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM item_metadata WHERE user_id=$1 and item_id=$2`)).
+		WithArgs("test", "1").
+		WillReturnError(fmt.Errorf("an error"))
+	mock.ExpectRollback()
+	_, err := ds.UpdateCard(ctx, card)
+	assert.Error(t, err, "metadata error should be returned")
+	assert.NoError(t, mock.ExpectationsWereMet(), "item change should be rolled back with metadata")
 }
 
 func TestDBStorage_UpdateCard_Error(t *testing.T) {
@@ -90,9 +141,11 @@ func TestDBStorage_UpdateCard_Error(t *testing.T) {
 	}
 	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
 	card := models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123")
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4 WHERE user_id=$5 and id=$6`)).
-		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), 1).
+	expectRevision(mock, "cards_history", "", 0, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), "", 0).
 		WillReturnError(fmt.Errorf("an error"))
+	mock.ExpectRollback()
 	_, err := ds.UpdateCard(ctx, card)
 	assert.NotNil(t, err, "error should occur")
 }
@@ -104,11 +157,13 @@ func TestDBStorage_UpdateCard_NoRowsAffected(t *testing.T) {
 	}
 	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
 	card := models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123")
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4 WHERE user_id=$5 and id=$6`)).
-		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), 1).
-		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectRevision(mock, "cards_history", "", 0, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), "", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}))
+	mock.ExpectRollback()
 	_, err := ds.UpdateCard(ctx, card)
-	assert.NotNil(t, err, "error should occur because no rows were affected")
+	assert.ErrorIs(t, err, ErrVersionConflict, "error should occur because no rows were affected")
 }
 
 func TestDBStorage_UpdateCard_ScanError(t *testing.T) {
@@ -118,14 +173,12 @@ func TestDBStorage_UpdateCard_ScanError(t *testing.T) {
 	}
 	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
 	card := models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123")
-	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4 WHERE user_id=$5 and id=$6`)).
-		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), 1).
-		WillReturnResult(sqlmock.NewResult(1, 1)).
-		WillReturnError(nil)
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT uploaded_at FROM cards WHERE id=$1 and user_id=$2`)).
-		WithArgs(1, "test").
-		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at"}).
-			AddRow("string-instead-of-time"))
+	expectRevision(mock, "cards_history", "", 0, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WithArgs(card.Number, card.ExpirationDate, card.HolderName, card.CVV, ctx.Value(config.USERIDCONTEXTKEY).(string), "", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).
+			AddRow("string-instead-of-time", 1))
+	mock.ExpectRollback()
 	_, err := ds.UpdateCard(ctx, card)
 	assert.NotNil(t, err, "should throw error on incorrect row scan")
 }
@@ -170,21 +223,22 @@ func TestDBStorage_GetCards(t *testing.T) {
 
 			tt.setup(ds)
 			if tt.wantErr {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at FROM cards WHERE user_id=$1`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at, version FROM cards WHERE user_id=$1 and deleted_at IS NULL`)).
 					WithArgs("test").
 					WillReturnError(errors.New("some error"))
 				return
 			}
 
-			rows := sqlmock.NewRows([]string{"id", "number", "expiration_date", "holder_name", "cvv", "uploaded_at"})
+			rows := sqlmock.NewRows([]string{"id", "number", "expiration_date", "holder_name", "cvv", "uploaded_at", "version"})
 			for i := 0; i < tt.cardCount; i++ {
-				rows.AddRow("test_id", "1234567812345678", "12/34", "Test User", "123", time.Now())
+				rows.AddRow("test_id", "1234567812345678", "12/34", "Test User", "123", time.Now(), 1)
 			}
 
-			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at FROM cards WHERE user_id=$1`)).
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at, version FROM cards WHERE user_id=$1 and deleted_at IS NULL`)).
 				WithArgs("test").
 				WillReturnRows(rows).
 				WillReturnError(nil)
+			expectLoadMetadata(mock, cardItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
 
 			ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
 			cards, err := ds.GetCards(ctx)
@@ -212,19 +266,23 @@ func TestDBStorage_CreateCard(t *testing.T) {
 	}{
 		{
 			name: "Valid card",
-			card: models.NewCard("1234 5678 9012 3456", "12/24", "Test User", "123"),
+			card: models.Card{ID: "1", Number: "1234 5678 9012 3456", ExpirationDate: "12/24", HolderName: "Test User", CVV: "123"},
 			setup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO cards(number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5)`)).WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, uploaded_at FROM cards WHERE number=$1 and user_id=$2`)).WillReturnRows(sqlmock.NewRows([]string{"id", "uploaded_at"}).AddRow("1", timeNow))
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO cards(id, number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`)).WithArgs("1", "1234 5678 9012 3456", "12/24", "Test User", "123", "test").
+					WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(timeNow, 1))
+				mock.ExpectCommit()
 			},
-			want:    models.Card{ID: "1", Number: "1234 5678 9012 3456", ExpirationDate: "12/24", HolderName: "Test User", CVV: "123", UploadedAt: timeNow},
+			want:    models.Card{ID: "1", Number: "1234 5678 9012 3456", ExpirationDate: "12/24", HolderName: "Test User", CVV: "123", UploadedAt: timeNow, Version: 1},
 			wantErr: false,
 		},
 		{
 			name: "Card with same number already exists",
 			card: models.NewCard("9876 5432 1098 7654", "07/26", "User Test", "321"),
 			setup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO cards(number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5)`)).WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO cards(id, number, expiration_date, holder_name, cvv, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`)).WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+				mock.ExpectRollback()
 			},
 			want:    models.NewCard("9876 5432 1098 7654", "07/26", "User Test", "321"),
 			wantErr: true,
@@ -263,18 +321,22 @@ func TestDBStorage_DeleteCredentials(t *testing.T) {
 		{
 			name: "Valid Credentials ID",
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM credentials WHERE user_id=$1 and id=$2`)).
-					WithArgs(userID, "1").
+				expectRevision(mock, "credentials_history", "1", 1, true)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`)).
+					WithArgs(userID, "1", 1).
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
 		{
 			name: "Query Execution Error",
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM credentials WHERE user_id=$1 and id=$2`)).
-					WithArgs(userID, "9999").
+				expectRevision(mock, "credentials_history", "1", 1, true)
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`)).
+					WithArgs(userID, "1", 1).
 					WillReturnError(errors.New("some error"))
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
@@ -285,7 +347,7 @@ func TestDBStorage_DeleteCredentials(t *testing.T) {
 			db, mock, _ := sqlmock.New()
 			ds := &DBStorage{db: db}
 			tt.setup(ds, mock, ctx.Value(config.USERIDCONTEXTKEY).(string))
-			err := ds.DeleteCredentials(ctx, "1")
+			err := ds.DeleteCredentials(ctx, "1", 1)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("DeleteCredentials() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -294,6 +356,26 @@ func TestDBStorage_DeleteCredentials(t *testing.T) {
 	}
 }
 
+func TestDBStorage_VersionConflict(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	expectRevision(mock, "credentials_history", "1", 1, false).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	_, err := ds.UpdateCredentials(ctx, models.Credentials{ID: "1", ServiceName: "Gmail", Identity: "user@gmail.com", Password: "password123", Version: 1})
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	expectRevision(mock, "cards_history", "1", 1, true)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`)).
+		WithArgs("test", "1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, ds.DeleteCard(ctx, "1", 1), ErrVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet(), "history is not written when item was not changed")
+}
+
 func TestDBStorage_UpdateCredentials(t *testing.T) {
 	timeNow := time.Now()
 	tests := []struct {
@@ -311,14 +393,15 @@ func TestDBStorage_UpdateCredentials(t *testing.T) {
 				Identity:    "user@gmail.com",
 				Password:    "password123",
 				UploadedAt:  timeNow,
+				Version:     1,
 			},
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET service_name=$1, identity=$2, password=$3 WHERE user_id=$4 and id=$5`)).
-					WithArgs("Gmail", "user@gmail.com", "password123", userID, "1").
-					WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT uploaded_at FROM credentials WHERE id=$1 and user_id=$2`)).
-					WithArgs("1", userID).
-					WillReturnRows(sqlmock.NewRows([]string{"uploaded_at"}).AddRow(timeNow))
+				expectRevision(mock, "credentials_history", "1", 1, false)
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credentials SET service_name=$1, identity=$2, password=$3, totp=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+					WithArgs("Gmail", "user@gmail.com", "password123", "", userID, "1", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(timeNow, 2))
+				expectSaveMetadata(mock, "1")
+				mock.ExpectCommit()
 			},
 			want: models.Credentials{
 				ID:          "1",
@@ -326,6 +409,7 @@ func TestDBStorage_UpdateCredentials(t *testing.T) {
 				Identity:    "user@gmail.com",
 				Password:    "password123",
 				UploadedAt:  timeNow,
+				Version:     2,
 			},
 			wantErr: false,
 		},
@@ -339,9 +423,11 @@ func TestDBStorage_UpdateCredentials(t *testing.T) {
 				UploadedAt:  time.Now(),
 			},
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET service_name=$1, identity=$2, password=$3 WHERE user_id=$4 and id=$5`)).
-					WithArgs("Yahoo", "user@yahoo.com", "password456", userID, "9999").
+				expectRevision(mock, "credentials_history", "9999", 0, false)
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE credentials SET service_name=$1, identity=$2, password=$3, totp=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+					WithArgs("Yahoo", "user@yahoo.com", "password456", "", userID, "9999", 0).
 					WillReturnError(fmt.Errorf("some error"))
+				mock.ExpectRollback()
 			},
 			want:    models.Credentials{},
 			wantErr: true,
@@ -377,11 +463,12 @@ func TestDBStorage_GetCredentials(t *testing.T) {
 		{
 			name: "Valid Get",
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mockRows := sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "uploaded_at"}).
-					AddRow("1", "Service", "Identity", "Password", timeNow)
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, uploaded_at FROM credentials WHERE user_id=$1`)).
+				mockRows := sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "totp", "uploaded_at", "version"}).
+					AddRow("1", "Service", "Identity", "Password", "JBSWY3DPEHPK3PXP", timeNow, 1)
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`)).
 					WithArgs("test").
 					WillReturnRows(mockRows)
+				expectLoadMetadata(mock, credentialsItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
 			},
 			want: []models.Credentials{
 				{
@@ -389,7 +476,9 @@ func TestDBStorage_GetCredentials(t *testing.T) {
 					ServiceName: "Service",
 					Identity:    "Identity",
 					Password:    "Password",
+					TOTP:        "JBSWY3DPEHPK3PXP",
 					UploadedAt:  timeNow,
+					Version:     1,
 				},
 			},
 			wantErr: false,
@@ -397,7 +486,7 @@ func TestDBStorage_GetCredentials(t *testing.T) {
 		{
 			name: "Invalid Get",
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, uploaded_at FROM credentials WHERE user_id=$1`)).
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`)).
 					WithArgs("invalid").
 					WillReturnError(fmt.Errorf("some error"))
 			},
@@ -436,17 +525,17 @@ func TestDBStorage_CreateCredentials(t *testing.T) {
 		{
 			name: "Valid Insert",
 			credentials: models.Credentials{
+				ID:          "1",
 				ServiceName: "Facebook",
 				Identity:    "tester@facebook.com",
 				Password:    "testpassword",
 			},
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO credentials(service_name, identity, password, user_id) VALUES ($1, $2, $3, $4)`)).
-					WithArgs("Facebook", "tester@facebook.com", "testpassword", userID).
-					WillReturnResult(sqlmock.NewResult(1, 1))
-				mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, uploaded_at FROM credentials WHERE service_name=$1 and user_id=$2`)).
-					WithArgs("Facebook", userID).
-					WillReturnRows(sqlmock.NewRows([]string{"id", "uploaded_at"}).AddRow("1", timeNow))
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO credentials(id, service_name, identity, password, totp, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`)).
+					WithArgs("1", "Facebook", "tester@facebook.com", "testpassword", "", userID).
+					WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(timeNow, 1))
+				mock.ExpectCommit()
 			},
 			want: models.Credentials{
 				ID:          "1",
@@ -454,6 +543,7 @@ func TestDBStorage_CreateCredentials(t *testing.T) {
 				Identity:    "tester@facebook.com",
 				Password:    "testpassword",
 				UploadedAt:  timeNow,
+				Version:     1,
 			},
 			wantErr: false,
 		},
@@ -465,9 +555,11 @@ func TestDBStorage_CreateCredentials(t *testing.T) {
 				Password:    "testpassword",
 			},
 			setup: func(ds *DBStorage, mock sqlmock.Sqlmock, userID string) {
-				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO credentials(service_name, identity, password, user_id) VALUES ($1, $2, $3, $4)`)).
-					WithArgs("Twitter", "tester@twitter.com", "testpassword", userID).
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO credentials(id, service_name, identity, password, totp, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`)).
+					WithArgs(sqlmock.AnyArg(), "Twitter", "tester@twitter.com", "testpassword", "", userID).
 					WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+				mock.ExpectRollback()
 			},
 			want: models.Credentials{
 				ServiceName: "Twitter",
@@ -611,3 +703,612 @@ func TestDBStorage_CreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestDBStorage_GetVaultParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		want    models.VaultParams
+		wantErr bool
+	}{
+		{
+			name: "Initialized vault",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT vault_salt, vault_key_check, vault_key FROM users WHERE id=$1`)).
+					WithArgs("test").
+					WillReturnRows(sqlmock.NewRows([]string{"vault_salt", "vault_key_check", "vault_key"}).AddRow("salt", "check", "key"))
+			},
+			want:    models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"},
+			wantErr: false,
+		},
+		{
+			name: "Not initialized vault",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT vault_salt, vault_key_check, vault_key FROM users WHERE id=$1`)).
+					WithArgs("test").
+					WillReturnRows(sqlmock.NewRows([]string{"vault_salt", "vault_key_check", "vault_key"}).AddRow(nil, nil, nil))
+			},
+			want:    models.VaultParams{},
+			wantErr: false,
+		},
+		{
+			name: "Query Execution Error",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT vault_salt, vault_key_check, vault_key FROM users WHERE id=$1`)).
+					WithArgs("test").
+					WillReturnError(errors.New("some error"))
+			},
+			want:    models.VaultParams{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			ds := &DBStorage{db: db}
+			tt.setup(mock)
+			ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+			got, err := ds.GetVaultParams(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetVaultParams() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDBStorage_SetVaultParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Vault initialized",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET vault_salt=$1, vault_key_check=$2 WHERE id=$3 and vault_salt IS NULL`)).
+					WithArgs("salt", "check", "test").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Vault already initialized",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET vault_salt=$1, vault_key_check=$2 WHERE id=$3 and vault_salt IS NULL`)).
+					WithArgs("salt", "check", "test").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrAlreadyExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			ds := &DBStorage{db: db}
+			tt.setup(mock)
+			ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+			err := ds.SetVaultParams(ctx, models.VaultParams{Salt: "salt", KeyCheck: "check"})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDBStorage_ChangeVaultParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "Vault changed",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET vault_salt=$1, vault_key_check=$2, vault_key=$3 WHERE id=$4 and vault_key_check=$5`)).
+					WithArgs("salt", "check", "key", "test", "old check").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Vault changed meanwhile",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET vault_salt=$1, vault_key_check=$2, vault_key=$3 WHERE id=$4 and vault_key_check=$5`)).
+					WithArgs("salt", "check", "key", "test", "old check").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: ErrVaultChanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			ds := &DBStorage{db: db}
+			tt.setup(mock)
+			ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+			err := ds.ChangeVaultParams(ctx, "old check", models.VaultParams{Salt: "salt", KeyCheck: "check", WrappedKey: "key"})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// sealedArg - sqlmock argument matcher which remembers sealed column value
+type sealedArg struct {
+	value *string
+}
+
+func (a sealedArg) Match(v driver.Value) bool {
+	value, ok := v.(string)
+	if ok && isBoundColumn(value) {
+		*a.value = value
+	}
+	return ok && isBoundColumn(value)
+}
+
+func TestDBStorage_EncryptedCredentials(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	keys, err := NewKeyManager(newTestMasterKey(t))
+	require.NoError(t, err)
+	ds := &DBStorage{db: db, keys: keys}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	dataKey, err := keys.newDataKey()
+	require.NoError(t, err)
+	var identity, password, totp string
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(nil))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET data_key=$1 WHERE id=$2 and data_key IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), "test").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(dataKey))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO credentials(id, service_name, identity, password, totp, user_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING uploaded_at, version`)).
+		WithArgs(sqlmock.AnyArg(), "aws", sealedArg{&identity}, sealedArg{&password}, sealedArg{&totp}, "test").
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(time.Now(), 1))
+	mock.ExpectCommit()
+
+	created, err := ds.CreateCredentials(ctx, models.NewCredentials("aws", "identity", "password"))
+	require.NoError(t, err)
+	assert.Equal(t, "password", created.Password, "created credentials should be returned decrypted")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(dataKey))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "totp", "uploaded_at", "version"}).
+			AddRow(created.ID, "aws", identity, password, totp, time.Now(), 1).
+			AddRow("2", "legacy", "plain identity", "plain password", "", time.Now(), 1))
+	expectLoadMetadata(mock, credentialsItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
+
+	credentials, err := ds.GetCredentials(ctx)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	assert.Equal(t, "identity", credentials[0].Identity)
+	assert.Equal(t, "password", credentials[0].Password)
+	assert.Equal(t, "plain password", credentials[1].Password)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(dataKey))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "totp", "uploaded_at", "version"}).
+			AddRow("3", "aws", identity, password, totp, time.Now(), 1))
+	_, err = ds.GetCredentials(ctx)
+	assert.Error(t, err, "values copied to another row should not be decrypted")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_RotateMasterKey(t *testing.T) {
+	oldKey, newKey := newTestMasterKey(t), newTestMasterKey(t)
+	oldKeys, err := NewKeyManager(oldKey)
+	require.NoError(t, err)
+	oldWrapped, err := oldKeys.newDataKey()
+	require.NoError(t, err)
+
+	keys, err := NewKeyManager(newKey, oldKey)
+	require.NoError(t, err)
+	currentWrapped, err := keys.newDataKey()
+	require.NoError(t, err)
+
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db, keys: keys}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, data_key FROM users WHERE data_key IS NOT NULL`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "data_key"}).
+			AddRow("old", oldWrapped).
+			AddRow("current", currentWrapped))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET data_key=$1 WHERE id=$2 and data_key=$3`)).
+		WithArgs(sqlmock.AnyArg(), "old", oldWrapped).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	rotated, err := ds.RotateMasterKey(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, rotated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = (&DBStorage{db: db}).RotateMasterKey(context.Background())
+	assert.ErrorIs(t, err, ErrNoMasterKey)
+}
+
+func TestDBStorage_EncryptPlaintextRows(t *testing.T) {
+	keys, err := NewKeyManager(newTestMasterKey(t))
+	require.NoError(t, err)
+	wrapped, err := keys.newDataKey()
+	require.NoError(t, err)
+	aead, err := keys.dataCipher("test", wrapped)
+	require.NoError(t, err)
+	// sealedColumns - values of identity, password and totp of credentials sealed bound to row with given ID
+	sealedColumns := func(id string) (identity, password, totp string) {
+		identity, password, totp = "identity", "password", "totp"
+		require.NoError(t, sealColumns(aead, boundRow{table: "credentials", id: id, userID: "test"}, &identity, &password, &totp))
+		return
+	}
+	_, sealedPassword, sealedTOTP := sealedColumns("1")
+	sealedIdentity2, sealedPassword2, sealedTOTP2 := sealedColumns("2")
+	legacySealed, err := seal(aead, []byte("legacy"), nil)
+	require.NoError(t, err)
+
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db, keys: keys}
+	var identity, legacy string
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, id, identity, password, totp FROM credentials`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "id", "identity", "password", "totp"}).
+			AddRow("1", "test", "1", "identity", sealedPassword, sealedTOTP).
+			AddRow("2", "test", "2", sealedIdentity2, sealedPassword2, sealedTOTP2).
+			AddRow("3", "test", "3", sealedIdentity2, legacyColumnPrefix+legacySealed, ""))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(wrapped))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET identity=$1, password=$2, totp=$3 WHERE id=$4 and user_id=$5`)).
+		WithArgs(sealedArg{&identity}, sealedPassword, sealedTOTP, "1", "test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(wrapped))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials SET identity=$1, password=$2, totp=$3 WHERE id=$4 and user_id=$5`)).
+		WithArgs(sealedIdentity2, sealedArg{&legacy}, sealedArg{new(string)}, "3", "test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, id, number, expiration_date, holder_name, cvv FROM cards`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "id", "number", "expiration_date", "holder_name", "cvv"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, id, body FROM notes`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "id", "body"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, CAST(item_id AS TEXT) || '/' || key, value FROM item_metadata`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "row_id", "value"}))
+	var revisionPassword string
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, item_id, identity, password, totp FROM credentials_history`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "item_id", "identity", "password", "totp"}).
+			AddRow("r1", "test", "2", sealedIdentity2, "old password", sealedTOTP2))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT data_key FROM users WHERE id=$1`)).WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"data_key"}).AddRow(wrapped))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE credentials_history SET identity=$1, password=$2, totp=$3 WHERE id=$4 and user_id=$5`)).
+		WithArgs(sealedIdentity2, sealedArg{&revisionPassword}, sealedTOTP2, "r1", "test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, item_id, number, expiration_date, holder_name, cvv FROM cards_history`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "item_id", "number", "expiration_date", "holder_name", "cvv"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, user_id, item_id, body FROM notes_history`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "item_id", "body"}))
+
+	encrypted, err := ds.EncryptPlaintextRows(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, encrypted)
+	assert.NoError(t, openColumn(aead, boundRow{table: "credentials", id: "1", userID: "test"}, "identity", &identity))
+	assert.Equal(t, "identity", identity)
+	assert.NoError(t, openColumn(aead, boundRow{table: "credentials", id: "3", userID: "test"}, "password", &legacy))
+	assert.Equal(t, "legacy", legacy, "values sealed before binding are sealed again bound to their row")
+	assert.NoError(t, openColumn(aead, boundRow{table: "credentials", id: "2", userID: "test"}, "password", &revisionPassword))
+	assert.Equal(t, "old password", revisionPassword, "revisions are encrypted bound to their items")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_CreateNote(t *testing.T) {
+	timeNow := time.Now()
+	tests := []struct {
+		name    string
+		note    models.Note
+		setup   func(mock sqlmock.Sqlmock)
+		want    models.Note
+		wantErr bool
+	}{
+		{
+			name: "Valid note",
+			note: models.Note{ID: "1", Title: "wifi", Body: "secret"},
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO notes(id, title, body, user_id) VALUES ($1, $2, $3, $4) RETURNING uploaded_at, version`)).
+					WithArgs("1", "wifi", "secret", "test").
+					WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(timeNow, 1))
+				mock.ExpectCommit()
+			},
+			want: models.Note{ID: "1", Title: "wifi", Body: "secret", UploadedAt: timeNow, Version: 1},
+		},
+		{
+			name: "Insert error",
+			note: models.NewNote("wifi", "secret"),
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO notes(id, title, body, user_id) VALUES ($1, $2, $3, $4) RETURNING uploaded_at, version`)).
+					WillReturnError(errors.New("insert error"))
+				mock.ExpectRollback()
+			},
+			want:    models.NewNote("wifi", "secret"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			ds := &DBStorage{db: db}
+			tt.setup(mock)
+
+			ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+			note, err := ds.CreateNote(ctx, tt.note)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateNote() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, note)
+		})
+	}
+}
+
+func TestDBStorage_GetNotes(t *testing.T) {
+	timeNow := time.Now()
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, body, uploaded_at, version FROM notes WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "uploaded_at", "version"}).
+			AddRow("1", "wifi", "secret", timeNow, 3))
+	expectLoadMetadata(mock, noteItem).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}).AddRow("1", "env", "prod"))
+	notes, err := ds.GetNotes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Note{{ID: "1", Title: "wifi", Body: "secret", UploadedAt: timeNow,
+		Version: 3, Metadata: map[string]string{"env": "prod"}}}, notes)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, body, uploaded_at, version FROM notes WHERE user_id=$1 and deleted_at IS NULL`)).
+		WillReturnError(errors.New("query error"))
+	_, err = ds.GetNotes(ctx)
+	assert.Error(t, err)
+}
+
+func TestDBStorage_UpdateNote(t *testing.T) {
+	timeNow := time.Now()
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	expectRevision(mock, "notes_history", "1", 1, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE notes SET title=$1, body=$2, uploaded_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$3 and id=$4 and version=$5 RETURNING uploaded_at, version`)).
+		WithArgs("wifi", "new secret", "test", "1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(timeNow, 2))
+	expectSaveMetadata(mock, "1", "bank", "Tinkoff", "env", "prod")
+	mock.ExpectCommit()
+	metadata := map[string]string{"env": "prod", "bank": "Tinkoff"}
+	note, err := ds.UpdateNote(ctx, models.Note{ID: "1", Title: "wifi", Body: "new secret", Version: 1, Metadata: metadata})
+	require.NoError(t, err)
+	assert.Equal(t, models.Note{ID: "1", Title: "wifi", Body: "new secret", UploadedAt: timeNow, Version: 2, Metadata: metadata}, note)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	expectRevision(mock, "notes_history", "2", 1, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE notes SET title=$1, body=$2, uploaded_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$3 and id=$4 and version=$5 RETURNING uploaded_at, version`)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+	_, err = ds.UpdateNote(ctx, models.Note{ID: "2", Title: "wifi", Version: 1})
+	assert.ErrorIs(t, err, ErrVersionConflict, "note was changed since it was read")
+}
+
+func TestDBStorage_DeleteNote(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	expectRevision(mock, "notes_history", "1", 1, true)
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE notes SET deleted_at=CURRENT_TIMESTAMP, version=version+1 WHERE user_id=$1 and id=$2 and version=$3 and deleted_at IS NULL`)).
+		WithArgs("test", "1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	err := ds.DeleteNote(context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test"), "1", 1)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_ListItemHistory(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	revisedAt, uploadedAt := time.Now(), time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, body, metadata, version, uploaded_at, revised_at, deleted FROM notes_history WHERE user_id=$1 and item_id=$2 ORDER BY version DESC, revised_at DESC`)).
+		WithArgs("test", "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "metadata", "version", "uploaded_at", "revised_at", "deleted"}).
+			AddRow("r2", "wifi", "new secret", nil, 2, uploadedAt, revisedAt, true).
+			AddRow("r1", "wifi", "secret", `{"env": "prod"}`, 1, uploadedAt, revisedAt, false))
+
+	revisions, err := ds.ListItemHistory(ctx, noteItem, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Revision{
+		{ID: "r2", ItemID: "1", ItemType: noteItem, Version: 2, RevisedAt: revisedAt, Deleted: true,
+			Item: models.Note{ID: "1", Title: "wifi", Body: "new secret", UploadedAt: uploadedAt, Version: 2}},
+		{ID: "r1", ItemID: "1", ItemType: noteItem, Version: 1, RevisedAt: revisedAt,
+			Item: models.Note{ID: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt, Version: 1, Metadata: map[string]string{"env": "prod"}}},
+	}, revisions)
+
+	_, err = ds.ListItemHistory(ctx, "file", "1")
+	assert.ErrorIs(t, err, ErrUnknownItemType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_RestoreItemRevision(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	expectHistory := func() {
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, metadata, version, uploaded_at, revised_at, deleted FROM cards_history WHERE user_id=$1 and item_id=$2`)).
+			WithArgs("test", "1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "number", "expiration_date", "holder_name", "cvv", "metadata", "version", "uploaded_at", "revised_at", "deleted"}).
+				AddRow("r1", "1234", "12/24", "Old Holder", "123", nil, 1, time.Now(), time.Now(), false))
+	}
+
+	expectHistory()
+	expectRevision(mock, "cards_history", "1", 3, false)
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE cards SET number=$1, expiration_date=$2, holder_name=$3, cvv=$4, version=version+1 WHERE user_id=$5 and id=$6 and version=$7 RETURNING uploaded_at, version`)).
+		WithArgs("1234", "12/24", "Old Holder", "123", "test", "1", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at", "version"}).AddRow(time.Now(), 4))
+	expectSaveMetadata(mock, "1")
+	mock.ExpectCommit()
+
+	version, err := ds.RestoreItemRevision(ctx, cardItem, "1", "r1", 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), version, "restore is saved as a new version")
+
+	expectHistory()
+	_, err = ds.RestoreItemRevision(ctx, cardItem, "1", "r9", 4)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_ListTrash(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	uploadedAt, deletedAt := time.Now().Add(-time.Hour), time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version, deleted_at FROM credentials WHERE user_id=$1 and deleted_at IS NOT NULL ORDER BY deleted_at DESC`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "totp", "uploaded_at", "version", "deleted_at"}).
+			AddRow("1", "aws", "admin", "secret", "", uploadedAt, 2, deletedAt))
+	expectLoadMetadata(mock, credentialsItem).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}).AddRow("1", "env", "prod"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at, version, deleted_at FROM cards WHERE user_id=$1 and deleted_at IS NOT NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "expiration_date", "holder_name", "cvv", "uploaded_at", "version", "deleted_at"}))
+	expectLoadMetadata(mock, cardItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, body, uploaded_at, version, deleted_at FROM notes WHERE user_id=$1 and deleted_at IS NOT NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "uploaded_at", "version", "deleted_at"}).
+			AddRow("2", "wifi", "secret", uploadedAt, 1, deletedAt))
+	expectLoadMetadata(mock, noteItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
+
+	items, err := ds.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.TrashItem{
+		{ItemType: credentialsItem, DeletedAt: deletedAt, Item: models.Credentials{ID: "1", ServiceName: "aws", Identity: "admin",
+			Password: "secret", UploadedAt: uploadedAt, Version: 2, Metadata: map[string]string{"env": "prod"}}},
+		{ItemType: noteItem, DeletedAt: deletedAt, Item: models.Note{ID: "2", Title: "wifi", Body: "secret", UploadedAt: uploadedAt, Version: 1}},
+	}, items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_Sync(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	uploadedAt := time.Now()
+	changedNotes := `SELECT id, title, body, uploaded_at, version FROM notes WHERE user_id=$1 and deleted_at IS NULL and CAST(id AS TEXT) IN (SELECT item_id FROM changes WHERE user_id=$1 and item_type=$2 and seq>$3)`
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM changes WHERE user_id=$1`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(9))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT item_type, item_id FROM changes WHERE user_id=$1 and seq>$2 GROUP BY item_type, item_id ORDER BY MAX(seq)`)).
+		WithArgs("test", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"item_type", "item_id"}).
+			AddRow(noteItem, "1").AddRow(models.FileItem, "report.pdf").AddRow(noteItem, "2"))
+	mock.ExpectQuery(regexp.QuoteMeta(changedNotes)).
+		WithArgs("test", noteItem, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "uploaded_at", "version"}).
+			AddRow("1", "wifi", "secret", uploadedAt, 3))
+	expectLoadMetadata(mock, noteItem).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}).AddRow("1", "env", "home"))
+
+	changes, err := ds.Sync(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, models.ChangeSet{
+		Cursor: 9,
+		Notes: []models.Note{{ID: "1", Title: "wifi", Body: "secret", UploadedAt: uploadedAt, Version: 3,
+			Metadata: map[string]string{"env": "home"}}},
+		Files:   []models.File{{Name: "report.pdf"}},
+		Deleted: []models.Change{{ItemType: noteItem, ItemID: "2"}},
+	}, changes, "items which are not found are deleted or in trash")
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(MAX(seq), 0) FROM changes WHERE user_id=$1`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, service_name, identity, password, totp, uploaded_at, version FROM credentials WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "service_name", "identity", "password", "totp", "uploaded_at", "version"}).
+			AddRow("1", "aws", "admin", "secret", "", uploadedAt, 1))
+	expectLoadMetadata(mock, credentialsItem).WillReturnRows(sqlmock.NewRows([]string{"item_id", "key", "value"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, number, expiration_date, holder_name, cvv, uploaded_at, version FROM cards WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "number", "expiration_date", "holder_name", "cvv", "uploaded_at", "version"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, body, uploaded_at, version FROM notes WHERE user_id=$1 and deleted_at IS NULL`)).
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "uploaded_at", "version"}))
+
+	changes, err = ds.Sync(ctx, 7)
+	require.NoError(t, err)
+	assert.Equal(t, models.ChangeSet{
+		Cursor: 3,
+		Full:   true,
+		Credentials: []models.Credentials{{ID: "1", ServiceName: "aws", Identity: "admin", Password: "secret",
+			UploadedAt: uploadedAt, Version: 1}},
+	}, changes, "unknown cursor requests full copy of vault")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBStorage_RestoreFromTrash(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE notes SET deleted_at=NULL, version=version+1 WHERE user_id=$1 and id=$2 and deleted_at IS NOT NULL`)).
+		WithArgs("test", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, ds.RestoreFromTrash(ctx, noteItem, "1"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE cards SET deleted_at=NULL, version=version+1 WHERE user_id=$1 and id=$2 and deleted_at IS NOT NULL`)).
+		WithArgs("test", "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, ds.RestoreFromTrash(ctx, cardItem, "2"), ErrNotFound, "item is not in trash")
+	assert.ErrorIs(t, ds.RestoreFromTrash(ctx, "file", "3"), ErrUnknownItemType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectPurge - expect trashed items matching condition to be deleted with their metadata and history
+func expectPurge(mock sqlmock.Sqlmock, condition string, arg any, purged int64) {
+	mock.ExpectBegin()
+	for _, table := range []string{"credentials", "cards", "notes"} {
+		trashed := `SELECT id FROM ` + table + ` WHERE deleted_at IS NOT NULL and ` + condition
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM item_metadata WHERE item_id IN (` + trashed + `)`)).
+			WithArgs(arg).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM ` + table + `_history WHERE item_id IN (` + trashed + `)`)).
+			WithArgs(arg).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM ` + table + ` WHERE deleted_at IS NOT NULL and ` + condition)).
+			WithArgs(arg).
+			WillReturnResult(sqlmock.NewResult(0, purged))
+	}
+	mock.ExpectCommit()
+}
+
+func TestDBStorage_PurgeTrash(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	ds := &DBStorage{db: db}
+	ctx := context.WithValue(context.Background(), config.USERIDCONTEXTKEY, "test")
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	expectPurge(mock, `deleted_at<$1`, before, 2)
+	purged, err := ds.PurgeTrash(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, 6, purged)
+
+	expectPurge(mock, `user_id=$1`, "test", 1)
+	assert.NoError(t, ds.EmptyTrash(ctx))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM item_metadata`)).WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+	assert.Error(t, ds.EmptyTrash(ctx))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
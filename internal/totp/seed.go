@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// ErrInvalidSeed - seed is neither base32 secret nor otpauth://totp/ URI
+var ErrInvalidSeed = errors.New("TOTP seed must be base32 secret or otpauth://totp/ URI")
+
+const (
+	uriScheme     = "otpauth://"
+	defaultPeriod = 30
+)
+
+// seed - parameters of codes of stored account, authenticator apps assume defaults of RFC 6238 for bare secret
+type seed struct {
+	secret string
+	opts   totp.ValidateOpts
+}
+
+// parseSeed - parse seed of stored account, it is either otpauth:// URI or base32 secret
+func parseSeed(value string) (seed, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(strings.ToLower(value), uriScheme) {
+		key, err := otp.NewKeyFromURL(value)
+		if err != nil || key.Type() != "totp" || key.Secret() == "" {
+			return seed{}, ErrInvalidSeed
+		}
+		parsed := seed{secret: key.Secret(), opts: totp.ValidateOpts{
+			Period:    uint(key.Period()),
+			Digits:    key.Digits(),
+			Algorithm: key.Algorithm(),
+		}}
+		return parsed, validateSecret(parsed.secret)
+	}
+
+	secret := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(value))
+	return seed{secret: secret, opts: totp.ValidateOpts{Period: defaultPeriod, Digits: otp.DigitsSix}}, validateSecret(secret)
+}
+
+// validateSecret - check secret is base32 encoded, padding is optional as authenticator apps omit it
+func validateSecret(secret string) error {
+	if secret == "" {
+		return ErrInvalidSeed
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(strings.ToUpper(secret), "=")); err != nil {
+		return ErrInvalidSeed
+	}
+	return nil
+}
+
+// NormalizeSeed - validate seed entered by user and return form it is stored in, URI is kept whole since it may
+// change period, digits or algorithm of codes
+func NormalizeSeed(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	parsed, err := parseSeed(value)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(strings.ToLower(value), uriScheme) {
+		return value, nil
+	}
+	return parsed.secret, nil
+}
+
+// CurrentCode - code of seed at given time and how long it stays valid
+func CurrentCode(value string, t time.Time) (code string, remaining time.Duration, err error) {
+	parsed, err := parseSeed(value)
+	if err != nil {
+		return "", 0, err
+	}
+	code, err = totp.GenerateCodeCustom(parsed.secret, t, parsed.opts)
+	if err != nil {
+		return "", 0, err
+	}
+	period := int64(parsed.opts.Period)
+	remaining = time.Duration(period-t.Unix()%period) * time.Second
+	return code, remaining, nil
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret - base32 of "12345678901234567890", secret of test vectors of RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCurrentCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		seed          string
+		at            time.Time
+		wantCode      string
+		wantRemaining time.Duration
+		wantErr       bool
+	}{
+		{
+			name:          "Base32 secret",
+			seed:          rfcSecret,
+			at:            time.Unix(59, 0),
+			wantCode:      "287082",
+			wantRemaining: time.Second,
+		},
+		{
+			name:          "Secret typed in groups",
+			seed:          "gezd gnbv gy3t qojq gezd gnbv gy3t qojq",
+			at:            time.Unix(1111111109, 0),
+			wantCode:      "081804",
+			wantRemaining: time.Second,
+		},
+		{
+			name:          "URI with 8 digits",
+			seed:          "otpauth://totp/Example:alice@example.com?secret=" + rfcSecret + "&digits=8&issuer=Example",
+			at:            time.Unix(59, 0),
+			wantCode:      "94287082",
+			wantRemaining: time.Second,
+		},
+		{
+			name:          "URI with 60 seconds period",
+			seed:          "otpauth://totp/Example:alice@example.com?secret=" + rfcSecret + "&period=60",
+			at:            time.Unix(59, 0),
+			wantCode:      "755224",
+			wantRemaining: time.Second,
+		},
+		{name: "HOTP URI", seed: "otpauth://hotp/Example:alice@example.com?secret=" + rfcSecret + "&counter=1", wantErr: true},
+		{name: "Not base32", seed: "not a secret!", wantErr: true},
+		{name: "Empty", seed: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, remaining, err := CurrentCode(tt.seed, tt.at)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CurrentCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if code != tt.wantCode || remaining != tt.wantRemaining {
+				t.Errorf("CurrentCode() = %q, %v, want %q, %v", code, remaining, tt.wantCode, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestNormalizeSeed(t *testing.T) {
+	uri := "otpauth://totp/Example:alice@example.com?secret=" + rfcSecret
+	if got, err := NormalizeSeed(" " + uri + " "); err != nil || got != uri {
+		t.Errorf("NormalizeSeed() = %q, %v, want URI kept whole", got, err)
+	}
+	if got, err := NormalizeSeed("gezd-gnbv gy3t qojq"); err != nil || got != "GEZDGNBVGY3TQOJQ" {
+		t.Errorf("NormalizeSeed() = %q, %v, want GEZDGNBVGY3TQOJQ", got, err)
+	}
+	if _, err := NormalizeSeed("otpauth://totp/Example"); err == nil {
+		t.Errorf("NormalizeSeed() of URI without secret error = nil")
+	}
+}
@@ -0,0 +1,69 @@
+// Package totp implements time-based one-time passwords (RFC 6238), which are second factor of sign in
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// Issuer - name under which account is shown in authenticator app
+	Issuer = "GophKeeper"
+	// RecoveryCodesCount - amount of recovery codes issued on enrollment
+	RecoveryCodesCount = 10
+
+	recoveryCodeLength = 10
+)
+
+// Key - secret shared with authenticator app and URI by which the app is provisioned
+type Key struct {
+	Secret string
+	URI    string
+}
+
+// Generate - new secret of account, the URI is shown to user as QR code
+func Generate(account string) (Key, error) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: Issuer, AccountName: account})
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+// Validate - check code against secret, codes of neighbour periods are accepted to tolerate clock skew
+func Validate(code, secret string) bool {
+	return totp.Validate(strings.TrimSpace(code), secret)
+}
+
+// GenerateCode - code of secret at given time
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return totp.GenerateCode(secret, t)
+}
+
+// NewRecoveryCodes - one-time codes which replace authenticator app when it is lost
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodesCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw)[:recoveryCodeLength])
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode - hash under which recovery code is stored, case and dashes typed by user do not matter
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,63 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	key, err := Generate("user@example.com")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(key.URI, "otpauth://totp/GophKeeper:user@example.com?") || !strings.Contains(key.URI, key.Secret) {
+		t.Errorf("Generate() URI = %q, want provisioning URI of secret", key.URI)
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name  string
+		at    time.Time
+		valid bool
+	}{
+		{name: "Current period", at: now, valid: true},
+		{name: "Previous period", at: now.Add(-30 * time.Second), valid: true},
+		{name: "Expired code", at: now.Add(-5 * time.Minute), valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := GenerateCode(key.Secret, tt.at)
+			if err != nil {
+				t.Fatalf("GenerateCode() error = %v", err)
+			}
+			if got := Validate(" "+code+" ", key.Secret); got != tt.valid {
+				t.Errorf("Validate() = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("NewRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != RecoveryCodesCount {
+		t.Fatalf("NewRecoveryCodes() = %d codes, want %d", len(codes), RecoveryCodesCount)
+	}
+	unique := make(map[string]bool)
+	for _, code := range codes {
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Errorf("NewRecoveryCodes() code = %q, want xxxxx-xxxxx", code)
+		}
+		unique[HashRecoveryCode(code)] = true
+	}
+	if len(unique) != RecoveryCodesCount {
+		t.Errorf("NewRecoveryCodes() codes are not unique: %v", codes)
+	}
+
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))) {
+		t.Errorf("HashRecoveryCode() depends on case and dashes")
+	}
+}
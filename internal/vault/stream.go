@@ -0,0 +1,152 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Parameters of file encryption. Files are encrypted with random per-file key by STREAM construction over AES-GCM:
+// content is split into chunks, each chunk is sealed with nonce built from random prefix, chunk counter and flag
+// of last chunk, so chunks can not be reordered, dropped or truncated unnoticed. Every chunk except the last one
+// carries exactly streamChunkSize bytes of content.
+const (
+	fileKeyLength         = 32
+	streamChunkSize       = 64 * 1024
+	streamNoncePrefixSize = 7
+)
+
+var (
+	// ErrTruncatedStream - error when encrypted stream ends before its last chunk
+	ErrTruncatedStream = errors.New("encrypted stream is truncated")
+	// ErrMalformedKey - error when wrapped file key can not be unwrapped
+	ErrMalformedKey = errors.New("malformed file key")
+)
+
+// NewFileKey - generate random key for file encryption
+func NewFileKey() ([]byte, error) {
+	key := make([]byte, fileKeyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// WrapKey - encrypt file key with vault key bound to file, so it can be stored next to file
+func (c *Cipher) WrapKey(key []byte, binding string) (string, error) {
+	return c.Seal(base64.StdEncoding.EncodeToString(key), binding)
+}
+
+// UnwrapKey - decrypt file key wrapped by WrapKey with the same binding
+func (c *Cipher) UnwrapKey(wrapped, binding string) ([]byte, error) {
+	if !IsSealed(wrapped) {
+		return nil, ErrMalformedKey
+	}
+	encoded, err := c.Open(wrapped, binding)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != fileKeyLength {
+		return nil, ErrMalformedKey
+	}
+	return key, nil
+}
+
+// streamNonce - nonce of chunk with counter, the last chunk gets distinct nonce
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, streamNoncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+func newFileAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptStream - encrypt content of src with file key into dst
+func EncryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newFileAEAD(key)
+	if err != nil {
+		return err
+	}
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err = rand.Read(prefix); err != nil {
+		return err
+	}
+	if _, err = dst.Write(prefix); err != nil {
+		return err
+	}
+
+	buffer := make([]byte, streamChunkSize, streamChunkSize+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(src, buffer)
+		last := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !last {
+			return err
+		}
+		if counter == math.MaxUint32 && !last {
+			return fmt.Errorf("file is too large to encrypt")
+		}
+
+		if _, err = dst.Write(aead.Seal(buffer[:0], streamNonce(prefix, counter, last), buffer[:n], nil)); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// DecryptStream - decrypt content encrypted by EncryptStream from src into dst, dst receives only authenticated
+// chunks, but content written before error should be discarded
+func DecryptStream(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newFileAEAD(key)
+	if err != nil {
+		return err
+	}
+	prefix := make([]byte, streamNoncePrefixSize)
+	if _, err = io.ReadFull(src, prefix); err != nil {
+		return ErrTruncatedStream
+	}
+
+	buffer := make([]byte, streamChunkSize+aead.Overhead())
+	for counter := uint32(0); ; counter++ {
+		n, err := io.ReadFull(src, buffer)
+		if err == io.EOF {
+			return ErrTruncatedStream
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		last := n < len(buffer)
+		plaintext, err := aead.Open(buffer[:0], streamNonce(prefix, counter, last), buffer[:n], nil)
+		if err != nil {
+			return fmt.Errorf("chunk %d can not be decrypted: %w", counter, err)
+		}
+		if _, err = dst.Write(plaintext); err != nil {
+			return err
+		}
+		if last {
+			return nil
+		}
+		if counter == math.MaxUint32 {
+			return ErrTruncatedStream
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package vault
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher_WrapKey(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+
+	key, err := NewFileKey()
+	require.NoError(t, err)
+	binding := Binding("files", "name", "file_key")
+	wrapped, err := c.WrapKey(key, binding)
+	require.NoError(t, err)
+	assert.True(t, IsSealed(wrapped))
+
+	unwrapped, err := c.UnwrapKey(wrapped, binding)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	_, err = newTestCipher(t, "other", salt).UnwrapKey(wrapped, binding)
+	assert.Error(t, err, "key wrapped with other master password should not be unwrapped")
+	_, err = c.UnwrapKey(wrapped, Binding("files", "other", "file_key"))
+	assert.Error(t, err, "key wrapped for other file should not be unwrapped")
+	_, err = c.UnwrapKey("plain key", binding)
+	assert.ErrorIs(t, err, ErrMalformedKey)
+	short, _ := c.Seal("c2hvcnQ=", binding)
+	_, err = c.UnwrapKey(short, binding)
+	assert.ErrorIs(t, err, ErrMalformedKey)
+}
+
+func TestEncryptStream(t *testing.T) {
+	key, _ := NewFileKey()
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, 3*streamChunkSize + 5} {
+		content := bytes.Repeat([]byte{0x42}, size)
+
+		var encrypted bytes.Buffer
+		require.NoError(t, EncryptStream(&encrypted, bytes.NewReader(content), key))
+		assert.Equal(t, streamNoncePrefixSize+size+(size/streamChunkSize+1)*16, encrypted.Len(), "size %d", size)
+
+		var decrypted bytes.Buffer
+		require.NoError(t, DecryptStream(&decrypted, bytes.NewReader(encrypted.Bytes()), key), "size %d", size)
+		assert.True(t, bytes.Equal(content, decrypted.Bytes()), "size %d", size)
+	}
+}
+
+func TestDecryptStream_Tampered(t *testing.T) {
+	key, _ := NewFileKey()
+	content := bytes.Repeat([]byte("gophkeeper"), streamChunkSize/5)
+	var encrypted bytes.Buffer
+	require.NoError(t, EncryptStream(&encrypted, bytes.NewReader(content), key))
+	sealed := encrypted.Bytes()
+	chunk := streamChunkSize + 16
+
+	otherKey, _ := NewFileKey()
+	assert.Error(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(sealed), otherKey), "other key")
+
+	truncated := sealed[:streamNoncePrefixSize+chunk]
+	assert.ErrorIs(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(truncated), key), ErrTruncatedStream)
+	assert.Error(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(sealed[:len(sealed)-1]), key), "cut last chunk")
+
+	corrupted := bytes.Clone(sealed)
+	corrupted[streamNoncePrefixSize+10] ^= 1
+	assert.Error(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(corrupted), key), "corrupted chunk")
+
+	reordered := bytes.Clone(sealed)
+	copy(reordered[streamNoncePrefixSize:], sealed[streamNoncePrefixSize+chunk:streamNoncePrefixSize+2*chunk])
+	copy(reordered[streamNoncePrefixSize+chunk:], sealed[streamNoncePrefixSize:streamNoncePrefixSize+chunk])
+	assert.Error(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(reordered), key), "reordered chunks")
+
+	assert.ErrorIs(t, DecryptStream(&bytes.Buffer{}, bytes.NewReader(nil), key), ErrTruncatedStream)
+}
@@ -0,0 +1,153 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Parameters of master key derivation (Argon2id) and sealed values format. Values of legacyPrefix were sealed
+// without binding and are only opened, so they are resealed by client on unlock.
+const (
+	saltLength    = 16
+	keyLength     = 32
+	argonTime     = 3
+	argonMemory   = 64 * 1024
+	argonThreads  = 4
+	sealedPrefix  = "gk2:"
+	legacyPrefix  = "gk1:"
+	keyCheckValue = "GophKeeper vault key check"
+)
+
+// keyCheckBinding - binding of key check value, vaultKeyBinding - binding of vault key wrapped by master password
+var (
+	keyCheckBinding = Binding("key_check")
+	vaultKeyBinding = Binding("vault_key")
+)
+
+var (
+	// ErrWrongMasterPassword - error when master password does not match vault key check
+	ErrWrongMasterPassword = errors.New("wrong master password")
+	// ErrMalformedValue - error when sealed value can not be decoded
+	ErrMalformedValue = errors.New("malformed sealed value")
+)
+
+// Cipher - AES-GCM cipher over key derived from user's master password
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewSalt - generate new random per-user salt encoded as base64 string
+func NewSalt() (string, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(salt), nil
+}
+
+// DeriveKey - derive vault key from master password and base64 encoded salt with Argon2id
+func DeriveKey(masterPassword, salt string) ([]byte, error) {
+	rawSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("salt can not be decoded: %w", err)
+	}
+	return argon2.IDKey([]byte(masterPassword), rawSalt, argonTime, argonMemory, argonThreads, keyLength), nil
+}
+
+// NewCipher - create Cipher from vault key
+func NewCipher(key []byte) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// IsSealed - check if value was sealed by Cipher
+func IsSealed(value string) bool {
+	return IsBound(value) || strings.HasPrefix(value, legacyPrefix)
+}
+
+// IsBound - check if value was sealed by Cipher together with its binding
+func IsBound(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Binding - build binding of sealed value from kind and ID of item it belongs to and name of its field
+func Binding(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// Seal - encrypt value and encode it as printable string. Binding is authenticated as additional data, so value
+// can not be opened with binding of other item or field.
+func (c *Cipher) Seal(plaintext, binding string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(binding))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open - decrypt value sealed by Seal with the same binding, values stored before vault was initialized are
+// returned as is and legacy values are opened without binding
+func (c *Cipher) Open(value, binding string) (string, error) {
+	var additionalData []byte
+	switch {
+	case IsBound(value):
+		value, additionalData = strings.TrimPrefix(value, sealedPrefix), []byte(binding)
+	case strings.HasPrefix(value, legacyPrefix):
+		value = strings.TrimPrefix(value, legacyPrefix)
+	default:
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrMalformedValue
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return "", fmt.Errorf("value can not be decrypted: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// KeyCheck - build value which is stored on server to verify master password on unlock
+func (c *Cipher) KeyCheck() (string, error) {
+	return c.Seal(keyCheckValue, keyCheckBinding)
+}
+
+// VerifyKeyCheck - check that Cipher was built from the same master password as key check
+func (c *Cipher) VerifyKeyCheck(keyCheck string) error {
+	if !IsSealed(keyCheck) {
+		return ErrWrongMasterPassword
+	}
+	value, err := c.Open(keyCheck, keyCheckBinding)
+	if err != nil || value != keyCheckValue {
+		return ErrWrongMasterPassword
+	}
+	return nil
+}
+
+// WrapVaultKey - wrap vault key with Cipher built from master password, so master password can be changed
+// without re-encryption of items
+func (c *Cipher) WrapVaultKey(key []byte) (string, error) {
+	return c.WrapKey(key, vaultKeyBinding)
+}
+
+// UnwrapVaultKey - unwrap vault key wrapped by WrapVaultKey
+func (c *Cipher) UnwrapVaultKey(wrapped string) ([]byte, error) {
+	return c.UnwrapKey(wrapped, vaultKeyBinding)
+}
@@ -0,0 +1,133 @@
+package vault
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T, masterPassword, salt string) *Cipher {
+	key, err := DeriveKey(masterPassword, salt)
+	require.NoError(t, err)
+	c, err := NewCipher(key)
+	require.NoError(t, err)
+	return c
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt, err := NewSalt()
+	require.NoError(t, err)
+
+	first, err := DeriveKey("master", salt)
+	require.NoError(t, err)
+	second, err := DeriveKey("master", salt)
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "same password and salt should give same key")
+	assert.Len(t, first, keyLength)
+
+	otherSalt, _ := NewSalt()
+	third, err := DeriveKey("master", otherSalt)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, third, "different salts should give different keys")
+
+	_, err = DeriveKey("master", "not base64 !")
+	assert.Error(t, err)
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "Password", value: "myPassword"},
+		{name: "Empty", value: ""},
+		{name: "Unicode", value: "пароль Σ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := c.Seal(tt.value, Binding("note", "id", "body"))
+			require.NoError(t, err)
+			assert.True(t, IsSealed(sealed))
+			assert.True(t, IsBound(sealed))
+			assert.NotEqual(t, tt.value, sealed)
+			opened, err := c.Open(sealed, Binding("note", "id", "body"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, opened)
+		})
+	}
+}
+
+func TestCipher_OpenLegacyAndMalformed(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+
+	opened, err := c.Open("plain value", "")
+	require.NoError(t, err)
+	assert.Equal(t, "plain value", opened, "legacy values should be returned as is")
+
+	nonce := make([]byte, c.aead.NonceSize())
+	legacy := legacyPrefix + base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte("secret"), nil))
+	assert.True(t, IsSealed(legacy))
+	assert.False(t, IsBound(legacy))
+	opened, err = c.Open(legacy, Binding("note", "id", "body"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", opened, "values sealed without binding should be opened")
+
+	_, err = c.Open(sealedPrefix+"@@@", "")
+	assert.ErrorIs(t, err, ErrMalformedValue)
+
+	other := newTestCipher(t, "other", salt)
+	sealed, _ := other.Seal("secret", "")
+	_, err = c.Open(sealed, "")
+	assert.Error(t, err, "value sealed with other key should not be opened")
+}
+
+func TestCipher_OpenOtherBinding(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+
+	sealed, err := c.Seal("secret", Binding("credentials", "first", "password"))
+	require.NoError(t, err)
+	_, err = c.Open(sealed, Binding("credentials", "first", "identity"))
+	assert.Error(t, err, "value should not be opened as other field")
+	_, err = c.Open(sealed, Binding("credentials", "second", "password"))
+	assert.Error(t, err, "value should not be opened as field of other item")
+	_, err = c.Open(sealed, Binding("cards", "first", "password"))
+	assert.Error(t, err, "value should not be opened as field of other kind of item")
+}
+
+func TestCipher_KeyCheck(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+	keyCheck, err := c.KeyCheck()
+	require.NoError(t, err)
+
+	assert.NoError(t, newTestCipher(t, "master", salt).VerifyKeyCheck(keyCheck))
+	assert.ErrorIs(t, newTestCipher(t, "wrong", salt).VerifyKeyCheck(keyCheck), ErrWrongMasterPassword)
+	assert.ErrorIs(t, c.VerifyKeyCheck("plain"), ErrWrongMasterPassword)
+}
+
+func TestCipher_WrapVaultKey(t *testing.T) {
+	salt, _ := NewSalt()
+	c := newTestCipher(t, "master", salt)
+	key, err := DeriveKey("old master", salt)
+	require.NoError(t, err)
+
+	wrapped, err := c.WrapVaultKey(key)
+	require.NoError(t, err)
+	unwrapped, err := newTestCipher(t, "master", salt).UnwrapVaultKey(wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	_, err = newTestCipher(t, "wrong", salt).UnwrapVaultKey(wrapped)
+	assert.Error(t, err, "vault key should not be unwrapped with other master password")
+	keyCheck, err := c.KeyCheck()
+	require.NoError(t, err)
+	_, err = c.UnwrapVaultKey(keyCheck)
+	assert.Error(t, err, "key check should not be unwrapped as vault key")
+}